@@ -50,7 +50,20 @@ func setupRoutes(router *gin.Engine) {
 	dashboardHandler := handlers.NewDashboardHandler()
 	customerHandler := handlers.NewCustomerHandler()
 	reportHandler := handlers.NewReportHandler()
-	categoryHandler := handlers.NewCategoryHandler() // <-- [BARU] Inisialisasi Handler Kategori
+	categoryHandler := handlers.NewCategoryHandler()           // <-- [BARU] Inisialisasi Handler Kategori
+	accountingHandler := handlers.NewAccountingHandler()       // <-- [BARU] Bagan akun & jurnal
+	fiscalPeriodHandler := handlers.NewFiscalPeriodHandler()   // <-- [BARU] Tutup buku periode akuntansi
+	taxHandler := handlers.NewTaxHandler()                     // <-- [BARU] Tarif pajak & laporan PPN
+	promotionHandler := handlers.NewPromotionHandler()         // <-- [BARU] Promo & pratinjau harga
+	stockOpnameHandler := handlers.NewStockOpnameHandler()     // <-- [BARU] Stok opname
+	costingHandler := handlers.NewCostingHandler()             // <-- [BARU] Penilaian persediaan (HPP)
+	batchHandler := handlers.NewBatchHandler()                 // <-- [BARU] Batch/lot & kedaluwarsa
+	locationHandler := handlers.NewLocationHandler()           // <-- [BARU] Multi lokasi & mutasi stok
+	compositeHandler := handlers.NewCompositeHandler()         // <-- [BARU] Resep produk komposit
+	unitHandler := handlers.NewUnitHandler()                   // <-- [BARU] Satuan produk & konversi
+	purchaseOrderHandler := handlers.NewPurchaseOrderHandler() // <-- [BARU] Pesanan pembelian & penerimaan barang
+	supplierHandler := handlers.NewSupplierHandler()           // <-- [BARU] Supplier (pihak lawan pembelian)
+	loyaltyHandler := handlers.NewLoyaltyHandler()             // <-- [BARU] Program poin loyalitas pelanggan
 
 	// --- Rute Halaman Web (Frontend) ---
 	// Grup ini menangani penyajian file HTML
@@ -205,12 +218,25 @@ func setupRoutes(router *gin.Engine) {
 			protected.PUT("/profile", handlers.UpdateProfile)
 			protected.PUT("/password", handlers.UpdatePassword)
 
+			// [BARU] Pengaturan metode penilaian persediaan (HPP)
+			protected.GET("/settings/costing-method", costingHandler.GetCostingMethod)
+			protected.PUT("/settings/costing-method", costingHandler.UpdateCostingMethod)
+
 			// Rute Produk (Tahap 3)
 			protected.POST("/products", productHandler.CreateProduct)
 			protected.GET("/products", productHandler.GetUserProducts)
 			protected.GET("/products/:id", productHandler.GetProductByID)
 			protected.PUT("/products/:id", productHandler.UpdateProduct)
 			protected.DELETE("/products/:id", productHandler.DeleteProduct)
+			protected.GET("/products/:id/stock-card", productHandler.GetStockCard)           // [BARU] Kartu stok
+			protected.GET("/products/:id/cost-layers", costingHandler.GetCostLayers)         // [BARU] Lapisan biaya FIFO
+			protected.GET("/products/:id/batches", batchHandler.GetProductBatches)           // [BARU] Batch/lot produk
+			protected.POST("/product-batches/:id/write-off", batchHandler.WriteOffBatch)     // [BARU] Pemusnahan batch
+			protected.GET("/products/:id/stocks", locationHandler.GetProductStocks)          // [BARU] Stok per lokasi
+			protected.GET("/products/:id/components", compositeHandler.GetProductComponents) // [BARU] Resep produk komposit
+			protected.PUT("/products/:id/components", compositeHandler.SetProductComponents) // [BARU] Atur resep produk komposit
+			protected.GET("/products/:id/units", unitHandler.GetProductUnits)                // [BARU] Satuan alternatif produk
+			protected.PUT("/products/:id/units", unitHandler.SetProductUnits)                // [BARU] Atur satuan alternatif produk
 
 			// [BARU] Rute Customer (Fitur #3)
 			protected.POST("/customers", customerHandler.CreateCustomer)
@@ -218,6 +244,27 @@ func setupRoutes(router *gin.Engine) {
 			protected.GET("/customers/:id", customerHandler.GetCustomerByID)
 			protected.PUT("/customers/:id", customerHandler.UpdateCustomer)
 			protected.DELETE("/customers/:id", customerHandler.DeleteCustomer)
+			protected.GET("/customers/:id/statement", customerHandler.GetCustomerStatement) // [BARU] Rekening koran (?format=pdf)
+			protected.GET("/reports/customer-rfm", customerHandler.GetCustomerRFM)          // [BARU] Segmentasi pelanggan RFM
+
+			// --- [BARU] Rute Poin Loyalitas ---
+			protected.GET("/loyalty/program", loyaltyHandler.GetProgram)
+			protected.PUT("/loyalty/program", loyaltyHandler.UpdateProgram)
+			protected.POST("/loyalty/expire", loyaltyHandler.ExpirePoints)
+			protected.GET("/customers/:id/loyalty", loyaltyHandler.GetCustomerLoyalty)
+			protected.POST("/customers/:id/loyalty/adjustments", loyaltyHandler.AdjustCustomerPoints)
+			protected.GET("/reports/loyalty-liability", loyaltyHandler.GetLiabilityReport)
+			// --- [AKHIR BARU] ---
+
+			// --- [BARU] Rute Supplier ---
+			protected.POST("/suppliers", supplierHandler.CreateSupplier)
+			protected.GET("/suppliers", supplierHandler.GetUserSuppliers)
+			protected.GET("/suppliers/:id", supplierHandler.GetSupplierByID)
+			protected.PUT("/suppliers/:id", supplierHandler.UpdateSupplier)
+			protected.DELETE("/suppliers/:id", supplierHandler.DeleteSupplier)
+			protected.GET("/suppliers/:id/purchases", supplierHandler.GetSupplierPurchases)
+			protected.GET("/reports/purchases-by-supplier", supplierHandler.GetPurchasesBySupplier)
+			// --- [AKHIR BARU] ---
 
 			// --- [BARU] Rute Kategori (Fitur #2) ---
 			protected.POST("/categories", categoryHandler.CreateCategory)
@@ -233,12 +280,20 @@ func setupRoutes(router *gin.Engine) {
 			// --- [BARU] Rute untuk menandai lunas ---
 			protected.PUT("/transactions/:id/mark-paid", transactionHandler.MarkTransactionPaid)
 			// --- [AKHIR BARU] ---
+			// --- [BARU] Rute untuk void & retur ---
+			protected.POST("/transactions/:id/void", transactionHandler.VoidTransaction)
+			protected.POST("/transactions/:id/refunds", transactionHandler.RefundTransaction)
+			// --- [AKHIR BARU] ---
+			// --- [BARU] Rute untuk pembayaran cicilan ---
+			protected.POST("/transactions/:id/payments", transactionHandler.AddPayment)
+			// --- [AKHIR BARU] ---
 
 			// Rute Dashboard (Tahap 5 & Fitur #2)
 			protected.GET("/dashboard/stats", dashboardHandler.GetDashboardStats)
 			protected.GET("/dashboard/chart", dashboardHandler.GetDashboardChartData)
 			// --- [BARU UNTUK FITUR STOK MINIMUM] ---
 			protected.GET("/dashboard/low-stock", dashboardHandler.GetLowStockProducts)
+			protected.GET("/dashboard/near-expiry", dashboardHandler.GetNearExpiryBatches) // [BARU]
 			// --- [AKHIR BARU] ---
 
 			// [BARU] Rute Laporan (Fitur #4)
@@ -249,6 +304,77 @@ func setupRoutes(router *gin.Engine) {
 
 			// --- [BARU] Rute untuk Laporan Utang/Piutang ---
 			protected.GET("/reports/unpaid", reportHandler.GetUnpaidReport)
+
+			// --- [BARU] Rute untuk Laporan Laba Rugi ---
+			protected.GET("/reports/profit-loss", reportHandler.GetProfitLoss)
+
+			// --- [BARU] Rute untuk Laporan Neraca ---
+			protected.GET("/reports/balance-sheet", reportHandler.GetBalanceSheet)
+
+			// --- [BARU] Rute untuk Laporan Arus Kas ---
+			protected.GET("/reports/cash-flow", reportHandler.GetCashFlow)
+
+			// --- [BARU] Rute Akuntansi (Double-Entry) ---
+			protected.GET("/accounts", accountingHandler.GetAccounts)
+			protected.POST("/accounts", accountingHandler.CreateAccount)
+			protected.GET("/journal-entries", accountingHandler.GetJournalEntries)
+			protected.GET("/reports/trial-balance", accountingHandler.GetTrialBalance)
+
+			// --- [BARU] Rute Tutup Buku (Periode Akuntansi) ---
+			protected.GET("/fiscal-periods", fiscalPeriodHandler.GetPeriods)
+			protected.POST("/fiscal-periods/:year/:month/close", fiscalPeriodHandler.ClosePeriod)
+			protected.POST("/fiscal-periods/:year/:month/reopen", fiscalPeriodHandler.ReopenPeriod)
+
+			// --- [BARU] Rute Pajak (PPN) ---
+			protected.GET("/tax-rates", taxHandler.GetTaxRates)
+			protected.POST("/tax-rates", taxHandler.CreateTaxRate)
+			protected.PUT("/tax-rates/:id", taxHandler.UpdateTaxRate)
+			protected.DELETE("/tax-rates/:id", taxHandler.DeleteTaxRate)
+			protected.GET("/reports/tax", taxHandler.GetTaxReport)
+
+			// --- [BARU] Rute Promo & Pratinjau Harga ---
+			protected.GET("/promotions", promotionHandler.GetPromotions)
+			protected.POST("/promotions", promotionHandler.CreatePromotion)
+			protected.POST("/promotions/preview", promotionHandler.PreviewPrice)
+			protected.PUT("/promotions/:id", promotionHandler.UpdatePromotion)
+			protected.DELETE("/promotions/:id", promotionHandler.DeletePromotion)
+			protected.GET("/reports/promotions", promotionHandler.GetPromotionReport)
+			// --- [AKHIR BARU] ---
+
+			// --- [BARU] Rute Stok Opname ---
+			protected.GET("/stock-opnames", stockOpnameHandler.GetStockOpnames)
+			protected.POST("/stock-opnames", stockOpnameHandler.CreateStockOpname)
+			protected.GET("/stock-opnames/:id", stockOpnameHandler.GetStockOpnameByID)
+			protected.POST("/stock-opnames/:id/counts", stockOpnameHandler.AddCounts)
+			protected.DELETE("/stock-opnames/:id/counts/:countId", stockOpnameHandler.DeleteCount)
+			protected.GET("/stock-opnames/:id/variance", stockOpnameHandler.GetVarianceReport)
+			protected.POST("/stock-opnames/:id/finalize", stockOpnameHandler.FinalizeStockOpname)
+			protected.POST("/stock-opnames/:id/cancel", stockOpnameHandler.CancelStockOpname)
+			// --- [AKHIR BARU] ---
+
+			// --- [BARU] Rute Lokasi (Gudang/Outlet) & Mutasi Stok ---
+			protected.GET("/locations", locationHandler.GetLocations)
+			protected.POST("/locations", locationHandler.CreateLocation)
+			protected.PUT("/locations/:id", locationHandler.UpdateLocation)
+			protected.DELETE("/locations/:id", locationHandler.DeleteLocation)
+			protected.GET("/locations/:id/stocks", locationHandler.GetLocationStocks)
+			protected.GET("/stock-transfers", locationHandler.GetStockTransfers)
+			protected.POST("/stock-transfers", locationHandler.CreateStockTransfer)
+			protected.GET("/stock-transfers/:id", locationHandler.GetStockTransferByID)
+			// --- [AKHIR BARU] ---
+
+			// --- [BARU] Rute Pesanan Pembelian (PO) & Penerimaan Barang ---
+			protected.GET("/purchase-orders", purchaseOrderHandler.GetPurchaseOrders)
+			protected.POST("/purchase-orders", purchaseOrderHandler.CreatePurchaseOrder)
+			protected.GET("/purchase-orders/:id", purchaseOrderHandler.GetPurchaseOrderByID)
+			protected.PUT("/purchase-orders/:id", purchaseOrderHandler.UpdatePurchaseOrder)
+			protected.DELETE("/purchase-orders/:id", purchaseOrderHandler.DeletePurchaseOrder)
+			protected.POST("/purchase-orders/:id/order", purchaseOrderHandler.OrderPurchaseOrder)
+			protected.POST("/purchase-orders/:id/cancel", purchaseOrderHandler.CancelPurchaseOrder)
+			protected.GET("/purchase-orders/:id/receipts", purchaseOrderHandler.GetGoodsReceipts)
+			protected.POST("/purchase-orders/:id/receipts", purchaseOrderHandler.ReceiveGoods)
+			protected.POST("/purchase-orders/:id/bill", purchaseOrderHandler.BillPurchaseOrder)
+			// --- [AKHIR BARU] ---
 		}
 	}
 }
@@ -3,6 +3,8 @@ package database
 import (
 	"fmt"
 	"log"
+	"math"
+	"time"
 
 	"github.com/danishyusrah/go_bisnis/config"
 	"github.com/danishyusrah/go_bisnis/internal/models"
@@ -38,20 +40,341 @@ func InitDatabase(cfg config.Config) {
 	MigrateDatabase()
 }
 
+// Models adalah seluruh model aplikasi yang dimigrasi otomatis (juga dipakai untuk database tes)
+// [DIUBAH] Tambahkan semua model Anda di sini
+var Models = []interface{}{
+	&models.User{},
+	&models.Product{},
+	&models.Transaction{},              // <-- BARU: Tambahkan model Transaction
+	&models.TransactionItem{},          // <-- BARU: Tambahkan model TransactionItem
+	&models.Customer{},                 // <-- BARU: Tambahkan model Customer
+	&models.Category{},                 // <-- [BARU] Tambahkan model Category
+	&models.Refund{},                   // <-- [BARU] Riwayat retur transaksi
+	&models.RefundItem{},               // <-- [BARU] Detail item yang diretur
+	&models.Payment{},                  // <-- [BARU] Riwayat pembayaran/cicilan
+	&models.Account{},                  // <-- [BARU] Bagan akun (double-entry)
+	&models.JournalEntry{},             // <-- [BARU] Header jurnal umum
+	&models.JournalLine{},              // <-- [BARU] Baris debit/kredit jurnal
+	&models.FiscalPeriod{},             // <-- [BARU] Periode akuntansi (tutup buku)
+	&models.TaxRate{},                  // <-- [BARU] Tarif pajak (PPN)
+	&models.Promotion{},                // <-- [BARU] Aturan promo otomatis
+	&models.TransactionPromotion{},     // <-- [BARU] Promo yang diterapkan per transaksi
+	&models.StockMovement{},            // <-- [BARU] Kartu stok (riwayat perubahan stok)
+	&models.StockOpname{},              // <-- [BARU] Sesi stok opname
+	&models.StockOpnameCount{},         // <-- [BARU] Entri hitung fisik stok opname
+	&models.StockOpnameItem{},          // <-- [BARU] Hasil finalisasi stok opname
+	&models.CostLayer{},                // <-- [BARU] Lapisan biaya FIFO
+	&models.ProductBatch{},             // <-- [BARU] Batch/lot produk (kedaluwarsa)
+	&models.BatchConsumption{},         // <-- [BARU] Pemakaian batch per transaksi
+	&models.BatchWriteOff{},            // <-- [BARU] Pemusnahan batch
+	&models.Location{},                 // <-- [BARU] Gudang/outlet
+	&models.ProductStock{},             // <-- [BARU] Stok per lokasi
+	&models.StockTransfer{},            // <-- [BARU] Mutasi stok antar lokasi
+	&models.StockTransferItem{},        // <-- [BARU] Item mutasi stok
+	&models.ProductAttribute{},         // <-- [BARU] Atribut varian produk
+	&models.ProductComponent{},         // <-- [BARU] Resep/BOM produk komposit
+	&models.TransactionItemComponent{}, // <-- [BARU] Bahan terpakai per item transaksi
+	&models.ProductUnit{},              // <-- [BARU] Satuan alternatif produk
+	&models.PurchaseOrder{},            // <-- [BARU] Pesanan pembelian (PO)
+	&models.PurchaseOrderItem{},        // <-- [BARU] Item PO
+	&models.GoodsReceipt{},             // <-- [BARU] Penerimaan barang dari PO
+	&models.GoodsReceiptItem{},         // <-- [BARU] Item penerimaan barang
+	&models.Supplier{},                 // <-- [BARU] Supplier (terpisah dari pelanggan)
+	&models.LoyaltyProgram{},           // <-- [BARU] Pengaturan program poin
+	&models.LoyaltyLedgerEntry{},       // <-- [BARU] Buku poin pelanggan
+}
+
 // MigrateDatabase menjalankan auto-migration
 func MigrateDatabase() {
 	log.Println("Menjalankan migrasi database...")
-	// Tambahkan semua model Anda di sini
-	err := DB.AutoMigrate(
-		&models.User{},
-		&models.Product{},
-		&models.Transaction{},     // <-- BARU: Tambahkan model Transaction
-		&models.TransactionItem{}, // <-- BARU: Tambahkan model TransactionItem
-		&models.Customer{},        // <-- BARU: Tambahkan model Customer
-		&models.Category{},        // <-- [BARU] Tambahkan model Category
-	)
+	err := DB.AutoMigrate(Models...)
 	if err != nil {
 		log.Fatalf("Gagal menjalankan migrasi: %v", err)
 	}
+
+	// [BARU] Migrasi data: lengkapi data lama agar konsisten dengan skema baru
+	if err := backfillPayments(); err != nil {
+		log.Fatalf("Gagal melengkapi data pembayaran lama: %v", err)
+	}
+	if err := backfillTaxableAmounts(); err != nil {
+		log.Fatalf("Gagal melengkapi DPP item lama: %v", err)
+	}
+	if err := backfillGrossAmounts(); err != nil {
+		log.Fatalf("Gagal melengkapi nilai kotor transaksi lama: %v", err)
+	}
+	if err := backfillStockMovements(); err != nil {
+		log.Fatalf("Gagal membuat saldo awal kartu stok: %v", err)
+	}
+	if err := backfillLocations(); err != nil {
+		log.Fatalf("Gagal membuat lokasi default & stok per lokasi: %v", err)
+	}
+	if err := backfillItemUnits(); err != nil {
+		log.Fatalf("Gagal melengkapi satuan item lama: %v", err)
+	}
+	if err := backfillSuppliers(); err != nil {
+		log.Fatalf("Gagal memigrasikan pihak lawan pengeluaran ke supplier: %v", err)
+	}
+	if err := backfillOpeningJournals(); err != nil {
+		log.Fatalf("Gagal membuat jurnal saldo awal data lama: %v", err)
+	}
 	log.Println("Migrasi database selesai.")
 }
+
+// backfillPayments membuat catatan pembayaran untuk transaksi LUNAS lama yang belum punya
+// baris di tabel 'payments'. Aman dijalankan berulang kali.
+func backfillPayments() error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`INSERT INTO payments (created_at, updated_at, transaction_id, user_id, amount, payment_date, method, notes)
+			SELECT t.created_at, t.created_at, t.id, t.user_id, t.total_amount - t.refunded_amount, t.created_at, ?, 'Migrasi data lama'
+			FROM transactions t
+			WHERE t.payment_status = ? AND t.deleted_at IS NULL
+			AND NOT EXISTS (SELECT 1 FROM payments p WHERE p.transaction_id = t.id)`,
+			models.MethodTunai, models.Lunas).Error; err != nil {
+			return err
+		}
+		return tx.Exec(`UPDATE transactions t
+			SET t.paid_amount = (SELECT COALESCE(SUM(p.amount), 0) FROM payments p WHERE p.transaction_id = t.id AND p.deleted_at IS NULL)
+			WHERE t.paid_amount = 0`).Error
+	})
+}
+
+// backfillTaxableAmounts mengisi DPP item lama (sebelum ada fitur pajak) = harga satuan x kuantitas.
+// Idempoten: hanya item tanpa DPP dan tanpa pajak yang diisi.
+func backfillTaxableAmounts() error {
+	return DB.Exec(`UPDATE transaction_items
+		SET taxable_amount = unit_price * quantity
+		WHERE taxable_amount = 0 AND tax_amount = 0 AND unit_price <> 0
+		AND discount_amount = 0 AND transaction_discount_amount = 0`).Error
+}
+
+// backfillGrossAmounts mengisi nilai kotor transaksi lama (sebelum ada fitur diskon) = total transaksi.
+// Idempoten: hanya transaksi tanpa nilai kotor dan tanpa diskon yang diisi.
+func backfillGrossAmounts() error {
+	return DB.Exec(`UPDATE transactions
+		SET gross_amount = total_amount
+		WHERE gross_amount = 0 AND discount_amount = 0 AND total_amount <> 0`).Error
+}
+
+// backfillStockMovements membuat saldo awal kartu stok untuk produk lama (sebelum ada kartu stok)
+// sebesar stok saat ini. Idempoten: hanya produk yang belum punya pergerakan stok yang diisi.
+func backfillStockMovements() error {
+	return DB.Exec(`INSERT INTO stock_movements (created_at, updated_at, user_id, product_id, reason, quantity, balance_after, notes)
+		SELECT NOW(), NOW(), p.user_id, p.id, ?, p.stock, p.stock, 'Saldo awal (migrasi data lama)'
+		FROM products p
+		WHERE p.deleted_at IS NULL AND p.stock <> 0
+		AND NOT EXISTS (SELECT 1 FROM stock_movements m WHERE m.product_id = p.id)`,
+		models.MovementAdjustment).Error
+}
+
+// backfillLocations membuat lokasi default untuk setiap user, memindahkan stok produk lama ke lokasi
+// tersebut, dan menandai transaksi/kartu stok/stok opname lama dengan lokasi default.
+// Idempoten: hanya data yang belum punya lokasi yang diisi.
+func backfillLocations() error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`INSERT INTO locations (created_at, updated_at, user_id, name, address, is_default)
+			SELECT NOW(), NOW(), u.id, 'Lokasi Utama', '', TRUE
+			FROM users u
+			WHERE u.deleted_at IS NULL
+			AND NOT EXISTS (SELECT 1 FROM locations l WHERE l.user_id = u.id AND l.is_default = TRUE AND l.deleted_at IS NULL)`).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(`INSERT INTO product_stocks (created_at, updated_at, user_id, product_id, location_id, quantity)
+			SELECT NOW(), NOW(), p.user_id, p.id, l.id, p.stock
+			FROM products p
+			JOIN locations l ON l.user_id = p.user_id AND l.is_default = TRUE AND l.deleted_at IS NULL
+			WHERE p.deleted_at IS NULL
+			AND NOT EXISTS (SELECT 1 FROM product_stocks s WHERE s.product_id = p.id)`).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(`UPDATE transactions t
+			JOIN locations l ON l.user_id = t.user_id AND l.is_default = TRUE AND l.deleted_at IS NULL
+			SET t.location_id = l.id
+			WHERE t.location_id IS NULL AND t.type IN (?, ?)`, models.Income, models.Expense).Error; err != nil {
+			return err
+		}
+		for _, table := range []string{"stock_movements", "stock_opnames", "batch_write_offs"} {
+			if err := tx.Exec(`UPDATE ` + table + ` x
+				JOIN locations l ON l.user_id = x.user_id AND l.is_default = TRUE AND l.deleted_at IS NULL
+				SET x.location_id = l.id
+				WHERE x.location_id IS NULL`).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// backfillItemUnits mengisi jumlah satuan transaksi item lama (sebelum ada satuan) = jumlah satuan dasar.
+// Idempoten: hanya item yang belum punya jumlah satuan yang diisi.
+func backfillItemUnits() error {
+	return DB.Exec(`UPDATE transaction_items
+		SET unit_quantity = quantity, unit_factor = 1
+		WHERE unit_quantity = 0`).Error
+}
+
+// backfillSuppliers menyalin pelanggan yang dipakai sebagai pihak lawan transaksi EXPENSE lama menjadi
+// supplier, lalu memindahkan transaksi tersebut ke supplier_id. Idempoten: hanya transaksi EXPENSE
+// yang belum punya supplier dan masih merujuk ke pelanggan yang diproses.
+func backfillSuppliers() error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`INSERT INTO suppliers (created_at, updated_at, deleted_at, name, email, phone, address, user_id, legacy_customer_id)
+			SELECT c.created_at, NOW(), c.deleted_at, c.name, c.email, c.phone, c.address, c.user_id, c.id
+			FROM customers c
+			WHERE c.id IN (SELECT t.customer_id FROM transactions t
+				WHERE t.type = ? AND t.supplier_id IS NULL AND t.customer_id IS NOT NULL)
+			AND NOT EXISTS (SELECT 1 FROM suppliers s WHERE s.legacy_customer_id = c.id)`, models.Expense).Error; err != nil {
+			return err
+		}
+		return tx.Exec(`UPDATE transactions t
+			JOIN suppliers s ON s.legacy_customer_id = t.customer_id
+			SET t.supplier_id = s.id, t.customer_id = NULL
+			WHERE t.type = ? AND t.supplier_id IS NULL`, models.Expense).Error
+	})
+}
+
+// openingBalanceSQL menghitung saldo akun neraca per user dari data transaksi & stok (bukan dari jurnal),
+// dengan nama kolom = kode akun. Utang, PPN Keluaran & Modal bernilai positif di sisi kredit.
+const openingBalanceSQL = `SELECT
+	(SELECT COALESCE(SUM(CASE WHEN t.type = @expense THEN -p.amount ELSE p.amount END), 0) FROM payments p
+		JOIN transactions t ON t.id = p.transaction_id
+		WHERE p.user_id = @user AND p.deleted_at IS NULL AND t.deleted_at IS NULL AND t.status <> @void) AS cash,
+	(SELECT COALESCE(SUM(t.total_amount - t.refunded_amount - t.paid_amount), 0) FROM transactions t
+		WHERE t.user_id = @user AND t.type = @income AND t.payment_status = @unpaid AND t.deleted_at IS NULL AND t.status <> @void) AS receivable,
+	(SELECT COALESCE(SUM(pr.stock * pr.purchase_price), 0) FROM products pr
+		WHERE pr.user_id = @user AND pr.deleted_at IS NULL) AS inventory,
+	(SELECT COALESCE(SUM(t.tax_amount), 0) FROM transactions t
+		WHERE t.user_id = @user AND t.type = @expense AND t.deleted_at IS NULL AND t.status <> @void)
+	- (SELECT COALESCE(SUM(r.tax_amount), 0) FROM refunds r JOIN transactions t ON t.id = r.transaction_id
+		WHERE r.user_id = @user AND t.type = @expense AND r.deleted_at IS NULL AND t.deleted_at IS NULL AND t.status <> @void) AS input_vat,
+	(SELECT COALESCE(SUM(t.total_amount - t.refunded_amount - t.paid_amount), 0) FROM transactions t
+		WHERE t.user_id = @user AND t.type = @expense AND t.payment_status = @unpaid AND t.deleted_at IS NULL AND t.status <> @void) AS payable,
+	(SELECT COALESCE(SUM(t.tax_amount), 0) FROM transactions t
+		WHERE t.user_id = @user AND t.type = @income AND t.deleted_at IS NULL AND t.status <> @void)
+	- (SELECT COALESCE(SUM(r.tax_amount), 0) FROM refunds r JOIN transactions t ON t.id = r.transaction_id
+		WHERE r.user_id = @user AND t.type = @income AND r.deleted_at IS NULL AND t.deleted_at IS NULL AND t.status <> @void) AS output_vat,
+	(SELECT COALESCE(SUM(t.total_amount), 0) FROM transactions t
+		WHERE t.user_id = @user AND t.type = @capital AND t.deleted_at IS NULL AND t.status <> @void) AS capital`
+
+// backfillOpeningJournals membuat satu jurnal saldo awal untuk user yang sudah punya data sebelum ada jurnal
+// otomatis (transaksi tanpa jurnal, atau produk berstok tanpa jurnal sama sekali). Saldo Kas, Piutang,
+// Persediaan, PPN, Utang & Modal di buku besar disamakan dengan data transaksi/stok; selisihnya dicatat
+// sebagai Laba Ditahan. Idempoten: user yang sudah punya jurnal saldo awal dilewati.
+func backfillOpeningJournals() error {
+	var userIDs []uint
+	if err := DB.Raw(`SELECT u.id FROM users u
+		WHERE u.deleted_at IS NULL
+		AND NOT EXISTS (SELECT 1 FROM journal_entries j WHERE j.user_id = u.id AND j.source_type = ?)
+		AND (EXISTS (SELECT 1 FROM transactions t WHERE t.user_id = u.id AND t.deleted_at IS NULL
+				AND NOT EXISTS (SELECT 1 FROM journal_entries j WHERE j.transaction_id = t.id AND j.source_type = ?))
+			OR (EXISTS (SELECT 1 FROM products p WHERE p.user_id = u.id AND p.deleted_at IS NULL AND p.stock <> 0)
+				AND NOT EXISTS (SELECT 1 FROM journal_entries j WHERE j.user_id = u.id)))`,
+		models.JournalSourceOpening, models.JournalSourceTransaction).Scan(&userIDs).Error; err != nil {
+		return err
+	}
+
+	for _, userID := range userIDs {
+		if err := DB.Transaction(func(tx *gorm.DB) error {
+			return postOpeningJournal(tx, userID)
+		}); err != nil {
+			return fmt.Errorf("user %d: %w", userID, err)
+		}
+	}
+	return nil
+}
+
+// postOpeningJournal menyimpan jurnal saldo awal satu user (lihat backfillOpeningJournals)
+func postOpeningJournal(tx *gorm.DB, userID uint) error {
+	accountIDs := make(map[string]uint)
+	for _, def := range models.DefaultAccounts {
+		account := models.Account{UserID: userID, Code: def.Code}
+		if err := tx.Where(&account).Attrs(models.Account{Name: def.Name, Type: def.Type, IsSystem: true}).
+			FirstOrCreate(&account).Error; err != nil {
+			return err
+		}
+		accountIDs[def.Code] = account.ID
+	}
+
+	var expected struct {
+		Cash, Receivable, Inventory, InputVAT float64
+		Payable, OutputVAT, Capital           float64
+	}
+	if err := tx.Raw(openingBalanceSQL, map[string]interface{}{
+		"user": userID, "income": models.Income, "expense": models.Expense, "capital": models.Capital,
+		"unpaid": models.BelumLunas, "void": models.StatusVoid,
+	}).Scan(&expected).Error; err != nil {
+		return err
+	}
+
+	// Saldo buku besar saat ini (debit - kredit) per kode akun
+	var ledgerRows []struct {
+		Code    string
+		Balance float64
+	}
+	if err := tx.Table("journal_lines").
+		Select("accounts.code, SUM(journal_lines.debit - journal_lines.credit) as balance").
+		Joins("JOIN journal_entries ON journal_entries.id = journal_lines.journal_entry_id").
+		Joins("JOIN accounts ON accounts.id = journal_lines.account_id").
+		Where("journal_entries.user_id = ? AND journal_entries.deleted_at IS NULL AND journal_lines.deleted_at IS NULL", userID).
+		Group("accounts.code").
+		Scan(&ledgerRows).Error; err != nil {
+		return err
+	}
+	ledger := make(map[string]float64)
+	for _, row := range ledgerRows {
+		ledger[row.Code] = row.Balance
+	}
+
+	// Selisih (debit - kredit) yang dibutuhkan agar saldo buku besar = saldo data transaksi/stok
+	differences := []struct {
+		code   string
+		amount float64
+	}{
+		{models.AccountCodeCash, expected.Cash - ledger[models.AccountCodeCash]},
+		{models.AccountCodeReceivable, expected.Receivable - ledger[models.AccountCodeReceivable]},
+		{models.AccountCodeInventory, expected.Inventory - ledger[models.AccountCodeInventory]},
+		{models.AccountCodeInputVAT, expected.InputVAT - ledger[models.AccountCodeInputVAT]},
+		{models.AccountCodePayable, -expected.Payable - ledger[models.AccountCodePayable]},
+		{models.AccountCodeOutputVAT, -expected.OutputVAT - ledger[models.AccountCodeOutputVAT]},
+		{models.AccountCodeCapital, -expected.Capital - ledger[models.AccountCodeCapital]},
+	}
+	var lines []models.JournalLine
+	var net float64
+	addLine := func(code string, amount float64) {
+		amount = math.Round(amount*100) / 100
+		if amount == 0 {
+			return
+		}
+		line := models.JournalLine{AccountID: accountIDs[code], Debit: amount}
+		if amount < 0 {
+			line = models.JournalLine{AccountID: accountIDs[code], Credit: -amount}
+		}
+		lines = append(lines, line)
+		net += amount
+	}
+	for _, difference := range differences {
+		addLine(difference.code, difference.amount)
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	addLine(models.AccountCodeRetainedEarnings, -net)
+
+	// Saldo awal dicatat tepat sebelum jurnal otomatis pertama user (atau sekarang jika belum ada)
+	entryDate := time.Now()
+	var first struct{ EntryDate *time.Time }
+	if err := tx.Table("journal_entries").Select("MIN(entry_date) as entry_date").
+		Where("user_id = ? AND deleted_at IS NULL", userID).Scan(&first).Error; err != nil {
+		return err
+	}
+	if first.EntryDate != nil && first.EntryDate.Before(entryDate) {
+		entryDate = first.EntryDate.Add(-time.Second)
+	}
+
+	return tx.Create(&models.JournalEntry{
+		UserID:      userID,
+		EntryDate:   entryDate,
+		Description: "Saldo awal (migrasi data lama)",
+		SourceType:  models.JournalSourceOpening,
+		Lines:       lines,
+	}).Error
+}
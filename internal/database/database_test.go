@@ -0,0 +1,137 @@
+package database
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/danishyusrah/go_bisnis/internal/models"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// useTestDB memasang database SQLite in-memory sebagai DB selama satu tes
+func useTestDB(t *testing.T, models ...interface{}) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger:                                   logger.Default.LogMode(logger.Silent),
+		DisableForeignKeyConstraintWhenMigrating: true,
+	})
+	if err != nil {
+		t.Fatalf("gagal membuka database tes: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("gagal mengambil koneksi database tes: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	if err := db.AutoMigrate(models...); err != nil {
+		t.Fatalf("gagal migrasi database tes: %v", err)
+	}
+
+	previous := DB
+	DB = db
+	t.Cleanup(func() {
+		DB = previous
+		sqlDB.Close()
+	})
+	return db
+}
+
+func TestBackfillOpeningJournals(t *testing.T) {
+	db := useTestDB(t, &models.User{}, &models.Product{}, &models.Transaction{}, &models.Payment{},
+		&models.Refund{}, &models.Account{}, &models.JournalEntry{}, &models.JournalLine{})
+
+	// Toko dengan data sebelum ada jurnal otomatis: setoran modal, pembelian sebagian dibayar,
+	// penjualan kredit ber-PPN yang sebagian diretur, dan stok gudang
+	user := models.User{Username: "toko", Email: "toko@example.com", PasswordHash: "x"}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	march := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	capital := models.Transaction{UserID: user.ID, Type: models.Capital, TotalAmount: 5000000, CreatedAt: march}
+	purchase := models.Transaction{UserID: user.ID, Type: models.Expense, TotalAmount: 1110000, TaxAmount: 110000,
+		PaidAmount: 600000, PaymentStatus: models.BelumLunas, CreatedAt: march}
+	sale := models.Transaction{UserID: user.ID, Type: models.Income, TotalAmount: 555000, TaxAmount: 55000,
+		RefundedAmount: 111000, PaidAmount: 200000, PaymentStatus: models.BelumLunas, Status: models.StatusRefunded, CreatedAt: march}
+	void := models.Transaction{UserID: user.ID, Type: models.Income, TotalAmount: 999000, PaymentStatus: models.BelumLunas,
+		Status: models.StatusVoid, CreatedAt: march}
+	for _, transaction := range []*models.Transaction{&capital, &purchase, &sale, &void} {
+		if err := db.Create(transaction).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	payments := []models.Payment{
+		{UserID: user.ID, TransactionID: capital.ID, Amount: 5000000, PaymentDate: march},
+		{UserID: user.ID, TransactionID: purchase.ID, Amount: 600000, PaymentDate: march},
+		{UserID: user.ID, TransactionID: sale.ID, Amount: 200000, PaymentDate: march},
+		{UserID: user.ID, TransactionID: void.ID, Amount: 999000, PaymentDate: march},
+	}
+	if err := db.Create(&payments).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&models.Refund{UserID: user.ID, TransactionID: sale.ID, Amount: 111000, TaxAmount: 11000}).Error; err != nil {
+		t.Fatal(err)
+	}
+	products := []models.Product{
+		{UserID: user.ID, SKU: "GULA", Name: "Gula", Stock: 40, PurchasePrice: 12500},
+		{UserID: user.ID, SKU: "KOPI", Name: "Kopi", Stock: 3, PurchasePrice: 33333.33},
+	}
+	if err := db.Create(&products).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// Dijalankan dua kali: jalan kedua tidak boleh menambah jurnal
+	for run := 1; run <= 2; run++ {
+		if err := backfillOpeningJournals(); err != nil {
+			t.Fatalf("jalan %d: backfillOpeningJournals() error = %v", run, err)
+		}
+	}
+
+	var entries []models.JournalEntry
+	if err := db.Preload("Lines").Where("user_id = ?", user.ID).Find(&entries).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].SourceType != models.JournalSourceOpening {
+		t.Fatalf("jurnal = %d entri, want 1 jurnal saldo awal", len(entries))
+	}
+
+	var accounts []models.Account
+	if err := db.Where("user_id = ?", user.ID).Find(&accounts).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(accounts) != len(models.DefaultAccounts) {
+		t.Errorf("jumlah akun = %d, want %d", len(accounts), len(models.DefaultAccounts))
+	}
+	codes := make(map[uint]string)
+	for _, account := range accounts {
+		codes[account.ID] = account.Code
+	}
+	balances := make(map[string]float64) // debit - kredit
+	var totalDebit, totalCredit float64
+	for _, line := range entries[0].Lines {
+		balances[codes[line.AccountID]] += line.Debit - line.Credit
+		totalDebit += line.Debit
+		totalCredit += line.Credit
+	}
+	if math.Abs(totalDebit-totalCredit) > 0.001 {
+		t.Errorf("jurnal tidak seimbang: debit %.2f, kredit %.2f", totalDebit, totalCredit)
+	}
+
+	want := map[string]float64{
+		models.AccountCodeCash:             5000000 - 600000 + 200000,
+		models.AccountCodeReceivable:       555000 - 111000 - 200000,
+		models.AccountCodeInventory:        40*12500 + 3*33333.33,
+		models.AccountCodeInputVAT:         110000,
+		models.AccountCodePayable:          -(1110000 - 600000),
+		models.AccountCodeOutputVAT:        -(55000 - 11000),
+		models.AccountCodeCapital:          -5000000,
+		models.AccountCodeRetainedEarnings: -(4600000 + 244000 + 599999.99 + 110000 - 510000 - 44000 - 5000000),
+	}
+	for code, amount := range want {
+		if math.Abs(balances[code]-amount) > 0.001 {
+			t.Errorf("saldo akun %s = %.2f, want %.2f", code, balances[code], amount)
+		}
+	}
+}
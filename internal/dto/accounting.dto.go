@@ -0,0 +1,58 @@
+package dto
+
+import "github.com/danishyusrah/go_bisnis/internal/models"
+
+// CreateAccountInput adalah DTO untuk menambah akun kustom
+type CreateAccountInput struct {
+	Code string             `json:"code" binding:"required,max=20"`
+	Name string             `json:"name" binding:"required"`
+	Type models.AccountType `json:"type" binding:"required,oneof=ASSET LIABILITY EQUITY REVENUE EXPENSE"`
+}
+
+// AccountResponse adalah DTO untuk data akun yang dikirim ke client
+type AccountResponse struct {
+	ID       uint               `json:"id"`
+	Code     string             `json:"code"`
+	Name     string             `json:"name"`
+	Type     models.AccountType `json:"type"`
+	IsSystem bool               `json:"is_system"`
+}
+
+// JournalLineResponse adalah DTO untuk satu baris jurnal
+type JournalLineResponse struct {
+	AccountID   uint    `json:"account_id"`
+	AccountCode string  `json:"account_code"`
+	AccountName string  `json:"account_name"`
+	Debit       float64 `json:"debit"`
+	Credit      float64 `json:"credit"`
+}
+
+// JournalEntryResponse adalah DTO untuk satu jurnal lengkap
+type JournalEntryResponse struct {
+	ID            uint                     `json:"id"`
+	EntryDate     string                   `json:"entry_date"`
+	Description   string                   `json:"description"`
+	SourceType    models.JournalSourceType `json:"source_type"`
+	TransactionID *uint                    `json:"transaction_id"`
+	Lines         []JournalLineResponse    `json:"lines"`
+}
+
+// TrialBalanceRow adalah saldo satu akun dalam neraca saldo
+type TrialBalanceRow struct {
+	AccountID uint               `json:"account_id"`
+	Code      string             `json:"code"`
+	Name      string             `json:"name"`
+	Type      models.AccountType `json:"type"`
+	Debit     float64            `json:"debit"`
+	Credit    float64            `json:"credit"`
+	Balance   float64            `json:"balance"` // Saldo menurut sisi normal akun
+}
+
+// TrialBalanceReport adalah DTO lengkap untuk neraca saldo
+type TrialBalanceReport struct {
+	AsOf        string            `json:"as_of"`
+	Rows        []TrialBalanceRow `json:"rows"`
+	TotalDebit  float64           `json:"total_debit"`
+	TotalCredit float64           `json:"total_credit"`
+	IsBalanced  bool              `json:"is_balanced"`
+}
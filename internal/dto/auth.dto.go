@@ -22,4 +22,6 @@ type UserResponse struct {
 	Email     string `json:"email"`
 	FullName  string `json:"full_name"`
 	CreatedAt string `json:"created_at"`
+	// [BARU] Metode penilaian persediaan (AVERAGE / FIFO)
+	CostingMethod string `json:"costing_method"`
 }
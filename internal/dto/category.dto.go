@@ -6,12 +6,16 @@ import "github.com/danishyusrah/go_bisnis/internal/models"
 type CreateCategoryInput struct {
 	Name string               `json:"name" binding:"required"`
 	Type models.CategoryType `json:"type" binding:"required,oneof=INCOME EXPENSE"`
+	// [BARU] Opsional, default OPERATING
+	CashFlowSection models.CashFlowSectionType `json:"cash_flow_section" binding:"omitempty,oneof=OPERATING INVESTING FINANCING"`
 }
 
 // UpdateCategoryInput adalah DTO untuk memperbarui kategori
 type UpdateCategoryInput struct {
 	Name string               `json:"name" binding:"omitempty"`
 	Type models.CategoryType `json:"type" binding:"omitempty,oneof=INCOME EXPENSE"`
+	// [BARU] Opsional, jika kosong nilai lama dipertahankan
+	CashFlowSection models.CashFlowSectionType `json:"cash_flow_section" binding:"omitempty,oneof=OPERATING INVESTING FINANCING"`
 }
 
 // CategoryResponse adalah DTO untuk data kategori yang dikirim ke client
@@ -19,4 +23,6 @@ type CategoryResponse struct {
 	ID   uint                `json:"id"`
 	Name string              `json:"name"`
 	Type models.CategoryType `json:"type"`
+	// [BARU]
+	CashFlowSection models.CashFlowSectionType `json:"cash_flow_section"`
 }
\ No newline at end of file
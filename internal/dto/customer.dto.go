@@ -9,6 +9,26 @@ type CustomerResponse struct {
 	Address   string `json:"address"`
 	CreatedAt string `json:"created_at"`
 	UpdatedAt string `json:"updated_at"`
+
+	// [BARU] Pengaturan batas kredit
+	CreditLimit         float64 `json:"credit_limit"` // 0 = tanpa batas
+	CreditHoldOnOverdue bool    `json:"credit_hold_on_overdue"`
+	MaxOverdueDays      int     `json:"max_overdue_days"`
+	// [BARU] Posisi kredit saat ini, hanya diisi di detail pelanggan
+	CreditExposure *CustomerCreditExposure `json:"credit_exposure,omitempty"`
+}
+
+// CustomerCreditExposure adalah DTO posisi piutang pelanggan terhadap batas kreditnya
+type CustomerCreditExposure struct {
+	Outstanding     float64  `json:"outstanding"`      // Total sisa piutang (setelah retur & cicilan)
+	OpenInvoices    int      `json:"open_invoices"`    // Jumlah transaksi BELUM LUNAS
+	OverdueAmount   float64  `json:"overdue_amount"`   // Sisa piutang yang sudah lewat jatuh tempo
+	OverdueInvoices int      `json:"overdue_invoices"` // Jumlah transaksi yang lewat jatuh tempo
+	MaxDaysOverdue  int      `json:"max_days_overdue"` // Keterlambatan terlama (hari)
+	AvailableCredit *float64 `json:"available_credit"` // Sisa plafon, null jika tanpa batas
+	IsOverLimit     bool     `json:"is_over_limit"`    // Sisa piutang melebihi batas kredit
+	IsOnHold        bool     `json:"is_on_hold"`       // Penjualan kredit baru akan ditolak
+	HoldReason      string   `json:"hold_reason,omitempty"`
 }
 
 // CreateCustomerInput adalah DTO untuk membuat pelanggan baru
@@ -17,6 +37,10 @@ type CreateCustomerInput struct {
 	Email   string `json:"email" binding:"omitempty,email"`
 	Phone   string `json:"phone"`
 	Address string `json:"address"`
+	// [BARU] Batas kredit (opsional)
+	CreditLimit         float64 `json:"credit_limit" binding:"omitempty,gte=0"`
+	CreditHoldOnOverdue bool    `json:"credit_hold_on_overdue"`
+	MaxOverdueDays      int     `json:"max_overdue_days" binding:"omitempty,gte=0"`
 }
 
 // UpdateCustomerInput adalah DTO untuk memperbarui pelanggan
@@ -25,4 +49,80 @@ type UpdateCustomerInput struct {
 	Email   string `json:"email" binding:"omitempty,email"`
 	Phone   string `json:"phone"`
 	Address string `json:"address"`
+	// [BARU] Pointer agar nilai 0/false tetap bisa disimpan; nil = tidak diubah
+	CreditLimit         *float64 `json:"credit_limit" binding:"omitempty,gte=0"`
+	CreditHoldOnOverdue *bool    `json:"credit_hold_on_overdue"`
+	MaxOverdueDays      *int     `json:"max_overdue_days" binding:"omitempty,gte=0"`
+}
+
+// --- [BARU] Rekening Koran Pelanggan (Customer Statement) ---
+
+// CustomerStatementEntry adalah satu baris mutasi piutang pelanggan
+type CustomerStatementEntry struct {
+	Date          string  `json:"date"`
+	Type          string  `json:"type"` // "SALE", "PAYMENT", "REFUND"
+	TransactionID uint    `json:"transaction_id"`
+	Description   string  `json:"description"`
+	Debit         float64 `json:"debit"`   // Menambah piutang (penjualan, dana retur dikembalikan)
+	Credit        float64 `json:"credit"`  // Mengurangi piutang (pembayaran, retur)
+	Balance       float64 `json:"balance"` // Saldo piutang berjalan
+}
+
+// CustomerStatement adalah DTO rekening koran pelanggan, strukturnya mengikuti GeneralLedgerReport
+type CustomerStatement struct {
+	CustomerID      uint                     `json:"customer_id"`
+	CustomerName    string                   `json:"customer_name"`
+	CustomerPhone   string                   `json:"customer_phone"`
+	CustomerAddress string                   `json:"customer_address"`
+	From            string                   `json:"from"`
+	To              string                   `json:"to"`
+	OpeningBalance  float64                  `json:"opening_balance"` // Saldo piutang sebelum 'from'
+	Entries         []CustomerStatementEntry `json:"entries"`
+	TotalDebit      float64                  `json:"total_debit"`
+	TotalCredit     float64                  `json:"total_credit"`
+	ClosingBalance  float64                  `json:"closing_balance"` // Saldo piutang per 'to'
+}
+
+// --- [BARU] Analitik Pembelian Pelanggan (RFM) ---
+
+// CustomerTopProduct adalah produk yang paling banyak dibeli seorang pelanggan
+type CustomerTopProduct struct {
+	ProductID   *uint   `json:"product_id"`
+	ProductName string  `json:"product_name"`
+	Quantity    int64   `json:"quantity"` // Dalam satuan dasar, setelah retur
+	Revenue     float64 `json:"revenue"`  // Nilai beli setelah diskon & retur (termasuk PPN)
+}
+
+// CustomerRFM adalah nilai recency, frequency, monetary dan segmen satu pelanggan
+type CustomerRFM struct {
+	CustomerID        uint                 `json:"customer_id"`
+	CustomerName      string               `json:"customer_name"`
+	Phone             string               `json:"phone"`
+	Email             string               `json:"email"`
+	LastPurchase      string               `json:"last_purchase"`       // Pembelian terakhir (bisa sebelum rentang)
+	RecencyDays       int                  `json:"recency_days"`        // Hari sejak pembelian terakhir hingga akhir rentang
+	Frequency         int64                `json:"frequency"`           // Jumlah transaksi dalam rentang
+	Monetary          float64              `json:"monetary"`            // Total belanja dalam rentang (setelah retur)
+	AverageOrderValue float64              `json:"average_order_value"` // Monetary / Frequency
+	RecencyScore      int                  `json:"recency_score"`       // 1-5, 5 = paling baru
+	FrequencyScore    int                  `json:"frequency_score"`     // 1-5, 5 = paling sering
+	MonetaryScore     int                  `json:"monetary_score"`      // 1-5, 5 = belanja terbesar
+	RFMScore          string               `json:"rfm_score"`           // Gabungan skor, cth: "545"
+	Segment           string               `json:"segment"`
+	TopProducts       []CustomerTopProduct `json:"top_products"`
+}
+
+// RFMSegmentSummary adalah ringkasan jumlah pelanggan & nilai belanja per segmen
+type RFMSegmentSummary struct {
+	Segment   string  `json:"segment"`
+	Customers int     `json:"customers"`
+	Monetary  float64 `json:"monetary"`
+}
+
+// CustomerRFMReport adalah DTO laporan segmentasi pelanggan berbasis RFM
+type CustomerRFMReport struct {
+	From      string              `json:"from"`
+	To        string              `json:"to"`
+	Segments  []RFMSegmentSummary `json:"segments"`
+	Customers []CustomerRFM       `json:"customers"`
 }
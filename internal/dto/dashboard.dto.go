@@ -9,6 +9,12 @@ type DashboardStats struct {
 	TotalExpense     float64 `json:"total_expense"`     // Total Pengeluaran (Biaya operasional)
 	NetProfit        float64 `json:"net_profit"`        // Laba Bersih (GrossProfit - Expense)
 	TransactionCount int64   `json:"transaction_count"` // Jumlah transaksi (Pemasukan + Pengeluaran)
+	// [BARU] Rincian diskon penjualan (tanpa PPN). NetSales = GrossSales - TotalDiscount
+	GrossSales    float64 `json:"gross_sales"`
+	TotalDiscount float64 `json:"total_discount"`
+	NetSales      float64 `json:"net_sales"`
+	// [BARU] Beban selisih persediaan bersih (stok opname & pemusnahan batch). NetProfit = GrossProfit - Expense - InventoryShrinkage
+	InventoryShrinkage float64 `json:"inventory_shrinkage"`
 	// TotalIncome (lama) dihapus
 }
 
@@ -34,3 +40,16 @@ type LowStockProduct struct {
 }
 
 // --- [AKHIR BARU] ---
+
+// [BARU] NearExpiryBatch adalah DTO untuk satu batch yang mendekati/sudah lewat tanggal kedaluwarsa
+type NearExpiryBatch struct {
+	BatchID           uint   `json:"batch_id"`
+	ProductID         uint   `json:"product_id"`
+	ProductName       string `json:"product_name"`
+	LotNumber         string `json:"lot_number"`
+	ExpiryDate        string `json:"expiry_date"`
+	RemainingQuantity int    `json:"remaining_quantity"`
+	DaysToExpiry      int    `json:"days_to_expiry"` // Negatif = sudah kedaluwarsa
+	IsExpired         bool   `json:"is_expired"`
+	LocationStock     *int   `json:"location_stock,omitempty"` // [BARU] Stok produk di lokasi filter (jika diisi)
+}
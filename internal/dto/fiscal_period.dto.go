@@ -0,0 +1,16 @@
+package dto
+
+import "github.com/danishyusrah/go_bisnis/internal/models"
+
+// FiscalPeriodResponse adalah DTO untuk data periode akuntansi
+type FiscalPeriodResponse struct {
+	ID                   uint                          `json:"id"`
+	Year                 int                           `json:"year"`
+	Month                int                           `json:"month"`
+	StartDate            string                        `json:"start_date"` // "YYYY-MM-DD"
+	EndDate              string                        `json:"end_date"`   // "YYYY-MM-DD"
+	Status               models.FiscalPeriodStatusType `json:"status"`
+	ClosedAt             *string                       `json:"closed_at"`
+	AccrualEndingBalance float64                       `json:"accrual_ending_balance"`
+	CashEndingBalance    float64                       `json:"cash_ending_balance"`
+}
@@ -0,0 +1,59 @@
+package dto
+
+// CreateLocationInput adalah DTO untuk membuat/memperbarui lokasi (gudang/outlet)
+type CreateLocationInput struct {
+	Name      string `json:"name" binding:"required"`
+	Address   string `json:"address"`
+	IsDefault bool   `json:"is_default"` // Lokasi default dipakai jika transaksi tidak menyebutkan lokasi
+}
+
+// LocationResponse adalah DTO untuk data lokasi
+type LocationResponse struct {
+	ID        uint   `json:"id"`
+	Name      string `json:"name"`
+	Address   string `json:"address"`
+	IsDefault bool   `json:"is_default"`
+}
+
+// ProductStockResponse adalah stok satu produk di satu lokasi
+type ProductStockResponse struct {
+	ProductID    uint   `json:"product_id"`
+	ProductName  string `json:"product_name"`
+	LocationID   uint   `json:"location_id"`
+	LocationName string `json:"location_name"`
+	Quantity     int    `json:"quantity"`
+}
+
+// StockTransferItemInput adalah satu produk yang dipindahkan
+type StockTransferItemInput struct {
+	ProductID uint `json:"product_id" binding:"required"`
+	Quantity  int  `json:"quantity" binding:"required,gt=0"`
+}
+
+// CreateStockTransferInput adalah DTO untuk membuat dokumen mutasi stok antar lokasi
+type CreateStockTransferInput struct {
+	FromLocationID uint                     `json:"from_location_id" binding:"required"`
+	ToLocationID   uint                     `json:"to_location_id" binding:"required"`
+	TransferDate   *string                  `json:"transfer_date" binding:"omitempty,datetime=2006-01-02"` // default hari ini
+	Notes          string                   `json:"notes"`
+	Items          []StockTransferItemInput `json:"items" binding:"required,min=1,dive"`
+}
+
+// StockTransferItemResponse adalah satu baris dokumen mutasi
+type StockTransferItemResponse struct {
+	ProductID   uint   `json:"product_id"`
+	ProductName string `json:"product_name"`
+	Quantity    int    `json:"quantity"`
+}
+
+// StockTransferResponse adalah DTO untuk dokumen mutasi stok
+type StockTransferResponse struct {
+	ID               uint                        `json:"id"`
+	FromLocationID   uint                        `json:"from_location_id"`
+	FromLocationName string                      `json:"from_location_name"`
+	ToLocationID     uint                        `json:"to_location_id"`
+	ToLocationName   string                      `json:"to_location_name"`
+	TransferDate     string                      `json:"transfer_date"`
+	Notes            string                      `json:"notes"`
+	Items            []StockTransferItemResponse `json:"items"`
+}
@@ -0,0 +1,77 @@
+package dto
+
+// LoyaltyProgramInput adalah DTO untuk mengatur program poin loyalitas
+type LoyaltyProgramInput struct {
+	IsActive        bool    `json:"is_active"`
+	SpendPerPoint   float64 `json:"spend_per_point" binding:"gte=0"`   // Belanja (Rp) untuk mendapat 1 poin
+	PointValue      float64 `json:"point_value" binding:"gte=0"`       // Nilai potongan (Rp) per poin
+	MinRedeemPoints int     `json:"min_redeem_points" binding:"gte=0"` // Minimal poin sekali tukar
+	ExpiryMonths    int     `json:"expiry_months" binding:"gte=0"`     // 0 = poin tidak kedaluwarsa
+}
+
+// LoyaltyProgramResponse adalah DTO untuk pengaturan program poin
+type LoyaltyProgramResponse struct {
+	IsActive        bool    `json:"is_active"`
+	SpendPerPoint   float64 `json:"spend_per_point"`
+	PointValue      float64 `json:"point_value"`
+	MinRedeemPoints int     `json:"min_redeem_points"`
+	ExpiryMonths    int     `json:"expiry_months"`
+}
+
+// LoyaltyAdjustmentInput adalah DTO untuk koreksi manual poin pelanggan
+type LoyaltyAdjustmentInput struct {
+	Points int    `json:"points" binding:"required,ne=0"` // Positif menambah, negatif mengurangi
+	Notes  string `json:"notes" binding:"required"`
+}
+
+// LoyaltyLedgerEntryResponse adalah DTO untuk satu mutasi poin
+type LoyaltyLedgerEntryResponse struct {
+	ID              uint    `json:"id"`
+	Type            string  `json:"type"` // "EARN", "REDEEM", "EXPIRE", "REVERSE", "ADJUST"
+	TransactionID   *uint   `json:"transaction_id"`
+	Date            string  `json:"date"`
+	Points          int     `json:"points"`
+	Value           float64 `json:"value"`
+	RemainingPoints int     `json:"remaining_points"` // Sisa poin lot ini (hanya entri positif)
+	ExpiresAt       *string `json:"expires_at"`
+	Notes           string  `json:"notes"`
+}
+
+// CustomerLoyaltyResponse adalah DTO saldo & riwayat poin satu pelanggan
+type CustomerLoyaltyResponse struct {
+	CustomerID     uint                         `json:"customer_id"`
+	CustomerName   string                       `json:"customer_name"`
+	Points         int                          `json:"points"`          // Saldo poin yang masih berlaku
+	Value          float64                      `json:"value"`           // Nilai tukar saldo poin saat ini
+	ExpiringPoints int                          `json:"expiring_points"` // Poin yang hangus dalam 30 hari
+	NextExpiry     *string                      `json:"next_expiry"`
+	Entries        []LoyaltyLedgerEntryResponse `json:"entries"`
+}
+
+// ExpireLoyaltyResult adalah ringkasan proses penghangusan poin
+type ExpireLoyaltyResult struct {
+	ExpiredPoints int     `json:"expired_points"`
+	ExpiredValue  float64 `json:"expired_value"` // Liabilitas yang dilepas
+}
+
+// LoyaltyLiabilityRow adalah liabilitas poin untuk satu pelanggan
+type LoyaltyLiabilityRow struct {
+	CustomerID     uint    `json:"customer_id"`
+	CustomerName   string  `json:"customer_name"`
+	Points         int     `json:"points"`
+	Value          float64 `json:"value"`          // Nilai tukar dengan nilai poin saat ini
+	CarryingValue  float64 `json:"carrying_value"` // Nilai tercatat di akun Liabilitas Poin Loyalitas
+	ExpiringPoints int     `json:"expiring_points"`
+	NextExpiry     *string `json:"next_expiry"`
+}
+
+// LoyaltyLiabilityReport adalah laporan poin beredar yang belum ditukar
+type LoyaltyLiabilityReport struct {
+	PointValue          float64               `json:"point_value"`
+	TotalPoints         int                   `json:"total_points"`
+	TotalValue          float64               `json:"total_value"`
+	TotalCarryingValue  float64               `json:"total_carrying_value"`
+	ExpiringPoints      int                   `json:"expiring_points"`       // Hangus dalam 30 hari
+	PendingExpiryPoints int                   `json:"pending_expiry_points"` // Sudah lewat masa berlaku, belum diproses
+	Customers           []LoyaltyLiabilityRow `json:"customers"`
+}
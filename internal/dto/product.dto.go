@@ -8,9 +8,16 @@ type CreateProductInput struct {
 	PurchasePrice float64 `json:"purchase_price" binding:"gte=0"`
 	SellingPrice  float64 `json:"selling_price" binding:"required,gte=0"`
 	Stock         int     `json:"stock" binding:"gte=0"`
+	BaseUnit      string  `json:"base_unit"` // [BARU] Satuan dasar stok, default "pcs"
 	// --- [BARU] ---
 	BatasStokMinimum int `json:"batas_stok_minimum" binding:"omitempty,gte=0"`
 	// --- [AKHIR BARU] ---
+	TaxExempt  bool  `json:"tax_exempt"`  // [BARU] Bebas PPN
+	CategoryID *uint `json:"category_id"` // [BARU] Kategori produk (tipe INCOME), opsional
+	TrackLots  bool  `json:"track_lots"`  // [BARU] Lacak batch/lot & kedaluwarsa
+	// [BARU] Varian: isi parent_id dengan produk induk dan atribut pembedanya (cth: ukuran, warna)
+	ParentID   *uint                   `json:"parent_id"`
+	Attributes []ProductAttributeInput `json:"attributes" binding:"omitempty,dive"`
 }
 
 // UpdateProductInput adalah DTO untuk memperbarui produk
@@ -21,9 +28,27 @@ type UpdateProductInput struct {
 	PurchasePrice float64 `json:"purchase_price" binding:"omitempty,gte=0"`
 	SellingPrice  float64 `json:"selling_price" binding:"omitempty,gte=0"`
 	Stock         int     `json:"stock" binding:"omitempty,gte=0"`
+	BaseUnit      string  `json:"base_unit"` // [BARU] Kosong = tidak berubah
 	// --- [BARU] ---
 	BatasStokMinimum int `json:"batas_stok_minimum" binding:"omitempty,gte=0"`
 	// --- [AKHIR BARU] ---
+	TaxExempt  bool  `json:"tax_exempt"`  // [BARU] Bebas PPN
+	CategoryID *uint `json:"category_id"` // [BARU] Kategori produk (tipe INCOME), opsional
+	TrackLots  bool  `json:"track_lots"`  // [BARU] Lacak batch/lot & kedaluwarsa
+	// [BARU] Atribut varian; jika dikirim, menggantikan seluruh atribut lama (khusus varian)
+	Attributes []ProductAttributeInput `json:"attributes" binding:"omitempty,dive"`
+}
+
+// [BARU] ProductAttributeInput adalah satu atribut varian (cth: {"name": "Ukuran", "value": "XL"})
+type ProductAttributeInput struct {
+	Name  string `json:"name" binding:"required"`
+	Value string `json:"value" binding:"required"`
+}
+
+// [BARU] ProductAttributeResponse adalah atribut varian yang dikirim ke client
+type ProductAttributeResponse struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
 }
 
 // ProductResponse adalah DTO untuk data produk yang dikirim ke client
@@ -40,4 +65,143 @@ type ProductResponse struct {
 	// --- [BARU] ---
 	BatasStokMinimum int `json:"batas_stok_minimum"`
 	// --- [AKHIR BARU] ---
+	TaxExempt  bool  `json:"tax_exempt"`  // [BARU]
+	CategoryID *uint `json:"category_id"` // [BARU]
+	TrackLots  bool  `json:"track_lots"`  // [BARU]
+	// [BARU] Varian produk. Produk induk membawa daftar variannya dan Stock = total stok varian.
+	ParentID   *uint                      `json:"parent_id"`
+	Attributes []ProductAttributeResponse `json:"attributes"`
+	Variants   []ProductResponse          `json:"variants,omitempty"`
+	// [BARU] Produk komposit: Stock = jumlah yang masih bisa dibuat dari stok bahan
+	IsComposite bool `json:"is_composite"`
+	// [BARU] Satuan dasar (satuan Stock) dan satuan alternatif
+	BaseUnit string                `json:"base_unit"`
+	Units    []ProductUnitResponse `json:"units"`
+}
+
+// --- [BARU] DTO Satuan Produk ---
+
+// ProductUnitInput adalah satu satuan alternatif produk (cth: karton = 24 pcs)
+type ProductUnitInput struct {
+	Name         string  `json:"name" binding:"required"`
+	Factor       int     `json:"factor" binding:"required,gt=1"` // Jumlah satuan dasar per 1 satuan ini
+	AllowDecimal bool    `json:"allow_decimal"`
+	SellingPrice float64 `json:"selling_price" binding:"omitempty,gte=0"`
+}
+
+// SetProductUnitsInput adalah DTO untuk mengganti seluruh satuan alternatif produk
+type SetProductUnitsInput struct {
+	Units []ProductUnitInput `json:"units" binding:"dive"`
+}
+
+// ProductUnitResponse adalah satuan alternatif produk
+type ProductUnitResponse struct {
+	Name         string  `json:"name"`
+	Factor       int     `json:"factor"`
+	AllowDecimal bool    `json:"allow_decimal"`
+	SellingPrice float64 `json:"selling_price"`
+}
+
+// --- [BARU] DTO Resep Produk Komposit (Bill of Materials) ---
+
+// ProductComponentInput adalah satu bahan dalam resep produk komposit
+type ProductComponentInput struct {
+	ComponentID uint `json:"component_id" binding:"required"`
+	Quantity    int  `json:"quantity" binding:"required,gt=0"` // Jumlah bahan per 1 unit komposit
+}
+
+// SetProductComponentsInput adalah DTO untuk mengganti resep produk.
+// Daftar kosong menjadikan produk kembali produk biasa.
+type SetProductComponentsInput struct {
+	Components []ProductComponentInput `json:"components" binding:"dive"`
+}
+
+// ProductComponentResponse adalah satu baris resep
+type ProductComponentResponse struct {
+	ComponentID    uint    `json:"component_id"`
+	ComponentName  string  `json:"component_name"`
+	Quantity       int     `json:"quantity"`
+	ComponentStock int     `json:"component_stock"`
+	UnitCost       float64 `json:"unit_cost"` // Biaya persediaan per unit bahan saat ini
+}
+
+// ProductRecipeResponse adalah resep lengkap produk komposit
+type ProductRecipeResponse struct {
+	ProductID   uint                       `json:"product_id"`
+	ProductName string                     `json:"product_name"`
+	IsComposite bool                       `json:"is_composite"`
+	UnitCost    float64                    `json:"unit_cost"` // Perkiraan HPP per unit dari biaya bahan saat ini
+	Buildable   int                        `json:"buildable"` // Jumlah yang masih bisa dibuat dari stok bahan
+	Components  []ProductComponentResponse `json:"components"`
+}
+
+// --- [BARU] DTO Kartu Stok ---
+
+// StockMovementResponse adalah satu baris kartu stok
+type StockMovementResponse struct {
+	ID            uint   `json:"id"`
+	Date          string `json:"date"`
+	Reason        string `json:"reason"`   // SALE, PURCHASE, ADJUSTMENT, VOID, REFUND, OPNAME
+	Quantity      int    `json:"quantity"` // Positif = masuk, negatif = keluar
+	BalanceAfter  int    `json:"balance_after"`
+	TransactionID *uint  `json:"transaction_id"`
+	Notes         string `json:"notes"`
+}
+
+// StockCardResponse adalah kartu stok satu produk dalam rentang waktu
+type StockCardResponse struct {
+	ProductID      uint                    `json:"product_id"`
+	ProductName    string                  `json:"product_name"`
+	From           string                  `json:"from"`
+	To             string                  `json:"to"`
+	OpeningBalance int                     `json:"opening_balance"` // Stok sebelum 'from'
+	TotalIn        int                     `json:"total_in"`
+	TotalOut       int                     `json:"total_out"`
+	ClosingBalance int                     `json:"closing_balance"` // Stok pada akhir 'to'
+	Movements      []StockMovementResponse `json:"movements"`
+}
+
+// [BARU] CostLayerResponse adalah satu lapisan biaya FIFO yang masih tersisa
+type CostLayerResponse struct {
+	ID                uint    `json:"id"`
+	TransactionID     *uint   `json:"transaction_id"`
+	UnitCost          float64 `json:"unit_cost"`
+	Quantity          int     `json:"quantity"`
+	RemainingQuantity int     `json:"remaining_quantity"`
+	ReceivedAt        string  `json:"received_at"`
+}
+
+// --- [BARU] DTO Batch/Lot Produk ---
+
+// ProductBatchResponse adalah satu batch produk yang masih tersisa
+type ProductBatchResponse struct {
+	ID                uint    `json:"id"`
+	ProductID         uint    `json:"product_id"`
+	LotNumber         string  `json:"lot_number"`
+	ExpiryDate        *string `json:"expiry_date"` // YYYY-MM-DD, null jika tidak ada
+	Quantity          int     `json:"quantity"`
+	RemainingQuantity int     `json:"remaining_quantity"`
+	TransactionID     *uint   `json:"transaction_id"`
+	ReceivedAt        string  `json:"received_at"`
+}
+
+// WriteOffBatchInput adalah DTO untuk memusnahkan (write-off) batch kedaluwarsa/rusak
+type WriteOffBatchInput struct {
+	Quantity int    `json:"quantity" binding:"omitempty,gte=0"` // 0 / kosong = seluruh sisa batch
+	Reason   string `json:"reason" binding:"required"`
+	// [BARU] Lokasi asal stok yang dimusnahkan, default lokasi utama
+	LocationID *uint `json:"location_id"`
+}
+
+// BatchWriteOffResponse adalah hasil pemusnahan batch
+type BatchWriteOffResponse struct {
+	ID             uint    `json:"id"`
+	ProductBatchID uint    `json:"product_batch_id"`
+	ProductID      uint    `json:"product_id"`
+	LocationID     *uint   `json:"location_id"` // [BARU]
+	Quantity       int     `json:"quantity"`
+	UnitCost       float64 `json:"unit_cost"`
+	Amount         float64 `json:"amount"`
+	Reason         string  `json:"reason"`
+	CreatedAt      string  `json:"created_at"`
 }
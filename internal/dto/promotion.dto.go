@@ -0,0 +1,107 @@
+package dto
+
+import (
+	"github.com/danishyusrah/go_bisnis/internal/models"
+)
+
+// CreatePromotionInput adalah DTO untuk membuat/memperbarui promo
+type CreatePromotionInput struct {
+	Name     string               `json:"name" binding:"required"`
+	Type     models.PromotionType `json:"type" binding:"required,oneof=BUY_X_GET_Y BUNDLE_PRICE HAPPY_HOUR MIN_SPEND CATEGORY_DISCOUNT"`
+	IsActive *bool                `json:"is_active"` // Default: aktif
+
+	// Masa berlaku (opsional), format YYYY-MM-DD dan HH:MM
+	StartDate *string `json:"start_date" binding:"omitempty,datetime=2006-01-02"`
+	EndDate   *string `json:"end_date" binding:"omitempty,datetime=2006-01-02"`
+	StartTime string  `json:"start_time" binding:"omitempty,datetime=15:04"`
+	EndTime   string  `json:"end_time" binding:"omitempty,datetime=15:04"`
+
+	ProductID  *uint `json:"product_id"`
+	CategoryID *uint `json:"category_id"`
+
+	BuyQuantity   int                 `json:"buy_quantity" binding:"omitempty,gte=0"`
+	GetQuantity   int                 `json:"get_quantity" binding:"omitempty,gte=0"`
+	BundlePrice   float64             `json:"bundle_price" binding:"omitempty,gte=0"`
+	MinSpend      float64             `json:"min_spend" binding:"omitempty,gte=0"`
+	DiscountType  models.DiscountType `json:"discount_type" binding:"omitempty,oneof=PERCENT NOMINAL"`
+	DiscountValue float64             `json:"discount_value" binding:"omitempty,gte=0"`
+}
+
+// PromotionResponse adalah DTO untuk data promo
+type PromotionResponse struct {
+	ID            uint                 `json:"id"`
+	Name          string               `json:"name"`
+	Type          models.PromotionType `json:"type"`
+	IsActive      bool                 `json:"is_active"`
+	StartDate     *string              `json:"start_date"`
+	EndDate       *string              `json:"end_date"`
+	StartTime     string               `json:"start_time"`
+	EndTime       string               `json:"end_time"`
+	ProductID     *uint                `json:"product_id"`
+	CategoryID    *uint                `json:"category_id"`
+	BuyQuantity   int                  `json:"buy_quantity"`
+	GetQuantity   int                  `json:"get_quantity"`
+	BundlePrice   float64              `json:"bundle_price"`
+	MinSpend      float64              `json:"min_spend"`
+	DiscountType  models.DiscountType  `json:"discount_type,omitempty"`
+	DiscountValue float64              `json:"discount_value"`
+}
+
+// AppliedPromotionResponse adalah promo yang diterapkan pada transaksi (atau pratinjau harga)
+type AppliedPromotionResponse struct {
+	PromotionID    uint                 `json:"promotion_id"`
+	Name           string               `json:"name"`
+	Type           models.PromotionType `json:"type"`
+	DiscountAmount float64              `json:"discount_amount"`
+}
+
+// --- Pratinjau Harga (POS) ---
+
+// PricePreviewInput adalah DTO untuk menghitung harga keranjang sebelum checkout
+type PricePreviewInput struct {
+	Items           []CreateTransactionItemInput `json:"items" binding:"required,min=1,dive"`
+	TaxRateID       *uint                        `json:"tax_rate_id"`
+	DiscountType    models.DiscountType          `json:"discount_type" binding:"omitempty,oneof=PERCENT NOMINAL"`
+	DiscountValue   float64                      `json:"discount_value" binding:"omitempty,gte=0"`
+	TransactionDate *string                      `json:"transaction_date" binding:"omitempty,datetime=2006-01-02"`
+}
+
+// PricePreviewItem adalah rincian harga satu baris keranjang
+type PricePreviewItem struct {
+	ProductID                 *uint   `json:"product_id"`
+	ProductName               string  `json:"product_name"`
+	Quantity                  int     `json:"quantity"` // [DIUBAH] Dalam satuan dasar produk
+	UnitPrice                 float64 `json:"unit_price"`
+	Unit                      string  `json:"unit"`          // [BARU]
+	UnitQuantity              float64 `json:"unit_quantity"` // [BARU]
+	GrossAmount               float64 `json:"gross_amount"`
+	DiscountAmount            float64 `json:"discount_amount"`
+	PromotionDiscountAmount   float64 `json:"promotion_discount_amount"`
+	TransactionDiscountAmount float64 `json:"transaction_discount_amount"`
+	TaxableAmount             float64 `json:"taxable_amount"`
+	TaxAmount                 float64 `json:"tax_amount"`
+	TotalAmount               float64 `json:"total_amount"`
+}
+
+// PricePreviewResponse adalah hasil pratinjau harga (sama dengan perhitungan saat transaksi disimpan)
+type PricePreviewResponse struct {
+	Items           []PricePreviewItem         `json:"items"`
+	GrossAmount     float64                    `json:"gross_amount"`
+	ItemDiscount    float64                    `json:"item_discount"`   // Total diskon manual per item
+	DiscountAmount  float64                    `json:"discount_amount"` // Diskon manual level transaksi
+	PromotionAmount float64                    `json:"promotion_amount"`
+	TaxAmount       float64                    `json:"tax_amount"`
+	TotalAmount     float64                    `json:"total_amount"`
+	Promotions      []AppliedPromotionResponse `json:"promotions"`
+}
+
+// --- Laporan Promo ---
+
+// PromotionReportLine adalah rekap pemakaian satu promo dalam periode
+type PromotionReportLine struct {
+	PromotionID      uint                 `json:"promotion_id"`
+	Name             string               `json:"name"`
+	Type             models.PromotionType `json:"type"`
+	TransactionCount int64                `json:"transaction_count"`
+	TotalDiscount    float64              `json:"total_discount"`
+}
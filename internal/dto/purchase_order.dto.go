@@ -0,0 +1,98 @@
+package dto
+
+// PurchaseOrderItemInput adalah satu baris pesanan. Jumlah dalam satuan dasar produk,
+// UnitCost adalah harga beli per unit sebelum PPN.
+type PurchaseOrderItemInput struct {
+	ProductID uint    `json:"product_id" binding:"required"`
+	Quantity  int     `json:"quantity" binding:"required,gt=0"`
+	UnitCost  float64 `json:"unit_cost" binding:"gte=0"`
+}
+
+// PurchaseOrderInput adalah DTO untuk membuat PO baru atau mengubah PO yang masih DRAFT
+type PurchaseOrderInput struct {
+	SupplierID   uint                     `json:"supplier_id" binding:"required"`
+	LocationID   *uint                    `json:"location_id"` // Lokasi penerimaan, default lokasi utama
+	TaxRateID    *uint                    `json:"tax_rate_id"` // Tarif PPN saat ditagih (opsional)
+	OrderDate    *string                  `json:"order_date" binding:"omitempty,datetime=2006-01-02"`
+	ExpectedDate *string                  `json:"expected_date" binding:"omitempty,datetime=2006-01-02"`
+	Notes        string                   `json:"notes"`
+	Items        []PurchaseOrderItemInput `json:"items" binding:"required,min=1,dive"`
+}
+
+// GoodsReceiptItemInput adalah jumlah yang diterima untuk satu baris PO
+type GoodsReceiptItemInput struct {
+	PurchaseOrderItemID uint    `json:"purchase_order_item_id" binding:"required"`
+	Quantity            int     `json:"quantity" binding:"required,gt=0"`
+	LotNumber           string  `json:"lot_number"`  // Untuk produk dengan pelacakan batch
+	ExpiryDate          *string `json:"expiry_date"` // YYYY-MM-DD
+}
+
+// CreateGoodsReceiptInput adalah DTO untuk mencatat penerimaan barang (boleh sebagian)
+type CreateGoodsReceiptInput struct {
+	ReceiptDate *string                 `json:"receipt_date" binding:"omitempty,datetime=2006-01-02"` // Default hari ini
+	Notes       string                  `json:"notes"`
+	Items       []GoodsReceiptItemInput `json:"items" binding:"required,min=1,dive"`
+}
+
+// BillPurchaseOrderInput adalah DTO untuk mencatat tagihan supplier atas barang yang sudah diterima
+type BillPurchaseOrderInput struct {
+	InvoiceNumber   string  `json:"invoice_number"` // Nomor faktur supplier
+	TransactionDate *string `json:"transaction_date" binding:"omitempty,datetime=2006-01-02"`
+	DueDate         *string `json:"due_date" binding:"omitempty,datetime=2006-01-02"`
+	CategoryID      *uint   `json:"category_id"` // Kategori pengeluaran (opsional)
+	Notes           string  `json:"notes"`
+}
+
+// PurchaseOrderItemResponse adalah DTO untuk satu baris PO
+type PurchaseOrderItemResponse struct {
+	ID                uint    `json:"id"`
+	ProductID         uint    `json:"product_id"`
+	ProductName       string  `json:"product_name"`
+	Quantity          int     `json:"quantity"`
+	ReceivedQuantity  int     `json:"received_quantity"`
+	RemainingQuantity int     `json:"remaining_quantity"`
+	UnitCost          float64 `json:"unit_cost"`
+	Subtotal          float64 `json:"subtotal"` // Quantity * UnitCost
+}
+
+// GoodsReceiptItemResponse adalah DTO untuk satu baris penerimaan barang
+type GoodsReceiptItemResponse struct {
+	PurchaseOrderItemID uint    `json:"purchase_order_item_id"`
+	ProductID           uint    `json:"product_id"`
+	ProductName         string  `json:"product_name"`
+	Quantity            int     `json:"quantity"`
+	UnitCost            float64 `json:"unit_cost"`
+	LotNumber           string  `json:"lot_number,omitempty"`
+	ExpiryDate          *string `json:"expiry_date"`
+}
+
+// GoodsReceiptResponse adalah DTO untuk dokumen penerimaan barang
+type GoodsReceiptResponse struct {
+	ID              uint                       `json:"id"`
+	PurchaseOrderID uint                       `json:"purchase_order_id"`
+	LocationID      uint                       `json:"location_id"`
+	ReceiptDate     string                     `json:"receipt_date"`
+	Notes           string                     `json:"notes"`
+	TotalAmount     float64                    `json:"total_amount"`
+	Items           []GoodsReceiptItemResponse `json:"items"`
+}
+
+// PurchaseOrderResponse adalah DTO untuk data PO
+type PurchaseOrderResponse struct {
+	ID                uint                        `json:"id"`
+	SupplierID        uint                        `json:"supplier_id"`
+	SupplierName      string                      `json:"supplier_name"`
+	LocationID        uint                        `json:"location_id"`
+	TaxRateID         *uint                       `json:"tax_rate_id"`
+	Status            string                      `json:"status"`
+	OrderDate         string                      `json:"order_date"`
+	ExpectedDate      *string                     `json:"expected_date"`
+	Notes             string                      `json:"notes"`
+	OrderedAt         *string                     `json:"ordered_at"`
+	BilledAt          *string                     `json:"billed_at"`
+	BillTransactionID *uint                       `json:"bill_transaction_id"`
+	TotalAmount       float64                     `json:"total_amount"`    // Nilai pesanan sebelum PPN
+	ReceivedAmount    float64                     `json:"received_amount"` // Nilai barang yang sudah diterima
+	Items             []PurchaseOrderItemResponse `json:"items"`
+	Receipts          []GoodsReceiptResponse      `json:"receipts,omitempty"`
+}
@@ -6,6 +6,10 @@ type ProductPerformanceReport struct {
 	ProductName  string  `json:"product_name"`  // Nama produk
 	TotalSold    int64   `json:"total_sold"`    // Total kuantitas terjual
 	TotalRevenue float64 `json:"total_revenue"` // Total pendapatan dari produk ini
+	// [BARU] Rincian diskon (tanpa PPN). NetSales = GrossSales - TotalDiscount = TotalRevenue
+	GrossSales    float64 `json:"gross_sales"`
+	TotalDiscount float64 `json:"total_discount"`
+	NetSales      float64 `json:"net_sales"`
 }
 
 // --- [BARU] Struct untuk Laporan Buku Besar (General Ledger) ---
@@ -21,6 +25,7 @@ type LedgerEntry struct {
 
 // GeneralLedgerReport adalah DTO lengkap untuk laporan buku besar
 type GeneralLedgerReport struct {
+	Basis            string        `json:"basis"`             // [BARU] "accrual" atau "cash"
 	BeginningBalance float64       `json:"beginning_balance"` // Saldo Awal
 	Entries          []LedgerEntry `json:"entries"`           // Daftar semua entri transaksi
 	TotalDebit       float64       `json:"total_debit"`
@@ -34,11 +39,35 @@ type GeneralLedgerReport struct {
 type UnpaidTransactionItem struct {
 	TransactionID uint    `json:"transaction_id"`
 	CustomerName  string  `json:"customer_name"` // Nama Pelanggan (Piutang) or Supplier (Utang)
-	Amount        float64 `json:"amount"`
+	TotalAmount   float64 `json:"total_amount"`  // [BARU] Nilai transaksi setelah retur
+	PaidAmount    float64 `json:"paid_amount"`   // [BARU] Sudah dibayar (cicilan)
+	Amount        float64 `json:"amount"`        // [DIUBAH] Sisa yang belum dibayar
 	CreatedAt     string  `json:"created_at"`
 	DueDate       *string `json:"due_date"`     // "YYYY-MM-DD" or null
 	IsOverdue     bool    `json:"is_overdue"`   // Dihitung di backend
 	PrimaryItem   string  `json:"primary_item"` // Nama item pertama
+	// [BARU] Analisis umur utang/piutang
+	DaysOverdue int    `json:"days_overdue"` // 0 jika belum jatuh tempo
+	AgingBucket string `json:"aging_bucket"` // "CURRENT", "1-30", "31-60", "61-90", "90+"
+}
+
+// --- [BARU] Struct untuk Analisis Umur (Aging) ---
+
+// AgingBuckets adalah total saldo per kelompok umur (hari lewat jatuh tempo)
+type AgingBuckets struct {
+	Current    float64 `json:"current"`      // Belum jatuh tempo (atau tanpa jatuh tempo)
+	Days1To30  float64 `json:"days_1_30"`    // Lewat 1-30 hari
+	Days31To60 float64 `json:"days_31_60"`   // Lewat 31-60 hari
+	Days61To90 float64 `json:"days_61_90"`   // Lewat 61-90 hari
+	Days90Plus float64 `json:"days_90_plus"` // Lewat lebih dari 90 hari
+	Total      float64 `json:"total"`
+}
+
+// AgingByParty adalah ringkasan umur saldo untuk satu pelanggan/supplier
+type AgingByParty struct {
+	PartyID   *uint        `json:"party_id"` // null untuk "Umum"
+	PartyName string       `json:"party_name"`
+	Aging     AgingBuckets `json:"aging"`
 }
 
 // UnpaidReport adalah DTO lengkap untuk laporan utang/piutang
@@ -47,4 +76,104 @@ type UnpaidReport struct {
 	TotalPayable    float64                 `json:"total_payable"`    // Total Utang (EXPENSE belum lunas)
 	Receivables     []UnpaidTransactionItem `json:"receivables"`      // Daftar Piutang
 	Payables        []UnpaidTransactionItem `json:"payables"`         // Daftar Utang
+
+	// [BARU] Analisis umur per kelompok dan per pelanggan/supplier
+	ReceivableAging       AgingBuckets   `json:"receivable_aging"`
+	PayableAging          AgingBuckets   `json:"payable_aging"`
+	ReceivablesByCustomer []AgingByParty `json:"receivables_by_customer"`
+	PayablesBySupplier    []AgingByParty `json:"payables_by_supplier"`
+}
+
+// --- [BARU] Struct untuk Laporan Laba Rugi (Profit & Loss) ---
+
+// ProfitLossLine adalah satu baris pendapatan/beban per kategori
+type ProfitLossLine struct {
+	CategoryID       *uint    `json:"category_id"` // null untuk "Tanpa Kategori"
+	CategoryName     string   `json:"category_name"`
+	Amount           float64  `json:"amount"`
+	PercentOfRevenue float64  `json:"percent_of_revenue"`        // Persentase terhadap total pendapatan
+	PreviousAmount   *float64 `json:"previous_amount,omitempty"` // Nilai periode sebelumnya (jika diminta)
+}
+
+// ProfitLossSummary adalah ringkasan angka utama laporan laba rugi
+type ProfitLossSummary struct {
+	TotalRevenue          float64 `json:"total_revenue"`
+	TotalCOGS             float64 `json:"total_cogs"`
+	GrossProfit           float64 `json:"gross_profit"`
+	TotalOperatingExpense float64 `json:"total_operating_expense"` // [DIUBAH] Termasuk selisih stok opname
+	InventoryShrinkage    float64 `json:"inventory_shrinkage"`     // [BARU] Beban selisih persediaan bersih
+	NetProfit             float64 `json:"net_profit"`
+	GrossMarginPercent    float64 `json:"gross_margin_percent"`
+	NetMarginPercent      float64 `json:"net_margin_percent"`
+}
+
+// ProfitLossReport adalah DTO lengkap untuk laporan laba rugi
+type ProfitLossReport struct {
+	From              string            `json:"from"`
+	To                string            `json:"to"`
+	Revenue           []ProfitLossLine  `json:"revenue"`            // Pendapatan per kategori
+	OperatingExpenses []ProfitLossLine  `json:"operating_expenses"` // Beban operasional per kategori
+	Summary           ProfitLossSummary `json:"summary"`
+
+	// Kolom pembanding (periode sebelumnya dengan durasi yang sama), opsional
+	ComparisonFrom *string            `json:"comparison_from,omitempty"`
+	ComparisonTo   *string            `json:"comparison_to,omitempty"`
+	Comparison     *ProfitLossSummary `json:"comparison,omitempty"`
+}
+
+// --- [BARU] Struct untuk Laporan Neraca (Balance Sheet) ---
+
+// BalanceSheetLine adalah satu pos dalam neraca
+type BalanceSheetLine struct {
+	Name   string  `json:"name"`
+	Amount float64 `json:"amount"`
+}
+
+// BalanceSheetSection adalah satu kelompok pos (Aset, Kewajiban, Ekuitas)
+type BalanceSheetSection struct {
+	Lines []BalanceSheetLine `json:"lines"`
+	Total float64            `json:"total"`
+}
+
+// BalanceSheetReport adalah DTO lengkap untuk laporan neraca
+type BalanceSheetReport struct {
+	AsOf        string              `json:"as_of"`
+	Assets      BalanceSheetSection `json:"assets"`
+	Liabilities BalanceSheetSection `json:"liabilities"`
+	Equity      BalanceSheetSection `json:"equity"`
+
+	TotalLiabilitiesAndEquity float64 `json:"total_liabilities_and_equity"`
+	// Discrepancy = Aset - (Kewajiban + Ekuitas). Idealnya 0.
+	Discrepancy float64 `json:"discrepancy"`
+	IsBalanced  bool    `json:"is_balanced"`
+}
+
+// --- [BARU] Struct untuk Laporan Arus Kas (Cash Flow) ---
+
+// CashFlowLine adalah satu baris arus kas per tipe transaksi & kategori
+type CashFlowLine struct {
+	CategoryID   *uint   `json:"category_id"` // null untuk "Tanpa Kategori"
+	CategoryName string  `json:"category_name"`
+	Type         string  `json:"type"`   // INCOME, EXPENSE, atau CAPITAL
+	Amount       float64 `json:"amount"` // Positif = kas masuk, negatif = kas keluar
+}
+
+// CashFlowSection adalah satu bagian laporan arus kas (Operasi, Investasi, Pendanaan)
+type CashFlowSection struct {
+	Lines   []CashFlowLine `json:"lines"`
+	Inflow  float64        `json:"inflow"`
+	Outflow float64        `json:"outflow"`
+	NetCash float64        `json:"net_cash"`
+}
+
+// CashFlowReport adalah DTO lengkap untuk laporan arus kas (basis kas)
+type CashFlowReport struct {
+	From          string          `json:"from"`
+	To            string          `json:"to"`
+	BeginningCash float64         `json:"beginning_cash"`
+	Operating     CashFlowSection `json:"operating"`
+	Investing     CashFlowSection `json:"investing"`
+	Financing     CashFlowSection `json:"financing"`
+	NetChange     float64         `json:"net_change"`
+	EndingCash    float64         `json:"ending_cash"`
 }
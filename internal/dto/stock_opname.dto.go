@@ -0,0 +1,64 @@
+package dto
+
+// CreateStockOpnameInput adalah DTO untuk membuka sesi stok opname baru
+type CreateStockOpnameInput struct {
+	Notes      string `json:"notes"`
+	LocationID *uint  `json:"location_id"` // [BARU] Lokasi yang dihitung, default lokasi utama
+}
+
+// StockOpnameCountItemInput adalah hasil hitung fisik satu produk
+type StockOpnameCountItemInput struct {
+	ProductID uint `json:"product_id" binding:"required"`
+	Quantity  int  `json:"quantity" binding:"gte=0"`
+}
+
+// AddStockOpnameCountsInput adalah DTO untuk memasukkan hasil hitung (boleh berkali-kali per produk)
+type AddStockOpnameCountsInput struct {
+	SessionLabel string                      `json:"session_label"` // cth: "Rak A"
+	Items        []StockOpnameCountItemInput `json:"items" binding:"required,min=1,dive"`
+}
+
+// StockOpnameCountResponse adalah satu entri hitung
+type StockOpnameCountResponse struct {
+	ID             uint   `json:"id"`
+	ProductID      uint   `json:"product_id"`
+	Quantity       int    `json:"quantity"`
+	SystemQuantity *int   `json:"system_quantity"` // [BARU] Stok sistem saat hitungan dimasukkan
+	SessionLabel   string `json:"session_label"`
+	CreatedAt      string `json:"created_at"`
+}
+
+// StockOpnameResponse adalah DTO untuk data sesi stok opname
+type StockOpnameResponse struct {
+	ID              uint                       `json:"id"`
+	Notes           string                     `json:"notes"`
+	LocationID      *uint                      `json:"location_id"` // [BARU]
+	Status          string                     `json:"status"`
+	CreatedAt       string                     `json:"created_at"`
+	FinalizedAt     *string                    `json:"finalized_at"`
+	ShrinkageAmount float64                    `json:"shrinkage_amount"`
+	SurplusAmount   float64                    `json:"surplus_amount"`
+	Counts          []StockOpnameCountResponse `json:"counts"`
+}
+
+// StockOpnameVarianceLine adalah perbandingan stok sistem vs hitung fisik satu produk
+type StockOpnameVarianceLine struct {
+	ProductID       uint    `json:"product_id"`
+	ProductName     string  `json:"product_name"`
+	SystemQuantity  int     `json:"system_quantity"`
+	CountedQuantity int     `json:"counted_quantity"`
+	Variance        int     `json:"variance"`       // Hitung - Sistem (negatif = kurang)
+	UnitCost        float64 `json:"unit_cost"`      // Harga beli
+	VarianceValue   float64 `json:"variance_value"` // Variance * UnitCost
+}
+
+// StockOpnameVarianceReport adalah laporan selisih stok opname.
+// [DIUBAH] Stok sistem adalah stok saat hitungan terakhir produk tersebut dimasukkan; setelah FINALIZED, snapshot hasil.
+type StockOpnameVarianceReport struct {
+	StockOpnameID   uint                      `json:"stock_opname_id"`
+	Status          string                    `json:"status"`
+	Lines           []StockOpnameVarianceLine `json:"lines"`
+	ShrinkageAmount float64                   `json:"shrinkage_amount"` // Total nilai selisih kurang
+	SurplusAmount   float64                   `json:"surplus_amount"`   // Total nilai selisih lebih
+	NetVariance     float64                   `json:"net_variance"`     // Surplus - Shrinkage
+}
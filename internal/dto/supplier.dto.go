@@ -0,0 +1,44 @@
+package dto
+
+// SupplierResponse adalah DTO untuk data supplier yang dikirim ke client
+type SupplierResponse struct {
+	ID        uint   `json:"id"`
+	Name      string `json:"name"`
+	Email     string `json:"email"`
+	Phone     string `json:"phone"`
+	Address   string `json:"address"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+// CreateSupplierInput adalah DTO untuk membuat supplier baru
+type CreateSupplierInput struct {
+	Name    string `json:"name" binding:"required"`
+	Email   string `json:"email" binding:"omitempty,email"`
+	Phone   string `json:"phone"`
+	Address string `json:"address"`
+}
+
+// UpdateSupplierInput adalah DTO untuk memperbarui supplier
+type UpdateSupplierInput struct {
+	Name    string `json:"name" binding:"omitempty"`
+	Email   string `json:"email" binding:"omitempty,email"`
+	Phone   string `json:"phone"`
+	Address string `json:"address"`
+}
+
+// SupplierPurchaseSummary adalah ringkasan pembelian & utang untuk satu supplier
+type SupplierPurchaseSummary struct {
+	SupplierID       *uint   `json:"supplier_id"` // null untuk pembelian tanpa supplier
+	SupplierName     string  `json:"supplier_name"`
+	TransactionCount int64   `json:"transaction_count"`
+	TotalPurchases   float64 `json:"total_purchases"` // Total pembelian setelah retur
+	TotalPaid        float64 `json:"total_paid"`
+	Outstanding      float64 `json:"outstanding"` // Sisa utang
+}
+
+// SupplierPurchaseHistory adalah riwayat pembelian dari satu supplier beserta ringkasannya
+type SupplierPurchaseHistory struct {
+	Summary      SupplierPurchaseSummary `json:"summary"`
+	Transactions []TransactionResponse   `json:"transactions"`
+}
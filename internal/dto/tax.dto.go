@@ -0,0 +1,43 @@
+package dto
+
+// CreateTaxRateInput adalah DTO untuk membuat/memperbarui tarif pajak
+type CreateTaxRateInput struct {
+	Name      string  `json:"name" binding:"required"`
+	Rate      float64 `json:"rate" binding:"gte=0,lte=100"` // Persentase, cth: 11
+	IsDefault bool    `json:"is_default"`
+}
+
+// TaxRateResponse adalah DTO untuk data tarif pajak
+type TaxRateResponse struct {
+	ID        uint    `json:"id"`
+	Name      string  `json:"name"`
+	Rate      float64 `json:"rate"`
+	IsDefault bool    `json:"is_default"`
+}
+
+// TaxReportLine adalah rekap PPN per tarif
+type TaxReportLine struct {
+	TaxRate          float64 `json:"tax_rate"`
+	TaxableSales     float64 `json:"taxable_sales"`     // DPP penjualan
+	OutputVAT        float64 `json:"output_vat"`        // PPN Keluaran
+	TaxablePurchases float64 `json:"taxable_purchases"` // DPP pembelian
+	InputVAT         float64 `json:"input_vat"`         // PPN Masukan
+}
+
+// TaxReport adalah DTO lengkap untuk laporan PPN (Keluaran vs Masukan) satu periode
+type TaxReport struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+
+	OutputVAT         float64 `json:"output_vat"`          // PPN dari penjualan
+	OutputVATReturned float64 `json:"output_vat_returned"` // PPN dari retur penjualan
+	InputVAT          float64 `json:"input_vat"`           // PPN dari pembelian
+	InputVATReturned  float64 `json:"input_vat_returned"`  // PPN dari retur pembelian
+
+	NetOutputVAT float64 `json:"net_output_vat"`
+	NetInputVAT  float64 `json:"net_input_vat"`
+	// VATPayable = PPN Keluaran bersih - PPN Masukan bersih (negatif = lebih bayar)
+	VATPayable float64 `json:"vat_payable"`
+
+	ByRate []TaxReportLine `json:"by_rate"`
+}
@@ -6,10 +6,23 @@ import (
 
 // CreateTransactionItemInput adalah DTO untuk satu item dalam transaksi
 type CreateTransactionItemInput struct {
-	ProductID   *uint   `json:"product_id"` // ID Produk (nullable, jika ini item non-produk)
-	ProductName string  `json:"product_name" binding:"required"`
-	Quantity    int     `json:"quantity" binding:"required,gt=0"`
-	UnitPrice   float64 `json:"unit_price" binding:"required,gte=0"`
+	ProductID   *uint  `json:"product_id"` // ID Produk (nullable, jika ini item non-produk)
+	ProductName string `json:"product_name" binding:"required"`
+	// [DIUBAH] Jumlah dalam satuan 'unit' (desimal hanya jika satuannya mengizinkan),
+	// UnitPrice adalah harga per satuan tersebut
+	Quantity  float64 `json:"quantity" binding:"required,gt=0"`
+	UnitPrice float64 `json:"unit_price" binding:"required,gte=0"`
+	Unit      string  `json:"unit"` // [BARU] Nama satuan produk (cth: karton), kosong = satuan dasar
+	// [BARU] Pajak per item. Jika TaxRateID kosong, tarif level transaksi (jika ada) yang dipakai.
+	// TaxInclusive = true berarti UnitPrice sudah termasuk pajak.
+	TaxRateID    *uint `json:"tax_rate_id"`
+	TaxInclusive bool  `json:"tax_inclusive"`
+	// [BARU] Diskon per item: PERCENT (0-100) atau NOMINAL (potongan rupiah untuk baris ini)
+	DiscountType  models.DiscountType `json:"discount_type" binding:"omitempty,oneof=PERCENT NOMINAL"`
+	DiscountValue float64             `json:"discount_value" binding:"omitempty,gte=0"`
+	// [BARU] Lot & kedaluwarsa untuk restock (EXPENSE) produk dengan pelacakan batch
+	LotNumber  string  `json:"lot_number"`
+	ExpiryDate *string `json:"expiry_date"` // YYYY-MM-DD
 }
 
 // CreateTransactionInput adalah DTO untuk membuat transaksi baru
@@ -19,7 +32,8 @@ type CreateTransactionInput struct {
 	// [PERUBAHAN] Items sekarang opsional (omitempty), tapi jika ada, minimal 1 (min=1)
 	// 'dive' berarti validasi akan dijalankan pada setiap item di dalam array
 	Items      []CreateTransactionItemInput `json:"items" binding:"omitempty,min=1,dive"`
-	CustomerID *uint                        `json:"customer_id"`
+	CustomerID *uint                        `json:"customer_id"` // Pelanggan, hanya untuk INCOME
+	SupplierID *uint                        `json:"supplier_id"` // [BARU] Supplier, hanya untuk EXPENSE
 	// [BARU] TotalAmount adalah untuk transaksi non-item (seperti Modal)
 	// Ini juga opsional, dan hanya akan digunakan jika tipenya 'CAPITAL'
 	TotalAmount float64 `json:"total_amount" binding:"omitempty,gte=0"`
@@ -29,10 +43,33 @@ type CreateTransactionInput struct {
 	PaymentStatus models.PaymentStatusType `json:"payment_status" binding:"omitempty,oneof=LUNAS 'BELUM LUNAS' ''"`
 	// Kita terima sebagai string pointer, format YYYY-MM-DD
 	DueDate *string `json:"due_date" binding:"omitempty,datetime=2006-01-02"`
+	// [BARU] Tanggal transaksi (YYYY-MM-DD), default hari ini. Tidak boleh di periode yang sudah ditutup
+	TransactionDate *string `json:"transaction_date" binding:"omitempty,datetime=2006-01-02"`
+	// [BARU] Metode pembayaran untuk transaksi yang langsung LUNAS (default: TUNAI)
+	PaymentMethod models.PaymentMethodType `json:"payment_method" binding:"omitempty,oneof=TUNAI TRANSFER QRIS LAINNYA"`
 
 	// --- [BARU UNTUK FITUR KATEGORI] ---
 	CategoryID *uint `json:"category_id"` // Opsional, hanya untuk Pemasukan/Pengeluaran
 	// --- [AKHIR BARU] ---
+
+	// [BARU] Tarif pajak default untuk semua item yang tidak menentukan tarifnya sendiri
+	TaxRateID *uint `json:"tax_rate_id"`
+
+	// [BARU] Diskon level transaksi, dihitung dari subtotal setelah diskon item
+	DiscountType  models.DiscountType `json:"discount_type" binding:"omitempty,oneof=PERCENT NOMINAL"`
+	DiscountValue float64             `json:"discount_value" binding:"omitempty,gte=0"`
+
+	// [BARU] Lokasi (gudang/outlet) tempat stok keluar/masuk, default lokasi utama
+	LocationID *uint `json:"location_id"`
+
+	// [BARU] Paksa penjualan BELUM LUNAS meskipun pelanggan melewati batas kredit atau sedang ditahan
+	CreditOverride bool `json:"credit_override"`
+
+	// [BARU] Jumlah poin loyalitas pelanggan yang ditukar sebagai potongan (hanya INCOME)
+	RedeemPoints int `json:"redeem_points" binding:"omitempty,gte=0"`
+
+	// [BARU] Hanya diisi oleh layanan PO saat menagih pesanan pembelian, tidak bisa dikirim lewat request
+	PurchaseOrderID *uint `json:"-"`
 }
 
 // TransactionItemResponse adalah DTO untuk detail item dalam respons
@@ -40,8 +77,25 @@ type TransactionItemResponse struct {
 	ID          uint    `json:"id"`
 	ProductID   *uint   `json:"product_id"`
 	ProductName string  `json:"product_name"`
-	Quantity    int     `json:"quantity"`
+	Quantity    int     `json:"quantity"` // [DIUBAH] Dalam satuan dasar produk
 	UnitPrice   float64 `json:"unit_price"`
+	// [BARU] Satuan transaksi: jumlah & harga di atas berlaku untuk satuan ini
+	Unit         string  `json:"unit"`
+	UnitFactor   int     `json:"unit_factor"`
+	UnitQuantity float64 `json:"unit_quantity"`
+	// [BARU] Jumlah unit yang sudah diretur
+	RefundedQuantity int `json:"refunded_quantity"`
+	// [BARU] Pajak per item
+	TaxRate       float64 `json:"tax_rate"`
+	TaxInclusive  bool    `json:"tax_inclusive"`
+	TaxableAmount float64 `json:"taxable_amount"` // DPP
+	TaxAmount     float64 `json:"tax_amount"`
+	// [BARU] Diskon per item
+	DiscountType              models.DiscountType `json:"discount_type,omitempty"`
+	DiscountValue             float64             `json:"discount_value"`
+	DiscountAmount            float64             `json:"discount_amount"`
+	TransactionDiscountAmount float64             `json:"transaction_discount_amount"`
+	PromotionDiscountAmount   float64             `json:"promotion_discount_amount"` // [BARU] Potongan promo item
 }
 
 // TransactionResponse adalah DTO untuk data transaksi lengkap
@@ -55,7 +109,11 @@ type TransactionResponse struct {
 
 	// Informasi pelanggan yang terstruktur
 	CustomerID   *uint  `json:"customer_id"`
-	CustomerName string `json:"customer_name"` // Kita akan isi nama pelanggan di sini
+	CustomerName string `json:"customer_name"` // Kita akan isi nama pelanggan di sini. [DIUBAH] Untuk EXPENSE berisi nama supplier
+
+	// [BARU] Supplier (khusus EXPENSE)
+	SupplierID   *uint  `json:"supplier_id"`
+	SupplierName string `json:"supplier_name"`
 
 	// [BARU UNTUK FITUR UTANG/PIUTANG]
 	PaymentStatus models.PaymentStatusType `json:"payment_status"`
@@ -65,4 +123,107 @@ type TransactionResponse struct {
 	CategoryID   *uint  `json:"category_id"`
 	CategoryName string `json:"category_name"` // Kita akan isi nama kategori di sini
 	// --- [AKHIR BARU] ---
+
+	// --- [BARU UNTUK FITUR MULTI LOKASI] ---
+	LocationID   *uint  `json:"location_id"`
+	LocationName string `json:"location_name"`
+	// --- [AKHIR BARU] ---
+
+	// [BARU] PO yang ditagih oleh transaksi ini (null jika bukan tagihan PO)
+	PurchaseOrderID *uint `json:"purchase_order_id"`
+
+	CreditOverride bool `json:"credit_override"` // [BARU] Dibuat dengan mengabaikan batas kredit pelanggan
+
+	// [BARU] Poin loyalitas
+	LoyaltyPointsEarned   int     `json:"loyalty_points_earned"`
+	LoyaltyPointsRedeemed int     `json:"loyalty_points_redeemed"`
+	LoyaltyDiscountAmount float64 `json:"loyalty_discount_amount"`
+
+	// --- [BARU UNTUK FITUR VOID & RETUR] ---
+	Status         models.TransactionStatusType `json:"status"`
+	RefundedAmount float64                      `json:"refunded_amount"`
+	VoidReason     string                       `json:"void_reason,omitempty"`
+	VoidedAt       *string                      `json:"voided_at"`
+	Refunds        []RefundResponse             `json:"refunds"`
+	// --- [AKHIR BARU] ---
+
+	// --- [BARU UNTUK FITUR CICILAN] ---
+	PaidAmount        float64           `json:"paid_amount"`
+	OutstandingAmount float64           `json:"outstanding_amount"`
+	Payments          []PaymentResponse `json:"payments"`
+	// --- [AKHIR BARU] ---
+
+	// --- [BARU UNTUK FITUR PAJAK] ---
+	TaxAmount         float64 `json:"tax_amount"`
+	RefundedTaxAmount float64 `json:"refunded_tax_amount"`
+	// --- [AKHIR BARU] ---
+
+	// --- [BARU UNTUK FITUR DISKON] ---
+	GrossAmount    float64             `json:"gross_amount"`
+	DiscountType   models.DiscountType `json:"discount_type,omitempty"`
+	DiscountValue  float64             `json:"discount_value"`
+	DiscountAmount float64             `json:"discount_amount"` // Diskon level transaksi
+	// --- [AKHIR BARU] ---
+
+	// --- [BARU UNTUK FITUR PROMO] ---
+	PromotionAmount float64                    `json:"promotion_amount"`
+	Promotions      []AppliedPromotionResponse `json:"promotions"`
+	// --- [AKHIR BARU] ---
+}
+
+// --- [BARU] DTO UNTUK VOID & RETUR ---
+
+// VoidTransactionInput adalah DTO untuk membatalkan (void) transaksi
+type VoidTransactionInput struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// RefundItemInput adalah DTO untuk satu item yang diretur
+type RefundItemInput struct {
+	TransactionItemID uint `json:"transaction_item_id" binding:"required"`
+	Quantity          int  `json:"quantity" binding:"required,gt=0"`
+}
+
+// RefundTransactionInput adalah DTO untuk retur sebagian
+type RefundTransactionInput struct {
+	Items  []RefundItemInput `json:"items" binding:"required,min=1,dive"`
+	Reason string            `json:"reason"`
+}
+
+// RefundItemResponse adalah DTO untuk detail item retur
+type RefundItemResponse struct {
+	TransactionItemID uint    `json:"transaction_item_id"`
+	Quantity          int     `json:"quantity"`
+	Amount            float64 `json:"amount"`
+	TaxAmount         float64 `json:"tax_amount"` // [BARU]
+}
+
+// RefundResponse adalah DTO untuk satu catatan retur
+type RefundResponse struct {
+	ID         uint                 `json:"id"`
+	Amount     float64              `json:"amount"`
+	CashAmount float64              `json:"cash_amount"`
+	TaxAmount  float64              `json:"tax_amount"` // [BARU]
+	Reason     string               `json:"reason"`
+	CreatedAt  string               `json:"created_at"`
+	Items      []RefundItemResponse `json:"items"`
+}
+
+// --- [BARU] DTO UNTUK PEMBAYARAN CICILAN ---
+
+// CreatePaymentInput adalah DTO untuk mencatat pembayaran (cicilan) utang/piutang
+type CreatePaymentInput struct {
+	Amount      float64                  `json:"amount" binding:"required,gt=0"`
+	PaymentDate *string                  `json:"payment_date" binding:"omitempty,datetime=2006-01-02"` // Default: hari ini
+	Method      models.PaymentMethodType `json:"method" binding:"omitempty,oneof=TUNAI TRANSFER QRIS LAINNYA"`
+	Notes       string                   `json:"notes"`
+}
+
+// PaymentResponse adalah DTO untuk satu catatan pembayaran
+type PaymentResponse struct {
+	ID          uint                     `json:"id"`
+	Amount      float64                  `json:"amount"`
+	PaymentDate string                   `json:"payment_date"`
+	Method      models.PaymentMethodType `json:"method"`
+	Notes       string                   `json:"notes"`
 }
@@ -11,3 +11,8 @@ type UpdatePasswordInput struct {
 	OldPassword string `json:"old_password" binding:"required"`
 	NewPassword string `json:"new_password" binding:"required,min=6"`
 }
+
+// [BARU] UpdateCostingMethodInput adalah DTO untuk mengganti metode penilaian persediaan (HPP)
+type UpdateCostingMethodInput struct {
+	CostingMethod string `json:"costing_method" binding:"required,oneof=AVERAGE FIFO"`
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/danishyusrah/go_bisnis/internal/dto"
+	"github.com/danishyusrah/go_bisnis/internal/models"
+	"github.com/danishyusrah/go_bisnis/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// AccountingHandler menghandle request terkait bagan akun & jurnal
+type AccountingHandler struct {
+	Service *services.AccountingService
+}
+
+// NewAccountingHandler membuat handler akuntansi baru
+func NewAccountingHandler() *AccountingHandler {
+	return &AccountingHandler{
+		Service: services.NewAccountingService(),
+	}
+}
+
+// helper untuk mengubah model akun menjadi DTO respons
+func toAccountResponse(account models.Account) dto.AccountResponse {
+	return dto.AccountResponse{
+		ID:       account.ID,
+		Code:     account.Code,
+		Name:     account.Name,
+		Type:     account.Type,
+		IsSystem: account.IsSystem,
+	}
+}
+
+// helper untuk mengubah model jurnal menjadi DTO respons
+func toJournalEntryResponse(entry models.JournalEntry) dto.JournalEntryResponse {
+	lines := []dto.JournalLineResponse{}
+	for _, line := range entry.Lines {
+		lines = append(lines, dto.JournalLineResponse{
+			AccountID:   line.AccountID,
+			AccountCode: line.Account.Code,
+			AccountName: line.Account.Name,
+			Debit:       line.Debit,
+			Credit:      line.Credit,
+		})
+	}
+	return dto.JournalEntryResponse{
+		ID:            entry.ID,
+		EntryDate:     entry.EntryDate.Format("2006-01-02 15:04:05"),
+		Description:   entry.Description,
+		SourceType:    entry.SourceType,
+		TransactionID: entry.TransactionID,
+		Lines:         lines,
+	}
+}
+
+// GetAccounts menangani pengambilan bagan akun
+func (h *AccountingHandler) GetAccounts(c *gin.Context) {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	accounts, err := h.Service.GetAccounts(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data akun"})
+		return
+	}
+
+	responses := []dto.AccountResponse{}
+	for _, acc := range accounts {
+		responses = append(responses, toAccountResponse(acc))
+	}
+
+	c.JSON(http.StatusOK, responses)
+}
+
+// CreateAccount menangani pembuatan akun kustom
+func (h *AccountingHandler) CreateAccount(c *gin.Context) {
+	var input dto.CreateAccountInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	account, err := h.Service.CreateAccount(input, userID)
+	if err != nil {
+		if err.Error() == "kode akun sudah digunakan" {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat akun"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, toAccountResponse(account))
+}
+
+// GetJournalEntries menangani pengambilan jurnal umum dalam rentang waktu
+func (h *AccountingHandler) GetJournalEntries(c *gin.Context) {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	startTime, endTime := parseDateRangeForReports(c)
+
+	entries, err := h.Service.GetJournalEntries(userID, startTime, endTime)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data jurnal"})
+		return
+	}
+
+	responses := []dto.JournalEntryResponse{}
+	for _, entry := range entries {
+		responses = append(responses, toJournalEntryResponse(entry))
+	}
+
+	c.JSON(http.StatusOK, responses)
+}
+
+// GetTrialBalance menangani permintaan neraca saldo (per tanggal 'to')
+func (h *AccountingHandler) GetTrialBalance(c *gin.Context) {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	_, endTime := parseDateRangeForReports(c)
+
+	report, err := h.Service.GetTrialBalance(userID, endTime)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data neraca saldo"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
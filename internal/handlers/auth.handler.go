@@ -42,11 +42,12 @@ func Register(c *gin.Context) {
 
 	// Buat respons yang aman (tanpa password)
 	response := dto.UserResponse{ // <-- DIUBAH
-		ID:        user.ID,
-		Username:  user.Username,
-		Email:     user.Email,
-		FullName:  user.FullName,
-		CreatedAt: user.CreatedAt.String(),
+		ID:            user.ID,
+		Username:      user.Username,
+		Email:         user.Email,
+		FullName:      user.FullName,
+		CreatedAt:     user.CreatedAt.String(),
+		CostingMethod: string(user.CostingMethod), // [BARU]
 	}
 
 	c.JSON(http.StatusCreated, gin.H{"message": "Registrasi berhasil", "user": response})
@@ -88,11 +89,12 @@ func GetProfile(c *gin.Context) {
 
 	// Kembalikan data profil yang aman
 	response := dto.UserResponse{ // <-- DIUBAH
-		ID:        user.ID,
-		Username:  user.Username,
-		Email:     user.Email,
-		FullName:  user.FullName,
-		CreatedAt: user.CreatedAt.String(),
+		ID:            user.ID,
+		Username:      user.Username,
+		Email:         user.Email,
+		FullName:      user.FullName,
+		CreatedAt:     user.CreatedAt.String(),
+		CostingMethod: string(user.CostingMethod), // [BARU]
 	}
 
 	c.JSON(http.StatusOK, gin.H{"user": response})
@@ -135,11 +137,12 @@ func UpdateProfile(c *gin.Context) {
 
 	// 4. Kembalikan data profil yang baru
 	response := dto.UserResponse{
-		ID:        user.ID,
-		Username:  user.Username,
-		Email:     user.Email,
-		FullName:  user.FullName,
-		CreatedAt: user.CreatedAt.String(),
+		ID:            user.ID,
+		Username:      user.Username,
+		Email:         user.Email,
+		FullName:      user.FullName,
+		CreatedAt:     user.CreatedAt.String(),
+		CostingMethod: string(user.CostingMethod), // [BARU]
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Profil berhasil diperbarui", "user": response})
 }
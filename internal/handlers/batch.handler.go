@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/danishyusrah/go_bisnis/internal/dto"
+	"github.com/danishyusrah/go_bisnis/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// BatchHandler menghandle request terkait batch/lot produk & pemusnahannya
+type BatchHandler struct {
+	Service *services.BatchService
+}
+
+// NewBatchHandler membuat handler batch baru
+func NewBatchHandler() *BatchHandler {
+	return &BatchHandler{
+		Service: services.NewBatchService(),
+	}
+}
+
+// GetProductBatches menangani pengambilan batch yang masih tersisa untuk satu produk
+func (h *BatchHandler) GetProductBatches(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID produk tidak valid"})
+		return
+	}
+
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	batches, err := h.Service.GetProductBatches(uint(productID), userID)
+	if err != nil {
+		if err.Error() == "produk tidak ditemukan" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err.Error() == "akses ditolak: Anda bukan pemilik produk ini" {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data batch"})
+		return
+	}
+
+	c.JSON(http.StatusOK, batches)
+}
+
+// WriteOffBatch menangani pemusnahan batch kedaluwarsa/rusak
+func (h *BatchHandler) WriteOffBatch(c *gin.Context) {
+	batchID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID batch tidak valid"})
+		return
+	}
+
+	var input dto.WriteOffBatchInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	writeOff, err := h.Service.WriteOffBatch(uint(batchID), userID, input)
+	if err != nil {
+		switch err.Error() {
+		case "batch tidak ditemukan":
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case "akses ditolak: Anda bukan pemilik batch ini":
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case "periode akuntansi sudah ditutup":
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.BatchWriteOffResponse{
+		ID:             writeOff.ID,
+		ProductBatchID: writeOff.ProductBatchID,
+		ProductID:      writeOff.ProductID,
+		LocationID:     writeOff.LocationID, // [BARU]
+		Quantity:       writeOff.Quantity,
+		UnitCost:       writeOff.UnitCost,
+		Amount:         writeOff.Amount,
+		Reason:         writeOff.Reason,
+		CreatedAt:      writeOff.CreatedAt.Format(time.RFC3339),
+	})
+}
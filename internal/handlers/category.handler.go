@@ -28,6 +28,8 @@ func toCategoryResponse(category models.Category) dto.CategoryResponse {
 		ID:   category.ID,
 		Name: category.Name,
 		Type: category.Type,
+		// [BARU]
+		CashFlowSection: category.CashFlowSection,
 	}
 }
 
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/danishyusrah/go_bisnis/internal/dto"
+	"github.com/danishyusrah/go_bisnis/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// CompositeHandler menghandle request terkait resep produk komposit (bill of materials)
+type CompositeHandler struct {
+	Service *services.CompositeService
+}
+
+// NewCompositeHandler membuat handler produk komposit baru
+func NewCompositeHandler() *CompositeHandler {
+	return &CompositeHandler{
+		Service: services.NewCompositeService(),
+	}
+}
+
+// respondCompositeError memetakan error resep produk ke status HTTP
+func respondCompositeError(c *gin.Context, err error) {
+	message := err.Error()
+	switch {
+	case message == "produk tidak ditemukan" || message == "lokasi tidak ditemukan" ||
+		(strings.HasPrefix(message, "bahan ID") && strings.HasSuffix(message, "tidak ditemukan")):
+		c.JSON(http.StatusNotFound, gin.H{"error": message})
+	case strings.HasPrefix(message, "akses ditolak"):
+		c.JSON(http.StatusForbidden, gin.H{"error": message})
+	default:
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": message})
+	}
+}
+
+// GetProductComponents menangani pengambilan resep produk (opsional ?location_id= untuk jumlah yang bisa dibuat)
+func (h *CompositeHandler) GetProductComponents(c *gin.Context) {
+	productID, ok := parseIDParam(c, "id", "produk")
+	if !ok {
+		return
+	}
+
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	locationID, ok := parseLocationFilter(c)
+	if !ok {
+		return
+	}
+
+	recipe, err := h.Service.GetProductComponents(productID, userID, locationID)
+	if err != nil {
+		respondCompositeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, recipe)
+}
+
+// SetProductComponents menangani penggantian resep produk komposit
+func (h *CompositeHandler) SetProductComponents(c *gin.Context) {
+	productID, ok := parseIDParam(c, "id", "produk")
+	if !ok {
+		return
+	}
+
+	var input dto.SetProductComponentsInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	recipe, err := h.Service.SetProductComponents(productID, userID, input)
+	if err != nil {
+		respondCompositeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, recipe)
+}
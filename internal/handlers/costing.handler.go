@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/danishyusrah/go_bisnis/internal/dto"
+	"github.com/danishyusrah/go_bisnis/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// CostingHandler menghandle request terkait metode penilaian persediaan (HPP)
+type CostingHandler struct {
+	Service *services.CostingService
+}
+
+// NewCostingHandler membuat handler penilaian persediaan baru
+func NewCostingHandler() *CostingHandler {
+	return &CostingHandler{
+		Service: services.NewCostingService(),
+	}
+}
+
+// GetCostingMethod menangani pengambilan metode penilaian persediaan user
+func (h *CostingHandler) GetCostingMethod(c *gin.Context) {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	method, err := h.Service.GetCostingMethod(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil metode penilaian persediaan"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"costing_method": method})
+}
+
+// UpdateCostingMethod menangani penggantian metode penilaian persediaan (AVERAGE / FIFO)
+func (h *CostingHandler) UpdateCostingMethod(c *gin.Context) {
+	var input dto.UpdateCostingMethodInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	method, err := h.Service.UpdateCostingMethod(userID, input)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengganti metode penilaian persediaan"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Metode penilaian persediaan berhasil diperbarui", "costing_method": method})
+}
+
+// GetCostLayers menangani pengambilan lapisan biaya FIFO satu produk
+func (h *CostingHandler) GetCostLayers(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID produk tidak valid"})
+		return
+	}
+
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	layers, err := h.Service.GetCostLayers(uint(productID), userID)
+	if err != nil {
+		if err.Error() == "produk tidak ditemukan" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err.Error() == "akses ditolak: Anda bukan pemilik produk ini" {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil lapisan biaya"})
+		return
+	}
+
+	c.JSON(http.StatusOK, layers)
+}
@@ -3,6 +3,8 @@ package handlers
 import (
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/danishyusrah/go_bisnis/internal/dto"
 	"github.com/danishyusrah/go_bisnis/internal/models"
@@ -32,6 +34,10 @@ func toCustomerResponse(customer models.Customer) dto.CustomerResponse {
 		Address:   customer.Address,
 		CreatedAt: customer.CreatedAt.Format("2006-01-02 15:04:05"),
 		UpdatedAt: customer.UpdatedAt.Format("2006-01-02 15:04:05"),
+		// [BARU] Batas kredit
+		CreditLimit:         customer.CreditLimit,
+		CreditHoldOnOverdue: customer.CreditHoldOnOverdue,
+		MaxOverdueDays:      customer.MaxOverdueDays,
 	}
 }
 
@@ -107,7 +113,16 @@ func (h *CustomerHandler) GetCustomerByID(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, toCustomerResponse(customer))
+	// [BARU] Detail pelanggan menyertakan posisi piutang terhadap batas kredit
+	exposure, err := h.Service.GetCustomerCreditExposure(customer)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghitung piutang pelanggan"})
+		return
+	}
+	response := toCustomerResponse(customer)
+	response.CreditExposure = &exposure
+
+	c.JSON(http.StatusOK, response)
 }
 
 // UpdateCustomer menangani pembaruan pelanggan
@@ -175,3 +190,52 @@ func (h *CustomerHandler) DeleteCustomer(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "Pelanggan berhasil dihapus"})
 }
+
+// GetCustomerRFM menangani analitik RFM & segmentasi pelanggan.
+// Query: ?from=&to= (RFC3339, default 12 bulan terakhir), ?segment= (opsional), ?top= produk teratas (default 3, maks 10)
+func (h *CustomerHandler) GetCustomerRFM(c *gin.Context) {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	startTime, endTime := parseDateRangeForReports(c)
+	if c.Query("from") == "" {
+		// RFM butuh riwayat panjang, jadi default-nya bukan "bulan ini" seperti laporan lain
+		yearAgo := endTime.AddDate(-1, 0, 0)
+		startTime = time.Date(yearAgo.Year(), yearAgo.Month(), yearAgo.Day(), 0, 0, 0, 0, yearAgo.Location())
+	}
+
+	segment := strings.ToUpper(c.Query("segment"))
+	if segment != "" {
+		valid := false
+		for _, name := range services.RFMSegments() {
+			if name == segment {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Segmen tidak valid, gunakan salah satu: " + strings.Join(services.RFMSegments(), ", ")})
+			return
+		}
+	}
+
+	topN := 3
+	if topStr := c.Query("top"); topStr != "" {
+		parsed, err := strconv.Atoi(topStr)
+		if err != nil || parsed < 0 || parsed > 10 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter 'top' harus angka 0-10"})
+			return
+		}
+		topN = parsed
+	}
+
+	report, err := h.Service.GetCustomerRFM(userID, startTime, endTime, segment, topN)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghitung analitik pelanggan"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danishyusrah/go_bisnis/internal/dto"
+	"github.com/danishyusrah/go_bisnis/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// GetCustomerStatement menangani rekening koran pelanggan (?from=&to=, ?format=pdf untuk unduhan PDF)
+func (h *CustomerHandler) GetCustomerStatement(c *gin.Context) {
+	customerID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID pelanggan tidak valid"})
+		return
+	}
+
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	startTime, endTime := parseDateRangeForReports(c)
+	statement, err := h.Service.GetCustomerStatement(uint(customerID), userID, startTime, endTime)
+	if err != nil {
+		if err.Error() == "pelanggan tidak ditemukan" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err.Error() == "akses ditolak: Anda bukan pemilik pelanggan ini" {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat rekening koran pelanggan"})
+		return
+	}
+
+	if c.Query("format") == "pdf" {
+		filename := fmt.Sprintf("rekening-koran-%d-%s-%s.pdf", statement.CustomerID, statement.From, statement.To)
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		c.Data(http.StatusOK, "application/pdf", renderCustomerStatementPDF(statement, time.Now()))
+		return
+	}
+
+	c.JSON(http.StatusOK, statement)
+}
+
+// formatRupiah memformat nominal dengan pemisah ribuan Indonesia (cth: 1.250.000,00)
+func formatRupiah(amount float64) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	cents := int64(math.Round(amount * 100))
+	whole := strconv.FormatInt(cents/100, 10)
+
+	var grouped strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped.WriteByte('.')
+		}
+		grouped.WriteRune(digit)
+	}
+	return fmt.Sprintf("%s%s,%02d", sign, grouped.String(), cents%100)
+}
+
+// truncateText memotong teks agar muat di kolom PDF
+func truncateText(text string, maxChars int) string {
+	runes := []rune(text)
+	if len(runes) <= maxChars {
+		return text
+	}
+	return string(runes[:maxChars-3]) + "..."
+}
+
+// renderCustomerStatementPDF menyusun rekening koran pelanggan menjadi dokumen PDF A4
+func renderCustomerStatementPDF(statement dto.CustomerStatement, printedAt time.Time) []byte {
+	const (
+		left        = 40.0
+		right       = utils.PDFPageWidth - 40
+		colDesc     = 125.0
+		colDebit    = 395.0 // Batas kanan kolom debit
+		colCredit   = 475.0 // Batas kanan kolom kredit
+		rowHeight   = 14.0
+		fontSize    = 8.0
+		bottomLimit = utils.PDFPageHeight - 50
+	)
+
+	doc := utils.NewPDFDocument()
+	y := 0.0
+
+	tableHeader := func() {
+		doc.Text(left, y, utils.PDFFontBold, fontSize, "Tanggal")
+		doc.Text(colDesc, y, utils.PDFFontBold, fontSize, "Keterangan")
+		doc.TextRight(colDebit, y, utils.PDFFontBold, fontSize, "Debit")
+		doc.TextRight(colCredit, y, utils.PDFFontBold, fontSize, "Kredit")
+		doc.TextRight(right, y, utils.PDFFontBold, fontSize, "Saldo")
+		doc.Line(left, y+4, right, y+4)
+		y += rowHeight + 2
+	}
+	newPage := func() {
+		doc.AddPage()
+		y = 50
+		if doc.PageCount() > 1 {
+			doc.Text(left, y, utils.PDFFontBold, 10, fmt.Sprintf("Rekening Koran - %s (lanjutan)", statement.CustomerName))
+			y += rowHeight * 2
+		}
+		tableHeader()
+	}
+	row := func(date, description string, debit, credit *float64, balance float64, font string) {
+		if y > bottomLimit {
+			newPage()
+		}
+		doc.Text(left, y, font, fontSize, date)
+		doc.Text(colDesc, y, font, fontSize, truncateText(description, 45))
+		if debit != nil {
+			doc.TextRight(colDebit, y, utils.PDFFontMono, fontSize, formatRupiah(*debit))
+		}
+		if credit != nil {
+			doc.TextRight(colCredit, y, utils.PDFFontMono, fontSize, formatRupiah(*credit))
+		}
+		doc.TextRight(right, y, utils.PDFFontMono, fontSize, formatRupiah(balance))
+		y += rowHeight
+	}
+
+	// --- Kop dokumen ---
+	doc.AddPage()
+	y = 50
+	doc.Text(left, y, utils.PDFFontBold, 16, "REKENING KORAN PELANGGAN")
+	y += rowHeight * 2
+	doc.Text(left, y, utils.PDFFontBold, 10, statement.CustomerName)
+	y += rowHeight
+	if statement.CustomerPhone != "" {
+		doc.Text(left, y, utils.PDFFontRegular, 9, "Telp: "+statement.CustomerPhone)
+		y += rowHeight
+	}
+	if statement.CustomerAddress != "" {
+		doc.Text(left, y, utils.PDFFontRegular, 9, truncateText(statement.CustomerAddress, 100))
+		y += rowHeight
+	}
+	doc.Text(left, y, utils.PDFFontRegular, 9, fmt.Sprintf("Periode: %s s/d %s", statement.From, statement.To))
+	doc.TextRight(right, y, utils.PDFFontRegular, 9, "Dicetak: "+printedAt.Format("02 Jan 2006 15:04"))
+	y += rowHeight * 2
+	tableHeader()
+
+	// --- Mutasi ---
+	row("", "Saldo Awal", nil, nil, statement.OpeningBalance, utils.PDFFontBold)
+	for _, entry := range statement.Entries {
+		var debit, credit *float64
+		if entry.Debit != 0 {
+			debit = &entry.Debit
+		}
+		if entry.Credit != 0 {
+			credit = &entry.Credit
+		}
+		row(entry.Date, entry.Description, debit, credit, entry.Balance, utils.PDFFontRegular)
+	}
+
+	// --- Ringkasan ---
+	if y > bottomLimit-rowHeight {
+		newPage()
+	}
+	doc.Line(left, y-rowHeight+4, right, y-rowHeight+4)
+	row("", "Total Mutasi", &statement.TotalDebit, &statement.TotalCredit, statement.ClosingBalance, utils.PDFFontBold)
+	y += rowHeight
+	doc.Text(left, y, utils.PDFFontBold, 10, "Saldo Akhir (Sisa Tagihan)")
+	doc.TextRight(right, y, utils.PDFFontBold, 10, "Rp "+formatRupiah(statement.ClosingBalance))
+
+	return doc.Bytes()
+}
@@ -2,7 +2,8 @@ package handlers
 
 import (
 	"net/http"
-	"time" // <-- Impor 'time'
+	"strconv" // <-- [BARU]
+	"time"    // <-- Impor 'time'
 
 	"github.com/danishyusrah/go_bisnis/internal/services"
 	"github.com/gin-gonic/gin"
@@ -77,10 +78,14 @@ func (h *DashboardHandler) GetDashboardStats(c *gin.Context) {
 
 	// [BARU] Dapatkan rentang waktu dari query
 	startTime, endTime := parseDateRange(c)
+	locationID, ok := parseLocationFilter(c) // [BARU] ?location_id=
+	if !ok {
+		return
+	}
 
 	// Panggil service untuk mendapatkan statistik
 	// [DIPERBARUI] Kirim rentang waktu ke service
-	stats, err := h.Service.GetDashboardStats(userID, startTime, endTime)
+	stats, err := h.Service.GetDashboardStats(userID, locationID, startTime, endTime)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data dashboard"})
 		return
@@ -103,9 +108,13 @@ func (h *DashboardHandler) GetDashboardChartData(c *gin.Context) {
 
 	// 2. Ambil rentang tanggal (menggunakan helper yang sama)
 	startTime, endTime := parseDateRange(c)
+	locationID, ok := parseLocationFilter(c) // [BARU] ?location_id=
+	if !ok {
+		return
+	}
 
 	// 3. Panggil service baru kita
-	chartData, err := h.Service.GetDashboardChartData(userID, startTime, endTime)
+	chartData, err := h.Service.GetDashboardChartData(userID, locationID, startTime, endTime)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data untuk grafik"})
 		return
@@ -125,8 +134,13 @@ func (h *DashboardHandler) GetLowStockProducts(c *gin.Context) {
 		return
 	}
 
+	locationID, ok := parseLocationFilter(c) // [BARU] ?location_id=
+	if !ok {
+		return
+	}
+
 	// 2. Panggil service baru kita
-	lowStockProducts, err := h.Service.GetLowStockProducts(userID)
+	lowStockProducts, err := h.Service.GetLowStockProducts(userID, locationID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data stok menipis"})
 		return
@@ -136,4 +150,36 @@ func (h *DashboardHandler) GetLowStockProducts(c *gin.Context) {
 	c.JSON(http.StatusOK, lowStockProducts)
 }
 
+// [BARU] GetNearExpiryBatches menangani permintaan batch yang mendekati kedaluwarsa.
+// Query 'days' (default 30) menentukan batas hari ke depan. [DIUBAH] Bisa difilter ?location_id=
+func (h *DashboardHandler) GetNearExpiryBatches(c *gin.Context) {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	days := 30
+	if daysStr := c.Query("days"); daysStr != "" {
+		parsed, err := strconv.Atoi(daysStr)
+		if err != nil || parsed < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter 'days' tidak valid"})
+			return
+		}
+		days = parsed
+	}
+
+	locationID, ok := parseLocationFilter(c) // [BARU] ?location_id=
+	if !ok {
+		return
+	}
+
+	batches, err := h.Service.GetNearExpiryBatches(userID, locationID, days)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data batch mendekati kedaluwarsa"})
+		return
+	}
+
+	c.JSON(http.StatusOK, batches)
+}
+
 // --- [AKHIR BARU] ---
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/danishyusrah/go_bisnis/internal/dto"
+	"github.com/danishyusrah/go_bisnis/internal/models"
+	"github.com/danishyusrah/go_bisnis/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// FiscalPeriodHandler menghandle request terkait penutupan periode akuntansi
+type FiscalPeriodHandler struct {
+	Service *services.FiscalPeriodService
+}
+
+// NewFiscalPeriodHandler membuat handler periode akuntansi baru
+func NewFiscalPeriodHandler() *FiscalPeriodHandler {
+	return &FiscalPeriodHandler{
+		Service: services.NewFiscalPeriodService(),
+	}
+}
+
+// helper untuk mengubah model periode menjadi DTO respons
+func toFiscalPeriodResponse(period models.FiscalPeriod) dto.FiscalPeriodResponse {
+	var closedAt *string
+	if period.ClosedAt != nil {
+		formatted := period.ClosedAt.Format("2006-01-02 15:04:05")
+		closedAt = &formatted
+	}
+	return dto.FiscalPeriodResponse{
+		ID:                   period.ID,
+		Year:                 period.Year,
+		Month:                period.Month,
+		StartDate:            period.StartDate.Format("2006-01-02"),
+		EndDate:              period.EndDate.Format("2006-01-02"),
+		Status:               period.Status,
+		ClosedAt:             closedAt,
+		AccrualEndingBalance: period.AccrualEndingBalance,
+		CashEndingBalance:    period.CashEndingBalance,
+	}
+}
+
+// parseYearMonth membaca parameter URL :year dan :month
+func parseYearMonth(c *gin.Context) (int, int, bool) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil || year < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Tahun tidak valid"})
+		return 0, 0, false
+	}
+	month, err := strconv.Atoi(c.Param("month"))
+	if err != nil || month < 1 || month > 12 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bulan tidak valid"})
+		return 0, 0, false
+	}
+	return year, month, true
+}
+
+// GetPeriods menangani pengambilan daftar periode akuntansi
+func (h *FiscalPeriodHandler) GetPeriods(c *gin.Context) {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	periods, err := h.Service.GetPeriods(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data periode"})
+		return
+	}
+
+	responses := []dto.FiscalPeriodResponse{}
+	for _, period := range periods {
+		responses = append(responses, toFiscalPeriodResponse(period))
+	}
+
+	c.JSON(http.StatusOK, responses)
+}
+
+// ClosePeriod menangani penutupan satu bulan akuntansi
+func (h *FiscalPeriodHandler) ClosePeriod(c *gin.Context) {
+	year, month, ok := parseYearMonth(c)
+	if !ok {
+		return
+	}
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	period, err := h.Service.ClosePeriod(userID, year, month)
+	if err != nil {
+		if err.Error() == "periode ini sudah ditutup" || err.Error() == "periode ini sudah terkunci oleh periode setelahnya" {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if err.Error() == "periode yang belum berakhir tidak dapat ditutup" {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menutup periode"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Periode berhasil ditutup", "period": toFiscalPeriodResponse(period)})
+}
+
+// ReopenPeriod menangani pembukaan kembali periode yang sudah ditutup
+func (h *FiscalPeriodHandler) ReopenPeriod(c *gin.Context) {
+	year, month, ok := parseYearMonth(c)
+	if !ok {
+		return
+	}
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	period, err := h.Service.ReopenPeriod(userID, year, month)
+	if err != nil {
+		if err.Error() == "periode tidak ditemukan" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err.Error() == "periode ini belum ditutup" || err.Error() == "buka kembali periode setelahnya terlebih dahulu" {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuka kembali periode"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Periode berhasil dibuka kembali", "period": toFiscalPeriodResponse(period)})
+}
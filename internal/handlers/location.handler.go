@@ -0,0 +1,292 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/danishyusrah/go_bisnis/internal/dto"
+	"github.com/danishyusrah/go_bisnis/internal/models"
+	"github.com/danishyusrah/go_bisnis/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// LocationHandler menghandle request terkait lokasi (gudang/outlet), stok per lokasi & mutasi stok
+type LocationHandler struct {
+	Service *services.LocationService
+}
+
+// NewLocationHandler membuat handler lokasi baru
+func NewLocationHandler() *LocationHandler {
+	return &LocationHandler{
+		Service: services.NewLocationService(),
+	}
+}
+
+// parseLocationFilter mengambil filter '?location_id=' (opsional) untuk laporan.
+// Mengembalikan nil jika tidak diisi (semua lokasi).
+func parseLocationFilter(c *gin.Context) (*uint, bool) {
+	value := c.Query("location_id")
+	if value == "" {
+		return nil, true
+	}
+	parsed, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter 'location_id' tidak valid"})
+		return nil, false
+	}
+	locationID := uint(parsed)
+	return &locationID, true
+}
+
+// helper untuk mengubah model lokasi menjadi DTO respons
+func toLocationResponse(location models.Location) dto.LocationResponse {
+	return dto.LocationResponse{
+		ID:        location.ID,
+		Name:      location.Name,
+		Address:   location.Address,
+		IsDefault: location.IsDefault,
+	}
+}
+
+// helper untuk mengubah model mutasi stok menjadi DTO respons
+func toStockTransferResponse(transfer models.StockTransfer) dto.StockTransferResponse {
+	items := []dto.StockTransferItemResponse{}
+	for _, item := range transfer.Items {
+		items = append(items, dto.StockTransferItemResponse{
+			ProductID:   item.ProductID,
+			ProductName: item.ProductName,
+			Quantity:    item.Quantity,
+		})
+	}
+	return dto.StockTransferResponse{
+		ID:               transfer.ID,
+		FromLocationID:   transfer.FromLocationID,
+		FromLocationName: transfer.FromLocation.Name,
+		ToLocationID:     transfer.ToLocationID,
+		ToLocationName:   transfer.ToLocation.Name,
+		TransferDate:     transfer.TransferDate.Format(time.RFC3339),
+		Notes:            transfer.Notes,
+		Items:            items,
+	}
+}
+
+// respondLocationError memetakan error layanan lokasi ke status HTTP
+func respondLocationError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "lokasi tidak ditemukan", "mutasi stok tidak ditemukan", "produk tidak ditemukan":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case "akses ditolak: Anda bukan pemilik lokasi ini", "akses ditolak: Anda bukan pemilik mutasi stok ini", "akses ditolak: Anda bukan pemilik produk ini":
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case "lokasi default tidak dapat dihapus", "lokasi masih memiliki stok, pindahkan stok terlebih dahulu", "jadikan lokasi lain sebagai default terlebih dahulu":
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	}
+}
+
+// parseIDParam mengambil ID dari URL dengan pesan error sesuai nama data
+func parseIDParam(c *gin.Context, name string, label string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID " + label + " tidak valid"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// GetLocations menangani pengambilan semua lokasi
+func (h *LocationHandler) GetLocations(c *gin.Context) {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	locations, err := h.Service.GetLocations(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data lokasi"})
+		return
+	}
+
+	responses := []dto.LocationResponse{}
+	for _, location := range locations {
+		responses = append(responses, toLocationResponse(location))
+	}
+
+	c.JSON(http.StatusOK, responses)
+}
+
+// CreateLocation menangani pembuatan lokasi baru
+func (h *LocationHandler) CreateLocation(c *gin.Context) {
+	var input dto.CreateLocationInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	location, err := h.Service.CreateLocation(input, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat lokasi"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, toLocationResponse(location))
+}
+
+// UpdateLocation menangani pembaruan lokasi
+func (h *LocationHandler) UpdateLocation(c *gin.Context) {
+	locationID, ok := parseIDParam(c, "id", "lokasi")
+	if !ok {
+		return
+	}
+
+	var input dto.CreateLocationInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	location, err := h.Service.UpdateLocation(locationID, input, userID)
+	if err != nil {
+		respondLocationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toLocationResponse(location))
+}
+
+// DeleteLocation menangani penghapusan lokasi
+func (h *LocationHandler) DeleteLocation(c *gin.Context) {
+	locationID, ok := parseIDParam(c, "id", "lokasi")
+	if !ok {
+		return
+	}
+
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	if err := h.Service.DeleteLocation(locationID, userID); err != nil {
+		respondLocationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Lokasi berhasil dihapus"})
+}
+
+// GetLocationStocks menangani pengambilan stok seluruh produk di satu lokasi
+func (h *LocationHandler) GetLocationStocks(c *gin.Context) {
+	locationID, ok := parseIDParam(c, "id", "lokasi")
+	if !ok {
+		return
+	}
+
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	stocks, err := h.Service.GetLocationStocks(locationID, userID)
+	if err != nil {
+		respondLocationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, stocks)
+}
+
+// GetProductStocks menangani pengambilan rincian stok satu produk per lokasi
+func (h *LocationHandler) GetProductStocks(c *gin.Context) {
+	productID, ok := parseIDParam(c, "id", "produk")
+	if !ok {
+		return
+	}
+
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	stocks, err := h.Service.GetProductStocks(productID, userID)
+	if err != nil {
+		respondLocationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, stocks)
+}
+
+// GetStockTransfers menangani pengambilan semua dokumen mutasi stok
+func (h *LocationHandler) GetStockTransfers(c *gin.Context) {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	transfers, err := h.Service.GetStockTransfers(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data mutasi stok"})
+		return
+	}
+
+	responses := []dto.StockTransferResponse{}
+	for _, transfer := range transfers {
+		responses = append(responses, toStockTransferResponse(transfer))
+	}
+
+	c.JSON(http.StatusOK, responses)
+}
+
+// GetStockTransferByID menangani pengambilan detail satu mutasi stok
+func (h *LocationHandler) GetStockTransferByID(c *gin.Context) {
+	transferID, ok := parseIDParam(c, "id", "mutasi stok")
+	if !ok {
+		return
+	}
+
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	transfer, err := h.Service.GetStockTransferByID(transferID, userID)
+	if err != nil {
+		respondLocationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toStockTransferResponse(transfer))
+}
+
+// CreateStockTransfer menangani pembuatan mutasi stok antar lokasi
+func (h *LocationHandler) CreateStockTransfer(c *gin.Context) {
+	var input dto.CreateStockTransferInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	transfer, err := h.Service.CreateStockTransfer(input, userID)
+	if err != nil {
+		respondLocationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, toStockTransferResponse(transfer))
+}
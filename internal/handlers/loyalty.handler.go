@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/danishyusrah/go_bisnis/internal/dto"
+	"github.com/danishyusrah/go_bisnis/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// LoyaltyHandler menghandle request terkait program poin loyalitas
+type LoyaltyHandler struct {
+	Service *services.LoyaltyService
+}
+
+// NewLoyaltyHandler membuat handler poin loyalitas baru
+func NewLoyaltyHandler() *LoyaltyHandler {
+	return &LoyaltyHandler{
+		Service: services.NewLoyaltyService(),
+	}
+}
+
+// respondLoyaltyError memetakan error layanan poin ke status HTTP
+func respondLoyaltyError(c *gin.Context, err error) {
+	switch {
+	case err.Error() == "pelanggan tidak ditemukan":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case strings.HasPrefix(err.Error(), "akses ditolak"):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	}
+}
+
+// GetProgram menangani pengambilan pengaturan program poin
+func (h *LoyaltyHandler) GetProgram(c *gin.Context) {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	program, err := h.Service.GetProgram(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil pengaturan program poin"})
+		return
+	}
+
+	c.JSON(http.StatusOK, program)
+}
+
+// UpdateProgram menangani perubahan pengaturan program poin
+func (h *LoyaltyHandler) UpdateProgram(c *gin.Context) {
+	var input dto.LoyaltyProgramInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	program, err := h.Service.UpdateProgram(userID, input)
+	if err != nil {
+		respondLoyaltyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, program)
+}
+
+// ExpirePoints menangani penghangusan poin yang sudah lewat masa berlaku
+func (h *LoyaltyHandler) ExpirePoints(c *gin.Context) {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.Service.ExpirePoints(userID)
+	if err != nil {
+		respondLoyaltyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetCustomerLoyalty menangani pengambilan saldo & riwayat poin pelanggan
+func (h *LoyaltyHandler) GetCustomerLoyalty(c *gin.Context) {
+	customerID, ok := parseIDParam(c, "id", "pelanggan")
+	if !ok {
+		return
+	}
+
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	loyalty, err := h.Service.GetCustomerLoyalty(customerID, userID)
+	if err != nil {
+		respondLoyaltyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, loyalty)
+}
+
+// AdjustCustomerPoints menangani koreksi manual poin pelanggan
+func (h *LoyaltyHandler) AdjustCustomerPoints(c *gin.Context) {
+	customerID, ok := parseIDParam(c, "id", "pelanggan")
+	if !ok {
+		return
+	}
+
+	var input dto.LoyaltyAdjustmentInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	loyalty, err := h.Service.AdjustCustomerPoints(customerID, userID, input)
+	if err != nil {
+		respondLoyaltyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, loyalty)
+}
+
+// GetLiabilityReport menangani laporan liabilitas poin yang belum ditukar
+func (h *LoyaltyHandler) GetLiabilityReport(c *gin.Context) {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.Service.GetLiabilityReport(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil laporan liabilitas poin"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
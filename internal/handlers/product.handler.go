@@ -3,6 +3,7 @@ package handlers
 import (
 	"net/http"
 	"strconv"
+	"strings" // [BARU]
 
 	"github.com/danishyusrah/go_bisnis/internal/dto"
 	"github.com/danishyusrah/go_bisnis/internal/models"
@@ -40,7 +41,7 @@ func getUserIDFromContext(c *gin.Context) (uint, bool) {
 
 // helper untuk mengubah model produk menjadi DTO respons
 func toProductResponse(product models.Product) dto.ProductResponse {
-	return dto.ProductResponse{
+	response := dto.ProductResponse{
 		ID:            product.ID,
 		Name:          product.Name,
 		SKU:           product.SKU,
@@ -53,7 +54,63 @@ func toProductResponse(product models.Product) dto.ProductResponse {
 		// --- [BARU] ---
 		BatasStokMinimum: product.BatasStokMinimum,
 		// --- [AKHIR BARU] ---
+		TaxExempt:  product.TaxExempt,  // [BARU]
+		TrackLots:  product.TrackLots,  // [BARU]
+		CategoryID: product.CategoryID, // [BARU]
+		ParentID:   product.ParentID,   // [BARU]
+		Attributes: toProductAttributeResponses(product.Attributes),
+		// [BARU] Produk komposit
+		IsComposite: product.IsComposite,
+		// [BARU] Satuan
+		BaseUnit: product.BaseUnit,
+		Units:    toProductUnitResponses(product.Units),
 	}
+	// [BARU] Produk induk menampilkan variannya, stoknya adalah total stok varian
+	for _, variant := range product.Variants {
+		response.Variants = append(response.Variants, toProductResponse(variant))
+		response.Stock += variant.Stock
+	}
+	return response
+}
+
+// [BARU] helper untuk mengubah atribut varian menjadi DTO respons
+func toProductAttributeResponses(attributes []models.ProductAttribute) []dto.ProductAttributeResponse {
+	responses := []dto.ProductAttributeResponse{}
+	for _, attribute := range attributes {
+		responses = append(responses, dto.ProductAttributeResponse{Name: attribute.Name, Value: attribute.Value})
+	}
+	return responses
+}
+
+// [BARU] helper untuk mengubah satuan alternatif produk menjadi DTO respons
+func toProductUnitResponses(units []models.ProductUnit) []dto.ProductUnitResponse {
+	responses := []dto.ProductUnitResponse{}
+	for _, unit := range units {
+		responses = append(responses, dto.ProductUnitResponse{
+			Name:         unit.Name,
+			Factor:       unit.Factor,
+			AllowDecimal: unit.AllowDecimal,
+			SellingPrice: unit.SellingPrice,
+		})
+	}
+	return responses
+}
+
+// [BARU] respondVariantError memetakan error validasi varian ke status HTTP.
+// Mengembalikan false jika error bukan error varian.
+func respondVariantError(c *gin.Context, err error) bool {
+	switch err.Error() {
+	case "produk induk tidak ditemukan":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case "akses ditolak: Anda bukan pemilik produk induk ini":
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case "varian tidak dapat menjadi produk induk", "stok produk induk harus 0 sebelum ditambah varian",
+		"varian harus memiliki minimal satu atribut (cth: ukuran, warna)", "atribut hanya berlaku untuk varian, isi parent_id":
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		return false
+	}
+	return true
 }
 
 // CreateProduct menangani pembuatan produk baru
@@ -72,6 +129,15 @@ func (h *ProductHandler) CreateProduct(c *gin.Context) {
 
 	product, err := h.Service.CreateProduct(input, userID)
 	if err != nil {
+		// [BARU] Kategori produk tidak valid
+		if err.Error() == "kategori produk tidak ditemukan" || err.Error() == "kategori produk harus bertipe INCOME" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		// [BARU] Validasi varian
+		if respondVariantError(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat produk"})
 		return
 	}
@@ -90,9 +156,11 @@ func (h *ProductHandler) GetUserProducts(c *gin.Context) {
 	// [BARU] Ambil query parameter "search" dari URL
 	// Cth: /api/v1/products?search=kopi
 	searchQuery := c.Query("search")
+	// [BARU] ?sellable=true -> daftar datar varian & produk tanpa varian (untuk kasir/transaksi)
+	sellable := c.Query("sellable") == "true"
 
 	// [DIPERBARUI] Kirim searchQuery ke service
-	products, err := h.Service.GetUserProducts(userID, searchQuery)
+	products, err := h.Service.GetUserProducts(userID, searchQuery, sellable)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data produk"})
 		return
@@ -121,7 +189,7 @@ func (h *ProductHandler) GetProductByID(c *gin.Context) {
 		return
 	}
 
-	product, err := h.Service.GetProductByID(uint(productID), userID)
+	product, err := h.Service.GetProductDetail(uint(productID), userID) // [DIUBAH] Sertakan atribut & varian
 	if err != nil {
 		if err.Error() == "produk tidak ditemukan" {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
@@ -169,6 +237,25 @@ func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
 			return
 		}
+		// [BARU] Kategori produk tidak valid
+		if err.Error() == "kategori produk tidak ditemukan" || err.Error() == "kategori produk harus bertipe INCOME" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		// [BARU] Pengurangan stok manual melebihi stok di lokasi default
+		if strings.HasPrefix(err.Error(), "stok tidak cukup") {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		// [BARU] Validasi atribut varian
+		if respondVariantError(c, err) {
+			return
+		}
+		// [BARU] Penggantian satuan dasar
+		if err.Error() == "satuan dasar hanya dapat diubah saat stok 0" || err.Error() == "harga beli produk FIFO hanya dapat diubah saat stok 0" {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui produk"})
 		return
 	}
@@ -201,9 +288,51 @@ func (h *ProductHandler) DeleteProduct(c *gin.Context) {
 			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
 			return
 		}
+		// [BARU] Produk induk yang masih memiliki varian / produk yang dipakai sebagai bahan
+		if err.Error() == "produk masih memiliki varian, hapus varian terlebih dahulu" ||
+			err.Error() == "produk dipakai sebagai bahan produk komposit, hapus dari resep terlebih dahulu" {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus produk"})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Produk berhasil dihapus"})
 }
+
+// [BARU] GetStockCard menangani permintaan kartu stok produk (?from=&to=, format RFC3339, opsional &location_id=)
+func (h *ProductHandler) GetStockCard(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID produk tidak valid"})
+		return
+	}
+
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	startTime, endTime := parseDateRangeForReports(c)
+	locationID, ok := parseLocationFilter(c) // [BARU]
+	if !ok {
+		return
+	}
+
+	card, err := h.Service.GetStockCard(uint(productID), userID, locationID, startTime, endTime)
+	if err != nil {
+		if err.Error() == "produk tidak ditemukan" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err.Error() == "akses ditolak: Anda bukan pemilik produk ini" {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil kartu stok"})
+		return
+	}
+
+	c.JSON(http.StatusOK, card)
+}
@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/danishyusrah/go_bisnis/internal/dto"
+	"github.com/danishyusrah/go_bisnis/internal/models"
+	"github.com/danishyusrah/go_bisnis/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// PromotionHandler menghandle request terkait promo, pratinjau harga & laporan promo
+type PromotionHandler struct {
+	Service *services.PromotionService
+}
+
+// NewPromotionHandler membuat handler promo baru
+func NewPromotionHandler() *PromotionHandler {
+	return &PromotionHandler{
+		Service: services.NewPromotionService(),
+	}
+}
+
+// helper untuk mengubah model promo menjadi DTO respons
+func toPromotionResponse(promotion models.Promotion) dto.PromotionResponse {
+	var startDate, endDate *string
+	if promotion.StartDate != nil {
+		formatted := promotion.StartDate.Format("2006-01-02")
+		startDate = &formatted
+	}
+	if promotion.EndDate != nil {
+		formatted := promotion.EndDate.Format("2006-01-02")
+		endDate = &formatted
+	}
+	return dto.PromotionResponse{
+		ID:            promotion.ID,
+		Name:          promotion.Name,
+		Type:          promotion.Type,
+		IsActive:      promotion.IsActive,
+		StartDate:     startDate,
+		EndDate:       endDate,
+		StartTime:     promotion.StartTime,
+		EndTime:       promotion.EndTime,
+		ProductID:     promotion.ProductID,
+		CategoryID:    promotion.CategoryID,
+		BuyQuantity:   promotion.BuyQuantity,
+		GetQuantity:   promotion.GetQuantity,
+		BundlePrice:   promotion.BundlePrice,
+		MinSpend:      promotion.MinSpend,
+		DiscountType:  promotion.DiscountType,
+		DiscountValue: promotion.DiscountValue,
+	}
+}
+
+// GetPromotions menangani pengambilan semua promo
+func (h *PromotionHandler) GetPromotions(c *gin.Context) {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	promotions, err := h.Service.GetPromotions(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data promo"})
+		return
+	}
+
+	responses := []dto.PromotionResponse{}
+	for _, promotion := range promotions {
+		responses = append(responses, toPromotionResponse(promotion))
+	}
+
+	c.JSON(http.StatusOK, responses)
+}
+
+// CreatePromotion menangani pembuatan promo baru
+func (h *PromotionHandler) CreatePromotion(c *gin.Context) {
+	var input dto.CreatePromotionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	promotion, err := h.Service.CreatePromotion(input, userID)
+	if err != nil {
+		// Parameter promo tidak valid
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, toPromotionResponse(promotion))
+}
+
+// UpdatePromotion menangani pembaruan promo
+func (h *PromotionHandler) UpdatePromotion(c *gin.Context) {
+	promotionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID promo tidak valid"})
+		return
+	}
+
+	var input dto.CreatePromotionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	promotion, err := h.Service.UpdatePromotion(uint(promotionID), input, userID)
+	if err != nil {
+		if err.Error() == "promo tidak ditemukan" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err.Error() == "akses ditolak: Anda bukan pemilik promo ini" {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, toPromotionResponse(promotion))
+}
+
+// DeletePromotion menangani penghapusan promo
+func (h *PromotionHandler) DeletePromotion(c *gin.Context) {
+	promotionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID promo tidak valid"})
+		return
+	}
+
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	if err := h.Service.DeletePromotion(uint(promotionID), userID); err != nil {
+		if err.Error() == "promo tidak ditemukan" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err.Error() == "akses ditolak: Anda bukan pemilik promo ini" {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus promo"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Promo berhasil dihapus"})
+}
+
+// PreviewPrice menangani pratinjau harga keranjang POS (diskon, promo, pajak) sebelum checkout
+func (h *PromotionHandler) PreviewPrice(c *gin.Context) {
+	var input dto.PricePreviewInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	preview, err := h.Service.PreviewPrice(input, userID)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, preview)
+}
+
+// GetPromotionReport menangani permintaan laporan pemakaian promo
+func (h *PromotionHandler) GetPromotionReport(c *gin.Context) {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	startTime, endTime := parseDateRangeForReports(c)
+	locationID, ok := parseLocationFilter(c) // [BARU]
+	if !ok {
+		return
+	}
+
+	report, err := h.Service.GetPromotionReport(userID, locationID, startTime, endTime)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data laporan promo"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,369 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/danishyusrah/go_bisnis/internal/dto"
+	"github.com/danishyusrah/go_bisnis/internal/models"
+	"github.com/danishyusrah/go_bisnis/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// PurchaseOrderHandler menghandle request terkait pesanan pembelian (PO) & penerimaan barang
+type PurchaseOrderHandler struct {
+	Service *services.PurchaseOrderService
+}
+
+// NewPurchaseOrderHandler membuat handler PO baru
+func NewPurchaseOrderHandler() *PurchaseOrderHandler {
+	return &PurchaseOrderHandler{
+		Service: services.NewPurchaseOrderService(),
+	}
+}
+
+// formatOptionalTime mengubah waktu opsional menjadi string RFC3339 (null jika kosong)
+func formatOptionalTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	formatted := t.Format(time.RFC3339)
+	return &formatted
+}
+
+// helper untuk mengubah model penerimaan barang menjadi DTO respons
+func toGoodsReceiptResponse(receipt models.GoodsReceipt) dto.GoodsReceiptResponse {
+	items := []dto.GoodsReceiptItemResponse{}
+	for _, item := range receipt.Items {
+		var expiryDate *string
+		if item.ExpiryDate != nil {
+			formatted := item.ExpiryDate.Format("2006-01-02")
+			expiryDate = &formatted
+		}
+		items = append(items, dto.GoodsReceiptItemResponse{
+			PurchaseOrderItemID: item.PurchaseOrderItemID,
+			ProductID:           item.ProductID,
+			ProductName:         item.ProductName,
+			Quantity:            item.Quantity,
+			UnitCost:            item.UnitCost,
+			LotNumber:           item.LotNumber,
+			ExpiryDate:          expiryDate,
+		})
+	}
+	return dto.GoodsReceiptResponse{
+		ID:              receipt.ID,
+		PurchaseOrderID: receipt.PurchaseOrderID,
+		LocationID:      receipt.LocationID,
+		ReceiptDate:     receipt.ReceiptDate.Format(time.RFC3339),
+		Notes:           receipt.Notes,
+		TotalAmount:     receipt.TotalAmount,
+		Items:           items,
+	}
+}
+
+// helper untuk mengubah model PO menjadi DTO respons
+func toPurchaseOrderResponse(order models.PurchaseOrder) dto.PurchaseOrderResponse {
+	var expectedDate *string
+	if order.ExpectedDate != nil {
+		formatted := order.ExpectedDate.Format("2006-01-02")
+		expectedDate = &formatted
+	}
+
+	response := dto.PurchaseOrderResponse{
+		ID:                order.ID,
+		SupplierID:        order.SupplierID,
+		SupplierName:      order.Supplier.Name,
+		LocationID:        order.LocationID,
+		TaxRateID:         order.TaxRateID,
+		Status:            string(order.Status),
+		OrderDate:         order.OrderDate.Format(time.RFC3339),
+		ExpectedDate:      expectedDate,
+		Notes:             order.Notes,
+		OrderedAt:         formatOptionalTime(order.OrderedAt),
+		BilledAt:          formatOptionalTime(order.BilledAt),
+		BillTransactionID: order.BillTransactionID,
+		Items:             []dto.PurchaseOrderItemResponse{},
+	}
+	for _, item := range order.Items {
+		subtotal := float64(item.Quantity) * item.UnitCost
+		response.TotalAmount += subtotal
+		response.ReceivedAmount += float64(item.ReceivedQuantity) * item.UnitCost
+		response.Items = append(response.Items, dto.PurchaseOrderItemResponse{
+			ID:                item.ID,
+			ProductID:         item.ProductID,
+			ProductName:       item.ProductName,
+			Quantity:          item.Quantity,
+			ReceivedQuantity:  item.ReceivedQuantity,
+			RemainingQuantity: item.RemainingQuantity(),
+			UnitCost:          item.UnitCost,
+			Subtotal:          subtotal,
+		})
+	}
+	for _, receipt := range order.Receipts {
+		response.Receipts = append(response.Receipts, toGoodsReceiptResponse(receipt))
+	}
+	return response
+}
+
+// respondPurchaseOrderError memetakan error layanan PO ke status HTTP
+func respondPurchaseOrderError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "PO tidak ditemukan", "supplier tidak ditemukan", "lokasi tidak ditemukan", "tarif pajak tidak ditemukan":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case "akses ditolak: Anda bukan pemilik PO ini", "akses ditolak: Anda bukan pemilik supplier ini":
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case "PO hanya dapat diubah saat masih DRAFT",
+		"barang hanya dapat diterima untuk PO yang sudah dipesan dan belum diterima seluruhnya",
+		"PO belum memiliki barang diterima atau sudah ditagih",
+		"PO yang sudah ada penerimaan barang atau sudah dibatalkan tidak dapat dibatalkan",
+		"penerimaan barang PO berubah, silakan ulangi penagihan",
+		"periode akuntansi sudah ditutup":
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	}
+}
+
+// GetPurchaseOrders menangani pengambilan semua PO (opsional ?status=ORDERED)
+func (h *PurchaseOrderHandler) GetPurchaseOrders(c *gin.Context) {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	orders, err := h.Service.GetPurchaseOrders(userID, c.Query("status"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data PO"})
+		return
+	}
+
+	responses := []dto.PurchaseOrderResponse{}
+	for _, order := range orders {
+		responses = append(responses, toPurchaseOrderResponse(order))
+	}
+
+	c.JSON(http.StatusOK, responses)
+}
+
+// CreatePurchaseOrder menangani pembuatan PO baru (DRAFT)
+func (h *PurchaseOrderHandler) CreatePurchaseOrder(c *gin.Context) {
+	var input dto.PurchaseOrderInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	order, err := h.Service.CreatePurchaseOrder(input, userID)
+	if err != nil {
+		respondPurchaseOrderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, toPurchaseOrderResponse(order))
+}
+
+// GetPurchaseOrderByID menangani pengambilan detail satu PO beserta penerimaannya
+func (h *PurchaseOrderHandler) GetPurchaseOrderByID(c *gin.Context) {
+	orderID, ok := parseIDParam(c, "id", "PO")
+	if !ok {
+		return
+	}
+
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	order, err := h.Service.GetPurchaseOrderByID(orderID, userID)
+	if err != nil {
+		respondPurchaseOrderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toPurchaseOrderResponse(order))
+}
+
+// UpdatePurchaseOrder menangani perubahan PO yang masih DRAFT
+func (h *PurchaseOrderHandler) UpdatePurchaseOrder(c *gin.Context) {
+	orderID, ok := parseIDParam(c, "id", "PO")
+	if !ok {
+		return
+	}
+
+	var input dto.PurchaseOrderInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	order, err := h.Service.UpdatePurchaseOrder(orderID, userID, input)
+	if err != nil {
+		respondPurchaseOrderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toPurchaseOrderResponse(order))
+}
+
+// DeletePurchaseOrder menangani penghapusan PO yang masih DRAFT
+func (h *PurchaseOrderHandler) DeletePurchaseOrder(c *gin.Context) {
+	orderID, ok := parseIDParam(c, "id", "PO")
+	if !ok {
+		return
+	}
+
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	if err := h.Service.DeletePurchaseOrder(orderID, userID); err != nil {
+		respondPurchaseOrderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "PO berhasil dihapus"})
+}
+
+// OrderPurchaseOrder menangani pengiriman PO ke supplier (DRAFT -> ORDERED)
+func (h *PurchaseOrderHandler) OrderPurchaseOrder(c *gin.Context) {
+	orderID, ok := parseIDParam(c, "id", "PO")
+	if !ok {
+		return
+	}
+
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	order, err := h.Service.OrderPurchaseOrder(orderID, userID)
+	if err != nil {
+		respondPurchaseOrderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toPurchaseOrderResponse(order))
+}
+
+// CancelPurchaseOrder menangani pembatalan PO
+func (h *PurchaseOrderHandler) CancelPurchaseOrder(c *gin.Context) {
+	orderID, ok := parseIDParam(c, "id", "PO")
+	if !ok {
+		return
+	}
+
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	order, err := h.Service.CancelPurchaseOrder(orderID, userID)
+	if err != nil {
+		respondPurchaseOrderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toPurchaseOrderResponse(order))
+}
+
+// GetGoodsReceipts menangani pengambilan riwayat penerimaan barang sebuah PO
+func (h *PurchaseOrderHandler) GetGoodsReceipts(c *gin.Context) {
+	orderID, ok := parseIDParam(c, "id", "PO")
+	if !ok {
+		return
+	}
+
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	// Validasi kepemilikan PO lebih dulu agar PO milik user lain menghasilkan 403, bukan daftar kosong
+	if _, err := h.Service.GetPurchaseOrderByID(orderID, userID); err != nil {
+		respondPurchaseOrderError(c, err)
+		return
+	}
+	receipts, err := h.Service.GetGoodsReceipts(orderID, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data penerimaan barang"})
+		return
+	}
+
+	responses := []dto.GoodsReceiptResponse{}
+	for _, receipt := range receipts {
+		responses = append(responses, toGoodsReceiptResponse(receipt))
+	}
+
+	c.JSON(http.StatusOK, responses)
+}
+
+// ReceiveGoods menangani pencatatan penerimaan barang (sebagian atau seluruhnya)
+func (h *PurchaseOrderHandler) ReceiveGoods(c *gin.Context) {
+	orderID, ok := parseIDParam(c, "id", "PO")
+	if !ok {
+		return
+	}
+
+	var input dto.CreateGoodsReceiptInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	receipt, err := h.Service.ReceiveGoods(orderID, userID, input)
+	if err != nil {
+		respondPurchaseOrderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, toGoodsReceiptResponse(receipt))
+}
+
+// BillPurchaseOrder menangani pencatatan tagihan supplier menjadi transaksi utang (BELUM LUNAS)
+func (h *PurchaseOrderHandler) BillPurchaseOrder(c *gin.Context) {
+	orderID, ok := parseIDParam(c, "id", "PO")
+	if !ok {
+		return
+	}
+
+	var input dto.BillPurchaseOrderInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	transaction, err := h.Service.BillPurchaseOrder(orderID, userID, input)
+	if err != nil {
+		respondPurchaseOrderError(c, err)
+		return
+	}
+
+	txWithDetails, err := services.NewTransactionService().GetTransactionByID(transaction.ID, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data transaksi setelah dibuat"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, toTransactionResponse(txWithDetails))
+}
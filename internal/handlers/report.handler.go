@@ -70,9 +70,20 @@ func (h *ReportHandler) GetProductPerformance(c *gin.Context) {
 
 	// 2. Ambil rentang tanggal dari query parameter (cth: ?from=...&to=...)
 	startTime, endTime := parseDateRangeForReports(c)
+	locationID, ok := parseLocationFilter(c) // [BARU] ?location_id=
+	if !ok {
+		return
+	}
+
+	// [BARU] ?group_by=parent menggabungkan varian ke produk induknya (default: per produk/varian)
+	groupBy := c.DefaultQuery("group_by", "product")
+	if groupBy != "product" && groupBy != "parent" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter 'group_by' tidak valid, gunakan 'product' atau 'parent'"})
+		return
+	}
 
 	// 3. Panggil service untuk mengambil data
-	report, err := h.Service.GetProductPerformanceReport(userID, startTime, endTime)
+	report, err := h.Service.GetProductPerformanceReport(userID, locationID, groupBy == "parent", startTime, endTime)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data laporan performa produk"})
 		return
@@ -92,11 +103,20 @@ func (h *ReportHandler) GetGeneralLedger(c *gin.Context) {
 		return
 	}
 
-	// 2. Ambil rentang tanggal
+	// 2. Ambil rentang tanggal dan basis pencatatan (?basis=cash atau accrual, default accrual)
 	startTime, endTime := parseDateRangeForReports(c)
+	basis := c.DefaultQuery("basis", services.LedgerBasisAccrual)
+	if basis != services.LedgerBasisAccrual && basis != services.LedgerBasisCash {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Basis tidak valid, gunakan 'accrual' atau 'cash'"})
+		return
+	}
+	locationID, ok := parseLocationFilter(c) // [BARU] ?location_id=
+	if !ok {
+		return
+	}
 
 	// 3. Panggil service baru kita
-	report, err := h.Service.GetGeneralLedgerReport(userID, startTime, endTime)
+	report, err := h.Service.GetGeneralLedgerReport(userID, locationID, startTime, endTime, basis)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data buku besar"})
 		return
@@ -117,7 +137,11 @@ func (h *ReportHandler) GetUnpaidReport(c *gin.Context) {
 	}
 
 	// 2. Panggil service (Tidak perlu filter tanggal, kita ingin lihat semua yang belum lunas)
-	report, err := h.Service.GetUnpaidReport(userID)
+	locationID, ok := parseLocationFilter(c) // [BARU] ?location_id=
+	if !ok {
+		return
+	}
+	report, err := h.Service.GetUnpaidReport(userID, locationID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data laporan utang/piutang"})
 		return
@@ -126,3 +150,94 @@ func (h *ReportHandler) GetUnpaidReport(c *gin.Context) {
 	// 3. Kembalikan laporan lengkap sebagai JSON
 	c.JSON(http.StatusOK, report)
 }
+
+// --- [BARU] FUNGSI UNTUK LAPORAN LABA RUGI ---
+
+// GetProfitLoss menangani permintaan API untuk laporan laba rugi per kategori
+func (h *ReportHandler) GetProfitLoss(c *gin.Context) {
+	// 1. Ambil UserID dari context
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	// 2. Ambil rentang tanggal dan opsi pembanding (cth: ?compare=true)
+	startTime, endTime := parseDateRangeForReports(c)
+	compare := c.Query("compare") == "true"
+	locationID, ok := parseLocationFilter(c) // [BARU] ?location_id=
+	if !ok {
+		return
+	}
+
+	// 3. Panggil service
+	report, err := h.Service.GetProfitLossReport(userID, locationID, startTime, endTime, compare)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data laporan laba rugi"})
+		return
+	}
+
+	// 4. Kembalikan laporan lengkap sebagai JSON
+	c.JSON(http.StatusOK, report)
+}
+
+// --- [BARU] FUNGSI UNTUK LAPORAN NERACA ---
+
+// GetBalanceSheet menangani permintaan API untuk laporan neraca (cth: ?as_of=2024-12-31)
+func (h *ReportHandler) GetBalanceSheet(c *gin.Context) {
+	// 1. Ambil UserID dari context
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	// 2. Tentukan tanggal neraca (default: hari ini), dihitung sampai akhir hari tersebut
+	now := time.Now()
+	asOfDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if asOfStr := c.Query("as_of"); asOfStr != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", asOfStr, now.Location())
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Format as_of tidak valid, gunakan YYYY-MM-DD"})
+			return
+		}
+		asOfDate = parsed
+	}
+	asOf := asOfDate.AddDate(0, 0, 1).Add(-time.Nanosecond)
+
+	// 3. Panggil service
+	report, err := h.Service.GetBalanceSheetReport(userID, asOf)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data laporan neraca"})
+		return
+	}
+
+	// 4. Kembalikan laporan lengkap sebagai JSON
+	c.JSON(http.StatusOK, report)
+}
+
+// --- [BARU] FUNGSI UNTUK LAPORAN ARUS KAS ---
+
+// GetCashFlow menangani permintaan API untuk laporan arus kas (basis kas)
+func (h *ReportHandler) GetCashFlow(c *gin.Context) {
+	// 1. Ambil UserID dari context
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	// 2. Ambil rentang tanggal
+	startTime, endTime := parseDateRangeForReports(c)
+	locationID, ok := parseLocationFilter(c) // [BARU] ?location_id=
+	if !ok {
+		return
+	}
+
+	// 3. Panggil service
+	report, err := h.Service.GetCashFlowReport(userID, locationID, startTime, endTime)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data laporan arus kas"})
+		return
+	}
+
+	// 4. Kembalikan laporan lengkap sebagai JSON
+	c.JSON(http.StatusOK, report)
+}
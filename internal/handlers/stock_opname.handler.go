@@ -0,0 +1,258 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/danishyusrah/go_bisnis/internal/dto"
+	"github.com/danishyusrah/go_bisnis/internal/models"
+	"github.com/danishyusrah/go_bisnis/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// StockOpnameHandler menghandle request terkait stok opname (hitung fisik persediaan)
+type StockOpnameHandler struct {
+	Service *services.StockOpnameService
+}
+
+// NewStockOpnameHandler membuat handler stok opname baru
+func NewStockOpnameHandler() *StockOpnameHandler {
+	return &StockOpnameHandler{
+		Service: services.NewStockOpnameService(),
+	}
+}
+
+// helper untuk mengubah model stok opname menjadi DTO respons
+func toStockOpnameResponse(opname models.StockOpname) dto.StockOpnameResponse {
+	var finalizedAt *string
+	if opname.FinalizedAt != nil {
+		formatted := opname.FinalizedAt.Format(time.RFC3339)
+		finalizedAt = &formatted
+	}
+	counts := []dto.StockOpnameCountResponse{}
+	for _, count := range opname.Counts {
+		counts = append(counts, dto.StockOpnameCountResponse{
+			ID:             count.ID,
+			ProductID:      count.ProductID,
+			Quantity:       count.Quantity,
+			SystemQuantity: count.SystemQuantity,
+			SessionLabel:   count.SessionLabel,
+			CreatedAt:      count.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	return dto.StockOpnameResponse{
+		ID:              opname.ID,
+		Notes:           opname.Notes,
+		LocationID:      opname.LocationID, // [BARU]
+		Status:          string(opname.Status),
+		CreatedAt:       opname.CreatedAt.Format(time.RFC3339),
+		FinalizedAt:     finalizedAt,
+		ShrinkageAmount: opname.ShrinkageAmount,
+		SurplusAmount:   opname.SurplusAmount,
+		Counts:          counts,
+	}
+}
+
+// respondStockOpnameError memetakan error layanan stok opname ke status HTTP
+func respondStockOpnameError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "stok opname tidak ditemukan", "entri hitung tidak ditemukan":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case "akses ditolak: Anda bukan pemilik stok opname ini":
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case "stok opname sudah tidak terbuka", "masih ada stok opname yang belum diselesaikan", "periode akuntansi sudah ditutup":
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	}
+}
+
+// parseStockOpnameID mengambil ID stok opname dari URL
+func parseStockOpnameID(c *gin.Context) (uint, bool) {
+	opnameID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID stok opname tidak valid"})
+		return 0, false
+	}
+	return uint(opnameID), true
+}
+
+// GetStockOpnames menangani pengambilan semua sesi stok opname
+func (h *StockOpnameHandler) GetStockOpnames(c *gin.Context) {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	opnames, err := h.Service.GetStockOpnames(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data stok opname"})
+		return
+	}
+
+	responses := []dto.StockOpnameResponse{}
+	for _, opname := range opnames {
+		responses = append(responses, toStockOpnameResponse(opname))
+	}
+
+	c.JSON(http.StatusOK, responses)
+}
+
+// CreateStockOpname menangani pembukaan sesi stok opname baru
+func (h *StockOpnameHandler) CreateStockOpname(c *gin.Context) {
+	var input dto.CreateStockOpnameInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	opname, err := h.Service.CreateStockOpname(input, userID)
+	if err != nil {
+		respondStockOpnameError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, toStockOpnameResponse(opname))
+}
+
+// GetStockOpnameByID menangani pengambilan detail satu sesi stok opname
+func (h *StockOpnameHandler) GetStockOpnameByID(c *gin.Context) {
+	opnameID, ok := parseStockOpnameID(c)
+	if !ok {
+		return
+	}
+
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	opname, err := h.Service.GetStockOpnameByID(opnameID, userID)
+	if err != nil {
+		respondStockOpnameError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toStockOpnameResponse(opname))
+}
+
+// AddCounts menangani input hasil hitung fisik
+func (h *StockOpnameHandler) AddCounts(c *gin.Context) {
+	opnameID, ok := parseStockOpnameID(c)
+	if !ok {
+		return
+	}
+
+	var input dto.AddStockOpnameCountsInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	opname, err := h.Service.AddCounts(opnameID, userID, input)
+	if err != nil {
+		respondStockOpnameError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toStockOpnameResponse(opname))
+}
+
+// DeleteCount menangani penghapusan satu entri hitung
+func (h *StockOpnameHandler) DeleteCount(c *gin.Context) {
+	opnameID, ok := parseStockOpnameID(c)
+	if !ok {
+		return
+	}
+	countID, err := strconv.ParseUint(c.Param("countId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID entri hitung tidak valid"})
+		return
+	}
+
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	if err := h.Service.DeleteCount(opnameID, uint(countID), userID); err != nil {
+		respondStockOpnameError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Entri hitung berhasil dihapus"})
+}
+
+// GetVarianceReport menangani permintaan laporan selisih stok opname
+func (h *StockOpnameHandler) GetVarianceReport(c *gin.Context) {
+	opnameID, ok := parseStockOpnameID(c)
+	if !ok {
+		return
+	}
+
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.Service.GetVarianceReport(opnameID, userID)
+	if err != nil {
+		respondStockOpnameError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// FinalizeStockOpname menangani finalisasi stok opname (penyesuaian stok & jurnal selisih)
+func (h *StockOpnameHandler) FinalizeStockOpname(c *gin.Context) {
+	opnameID, ok := parseStockOpnameID(c)
+	if !ok {
+		return
+	}
+
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	opname, err := h.Service.FinalizeStockOpname(opnameID, userID)
+	if err != nil {
+		respondStockOpnameError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toStockOpnameResponse(opname))
+}
+
+// CancelStockOpname menangani pembatalan sesi stok opname
+func (h *StockOpnameHandler) CancelStockOpname(c *gin.Context) {
+	opnameID, ok := parseStockOpnameID(c)
+	if !ok {
+		return
+	}
+
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	opname, err := h.Service.CancelStockOpname(opnameID, userID)
+	if err != nil {
+		respondStockOpnameError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toStockOpnameResponse(opname))
+}
@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/danishyusrah/go_bisnis/internal/dto"
+	"github.com/danishyusrah/go_bisnis/internal/models"
+	"github.com/danishyusrah/go_bisnis/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// SupplierHandler menghandle request terkait supplier
+type SupplierHandler struct {
+	Service *services.SupplierService
+}
+
+// NewSupplierHandler membuat handler supplier baru
+func NewSupplierHandler() *SupplierHandler {
+	return &SupplierHandler{
+		Service: services.NewSupplierService(),
+	}
+}
+
+// helper untuk mengubah model supplier menjadi DTO respons
+func toSupplierResponse(supplier models.Supplier) dto.SupplierResponse {
+	return dto.SupplierResponse{
+		ID:        supplier.ID,
+		Name:      supplier.Name,
+		Email:     supplier.Email,
+		Phone:     supplier.Phone,
+		Address:   supplier.Address,
+		CreatedAt: supplier.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt: supplier.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+// respondSupplierError memetakan error layanan supplier ke status HTTP
+func respondSupplierError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "supplier tidak ditemukan":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case "akses ditolak: Anda bukan pemilik supplier ini":
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case "supplier masih memiliki PO yang belum selesai":
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memproses data supplier"})
+	}
+}
+
+// CreateSupplier menangani pembuatan supplier baru
+func (h *SupplierHandler) CreateSupplier(c *gin.Context) {
+	var input dto.CreateSupplierInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	supplier, err := h.Service.CreateSupplier(input, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat supplier"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, toSupplierResponse(supplier))
+}
+
+// GetUserSuppliers menangani pengambilan semua supplier milik user
+func (h *SupplierHandler) GetUserSuppliers(c *gin.Context) {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	suppliers, err := h.Service.GetUserSuppliers(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data supplier"})
+		return
+	}
+
+	responses := []dto.SupplierResponse{}
+	for _, supplier := range suppliers {
+		responses = append(responses, toSupplierResponse(supplier))
+	}
+
+	c.JSON(http.StatusOK, responses)
+}
+
+// GetSupplierByID menangani pengambilan satu supplier
+func (h *SupplierHandler) GetSupplierByID(c *gin.Context) {
+	supplierID, ok := parseIDParam(c, "id", "supplier")
+	if !ok {
+		return
+	}
+
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	supplier, err := h.Service.GetSupplierByID(supplierID, userID)
+	if err != nil {
+		respondSupplierError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toSupplierResponse(supplier))
+}
+
+// UpdateSupplier menangani pembaruan supplier
+func (h *SupplierHandler) UpdateSupplier(c *gin.Context) {
+	supplierID, ok := parseIDParam(c, "id", "supplier")
+	if !ok {
+		return
+	}
+
+	var input dto.UpdateSupplierInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	supplier, err := h.Service.UpdateSupplier(supplierID, input, userID)
+	if err != nil {
+		respondSupplierError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toSupplierResponse(supplier))
+}
+
+// DeleteSupplier menangani penghapusan supplier
+func (h *SupplierHandler) DeleteSupplier(c *gin.Context) {
+	supplierID, ok := parseIDParam(c, "id", "supplier")
+	if !ok {
+		return
+	}
+
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	if err := h.Service.DeleteSupplier(supplierID, userID); err != nil {
+		respondSupplierError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Supplier berhasil dihapus"})
+}
+
+// GetSupplierPurchases menangani pengambilan riwayat pembelian dari satu supplier
+func (h *SupplierHandler) GetSupplierPurchases(c *gin.Context) {
+	supplierID, ok := parseIDParam(c, "id", "supplier")
+	if !ok {
+		return
+	}
+
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	summary, transactions, err := h.Service.GetSupplierPurchases(supplierID, userID)
+	if err != nil {
+		respondSupplierError(c, err)
+		return
+	}
+
+	history := dto.SupplierPurchaseHistory{
+		Summary:      summary,
+		Transactions: []dto.TransactionResponse{},
+	}
+	for _, tx := range transactions {
+		history.Transactions = append(history.Transactions, toTransactionResponse(tx))
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// GetPurchasesBySupplier menangani laporan total pembelian & sisa utang per supplier (?from=&to=)
+func (h *SupplierHandler) GetPurchasesBySupplier(c *gin.Context) {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	startTime, endTime := parseDateRangeForReports(c)
+	summaries, err := h.Service.GetPurchasesBySupplier(userID, startTime, endTime)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil laporan pembelian per supplier"})
+		return
+	}
+
+	c.JSON(http.StatusOK, summaries)
+}
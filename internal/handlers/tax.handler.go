@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/danishyusrah/go_bisnis/internal/dto"
+	"github.com/danishyusrah/go_bisnis/internal/models"
+	"github.com/danishyusrah/go_bisnis/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// TaxHandler menghandle request terkait tarif pajak & laporan PPN
+type TaxHandler struct {
+	Service *services.TaxService
+}
+
+// NewTaxHandler membuat handler pajak baru
+func NewTaxHandler() *TaxHandler {
+	return &TaxHandler{
+		Service: services.NewTaxService(),
+	}
+}
+
+// helper untuk mengubah model tarif pajak menjadi DTO respons
+func toTaxRateResponse(taxRate models.TaxRate) dto.TaxRateResponse {
+	return dto.TaxRateResponse{
+		ID:        taxRate.ID,
+		Name:      taxRate.Name,
+		Rate:      taxRate.Rate,
+		IsDefault: taxRate.IsDefault,
+	}
+}
+
+// GetTaxRates menangani pengambilan semua tarif pajak
+func (h *TaxHandler) GetTaxRates(c *gin.Context) {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	taxRates, err := h.Service.GetTaxRates(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data tarif pajak"})
+		return
+	}
+
+	responses := []dto.TaxRateResponse{}
+	for _, taxRate := range taxRates {
+		responses = append(responses, toTaxRateResponse(taxRate))
+	}
+
+	c.JSON(http.StatusOK, responses)
+}
+
+// CreateTaxRate menangani pembuatan tarif pajak baru
+func (h *TaxHandler) CreateTaxRate(c *gin.Context) {
+	var input dto.CreateTaxRateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	taxRate, err := h.Service.CreateTaxRate(input, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat tarif pajak"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, toTaxRateResponse(taxRate))
+}
+
+// UpdateTaxRate menangani pembaruan tarif pajak
+func (h *TaxHandler) UpdateTaxRate(c *gin.Context) {
+	taxRateID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID tarif pajak tidak valid"})
+		return
+	}
+
+	var input dto.CreateTaxRateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	taxRate, err := h.Service.UpdateTaxRate(uint(taxRateID), input, userID)
+	if err != nil {
+		if err.Error() == "tarif pajak tidak ditemukan" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err.Error() == "akses ditolak: Anda bukan pemilik tarif pajak ini" {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui tarif pajak"})
+		return
+	}
+
+	c.JSON(http.StatusOK, toTaxRateResponse(taxRate))
+}
+
+// DeleteTaxRate menangani penghapusan tarif pajak
+func (h *TaxHandler) DeleteTaxRate(c *gin.Context) {
+	taxRateID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID tarif pajak tidak valid"})
+		return
+	}
+
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	if err := h.Service.DeleteTaxRate(uint(taxRateID), userID); err != nil {
+		if err.Error() == "tarif pajak tidak ditemukan" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err.Error() == "akses ditolak: Anda bukan pemilik tarif pajak ini" {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus tarif pajak"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Tarif pajak berhasil dihapus"})
+}
+
+// GetTaxReport menangani permintaan laporan PPN Keluaran vs Masukan
+func (h *TaxHandler) GetTaxReport(c *gin.Context) {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	startTime, endTime := parseDateRangeForReports(c)
+	locationID, ok := parseLocationFilter(c) // [BARU]
+	if !ok {
+		return
+	}
+
+	report, err := h.Service.GetTaxReport(userID, locationID, startTime, endTime)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data laporan pajak"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
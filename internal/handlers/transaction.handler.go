@@ -33,9 +33,54 @@ func toTransactionResponse(tx models.Transaction) dto.TransactionResponse {
 			ProductName: item.ProductName,
 			Quantity:    item.Quantity,
 			UnitPrice:   item.UnitPrice,
+			// [BARU] Satuan transaksi
+			Unit:         item.UnitName,
+			UnitFactor:   item.UnitFactor,
+			UnitQuantity: item.UnitQuantity,
+			// [BARU]
+			RefundedQuantity: item.RefundedQuantity,
+			TaxRate:          item.TaxRate,
+			TaxInclusive:     item.TaxInclusive,
+			TaxableAmount:    item.TaxableAmount,
+			TaxAmount:        item.TaxAmount,
+			// [BARU] Diskon
+			DiscountType:              item.DiscountType,
+			DiscountValue:             item.DiscountValue,
+			DiscountAmount:            item.DiscountAmount,
+			TransactionDiscountAmount: item.TransactionDiscountAmount,
+			PromotionDiscountAmount:   item.PromotionDiscountAmount, // [BARU]
 		})
 	}
 
+	// --- [BARU] Logika untuk mengisi data Void & Retur ---
+	var voidedAtStr *string
+	if tx.VoidedAt != nil {
+		formatted := tx.VoidedAt.Format("2006-01-02 15:04:05")
+		voidedAtStr = &formatted
+	}
+	refunds := []dto.RefundResponse{}
+	for _, refund := range tx.Refunds {
+		refundItems := []dto.RefundItemResponse{}
+		for _, ri := range refund.Items {
+			refundItems = append(refundItems, dto.RefundItemResponse{
+				TransactionItemID: ri.TransactionItemID,
+				Quantity:          ri.Quantity,
+				Amount:            ri.Amount,
+				TaxAmount:         ri.TaxAmount,
+			})
+		}
+		refunds = append(refunds, dto.RefundResponse{
+			ID:         refund.ID,
+			Amount:     refund.Amount,
+			CashAmount: refund.CashAmount,
+			TaxAmount:  refund.TaxAmount,
+			Reason:     refund.Reason,
+			CreatedAt:  refund.CreatedAt.Format("2006-01-02 15:04:05"),
+			Items:      refundItems,
+		})
+	}
+	// --- [AKHIR BARU] ---
+
 	// [DIUBAH] Logika untuk mengisi data pelanggan
 	var customerID *uint
 	var customerName string
@@ -47,6 +92,15 @@ func toTransactionResponse(tx models.Transaction) dto.TransactionResponse {
 		customerName = "Umum" // Default
 	}
 
+	// [BARU] Supplier untuk pengeluaran. Nama pihak lawan di daftar transaksi ikut memakai nama supplier.
+	var supplierID *uint
+	var supplierName string
+	if tx.Supplier != nil {
+		supplierID = &tx.Supplier.ID
+		supplierName = tx.Supplier.Name
+		customerName = tx.Supplier.Name
+	}
+
 	// --- [BARU] Logika untuk mengisi data Jatuh Tempo ---
 	var dueDateStr *string
 	if tx.DueDate != nil {
@@ -55,6 +109,31 @@ func toTransactionResponse(tx models.Transaction) dto.TransactionResponse {
 	}
 	// --- [AKHIR BARU] ---
 
+	// --- [BARU] Logika untuk mengisi data Pembayaran ---
+	payments := []dto.PaymentResponse{}
+	for _, p := range tx.Payments {
+		payments = append(payments, dto.PaymentResponse{
+			ID:          p.ID,
+			Amount:      p.Amount,
+			PaymentDate: p.PaymentDate.Format("2006-01-02 15:04:05"),
+			Method:      p.Method,
+			Notes:       p.Notes,
+		})
+	}
+	// --- [AKHIR BARU] ---
+
+	// --- [BARU] Logika untuk mengisi data Promo ---
+	promotions := []dto.AppliedPromotionResponse{}
+	for _, p := range tx.Promotions {
+		promotions = append(promotions, dto.AppliedPromotionResponse{
+			PromotionID:    p.PromotionID,
+			Name:           p.Name,
+			Type:           p.Type,
+			DiscountAmount: p.DiscountAmount,
+		})
+	}
+	// --- [AKHIR BARU] ---
+
 	// --- [BARU] Logika untuk mengisi data Kategori ---
 	var categoryID *uint
 	var categoryName string
@@ -64,6 +143,12 @@ func toTransactionResponse(tx models.Transaction) dto.TransactionResponse {
 	}
 	// --- [AKHIR BARU] ---
 
+	// [BARU] Nama lokasi transaksi
+	var locationName string
+	if tx.Location != nil {
+		locationName = tx.Location.Name
+	}
+
 	return dto.TransactionResponse{
 		ID:           tx.ID,
 		Type:         tx.Type,
@@ -73,6 +158,8 @@ func toTransactionResponse(tx models.Transaction) dto.TransactionResponse {
 		Items:        items,
 		CustomerID:   customerID,
 		CustomerName: customerName,
+		SupplierID:   supplierID,   // [BARU]
+		SupplierName: supplierName, // [BARU]
 
 		// [BARU DARI FITUR SEBELUMNYA]
 		PaymentStatus: tx.PaymentStatus,
@@ -82,6 +169,49 @@ func toTransactionResponse(tx models.Transaction) dto.TransactionResponse {
 		CategoryID:   categoryID,
 		CategoryName: categoryName,
 		// --- [AKHIR BARU] ---
+
+		// --- [BARU UNTUK FITUR MULTI LOKASI] ---
+		LocationID:   tx.LocationID,
+		LocationName: locationName,
+		// --- [AKHIR BARU] ---
+
+		PurchaseOrderID: tx.PurchaseOrderID, // [BARU]
+		CreditOverride:  tx.CreditOverride,  // [BARU]
+		// [BARU] Poin loyalitas
+		LoyaltyPointsEarned:   tx.LoyaltyPointsEarned,
+		LoyaltyPointsRedeemed: tx.LoyaltyPointsRedeemed,
+		LoyaltyDiscountAmount: tx.LoyaltyDiscountAmount,
+
+		// --- [BARU UNTUK FITUR VOID & RETUR] ---
+		Status:         tx.Status,
+		RefundedAmount: tx.RefundedAmount,
+		VoidReason:     tx.VoidReason,
+		VoidedAt:       voidedAtStr,
+		Refunds:        refunds,
+		// --- [AKHIR BARU] ---
+
+		// --- [BARU UNTUK FITUR CICILAN] ---
+		PaidAmount:        tx.PaidAmount,
+		OutstandingAmount: tx.OutstandingAmount(),
+		Payments:          payments,
+		// --- [AKHIR BARU] ---
+
+		// --- [BARU UNTUK FITUR PAJAK] ---
+		TaxAmount:         tx.TaxAmount,
+		RefundedTaxAmount: tx.RefundedTaxAmount,
+		// --- [AKHIR BARU] ---
+
+		// --- [BARU UNTUK FITUR DISKON] ---
+		GrossAmount:    tx.GrossAmount,
+		DiscountType:   tx.DiscountType,
+		DiscountValue:  tx.DiscountValue,
+		DiscountAmount: tx.DiscountAmount,
+		// --- [AKHIR BARU] ---
+
+		// --- [BARU UNTUK FITUR PROMO] ---
+		PromotionAmount: tx.PromotionAmount,
+		Promotions:      promotions,
+		// --- [AKHIR BARU] ---
 	}
 }
 
@@ -201,7 +331,7 @@ func (h *TransactionHandler) MarkTransactionPaid(c *gin.Context) {
 			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
 			return
 		}
-		if err.Error() == "transaksi ini sudah lunas" {
+		if err.Error() == "transaksi ini sudah lunas" || err.Error() == "transaksi ini sudah dibatalkan" || err.Error() == "periode akuntansi sudah ditutup" {
 			c.JSON(http.StatusConflict, gin.H{"error": err.Error()}) // 409 Conflict
 			return
 		}
@@ -212,3 +342,128 @@ func (h *TransactionHandler) MarkTransactionPaid(c *gin.Context) {
 	// 4. Kirim respons sukses
 	c.JSON(http.StatusOK, gin.H{"message": "Transaksi berhasil ditandai sebagai lunas"})
 }
+
+// --- [BARU] FUNGSI UNTUK VOID & RETUR ---
+
+// VoidTransaction menangani pembatalan (void) seluruh transaksi
+func (h *TransactionHandler) VoidTransaction(c *gin.Context) {
+	txID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID transaksi tidak valid"})
+		return
+	}
+
+	var input dto.VoidTransactionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	transaction, err := h.Service.VoidTransaction(uint(txID), userID, input)
+	if err != nil {
+		if err.Error() == "transaksi tidak ditemukan" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err.Error() == "akses ditolak: Anda bukan pemilik transaksi ini" {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if err.Error() == "transaksi ini sudah dibatalkan" || err.Error() == "periode akuntansi sudah ditutup" {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		// Error validasi lain (cth: stok tidak cukup untuk membatalkan pembelian)
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, toTransactionResponse(transaction))
+}
+
+// RefundTransaction menangani retur sebagian item transaksi
+func (h *TransactionHandler) RefundTransaction(c *gin.Context) {
+	txID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID transaksi tidak valid"})
+		return
+	}
+
+	var input dto.RefundTransactionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	transaction, err := h.Service.RefundTransaction(uint(txID), userID, input)
+	if err != nil {
+		if err.Error() == "transaksi tidak ditemukan" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err.Error() == "akses ditolak: Anda bukan pemilik transaksi ini" {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if err.Error() == "transaksi ini sudah dibatalkan" || err.Error() == "periode akuntansi sudah ditutup" {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, toTransactionResponse(transaction))
+}
+
+// --- [BARU] FUNGSI UNTUK PEMBAYARAN CICILAN ---
+
+// AddPayment menangani pencatatan pembayaran (cicilan) utang/piutang
+func (h *TransactionHandler) AddPayment(c *gin.Context) {
+	txID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID transaksi tidak valid"})
+		return
+	}
+
+	var input dto.CreatePaymentInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	transaction, err := h.Service.AddPayment(uint(txID), userID, input)
+	if err != nil {
+		if err.Error() == "transaksi tidak ditemukan" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err.Error() == "akses ditolak: Anda bukan pemilik transaksi ini" {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if err.Error() == "transaksi ini sudah lunas" || err.Error() == "transaksi ini sudah dibatalkan" || err.Error() == "periode akuntansi sudah ditutup" {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, toTransactionResponse(transaction))
+}
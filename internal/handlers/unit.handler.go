@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/danishyusrah/go_bisnis/internal/dto"
+	"github.com/danishyusrah/go_bisnis/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// UnitHandler menghandle request terkait satuan produk & konversinya
+type UnitHandler struct {
+	Service *services.UnitService
+}
+
+// NewUnitHandler membuat handler satuan produk baru
+func NewUnitHandler() *UnitHandler {
+	return &UnitHandler{
+		Service: services.NewUnitService(),
+	}
+}
+
+// respondUnitError memetakan error satuan produk ke status HTTP
+func respondUnitError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "produk tidak ditemukan":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case "akses ditolak: Anda bukan pemilik produk ini":
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	}
+}
+
+// GetProductUnits menangani pengambilan satuan alternatif produk
+func (h *UnitHandler) GetProductUnits(c *gin.Context) {
+	productID, ok := parseIDParam(c, "id", "produk")
+	if !ok {
+		return
+	}
+
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	units, err := h.Service.GetProductUnits(productID, userID)
+	if err != nil {
+		respondUnitError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toProductUnitResponses(units))
+}
+
+// SetProductUnits menangani penggantian seluruh satuan alternatif produk
+func (h *UnitHandler) SetProductUnits(c *gin.Context) {
+	productID, ok := parseIDParam(c, "id", "produk")
+	if !ok {
+		return
+	}
+
+	var input dto.SetProductUnitsInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	units, err := h.Service.SetProductUnits(productID, userID, input)
+	if err != nil {
+		respondUnitError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toProductUnitResponses(units))
+}
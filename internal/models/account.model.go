@@ -0,0 +1,72 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+// AccountType mendefinisikan golongan akun dalam bagan akun (chart of accounts)
+type AccountType string
+
+const (
+	AccountAsset     AccountType = "ASSET"     // Aset / Harta
+	AccountLiability AccountType = "LIABILITY" // Kewajiban / Utang
+	AccountEquity    AccountType = "EQUITY"    // Ekuitas / Modal
+	AccountRevenue   AccountType = "REVENUE"   // Pendapatan
+	AccountExpense   AccountType = "EXPENSE"   // Beban
+)
+
+// Kode akun sistem yang dipakai oleh posting jurnal otomatis
+const (
+	AccountCodeCash             = "1100" // Kas
+	AccountCodeReceivable       = "1200" // Piutang Usaha
+	AccountCodeInventory        = "1300" // Persediaan Barang
+	AccountCodeInputVAT         = "1400" // PPN Masukan [BARU]
+	AccountCodePayable          = "2100" // Utang Usaha
+	AccountCodeGoodsReceived    = "2150" // Barang Diterima Belum Ditagih (PO) [BARU]
+	AccountCodeOutputVAT        = "2200" // PPN Keluaran [BARU]
+	AccountCodeLoyaltyLiability = "2300" // Liabilitas Poin Loyalitas [BARU]
+	AccountCodeCapital          = "3100" // Modal Pemilik
+	AccountCodeRetainedEarnings = "3200" // Laba Ditahan
+	AccountCodeStockAdjustment  = "3300" // Penyesuaian Persediaan (stok awal & edit stok manual) [BARU]
+	AccountCodeSales            = "4100" // Pendapatan Penjualan
+	AccountCodeCOGS             = "5100" // Harga Pokok Penjualan
+	AccountCodeShrinkage        = "5200" // Beban Selisih Persediaan (stok opname) [BARU]
+	AccountCodeOperatingExpense = "6100" // Beban Operasional
+	AccountCodeLoyaltyExpense   = "6200" // Beban Program Loyalitas [BARU]
+)
+
+// DefaultAccounts adalah bagan akun standar yang dibuat otomatis untuk setiap user
+var DefaultAccounts = []Account{
+	{Code: AccountCodeCash, Name: "Kas", Type: AccountAsset},
+	{Code: AccountCodeReceivable, Name: "Piutang Usaha", Type: AccountAsset},
+	{Code: AccountCodeInventory, Name: "Persediaan Barang", Type: AccountAsset},
+	{Code: AccountCodeInputVAT, Name: "PPN Masukan", Type: AccountAsset},
+	{Code: AccountCodePayable, Name: "Utang Usaha", Type: AccountLiability},
+	{Code: AccountCodeGoodsReceived, Name: "Barang Diterima Belum Ditagih", Type: AccountLiability},
+	{Code: AccountCodeOutputVAT, Name: "PPN Keluaran", Type: AccountLiability},
+	{Code: AccountCodeLoyaltyLiability, Name: "Liabilitas Poin Loyalitas", Type: AccountLiability},
+	{Code: AccountCodeCapital, Name: "Modal Pemilik", Type: AccountEquity},
+	{Code: AccountCodeRetainedEarnings, Name: "Laba Ditahan", Type: AccountEquity},
+	{Code: AccountCodeStockAdjustment, Name: "Penyesuaian Persediaan", Type: AccountEquity},
+	{Code: AccountCodeSales, Name: "Pendapatan Penjualan", Type: AccountRevenue},
+	{Code: AccountCodeCOGS, Name: "Harga Pokok Penjualan", Type: AccountExpense},
+	{Code: AccountCodeShrinkage, Name: "Beban Selisih Persediaan", Type: AccountExpense},
+	{Code: AccountCodeOperatingExpense, Name: "Beban Operasional", Type: AccountExpense},
+	{Code: AccountCodeLoyaltyExpense, Name: "Beban Program Loyalitas", Type: AccountExpense},
+}
+
+// Account adalah model untuk tabel 'accounts'
+// Setiap user memiliki bagan akunnya sendiri
+type Account struct {
+	gorm.Model
+	UserID   uint        `gorm:"not null;uniqueIndex:idx_accounts_user_code"`
+	Code     string      `gorm:"not null;size:20;uniqueIndex:idx_accounts_user_code"`
+	Name     string      `gorm:"not null;size:255"`
+	Type     AccountType `gorm:"not null;size:20;index"`
+	IsSystem bool        `gorm:"default:false"` // Akun sistem dipakai posting otomatis, tidak boleh diubah
+}
+
+// IsDebitNormal mengembalikan true jika saldo normal akun ada di sisi debit
+func (a Account) IsDebitNormal() bool {
+	return a.Type == AccountAsset || a.Type == AccountExpense
+}
@@ -12,12 +12,23 @@ const (
 	ExpenseCategory CategoryType = "EXPENSE"
 )
 
+// [BARU] CashFlowSectionType menentukan bagian laporan arus kas untuk kategori ini
+type CashFlowSectionType string
+
+const (
+	CashFlowOperating CashFlowSectionType = "OPERATING" // Aktivitas operasi (default)
+	CashFlowInvesting CashFlowSectionType = "INVESTING" // Aktivitas investasi (cth: beli peralatan)
+	CashFlowFinancing CashFlowSectionType = "FINANCING" // Aktivitas pendanaan (cth: pinjaman)
+)
+
 // Category adalah model untuk tabel 'categories'
 type Category struct {
 	gorm.Model
 	Name   string       `gorm:"not null;size:255"`
 	Type   CategoryType `gorm:"not null;index"` // "INCOME" atau "EXPENSE"
 	UserID uint         `gorm:"not null;index"` // Milik user siapa
+	// [BARU] Bagian arus kas (OPERATING, INVESTING, FINANCING)
+	CashFlowSection CashFlowSectionType `gorm:"not null;size:20;default:'OPERATING'"`
 
 	// Relasi
 	User         User
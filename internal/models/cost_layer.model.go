@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CostingMethod mendefinisikan metode penilaian persediaan (HPP) per user
+type CostingMethod string
+
+const (
+	CostingAverage CostingMethod = "AVERAGE" // Rata-rata tertimbang bergerak (moving average)
+	CostingFIFO    CostingMethod = "FIFO"    // Masuk pertama keluar pertama (lapisan biaya)
+)
+
+// CostLayer adalah model untuk tabel 'cost_layers' (lapisan biaya FIFO).
+// Setiap barang masuk membentuk satu lapisan, barang keluar mengurangi lapisan tertua lebih dulu.
+type CostLayer struct {
+	gorm.Model
+	UserID            uint      `gorm:"not null;index"`
+	ProductID         uint      `gorm:"not null;index"`
+	TransactionID     *uint     `gorm:"index"` // Transaksi sumber barang masuk (jika ada)
+	UnitCost          float64   `gorm:"not null;type:decimal(20,2)"`
+	Quantity          int       `gorm:"not null"` // Jumlah awal saat masuk
+	RemainingQuantity int       `gorm:"not null"` // Sisa yang belum terpakai
+	ReceivedAt        time.Time `gorm:"not null;index"`
+}
@@ -13,6 +13,14 @@ type Customer struct {
 	Phone   string `gorm:"size:50"`  // Opsional
 	Address string // Opsional
 
+	// --- [BARU] Batas kredit (penjualan BELUM LUNAS) ---
+	CreditLimit float64 `gorm:"type:decimal(20,2);default:0"` // Maksimal sisa piutang, 0 = tanpa batas
+	// Jika aktif, penjualan kredit ditolak selama ada tagihan yang lewat jatuh tempo
+	// lebih dari MaxOverdueDays hari (0 = tidak ada toleransi)
+	CreditHoldOnOverdue bool `gorm:"default:false"`
+	MaxOverdueDays      int  `gorm:"default:0"`
+	// --- [AKHIR BARU] ---
+
 	// Relasi: Setiap pelanggan dimiliki oleh satu User
 	UserID uint `gorm:"not null"` // Foreign Key ke tabel users
 	User   User // GORM akan otomatis mengelola relasi ini
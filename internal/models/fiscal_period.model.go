@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// FiscalPeriodStatusType mendefinisikan status periode akuntansi
+type FiscalPeriodStatusType string
+
+const (
+	PeriodOpen   FiscalPeriodStatusType = "OPEN"   // Periode masih bisa diubah
+	PeriodClosed FiscalPeriodStatusType = "CLOSED" // Periode sudah ditutup (terkunci)
+)
+
+// FiscalPeriod adalah model untuk tabel 'fiscal_periods'
+// Satu baris mewakili satu bulan akuntansi milik user. Saat ditutup, saldo akhir
+// disimpan sebagai snapshot sehingga laporan tidak perlu menjumlahkan seluruh riwayat.
+// Menutup sebuah periode juga mengunci semua periode sebelumnya.
+type FiscalPeriod struct {
+	gorm.Model
+	UserID    uint                   `gorm:"not null;uniqueIndex:idx_fiscal_period_user_month"`
+	Year      int                    `gorm:"not null;uniqueIndex:idx_fiscal_period_user_month"`
+	Month     int                    `gorm:"not null;uniqueIndex:idx_fiscal_period_user_month"`
+	StartDate time.Time              `gorm:"not null;type:date"` // Hari pertama periode
+	EndDate   time.Time              `gorm:"not null;type:date"` // Hari terakhir periode
+	Status    FiscalPeriodStatusType `gorm:"not null;size:20;default:'OPEN';index"`
+	ClosedAt  *time.Time             `gorm:"null"`
+
+	// Snapshot saldo akhir buku besar saat periode ditutup
+	AccrualEndingBalance float64 `gorm:"type:decimal(20,2);default:0"` // Basis akrual
+	CashEndingBalance    float64 `gorm:"type:decimal(20,2);default:0"` // Basis kas
+}
+
+// Key mengubah tahun & bulan menjadi angka berurutan (untuk membandingkan periode)
+func (p FiscalPeriod) Key() int {
+	return periodKey(p.Year, time.Month(p.Month))
+}
+
+// FiscalPeriodKey menghitung key periode untuk sebuah tanggal (zona waktu lokal server)
+func FiscalPeriodKey(t time.Time) int {
+	local := t.In(time.Local)
+	return periodKey(local.Year(), local.Month())
+}
+
+func periodKey(year int, month time.Month) int {
+	return year*12 + int(month) - 1
+}
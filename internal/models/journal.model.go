@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// JournalSourceType mendefinisikan asal sebuah jurnal
+type JournalSourceType string
+
+const (
+	JournalSourceTransaction JournalSourceType = "TRANSACTION"      // Posting otomatis saat transaksi dibuat
+	JournalSourcePayment     JournalSourceType = "PAYMENT"          // Posting otomatis saat cicilan dibayar
+	JournalSourceRefund      JournalSourceType = "REFUND"           // Posting otomatis saat retur
+	JournalSourceVoid        JournalSourceType = "VOID"             // Jurnal pembalik saat transaksi dibatalkan
+	JournalSourceWriteOff    JournalSourceType = "BATCH_WRITE_OFF"  // [BARU] Pemusnahan batch kedaluwarsa/rusak
+	JournalSourceOpname      JournalSourceType = "STOCK_OPNAME"     // [BARU] Selisih persediaan hasil stok opname
+	JournalSourceReceipt     JournalSourceType = "GOODS_RECEIPT"    // [BARU] Penerimaan barang dari PO
+	JournalSourceLoyalty     JournalSourceType = "LOYALTY"          // [BARU] Mutasi liabilitas poin loyalitas
+	JournalSourceStockAdjust JournalSourceType = "STOCK_ADJUSTMENT" // [BARU] Stok awal & edit stok/harga produk manual
+	JournalSourceOpening     JournalSourceType = "OPENING_BALANCE"  // [BARU] Saldo awal data lama (sebelum ada jurnal otomatis)
+)
+
+// JournalEntry adalah model untuk tabel 'journal_entries' (header jurnal umum)
+// Jumlah debit seluruh baris selalu sama dengan jumlah kredit
+type JournalEntry struct {
+	gorm.Model
+	UserID        uint              `gorm:"not null;index"`
+	EntryDate     time.Time         `gorm:"not null;index"`
+	Description   string            `gorm:"size:255"`
+	SourceType    JournalSourceType `gorm:"not null;size:30;index"`
+	TransactionID *uint             `gorm:"index"` // Transaksi asal (nullable)
+
+	// Relasi
+	Lines []JournalLine `gorm:"foreignKey:JournalEntryID"`
+}
+
+// JournalLine adalah model untuk tabel 'journal_lines' (baris debit/kredit)
+type JournalLine struct {
+	gorm.Model
+	JournalEntryID uint    `gorm:"not null;index"`
+	AccountID      uint    `gorm:"not null;index"`
+	Debit          float64 `gorm:"type:decimal(20,2);default:0"`
+	Credit         float64 `gorm:"type:decimal(20,2);default:0"`
+
+	// Relasi
+	Account Account
+}
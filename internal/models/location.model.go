@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Location adalah model untuk tabel 'locations' (gudang/outlet).
+// Setiap user memiliki tepat satu lokasi default yang dipakai jika lokasi tidak disebutkan.
+type Location struct {
+	gorm.Model
+	UserID    uint   `gorm:"not null;index"`
+	Name      string `gorm:"not null;size:100"`
+	Address   string `gorm:"size:255"`
+	IsDefault bool   `gorm:"not null;default:false"`
+}
+
+// ProductStock adalah model untuk tabel 'product_stocks' (stok produk per lokasi).
+// Product.Stock selalu sama dengan jumlah Quantity seluruh lokasi.
+type ProductStock struct {
+	gorm.Model
+	UserID     uint `gorm:"not null;index"`
+	ProductID  uint `gorm:"not null;uniqueIndex:idx_product_location"`
+	LocationID uint `gorm:"not null;uniqueIndex:idx_product_location"`
+	Quantity   int  `gorm:"not null;default:0"`
+
+	// Relasi
+	Product  Product  `gorm:"foreignKey:ProductID"`
+	Location Location `gorm:"foreignKey:LocationID"`
+}
+
+// StockTransfer adalah model untuk tabel 'stock_transfers' (dokumen mutasi stok antar lokasi)
+type StockTransfer struct {
+	gorm.Model
+	UserID         uint      `gorm:"not null;index"`
+	FromLocationID uint      `gorm:"not null;index"`
+	ToLocationID   uint      `gorm:"not null;index"`
+	TransferDate   time.Time `gorm:"not null"`
+	Notes          string
+
+	// Relasi
+	FromLocation Location            `gorm:"foreignKey:FromLocationID"`
+	ToLocation   Location            `gorm:"foreignKey:ToLocationID"`
+	Items        []StockTransferItem `gorm:"foreignKey:StockTransferID"`
+}
+
+// StockTransferItem adalah model untuk tabel 'stock_transfer_items'
+type StockTransferItem struct {
+	gorm.Model
+	StockTransferID uint   `gorm:"not null;index"`
+	ProductID       uint   `gorm:"not null;index"`
+	ProductName     string `gorm:"not null"`
+	Quantity        int    `gorm:"not null"`
+}
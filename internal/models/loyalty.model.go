@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// LoyaltyProgram adalah model untuk tabel 'loyalty_programs'.
+// Setiap user punya satu pengaturan program poin untuk pelanggannya.
+type LoyaltyProgram struct {
+	gorm.Model
+	UserID          uint    `gorm:"not null;uniqueIndex"`
+	IsActive        bool    `gorm:"default:false"`
+	SpendPerPoint   float64 `gorm:"type:decimal(20,2);default:0"` // Belanja (Rp) untuk mendapat 1 poin
+	PointValue      float64 `gorm:"type:decimal(20,2);default:0"` // Nilai potongan (Rp) per 1 poin saat ditukar
+	MinRedeemPoints int     `gorm:"default:0"`                    // Minimal poin sekali tukar
+	ExpiryMonths    int     `gorm:"default:0"`                    // Masa berlaku poin sejak diperoleh, 0 = tidak kedaluwarsa
+}
+
+// LoyaltyEntryType mendefinisikan jenis mutasi poin pelanggan
+type LoyaltyEntryType string
+
+const (
+	LoyaltyEarn    LoyaltyEntryType = "EARN"    // Poin dari transaksi penjualan
+	LoyaltyRedeem  LoyaltyEntryType = "REDEEM"  // Poin ditukar sebagai potongan transaksi
+	LoyaltyExpire  LoyaltyEntryType = "EXPIRE"  // Poin hangus karena melewati masa berlaku
+	LoyaltyReverse LoyaltyEntryType = "REVERSE" // Pembalikan poin karena transaksi dibatalkan (void)
+	LoyaltyAdjust  LoyaltyEntryType = "ADJUST"  // Koreksi manual
+)
+
+// LoyaltyLedgerEntry adalah model untuk tabel 'loyalty_ledger_entries' (buku poin pelanggan).
+// Entri bernilai positif sekaligus menjadi "lot" poin yang dipakai/hangus secara FIFO.
+type LoyaltyLedgerEntry struct {
+	gorm.Model
+	UserID        uint             `gorm:"not null;index"`
+	CustomerID    uint             `gorm:"not null;index"`
+	TransactionID *uint            `gorm:"index"`
+	Type          LoyaltyEntryType `gorm:"not null;size:20;index"`
+	EntryDate     time.Time        `gorm:"not null;index"`
+	Points        int              `gorm:"not null"` // Positif menambah saldo, negatif mengurangi
+	// Nilai rupiah mutasi ini (Points x nilai per poin saat itu), dasar pencatatan liabilitas poin
+	Value float64 `gorm:"type:decimal(20,2);default:0"`
+	// Khusus entri positif: sisa poin yang belum terpakai/hangus dan tanggal kedaluwarsanya
+	RemainingPoints int        `gorm:"default:0"`
+	ExpiresAt       *time.Time `gorm:"null;index"`
+	Notes           string
+}
+
+// UnitValue mengembalikan nilai rupiah per poin dari sebuah lot poin
+func (e LoyaltyLedgerEntry) UnitValue() float64 {
+	if e.Points == 0 {
+		return 0
+	}
+	return e.Value / float64(e.Points)
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PaymentMethodType mendefinisikan cara pembayaran
+type PaymentMethodType string
+
+const (
+	MethodTunai    PaymentMethodType = "TUNAI"
+	MethodTransfer PaymentMethodType = "TRANSFER"
+	MethodQRIS     PaymentMethodType = "QRIS"
+	MethodLainnya  PaymentMethodType = "LAINNYA"
+	MethodRetur    PaymentMethodType = "RETUR" // Pengembalian dana karena retur (nilai negatif)
+)
+
+// Payment adalah model untuk tabel 'payments'
+// Setiap uang yang diterima/dibayarkan untuk sebuah transaksi dicatat di sini,
+// sehingga utang/piutang bisa dilunasi secara cicilan
+type Payment struct {
+	gorm.Model
+	TransactionID uint              `gorm:"not null;index"`
+	UserID        uint              `gorm:"not null;index"`
+	Amount        float64           `gorm:"not null;type:decimal(20,2)"` // Negatif jika uang dikembalikan (retur)
+	PaymentDate   time.Time         `gorm:"not null;index"`
+	Method        PaymentMethodType `gorm:"not null;size:20;default:'TUNAI'"`
+	Notes         string
+}
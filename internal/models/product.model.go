@@ -14,13 +14,58 @@ type Product struct {
 	PurchasePrice float64 `gorm:"type:decimal(20,2)"` // Harga Beli
 	// [DIUBAH] decimal(10,2) -> decimal(20,2)
 	SellingPrice float64 `gorm:"type:decimal(20,2)"` // Harga Jual
-	Stock        int     `gorm:"default:0"`
+	Stock        int     `gorm:"default:0"`          // [DIUBAH] Selalu dalam satuan dasar (BaseUnit)
+
+	// [BARU] Satuan dasar stok (cth: pcs, gram). Harus satuan terkecil karena stok disimpan
+	// sebagai bilangan bulat; satuan lain (Units) dikonversi ke satuan ini.
+	BaseUnit string        `gorm:"size:50;not null;default:'pcs'"`
+	Units    []ProductUnit `gorm:"foreignKey:ProductID"`
 
 	// --- [BARU UNTUK FITUR STOK MINIMUM] ---
 	BatasStokMinimum int `gorm:"default:0"` // Batas stok untuk peringatan
 	// --- [AKHIR BARU] ---
 
+	// [BARU] Produk bebas pajak (cth: barang kebutuhan pokok) tidak dikenakan PPN
+	TaxExempt bool `gorm:"not null;default:false"`
+
+	// [BARU] Kategori produk (kategori tipe INCOME), dipakai untuk promo per kategori
+	CategoryID *uint     `gorm:"index"`
+	Category   *Category `gorm:"foreignKey:CategoryID"`
+
+	// [BARU] Pelacakan batch/lot & tanggal kedaluwarsa (FEFO) untuk produk mudah rusak
+	TrackLots bool `gorm:"not null;default:false"`
+
+	// [BARU] Varian produk (cth: ukuran/warna). Varian tetap produk biasa dengan SKU, harga & stok
+	// sendiri; ParentID menunjuk ke produk induk yang hanya berfungsi sebagai pengelompok.
+	ParentID   *uint              `gorm:"index"`
+	Variants   []Product          `gorm:"foreignKey:ParentID"`
+	Attributes []ProductAttribute `gorm:"foreignKey:ProductID"`
+
+	// [BARU] Produk komposit (cth: Kopi Susu) tidak menyimpan stok sendiri; penjualannya
+	// mengurangi stok bahan sesuai resep (Components), dan stoknya = jumlah yang masih bisa dibuat.
+	IsComposite bool               `gorm:"not null;default:false"`
+	Components  []ProductComponent `gorm:"foreignKey:ProductID"`
+
 	// Relasi: Setiap produk dimiliki oleh satu User
 	UserID uint `gorm:"not null"` // Foreign Key ke tabel users
 	User   User // GORM akan otomatis mengelola relasi ini
 }
+
+// [BARU] ProductAttribute adalah atribut pembeda satu varian (cth: Ukuran = XL, Warna = Merah)
+type ProductAttribute struct {
+	gorm.Model
+	ProductID uint   `gorm:"not null;index"`
+	Name      string `gorm:"not null;size:100"`
+	Value     string `gorm:"not null;size:100"`
+}
+
+// [BARU] ProductUnit adalah satuan alternatif produk untuk pembelian/penjualan (cth: karton = 24 pcs,
+// kg = 1000 gram). Jumlah dalam satuan ini dikalikan Factor menjadi jumlah satuan dasar.
+type ProductUnit struct {
+	gorm.Model
+	ProductID    uint    `gorm:"not null;uniqueIndex:idx_product_unit_name"`
+	Name         string  `gorm:"not null;size:50;uniqueIndex:idx_product_unit_name"`
+	Factor       int     `gorm:"not null"`                     // Jumlah satuan dasar per 1 satuan ini
+	AllowDecimal bool    `gorm:"not null;default:false"`       // Boleh jumlah desimal (cth: 1,25 kg)
+	SellingPrice float64 `gorm:"type:decimal(20,2);default:0"` // Harga jual per satuan ini (0 = harga dasar x Factor)
+}
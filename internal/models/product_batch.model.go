@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ProductBatch adalah model untuk tabel 'product_batches' (batch/lot produk yang dilacak kedaluwarsanya).
+// Untuk produk dengan TrackLots = true, jumlah RemainingQuantity seluruh batch = Product.Stock.
+type ProductBatch struct {
+	gorm.Model
+	UserID            uint       `gorm:"not null;index"`
+	ProductID         uint       `gorm:"not null;index"`
+	LotNumber         string     `gorm:"size:100"`
+	ExpiryDate        *time.Time `gorm:"type:date;index"` // Kosong = tidak ada tanggal kedaluwarsa
+	Quantity          int        `gorm:"not null"`        // Jumlah awal saat masuk
+	RemainingQuantity int        `gorm:"not null"`        // Sisa yang belum terjual/dimusnahkan
+	TransactionID     *uint      `gorm:"index"`           // Transaksi pembelian sumber (jika ada)
+	ReceivedAt        time.Time  `gorm:"not null"`
+
+	// Relasi
+	Product Product `gorm:"foreignKey:ProductID"`
+}
+
+// BatchConsumption adalah model untuk tabel 'batch_consumptions'.
+// Mencatat batch mana yang terpakai oleh sebuah transaksi, agar void/retur bisa mengembalikannya ke batch asal.
+type BatchConsumption struct {
+	gorm.Model
+	UserID         uint  `gorm:"not null;index"`
+	ProductBatchID uint  `gorm:"not null;index"`
+	ProductID      uint  `gorm:"not null;index"`
+	TransactionID  *uint `gorm:"index"`
+	Quantity       int   `gorm:"not null"` // Sisa yang belum dikembalikan
+}
+
+// BatchWriteOff adalah model untuk tabel 'batch_write_offs' (pemusnahan batch kedaluwarsa/rusak)
+type BatchWriteOff struct {
+	gorm.Model
+	UserID         uint    `gorm:"not null;index"`
+	ProductBatchID uint    `gorm:"not null;index"`
+	ProductID      uint    `gorm:"not null;index"`
+	LocationID     *uint   `gorm:"index"` // [BARU] Lokasi asal stok yang dimusnahkan
+	Quantity       int     `gorm:"not null"`
+	UnitCost       float64 `gorm:"type:decimal(20,2);default:0"`
+	Amount         float64 `gorm:"type:decimal(20,2);default:0"` // Nilai persediaan yang dihapus (beban)
+	Reason         string  `gorm:"size:255"`
+}
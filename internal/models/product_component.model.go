@@ -0,0 +1,26 @@
+package models
+
+import "gorm.io/gorm"
+
+// ProductComponent adalah model untuk tabel 'product_components' (resep/BOM produk komposit).
+// Satu baris = satu bahan beserta jumlah yang dipakai untuk membuat 1 unit produk komposit.
+type ProductComponent struct {
+	gorm.Model
+	ProductID   uint `gorm:"not null;index"` // Produk komposit (cth: Kopi Susu)
+	ComponentID uint `gorm:"not null;index"` // Produk bahan (cth: Biji Kopi, Susu, Gelas)
+	Quantity    int  `gorm:"not null"`       // Jumlah bahan per 1 unit komposit
+
+	// Relasi
+	Component Product `gorm:"foreignKey:ComponentID"`
+}
+
+// TransactionItemComponent adalah model untuk tabel 'transaction_item_components'.
+// Snapshot bahan yang terpakai saat produk komposit terjual, agar void/retur mengembalikan
+// stok bahan yang sama meskipun resepnya sudah berubah.
+type TransactionItemComponent struct {
+	gorm.Model
+	TransactionItemID uint    `gorm:"not null;index"`
+	ComponentID       uint    `gorm:"not null;index"`
+	Quantity          int     `gorm:"not null"`                     // Jumlah bahan per 1 unit item
+	UnitCost          float64 `gorm:"type:decimal(20,2);default:0"` // Biaya per unit bahan saat terjual
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PromotionType mendefinisikan jenis aturan promo
+type PromotionType string
+
+const (
+	PromoBuyXGetY  PromotionType = "BUY_X_GET_Y"       // Beli BuyQuantity, gratis GetQuantity (produk yang sama)
+	PromoBundle    PromotionType = "BUNDLE_PRICE"      // BuyQuantity unit produk dijual seharga BundlePrice
+	PromoHappyHour PromotionType = "HAPPY_HOUR"        // Diskon pada jam tertentu (semua produk atau satu produk)
+	PromoMinSpend  PromotionType = "MIN_SPEND"         // Diskon transaksi jika belanja minimal MinSpend
+	PromoCategory  PromotionType = "CATEGORY_DISCOUNT" // Diskon untuk semua produk dalam satu kategori
+)
+
+// Promotion adalah model untuk tabel 'promotions'
+// Aturan promo dievaluasi otomatis saat membuat transaksi penjualan (INCOME)
+type Promotion struct {
+	gorm.Model
+	UserID   uint          `gorm:"not null;index"`
+	Name     string        `gorm:"not null;size:255"`
+	Type     PromotionType `gorm:"not null;size:30"`
+	IsActive bool          `gorm:"not null;default:true"`
+
+	// Masa berlaku (opsional). StartTime/EndTime ("HH:MM") membatasi jam berlaku setiap hari,
+	// wajib untuk HAPPY_HOUR. Jika EndTime < StartTime, jam berlaku melewati tengah malam.
+	StartDate *time.Time `gorm:"type:date"`
+	EndDate   *time.Time `gorm:"type:date"`
+	StartTime string     `gorm:"size:5"`
+	EndTime   string     `gorm:"size:5"`
+
+	// Target promo
+	ProductID  *uint `gorm:"index"` // BUY_X_GET_Y, BUNDLE_PRICE (wajib), HAPPY_HOUR (opsional)
+	CategoryID *uint `gorm:"index"` // CATEGORY_DISCOUNT (wajib)
+
+	// Parameter promo
+	BuyQuantity   int          `gorm:"default:0"`
+	GetQuantity   int          `gorm:"default:0"`
+	BundlePrice   float64      `gorm:"type:decimal(20,2);default:0"`
+	MinSpend      float64      `gorm:"type:decimal(20,2);default:0"`
+	DiscountType  DiscountType `gorm:"size:20"` // HAPPY_HOUR, MIN_SPEND, CATEGORY_DISCOUNT
+	DiscountValue float64      `gorm:"type:decimal(20,2);default:0"`
+}
+
+// TransactionPromotion adalah model untuk tabel 'transaction_promotions'
+// Mencatat promo yang diterapkan pada sebuah transaksi (snapshot nama & jenis untuk laporan)
+type TransactionPromotion struct {
+	gorm.Model
+	TransactionID  uint          `gorm:"not null;index"`
+	PromotionID    uint          `gorm:"not null;index"`
+	Name           string        `gorm:"not null;size:255"`
+	Type           PromotionType `gorm:"not null;size:30"`
+	DiscountAmount float64       `gorm:"type:decimal(20,2);default:0"`
+}
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PurchaseOrderStatus mendefinisikan status siklus hidup pesanan pembelian (PO)
+type PurchaseOrderStatus string
+
+const (
+	PODraft             PurchaseOrderStatus = "DRAFT"              // Masih bisa diubah, belum dikirim ke supplier
+	POOrdered           PurchaseOrderStatus = "ORDERED"            // Sudah dipesan, menunggu barang datang
+	POPartiallyReceived PurchaseOrderStatus = "PARTIALLY_RECEIVED" // Sebagian barang sudah diterima
+	POReceived          PurchaseOrderStatus = "RECEIVED"           // Semua barang sudah diterima
+	POBilled            PurchaseOrderStatus = "BILLED"             // Tagihan supplier sudah dicatat sebagai utang
+	POCancelled         PurchaseOrderStatus = "CANCELLED"          // Dibatalkan sebelum ada barang diterima
+)
+
+// PurchaseOrder adalah model untuk tabel 'purchase_orders' (pesanan pembelian ke supplier)
+type PurchaseOrder struct {
+	gorm.Model
+	UserID       uint                `gorm:"not null;index"`
+	SupplierID   uint                `gorm:"not null;index"`
+	LocationID   uint                `gorm:"not null;index"` // Lokasi penerimaan barang
+	TaxRateID    *uint               `gorm:"index"`          // Tarif PPN default saat ditagih
+	Status       PurchaseOrderStatus `gorm:"not null;size:20;default:'DRAFT';index"`
+	OrderDate    time.Time           `gorm:"not null"`
+	ExpectedDate *time.Time          `gorm:"null"` // Perkiraan barang datang (opsional)
+	Notes        string
+	OrderedAt    *time.Time `gorm:"null"`
+	BilledAt     *time.Time `gorm:"null"`
+
+	// Transaksi EXPENSE (BELUM LUNAS) yang dibuat saat tagihan supplier diterima
+	BillTransactionID *uint `gorm:"index"`
+
+	// Relasi
+	Supplier Supplier            `gorm:"foreignKey:SupplierID"`
+	Items    []PurchaseOrderItem `gorm:"foreignKey:PurchaseOrderID"`
+	Receipts []GoodsReceipt      `gorm:"foreignKey:PurchaseOrderID"`
+}
+
+// PurchaseOrderItem adalah model untuk tabel 'purchase_order_items'.
+// Jumlah selalu dalam satuan dasar produk, UnitCost adalah harga beli per unit sebelum PPN.
+type PurchaseOrderItem struct {
+	gorm.Model
+	PurchaseOrderID  uint    `gorm:"not null;index"`
+	ProductID        uint    `gorm:"not null;index"`
+	ProductName      string  `gorm:"not null"`
+	Quantity         int     `gorm:"not null"`
+	UnitCost         float64 `gorm:"not null;type:decimal(20,2)"`
+	ReceivedQuantity int     `gorm:"not null;default:0"` // Total yang sudah diterima (tidak pernah melebihi Quantity)
+}
+
+// RemainingQuantity mengembalikan jumlah yang belum diterima
+func (i PurchaseOrderItem) RemainingQuantity() int {
+	return i.Quantity - i.ReceivedQuantity
+}
+
+// GoodsReceipt adalah model untuk tabel 'goods_receipts' (dokumen penerimaan barang dari PO)
+type GoodsReceipt struct {
+	gorm.Model
+	UserID          uint      `gorm:"not null;index"`
+	PurchaseOrderID uint      `gorm:"not null;index"`
+	LocationID      uint      `gorm:"not null;index"`
+	ReceiptDate     time.Time `gorm:"not null;index"`
+	Notes           string
+	TotalAmount     float64 `gorm:"type:decimal(20,2);default:0"` // Nilai barang diterima (harga PO, sebelum PPN)
+
+	// Relasi
+	Items []GoodsReceiptItem `gorm:"foreignKey:GoodsReceiptID"`
+}
+
+// GoodsReceiptItem adalah model untuk tabel 'goods_receipt_items'
+type GoodsReceiptItem struct {
+	gorm.Model
+	GoodsReceiptID      uint       `gorm:"not null;index"`
+	PurchaseOrderItemID uint       `gorm:"not null;index"`
+	ProductID           uint       `gorm:"not null;index"`
+	ProductName         string     `gorm:"not null"`
+	Quantity            int        `gorm:"not null"`
+	UnitCost            float64    `gorm:"type:decimal(20,2);default:0"` // Snapshot harga PO
+	LotNumber           string     `gorm:"size:100"`
+	ExpiryDate          *time.Time `gorm:"null"`
+}
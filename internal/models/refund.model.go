@@ -0,0 +1,33 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+// Refund adalah model untuk tabel 'refunds'
+// Setiap retur (sebagian) atas sebuah transaksi dicatat di sini sebagai jejak audit
+type Refund struct {
+	gorm.Model
+	TransactionID uint    `gorm:"not null;index"`
+	UserID        uint    `gorm:"not null;index"`
+	Amount        float64 `gorm:"not null;type:decimal(20,2)"` // Nilai total item yang diretur
+	// CashAmount adalah porsi yang benar-benar dikembalikan/diterima sebagai uang tunai.
+	// Sisanya hanya mengurangi piutang/utang yang belum lunas.
+	CashAmount float64 `gorm:"type:decimal(20,2);default:0"`
+	Reason     string
+	TaxAmount  float64 `gorm:"type:decimal(20,2);default:0"` // [BARU] Porsi pajak (PPN) dalam Amount
+
+	// Relasi
+	Items []RefundItem `gorm:"foreignKey:RefundID"`
+}
+
+// RefundItem adalah model untuk tabel 'refund_items'
+// Mencatat item transaksi mana saja yang diretur dan berapa banyak
+type RefundItem struct {
+	gorm.Model
+	RefundID          uint    `gorm:"not null;index"`
+	TransactionItemID uint    `gorm:"not null;index"`
+	Quantity          int     `gorm:"not null"`
+	Amount            float64 `gorm:"not null;type:decimal(20,2)"`
+	TaxAmount         float64 `gorm:"type:decimal(20,2);default:0"` // [BARU] Porsi pajak dalam Amount
+}
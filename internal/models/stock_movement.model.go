@@ -0,0 +1,34 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+// StockMovementReason mendefinisikan alasan perubahan stok
+type StockMovementReason string
+
+const (
+	MovementSale       StockMovementReason = "SALE"       // Penjualan (stok keluar)
+	MovementPurchase   StockMovementReason = "PURCHASE"   // Pembelian/restock (stok masuk)
+	MovementAdjustment StockMovementReason = "ADJUSTMENT" // Penyesuaian manual (cth: edit produk, stok awal)
+	MovementVoid       StockMovementReason = "VOID"       // Pembatalan transaksi
+	MovementRefund     StockMovementReason = "REFUND"     // Retur transaksi
+	MovementOpname     StockMovementReason = "OPNAME"     // Hasil stok opname
+	MovementWriteOff   StockMovementReason = "WRITE_OFF"  // Pemusnahan batch kedaluwarsa/rusak
+	MovementTransfer   StockMovementReason = "TRANSFER"   // Mutasi antar lokasi
+	MovementReceipt    StockMovementReason = "RECEIPT"    // [BARU] Penerimaan barang dari PO
+)
+
+// StockMovement adalah model untuk tabel 'stock_movements' (kartu stok)
+// Setiap perubahan Product.Stock dicatat di sini beserta saldo setelah perubahan
+type StockMovement struct {
+	gorm.Model
+	UserID        uint                `gorm:"not null;index"`
+	ProductID     uint                `gorm:"not null;index"`
+	Reason        StockMovementReason `gorm:"not null;size:20"`
+	Quantity      int                 `gorm:"not null"` // Perubahan stok (positif = masuk, negatif = keluar)
+	BalanceAfter  int                 `gorm:"not null"` // Stok setelah perubahan ini
+	TransactionID *uint               `gorm:"index"`    // Transaksi sumber (jika ada)
+	LocationID    *uint               `gorm:"index"`    // [BARU] Lokasi tempat stok berubah
+	Notes         string              `gorm:"size:255"`
+}
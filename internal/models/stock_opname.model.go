@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// StockOpnameStatus mendefinisikan status sesi stok opname
+type StockOpnameStatus string
+
+const (
+	OpnameOpen      StockOpnameStatus = "OPEN"      // Sedang menghitung
+	OpnameFinalized StockOpnameStatus = "FINALIZED" // Selisih sudah diposting ke stok & jurnal
+	OpnameCancelled StockOpnameStatus = "CANCELLED" // Dibatalkan tanpa perubahan stok
+)
+
+// StockOpname adalah model untuk tabel 'stock_opnames' (sesi hitung fisik persediaan)
+type StockOpname struct {
+	gorm.Model
+	UserID      uint              `gorm:"not null;index"`
+	LocationID  *uint             `gorm:"index"` // [BARU] Lokasi yang dihitung
+	Notes       string            `gorm:"size:255"`
+	Status      StockOpnameStatus `gorm:"not null;size:20;default:'OPEN'"`
+	FinalizedAt *time.Time
+
+	// Nilai selisih (harga beli) saat finalisasi. Beban selisih = ShrinkageAmount - SurplusAmount.
+	ShrinkageAmount float64 `gorm:"type:decimal(20,2);default:0"` // Selisih kurang (hilang/rusak)
+	SurplusAmount   float64 `gorm:"type:decimal(20,2);default:0"` // Selisih lebih
+
+	// Relasi
+	Counts []StockOpnameCount `gorm:"foreignKey:StockOpnameID"`
+	Items  []StockOpnameItem  `gorm:"foreignKey:StockOpnameID"` // Snapshot hasil saat finalisasi
+}
+
+// StockOpnameCount adalah model untuk tabel 'stock_opname_counts'
+// Satu entri hitungan; satu produk boleh dihitung di beberapa sesi (cth: per rak/gudang) dan dijumlahkan
+type StockOpnameCount struct {
+	gorm.Model
+	StockOpnameID uint   `gorm:"not null;index"`
+	ProductID     uint   `gorm:"not null;index"`
+	Quantity      int    `gorm:"not null"`
+	SessionLabel  string `gorm:"size:100"` // cth: "Rak A", "Gudang belakang"
+	// [BARU] Stok sistem di lokasi opname saat hitungan dimasukkan. Selisih dihitung terhadap nilai ini
+	// agar penjualan/pembelian antara hitung dan finalisasi tidak dianggap selisih. nil = entri lama.
+	SystemQuantity *int
+}
+
+// StockOpnameItem adalah model untuk tabel 'stock_opname_items'
+// Snapshot per produk saat finalisasi: stok sistem vs hasil hitung dan nilai selisihnya
+type StockOpnameItem struct {
+	gorm.Model
+	StockOpnameID   uint    `gorm:"not null;index"`
+	ProductID       uint    `gorm:"not null;index"`
+	ProductName     string  `gorm:"not null"`
+	SystemQuantity  int     `gorm:"not null"`
+	CountedQuantity int     `gorm:"not null"`
+	Variance        int     `gorm:"not null"`                     // Hitung - Sistem
+	UnitCost        float64 `gorm:"type:decimal(20,2);default:0"` // Harga beli saat finalisasi
+	VarianceValue   float64 `gorm:"type:decimal(20,2);default:0"` // Variance * UnitCost
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+// Supplier adalah model untuk tabel 'suppliers'
+// Pihak lawan untuk pembelian (EXPENSE) dan pesanan pembelian, terpisah dari pelanggan
+type Supplier struct {
+	gorm.Model
+	Name    string `gorm:"not null;size:255"`
+	Email   string `gorm:"size:255"` // Opsional
+	Phone   string `gorm:"size:50"`  // Opsional
+	Address string // Opsional
+
+	// Relasi: Setiap supplier dimiliki oleh satu User
+	UserID uint `gorm:"not null;index"`
+	User   User
+
+	// Pelanggan asal jika supplier ini dibuat dari migrasi data lama (pihak lawan EXPENSE)
+	LegacyCustomerID *uint `gorm:"index"`
+
+	// Relasi: Seorang Supplier 'has many' Transactions (EXPENSE)
+	Transactions []Transaction `gorm:"foreignKey:SupplierID"`
+}
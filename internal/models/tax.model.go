@@ -0,0 +1,15 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+// TaxRate adalah model untuk tabel 'tax_rates'
+// Tarif pajak (cth: PPN 11%) yang bisa dipilih per item saat membuat transaksi
+type TaxRate struct {
+	gorm.Model
+	UserID    uint    `gorm:"not null;index"`
+	Name      string  `gorm:"not null;size:100"`          // cth: "PPN 11%"
+	Rate      float64 `gorm:"not null;type:decimal(5,2)"` // Persentase, cth: 11.00
+	IsDefault bool    `gorm:"not null;default:false"`     // Tarif yang dipilih otomatis di form
+}
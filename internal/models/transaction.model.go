@@ -23,6 +23,23 @@ const (
 	BelumLunas PaymentStatusType = "BELUM LUNAS" // Transaksi belum dibayar (Utang/Piutang)
 )
 
+// [BARU] TransactionStatusType mendefinisikan status siklus hidup transaksi
+type TransactionStatusType string
+
+const (
+	StatusActive   TransactionStatusType = "ACTIVE"   // Transaksi normal
+	StatusVoid     TransactionStatusType = "VOID"     // Dibatalkan seluruhnya, stok sudah dikembalikan
+	StatusRefunded TransactionStatusType = "REFUNDED" // Sebagian/seluruh item sudah diretur
+)
+
+// [BARU] DiscountType mendefinisikan cara diskon dihitung
+type DiscountType string
+
+const (
+	DiscountPercent DiscountType = "PERCENT" // Persentase dari nilai sebelum diskon
+	DiscountNominal DiscountType = "NOMINAL" // Potongan nominal (rupiah) untuk seluruh baris/transaksi
+)
+
 // Transaction adalah model untuk tabel 'transactions'
 type Transaction struct {
 	gorm.Model
@@ -43,14 +60,68 @@ type Transaction struct {
 	CustomerID *uint     `gorm:"index"`                 // Foreign key ke Customer (sudah di-index)
 	Customer   *Customer `gorm:"foreignKey:CustomerID"` // Relasi GORM (nullable)
 
+	// [BARU] Supplier untuk transaksi EXPENSE (pengganti Customer sebagai pihak lawan utang)
+	SupplierID *uint     `gorm:"index"`
+	Supplier   *Supplier `gorm:"foreignKey:SupplierID"`
+
 	// --- [BARU UNTUK FITUR KATEGORI] ---
 	CategoryID *uint     `gorm:"index"`                 // Foreign key ke Category (nullable, di-index)
 	Category   *Category `gorm:"foreignKey:CategoryID"` // Relasi GORM (nullable)
 	// --- [AKHIR BARU] ---
 
+	// [BARU] Lokasi (gudang/outlet) tempat transaksi terjadi
+	LocationID *uint     `gorm:"index"`
+	Location   *Location `gorm:"foreignKey:LocationID"`
+
+	// [BARU] PO yang ditagih oleh transaksi EXPENSE ini. Stoknya sudah masuk saat penerimaan barang.
+	PurchaseOrderID *uint `gorm:"index"`
+
+	// [BARU] Penjualan kredit ini tetap dibuat meskipun pelanggan melewati batas kredit / sedang ditahan
+	CreditOverride bool `gorm:"default:false"`
+
+	// --- [BARU] Poin loyalitas ---
+	LoyaltyPointsEarned   int     `gorm:"default:0"`                    // Poin yang diperoleh pelanggan dari transaksi ini
+	LoyaltyPointsRedeemed int     `gorm:"default:0"`                    // Poin yang ditukar di transaksi ini
+	LoyaltyDiscountAmount float64 `gorm:"type:decimal(20,2);default:0"` // Potongan dari penukaran poin
+	// --- [AKHIR BARU] ---
+
+	// --- [BARU UNTUK FITUR VOID & RETUR] ---
+	// Baris asli tidak pernah dihapus agar jejak audit tetap utuh
+	Status         TransactionStatusType `gorm:"not null;default:'ACTIVE';index"`
+	RefundedAmount float64               `gorm:"type:decimal(20,2);default:0"` // Total nilai yang sudah diretur
+	VoidReason     string
+	VoidedAt       *time.Time `gorm:"null"`
+	// --- [AKHIR BARU] ---
+
+	// [BARU] Total yang sudah dibayar (cicilan). Selalu sama dengan SUM(payments.amount)
+	PaidAmount float64 `gorm:"type:decimal(20,2);default:0"`
+
+	// [BARU] Pajak (PPN) yang sudah termasuk di TotalAmount, dan porsi pajak yang sudah diretur
+	TaxAmount         float64 `gorm:"type:decimal(20,2);default:0"`
+	RefundedTaxAmount float64 `gorm:"type:decimal(20,2);default:0"`
+
+	// --- [BARU] Diskon ---
+	// GrossAmount adalah total harga sebelum semua diskon (sum UnitPrice * Quantity).
+	// DiscountType/DiscountValue/DiscountAmount adalah diskon level transaksi (di luar diskon per item).
+	GrossAmount    float64      `gorm:"type:decimal(20,2);default:0"`
+	DiscountType   DiscountType `gorm:"size:20"`
+	DiscountValue  float64      `gorm:"type:decimal(20,2);default:0"`
+	DiscountAmount float64      `gorm:"type:decimal(20,2);default:0"`
+	// [BARU] PromotionAmount adalah total potongan dari promo otomatis (lihat Promotions)
+	PromotionAmount float64 `gorm:"type:decimal(20,2);default:0"`
+	// --- [AKHIR BARU] ---
+
 	// Relasi: Sebuah Transaksi memiliki banyak Item
-	Items []TransactionItem `gorm:"foreignKey:TransactionID"`
-	User  User              `gorm:"foreignKey:UserID"`
+	Items      []TransactionItem      `gorm:"foreignKey:TransactionID"`
+	Promotions []TransactionPromotion `gorm:"foreignKey:TransactionID"` // [BARU] Promo yang diterapkan
+	Refunds    []Refund               `gorm:"foreignKey:TransactionID"` // [BARU] Riwayat retur
+	Payments   []Payment              `gorm:"foreignKey:TransactionID"` // [BARU] Riwayat pembayaran/cicilan
+	User       User                   `gorm:"foreignKey:UserID"`
+}
+
+// OutstandingAmount menghitung sisa tagihan (utang/piutang) yang belum dibayar
+func (t Transaction) OutstandingAmount() float64 {
+	return t.TotalAmount - t.RefundedAmount - t.PaidAmount
 }
 
 // TransactionItem adalah model untuk tabel 'transaction_items'
@@ -62,13 +133,65 @@ type TransactionItem struct {
 	TransactionID uint   `gorm:"not null;index"`
 	ProductID     *uint  `gorm:"index"`    // [OPTIMASI 4] Index di ProductID baik untuk laporan performa
 	ProductName   string `gorm:"not null"` // Nama item (bisa produk / biaya lain)
-	Quantity      int    `gorm:"not null"`
+	Quantity      int    `gorm:"not null"` // [DIUBAH] Jumlah dalam satuan dasar produk
 	// [DIUBAH] decimal(10,2) -> decimal(20,2)
-	UnitPrice float64 `gorm:"not null;type:decimal(20,2)"` // Harga satuan (Harga Jual)
+	UnitPrice float64 `gorm:"not null;type:decimal(20,2)"` // Harga satuan (Harga Jual). [DIUBAH] Per satuan transaksi (UnitName)
+
+	// --- [BARU] Satuan transaksi ---
+	// Jumlah yang diinput (UnitQuantity, boleh desimal jika satuannya mengizinkan) dalam satuan UnitName.
+	// Quantity = UnitQuantity x UnitFactor. UnitName kosong = satuan dasar.
+	UnitName     string  `gorm:"size:50"`
+	UnitFactor   int     `gorm:"not null;default:1"`
+	UnitQuantity float64 `gorm:"type:decimal(20,3);default:0"`
+	// --- [AKHIR BARU] ---
 	// [DIUBAH] decimal(10,2) -> decimal(20,2)
 	PurchasePrice float64 `gorm:"type:decimal(20,2);default:0"` // [BARU] Harga modal saat item ini terjual
+	// [BARU] Jumlah unit yang sudah diretur (tidak pernah melebihi Quantity)
+	RefundedQuantity int `gorm:"not null;default:0"`
+
+	// --- [BARU] Pajak per item ---
+	// TaxableAmount adalah DPP (Dasar Pengenaan Pajak) baris ini, TaxAmount adalah pajaknya.
+	// Nilai baris = TaxableAmount + TaxAmount. Untuk harga termasuk pajak, UnitPrice * Quantity = nilai baris.
+	TaxRateID     *uint   `gorm:"index"`
+	TaxRate       float64 `gorm:"type:decimal(5,2);default:0"` // Snapshot persentase saat transaksi dibuat
+	TaxInclusive  bool    `gorm:"not null;default:false"`
+	TaxableAmount float64 `gorm:"type:decimal(20,2);default:0"`
+	TaxAmount     float64 `gorm:"type:decimal(20,2);default:0"`
+	// --- [AKHIR BARU] ---
+
+	// --- [BARU] Diskon per item ---
+	// DiscountAmount adalah diskon item ini, TransactionDiscountAmount adalah bagian diskon transaksi
+	// yang dialokasikan ke baris ini. Keduanya dalam basis harga yang diinput (sebelum pajak dipisahkan).
+	DiscountType              DiscountType `gorm:"size:20"`
+	DiscountValue             float64      `gorm:"type:decimal(20,2);default:0"`
+	DiscountAmount            float64      `gorm:"type:decimal(20,2);default:0"`
+	TransactionDiscountAmount float64      `gorm:"type:decimal(20,2);default:0"`
+	// [BARU] PromotionDiscountAmount adalah potongan promo level item (beli X gratis Y, paket, dll.)
+	PromotionDiscountAmount float64 `gorm:"type:decimal(20,2);default:0"`
+	// --- [AKHIR BARU] ---
+
+	// [BARU] Lot & kedaluwarsa barang yang dibeli (hanya untuk restock produk dengan pelacakan batch)
+	LotNumber  string     `gorm:"size:100"`
+	ExpiryDate *time.Time `gorm:"type:date"`
 
 	// Relasi
 	Transaction Transaction
-	Product     *Product // Relasi ke produk (nullable)
+	Product     *Product                   // Relasi ke produk (nullable)
+	Components  []TransactionItemComponent // [BARU] Bahan yang terpakai (produk komposit)
+}
+
+// [BARU] LineGross adalah nilai baris sebelum diskon (harga per satuan transaksi x jumlah dalam satuan itu)
+func (item TransactionItem) LineGross() float64 {
+	if item.UnitQuantity == 0 {
+		return item.UnitPrice * float64(item.Quantity) // Data lama sebelum ada satuan
+	}
+	return item.UnitPrice * item.UnitQuantity
+}
+
+// [BARU] BaseUnitPrice adalah harga per satuan dasar (dipakai promo yang dihitung per unit stok)
+func (item TransactionItem) BaseUnitPrice() float64 {
+	if item.UnitFactor <= 1 {
+		return item.UnitPrice
+	}
+	return item.UnitPrice / float64(item.UnitFactor)
 }
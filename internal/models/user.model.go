@@ -14,6 +14,9 @@ type User struct {
 	PasswordHash string `gorm:"not null"`
 	FullName     string `gorm:"size:255"`
 
+	// [BARU] Metode penilaian persediaan untuk HPP (AVERAGE / FIFO)
+	CostingMethod CostingMethod `gorm:"size:20;not null;default:'AVERAGE'"`
+
 	// Relasi: Seorang User 'has many' Products
 	Products []Product `gorm:"foreignKey:UserID"` // <-- BARU
 }
@@ -0,0 +1,416 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/danishyusrah/go_bisnis/internal/database"
+	"github.com/danishyusrah/go_bisnis/internal/dto"
+	"github.com/danishyusrah/go_bisnis/internal/models"
+	"gorm.io/gorm"
+)
+
+// AccountingService adalah struct untuk layanan akuntansi (bagan akun & jurnal)
+type AccountingService struct{}
+
+// NewAccountingService membuat instance AccountingService baru
+func NewAccountingService() *AccountingService {
+	return &AccountingService{}
+}
+
+// journalLineInput adalah satu baris jurnal yang akunnya dirujuk lewat kode akun
+type journalLineInput struct {
+	Code   string
+	Debit  float64
+	Credit float64
+}
+
+// ensureDefaultAccounts memastikan akun sistem milik user sudah ada,
+// lalu mengembalikan peta kode akun -> ID akun
+func ensureDefaultAccounts(tx *gorm.DB, userID uint) (map[string]uint, error) {
+	var existing []models.Account
+	if err := tx.Where("user_id = ?", userID).Find(&existing).Error; err != nil {
+		return nil, err
+	}
+
+	codes := make(map[string]uint)
+	for _, acc := range existing {
+		codes[acc.Code] = acc.ID
+	}
+
+	for _, def := range models.DefaultAccounts {
+		if _, ok := codes[def.Code]; ok {
+			continue
+		}
+		acc := def
+		acc.UserID = userID
+		acc.IsSystem = true
+		if err := tx.Create(&acc).Error; err != nil {
+			return nil, fmt.Errorf("gagal membuat akun sistem %s", def.Code)
+		}
+		codes[acc.Code] = acc.ID
+	}
+	return codes, nil
+}
+
+// postJournal menyimpan satu jurnal seimbang. Baris bernilai nol diabaikan,
+// dan jurnal tanpa baris sama sekali tidak disimpan.
+func postJournal(tx *gorm.DB, userID uint, entryDate time.Time, description string, source models.JournalSourceType, transactionID *uint, lines []journalLineInput) error {
+	codes, err := ensureDefaultAccounts(tx, userID)
+	if err != nil {
+		return err
+	}
+
+	var totalDebit, totalCredit float64
+	var journalLines []models.JournalLine
+	for _, line := range lines {
+		if math.Abs(line.Debit) < amountEpsilon && math.Abs(line.Credit) < amountEpsilon {
+			continue
+		}
+		// Nilai negatif dipindahkan ke sisi sebaliknya agar semua baris positif
+		debit, credit := line.Debit, line.Credit
+		if debit < 0 {
+			credit, debit = credit-debit, 0
+		}
+		if credit < 0 {
+			debit, credit = debit-credit, 0
+		}
+		accountID, ok := codes[line.Code]
+		if !ok {
+			return fmt.Errorf("akun dengan kode %s tidak ditemukan", line.Code)
+		}
+		journalLines = append(journalLines, models.JournalLine{AccountID: accountID, Debit: debit, Credit: credit})
+		totalDebit += debit
+		totalCredit += credit
+	}
+
+	if len(journalLines) == 0 {
+		return nil
+	}
+	if math.Abs(totalDebit-totalCredit) > amountEpsilon {
+		return fmt.Errorf("jurnal tidak seimbang (debit %.2f, kredit %.2f)", totalDebit, totalCredit)
+	}
+
+	entry := models.JournalEntry{
+		UserID:        userID,
+		EntryDate:     entryDate,
+		Description:   description,
+		SourceType:    source,
+		TransactionID: transactionID,
+		Lines:         journalLines,
+	}
+	if err := tx.Create(&entry).Error; err != nil {
+		return errors.New("gagal menyimpan jurnal")
+	}
+	return nil
+}
+
+// counterAccountCode menentukan akun lawan (Kas atau Piutang/Utang) berdasarkan status pembayaran
+func counterAccountCode(transaction *models.Transaction) string {
+	if transaction.PaymentStatus == models.Lunas {
+		return models.AccountCodeCash
+	}
+	if transaction.Type == models.Expense {
+		return models.AccountCodePayable
+	}
+	return models.AccountCodeReceivable
+}
+
+// postTransactionJournal membuat jurnal otomatis untuk transaksi yang baru dibuat
+func postTransactionJournal(tx *gorm.DB, transaction *models.Transaction) error {
+	var lines []journalLineInput
+	counter := counterAccountCode(transaction)
+
+	switch transaction.Type {
+	case models.Capital:
+		// Setoran modal: Kas bertambah, Modal Pemilik bertambah
+		lines = append(lines,
+			journalLineInput{Code: models.AccountCodeCash, Debit: transaction.TotalAmount},
+			journalLineInput{Code: models.AccountCodeCapital, Credit: transaction.TotalAmount},
+		)
+
+	case models.Income:
+		// Penjualan: Kas/Piutang (D) - Pendapatan (K), lalu HPP (D) - Persediaan (K)
+		var cogs float64
+		for _, item := range transaction.Items {
+			if item.ProductID != nil {
+				cogs += item.PurchasePrice * float64(item.Quantity)
+			}
+		}
+		// [DIUBAH] PPN yang dipungut dicatat sebagai kewajiban (PPN Keluaran), bukan pendapatan
+		lines = append(lines,
+			journalLineInput{Code: counter, Debit: transaction.TotalAmount},
+			journalLineInput{Code: models.AccountCodeSales, Credit: transaction.TotalAmount - transaction.TaxAmount},
+			journalLineInput{Code: models.AccountCodeOutputVAT, Credit: transaction.TaxAmount},
+			journalLineInput{Code: models.AccountCodeCOGS, Debit: cogs},
+			journalLineInput{Code: models.AccountCodeInventory, Credit: cogs},
+		)
+
+	case models.Expense:
+		// Pembelian produk menambah Persediaan, selain itu dicatat sebagai Beban Operasional.
+		// [DIUBAH] Nilai yang dicatat adalah DPP; PPN yang dibayar menjadi PPN Masukan.
+		var inventory, expense float64
+		for _, item := range transaction.Items {
+			amount := item.TaxableAmount
+			if item.ProductID != nil {
+				inventory += amount
+			} else {
+				expense += amount
+			}
+		}
+		// [BARU] Tagihan PO melunasi akun Barang Diterima Belum Ditagih yang dicatat saat penerimaan
+		inventoryCode := models.AccountCodeInventory
+		if transaction.PurchaseOrderID != nil {
+			inventoryCode = models.AccountCodeGoodsReceived
+		}
+		lines = append(lines,
+			journalLineInput{Code: inventoryCode, Debit: inventory},
+			journalLineInput{Code: models.AccountCodeOperatingExpense, Debit: expense},
+			journalLineInput{Code: models.AccountCodeInputVAT, Debit: transaction.TaxAmount},
+			journalLineInput{Code: counter, Credit: transaction.TotalAmount},
+		)
+	}
+
+	description := fmt.Sprintf("Transaksi %s #%d", transaction.Type, transaction.ID)
+	return postJournal(tx, transaction.UserID, transaction.CreatedAt, description, models.JournalSourceTransaction, &transaction.ID, lines)
+}
+
+// postPaymentJournal membuat jurnal pelunasan/cicilan (nilai negatif = uang dikembalikan)
+func postPaymentJournal(tx *gorm.DB, transaction *models.Transaction, payment *models.Payment) error {
+	var lines []journalLineInput
+	switch transaction.Type {
+	case models.Income:
+		lines = []journalLineInput{
+			{Code: models.AccountCodeCash, Debit: payment.Amount},
+			{Code: models.AccountCodeReceivable, Credit: payment.Amount},
+		}
+	case models.Expense:
+		lines = []journalLineInput{
+			{Code: models.AccountCodePayable, Debit: payment.Amount},
+			{Code: models.AccountCodeCash, Credit: payment.Amount},
+		}
+	default:
+		return nil
+	}
+
+	description := fmt.Sprintf("Pembayaran transaksi #%d", transaction.ID)
+	return postJournal(tx, transaction.UserID, payment.PaymentDate, description, models.JournalSourcePayment, &transaction.ID, lines)
+}
+
+// postRefundJournal membuat jurnal retur. Seluruh nilai retur mengurangi Piutang/Utang;
+// porsi tunainya dicatat terpisah sebagai pembayaran negatif.
+func postRefundJournal(tx *gorm.DB, transaction *models.Transaction, refund *models.Refund, itemsByID map[uint]*models.TransactionItem) error {
+	var productCost, productAmount float64
+	for _, ri := range refund.Items {
+		item, ok := itemsByID[ri.TransactionItemID]
+		if !ok || item.ProductID == nil {
+			continue
+		}
+		productCost += item.PurchasePrice * float64(ri.Quantity)
+		productAmount += ri.Amount - ri.TaxAmount // [DIUBAH] DPP saja
+	}
+
+	var lines []journalLineInput
+	switch transaction.Type {
+	case models.Income:
+		lines = []journalLineInput{
+			{Code: models.AccountCodeSales, Debit: refund.Amount - refund.TaxAmount},
+			{Code: models.AccountCodeOutputVAT, Debit: refund.TaxAmount},
+			{Code: models.AccountCodeReceivable, Credit: refund.Amount},
+			{Code: models.AccountCodeInventory, Debit: productCost},
+			{Code: models.AccountCodeCOGS, Credit: productCost},
+		}
+	case models.Expense:
+		lines = []journalLineInput{
+			{Code: models.AccountCodePayable, Debit: refund.Amount},
+			{Code: models.AccountCodeInventory, Credit: productAmount},
+			{Code: models.AccountCodeOperatingExpense, Credit: refund.Amount - refund.TaxAmount - productAmount},
+			{Code: models.AccountCodeInputVAT, Credit: refund.TaxAmount},
+		}
+	default:
+		return nil
+	}
+
+	description := fmt.Sprintf("Retur transaksi #%d", transaction.ID)
+	return postJournal(tx, transaction.UserID, refund.CreatedAt, description, models.JournalSourceRefund, &transaction.ID, lines)
+}
+
+// [BARU] postStockAdjustmentJournal mencatat perubahan nilai persediaan di luar transaksi (stok awal,
+// edit stok/harga beli produk). Nilai positif menambah Persediaan, negatif menguranginya.
+func postStockAdjustmentJournal(tx *gorm.DB, product *models.Product, amount float64, entryDate time.Time, description string) error {
+	amount = roundAmount(amount)
+	if math.Abs(amount) < amountEpsilon {
+		return nil
+	}
+	if err := ensurePeriodOpen(tx, product.UserID, entryDate); err != nil {
+		return err
+	}
+	lines := []journalLineInput{
+		{Code: models.AccountCodeInventory, Debit: amount},
+		{Code: models.AccountCodeStockAdjustment, Credit: amount},
+	}
+	return postJournal(tx, product.UserID, entryDate, fmt.Sprintf("%s: %s", description, product.Name), models.JournalSourceStockAdjust, nil, lines)
+}
+
+// [BARU] accountBalanceAsOf menghitung saldo (debit - kredit) satu akun sistem per 'asOf'
+func accountBalanceAsOf(userID uint, code string, asOf time.Time) (float64, error) {
+	var result sumResult
+	err := database.DB.Model(&models.JournalLine{}).
+		Select("COALESCE(SUM(journal_lines.debit - journal_lines.credit), 0) as total").
+		Joins("JOIN journal_entries ON journal_entries.id = journal_lines.journal_entry_id").
+		Joins("JOIN accounts ON accounts.id = journal_lines.account_id").
+		Where("journal_entries.user_id = ? AND accounts.code = ? AND journal_entries.entry_date <= ? AND journal_entries.deleted_at IS NULL", userID, code, asOf).
+		Scan(&result).Error
+	return result.Total, err
+}
+
+// postVoidJournal membalik seluruh jurnal yang pernah diposting untuk sebuah transaksi
+func postVoidJournal(tx *gorm.DB, transaction *models.Transaction, voidedAt time.Time) error {
+	type accountSum struct {
+		Code   string
+		Debit  float64
+		Credit float64
+	}
+	var sums []accountSum
+	err := tx.Model(&models.JournalLine{}).
+		Select("accounts.code, SUM(journal_lines.debit) as debit, SUM(journal_lines.credit) as credit").
+		Joins("JOIN journal_entries ON journal_entries.id = journal_lines.journal_entry_id").
+		Joins("JOIN accounts ON accounts.id = journal_lines.account_id").
+		Where("journal_entries.transaction_id = ? AND journal_entries.deleted_at IS NULL", transaction.ID).
+		Group("accounts.code").
+		Scan(&sums).Error
+	if err != nil {
+		return err
+	}
+
+	var lines []journalLineInput
+	for _, sum := range sums {
+		lines = append(lines, journalLineInput{Code: sum.Code, Debit: sum.Credit, Credit: sum.Debit})
+	}
+
+	description := fmt.Sprintf("Pembatalan transaksi #%d", transaction.ID)
+	return postJournal(tx, transaction.UserID, voidedAt, description, models.JournalSourceVoid, &transaction.ID, lines)
+}
+
+// GetAccounts mengambil bagan akun milik user (akun sistem dibuat otomatis jika belum ada)
+func (s *AccountingService) GetAccounts(userID uint) ([]models.Account, error) {
+	db := database.DB
+
+	if _, err := ensureDefaultAccounts(db, userID); err != nil {
+		return nil, err
+	}
+
+	var accounts []models.Account
+	if err := db.Where("user_id = ?", userID).Order("code asc").Find(&accounts).Error; err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
+
+// CreateAccount menambahkan akun kustom ke bagan akun user
+func (s *AccountingService) CreateAccount(input dto.CreateAccountInput, userID uint) (models.Account, error) {
+	db := database.DB
+
+	if _, err := ensureDefaultAccounts(db, userID); err != nil {
+		return models.Account{}, err
+	}
+
+	var existing models.Account
+	if err := db.Where("user_id = ? AND code = ?", userID, input.Code).First(&existing).Error; err == nil {
+		return models.Account{}, errors.New("kode akun sudah digunakan")
+	}
+
+	account := models.Account{
+		UserID: userID,
+		Code:   input.Code,
+		Name:   input.Name,
+		Type:   input.Type,
+	}
+	if err := db.Create(&account).Error; err != nil {
+		return models.Account{}, err
+	}
+	return account, nil
+}
+
+// GetJournalEntries mengambil jurnal umum dalam rentang waktu
+func (s *AccountingService) GetJournalEntries(userID uint, startTime time.Time, endTime time.Time) ([]models.JournalEntry, error) {
+	db := database.DB
+	var entries []models.JournalEntry
+
+	err := db.Preload("Lines.Account").
+		Where("user_id = ? AND entry_date BETWEEN ? AND ?", userID, startTime, endTime).
+		Order("entry_date asc, id asc").
+		Find(&entries).Error
+	if err != nil {
+		log.Printf("Error fetching journal entries: %v", err)
+		return nil, err
+	}
+	return entries, nil
+}
+
+// GetTrialBalance menghitung neraca saldo (saldo setiap akun) per tanggal 'asOf'
+func (s *AccountingService) GetTrialBalance(userID uint, asOf time.Time) (dto.TrialBalanceReport, error) {
+	db := database.DB
+	report := dto.TrialBalanceReport{
+		AsOf: asOf.Format("2006-01-02"),
+		Rows: []dto.TrialBalanceRow{},
+	}
+
+	accounts, err := s.GetAccounts(userID)
+	if err != nil {
+		return report, err
+	}
+
+	type accountTotal struct {
+		AccountID uint
+		Debit     float64
+		Credit    float64
+	}
+	var totals []accountTotal
+	err = db.Model(&models.JournalLine{}).
+		Select("journal_lines.account_id, SUM(journal_lines.debit) as debit, SUM(journal_lines.credit) as credit").
+		Joins("JOIN journal_entries ON journal_entries.id = journal_lines.journal_entry_id").
+		Where("journal_entries.user_id = ? AND journal_entries.entry_date <= ? AND journal_entries.deleted_at IS NULL", userID, asOf).
+		Group("journal_lines.account_id").
+		Scan(&totals).Error
+	if err != nil {
+		log.Printf("Error calculating trial balance: %v", err)
+		return report, err
+	}
+
+	totalsByAccount := make(map[uint]accountTotal)
+	for _, t := range totals {
+		totalsByAccount[t.AccountID] = t
+	}
+
+	for _, acc := range accounts {
+		t := totalsByAccount[acc.ID]
+		row := dto.TrialBalanceRow{
+			AccountID: acc.ID,
+			Code:      acc.Code,
+			Name:      acc.Name,
+			Type:      acc.Type,
+		}
+		// Saldo ditampilkan di sisi normal akun
+		net := t.Debit - t.Credit
+		if net >= 0 {
+			row.Debit = net
+		} else {
+			row.Credit = -net
+		}
+		if acc.IsDebitNormal() {
+			row.Balance = net
+		} else {
+			row.Balance = -net
+		}
+		report.TotalDebit += row.Debit
+		report.TotalCredit += row.Credit
+		report.Rows = append(report.Rows, row)
+	}
+	report.IsBalanced = math.Abs(report.TotalDebit-report.TotalCredit) <= amountEpsilon
+
+	return report, nil
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danishyusrah/go_bisnis/internal/models"
+)
+
+func TestPostJournalBalancing(t *testing.T) {
+	db := newTestDB(t, &models.Account{}, &models.JournalEntry{}, &models.JournalLine{})
+	entryDate := time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC)
+	saleID := uint(11)
+
+	// Penjualan kredit dengan diskon yang dicatat sebagai pendapatan negatif: baris negatif pindah sisi,
+	// baris nol dilewati, dan akun sistem dibuat saat jurnal pertama
+	err := postJournal(db, 1, entryDate, "Penjualan", models.JournalSourceTransaction, &saleID, []journalLineInput{
+		{Code: models.AccountCodeReceivable, Debit: 111000},
+		{Code: models.AccountCodeSales, Credit: 120000},
+		{Code: models.AccountCodeSales, Credit: -20000},
+		{Code: models.AccountCodeOutputVAT, Credit: 11000},
+		{Code: models.AccountCodeLoyaltyExpense, Debit: 0},
+	})
+	if err != nil {
+		t.Fatalf("postJournal() error = %v", err)
+	}
+
+	var entry models.JournalEntry
+	if err := db.Preload("Lines").First(&entry).Error; err != nil {
+		t.Fatal(err)
+	}
+	if entry.TransactionID == nil || *entry.TransactionID != saleID || entry.SourceType != models.JournalSourceTransaction {
+		t.Errorf("jurnal = (%v, %s), want transaksi %d bersumber TRANSACTION", entry.TransactionID, entry.SourceType, saleID)
+	}
+	if len(entry.Lines) != 4 {
+		t.Fatalf("jumlah baris = %d, want 4 (baris nol dilewati)", len(entry.Lines))
+	}
+	var totalDebit, totalCredit float64
+	for _, line := range entry.Lines {
+		if line.Debit < 0 || line.Credit < 0 {
+			t.Errorf("baris akun %d bernilai negatif: debit %.2f, kredit %.2f", line.AccountID, line.Debit, line.Credit)
+		}
+		totalDebit += line.Debit
+		totalCredit += line.Credit
+	}
+	assertAmount(t, "total debit", totalDebit, 131000)
+	assertAmount(t, "total kredit", totalCredit, 131000)
+
+	var accountCount int64
+	if err := db.Model(&models.Account{}).Where("user_id = ?", 1).Count(&accountCount).Error; err != nil {
+		t.Fatal(err)
+	}
+	if int(accountCount) != len(models.DefaultAccounts) {
+		t.Errorf("jumlah akun = %d, want %d", accountCount, len(models.DefaultAccounts))
+	}
+
+	// Jurnal tidak seimbang ditolak tanpa menyimpan apa pun
+	err = postJournal(db, 1, entryDate, "Salah ketik", models.JournalSourceTransaction, nil, []journalLineInput{
+		{Code: models.AccountCodeCash, Debit: 50000},
+		{Code: models.AccountCodeSales, Credit: 5000},
+	})
+	if err == nil || !strings.Contains(err.Error(), "tidak seimbang") {
+		t.Fatalf("postJournal() error = %v, want jurnal tidak seimbang", err)
+	}
+
+	// Selisih pembulatan di bawah satu sen masih diterima
+	err = postJournal(db, 1, entryDate, "Pembulatan", models.JournalSourceTransaction, nil, []journalLineInput{
+		{Code: models.AccountCodeCash, Debit: 33333.33},
+		{Code: models.AccountCodeSales, Credit: 33333.334},
+	})
+	if err != nil {
+		t.Fatalf("postJournal() pembulatan error = %v", err)
+	}
+
+	// Kode akun yang tidak ada ditolak
+	err = postJournal(db, 1, entryDate, "Akun asing", models.JournalSourceTransaction, nil, []journalLineInput{
+		{Code: "9999", Debit: 1000},
+		{Code: models.AccountCodeCash, Credit: 1000},
+	})
+	if err == nil || !strings.Contains(err.Error(), "tidak ditemukan") {
+		t.Fatalf("postJournal() error = %v, want akun tidak ditemukan", err)
+	}
+
+	var entryCount int64
+	if err := db.Model(&models.JournalEntry{}).Count(&entryCount).Error; err != nil {
+		t.Fatal(err)
+	}
+	if entryCount != 2 {
+		t.Errorf("jumlah jurnal = %d, want 2", entryCount)
+	}
+}
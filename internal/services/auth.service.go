@@ -38,6 +38,8 @@ func RegisterUser(input dto.RegisterInput) (models.User, error) { // <-- DIUBAH
 		Email:        input.Email,
 		PasswordHash: hashedPassword,
 		FullName:     input.FullName,
+		// [BARU] Default penilaian persediaan
+		CostingMethod: models.CostingAverage,
 	}
 
 	// Simpan ke database
@@ -0,0 +1,280 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/danishyusrah/go_bisnis/internal/database"
+	"github.com/danishyusrah/go_bisnis/internal/dto"
+	"github.com/danishyusrah/go_bisnis/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// --- [BARU] Pelacakan batch/lot & kedaluwarsa (FEFO: first-expired, first-out) ---
+
+// fefoOrder mengurutkan batch dari tanggal kedaluwarsa terdekat; batch tanpa tanggal dipakai terakhir
+const fefoOrder = "expiry_date IS NULL, expiry_date asc, received_at asc, id asc"
+
+// parseExpiryDate mengubah tanggal kedaluwarsa (YYYY-MM-DD, opsional) menjadi *time.Time
+func parseExpiryDate(value *string) (*time.Time, error) {
+	if value == nil || *value == "" {
+		return nil, nil
+	}
+	parsed, err := time.ParseInLocation("2006-01-02", *value, time.Local)
+	if err != nil {
+		return nil, errors.New("format tanggal kedaluwarsa tidak valid, gunakan YYYY-MM-DD")
+	}
+	return &parsed, nil
+}
+
+// receiveProductBatch membuat batch baru untuk barang masuk
+func receiveProductBatch(tx *gorm.DB, product *models.Product, quantity int, lotNumber string, expiryDate *time.Time, transactionID *uint) error {
+	if quantity <= 0 {
+		return nil
+	}
+	batch := models.ProductBatch{
+		UserID:            product.UserID,
+		ProductID:         product.ID,
+		LotNumber:         lotNumber,
+		ExpiryDate:        expiryDate,
+		Quantity:          quantity,
+		RemainingQuantity: quantity,
+		TransactionID:     transactionID,
+		ReceivedAt:        time.Now(),
+	}
+	if err := tx.Create(&batch).Error; err != nil {
+		return errors.New("gagal mencatat batch produk")
+	}
+	return nil
+}
+
+// consumeProductBatches mengurangi sisa batch secara FEFO. Jika preferTransactionID diisi
+// (pembatalan/retur pembelian), batch dari transaksi tersebut dipakai lebih dulu.
+// Mengembalikan catatan pemakaian per batch (belum disimpan).
+func consumeProductBatches(tx *gorm.DB, product *models.Product, quantity int, preferTransactionID *uint) ([]models.BatchConsumption, error) {
+	var batches []models.ProductBatch
+	if preferTransactionID != nil {
+		var sourceBatches []models.ProductBatch
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("product_id = ? AND transaction_id = ? AND remaining_quantity > 0", product.ID, *preferTransactionID).
+			Order(fefoOrder).Find(&sourceBatches).Error; err != nil {
+			return nil, err
+		}
+		batches = append(batches, sourceBatches...)
+	}
+	var fefoBatches []models.ProductBatch
+	query := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("product_id = ? AND remaining_quantity > 0", product.ID)
+	if preferTransactionID != nil {
+		query = query.Where("(transaction_id IS NULL OR transaction_id <> ?)", *preferTransactionID)
+	}
+	if err := query.Order(fefoOrder).Find(&fefoBatches).Error; err != nil {
+		return nil, err
+	}
+	batches = append(batches, fefoBatches...)
+
+	var consumptions []models.BatchConsumption
+	remaining := quantity
+	for i := range batches {
+		if remaining == 0 {
+			break
+		}
+		take := batches[i].RemainingQuantity
+		if take > remaining {
+			take = remaining
+		}
+		if err := tx.Model(&batches[i]).Update("remaining_quantity", batches[i].RemainingQuantity-take).Error; err != nil {
+			return nil, errors.New("gagal memperbarui sisa batch")
+		}
+		consumptions = append(consumptions, models.BatchConsumption{
+			UserID:         product.UserID,
+			ProductBatchID: batches[i].ID,
+			ProductID:      product.ID,
+			Quantity:       take,
+		})
+		remaining -= take
+	}
+	// Sisa yang tidak tercakup batch (data sebelum pelacakan aktif) diabaikan
+	return consumptions, nil
+}
+
+// restoreBatchConsumptions mengembalikan unit penjualan yang di-void/diretur ke batch asalnya.
+// Mengembalikan jumlah unit yang berhasil dikembalikan.
+func restoreBatchConsumptions(tx *gorm.DB, product *models.Product, quantity int, transactionID uint) (int, error) {
+	var consumptions []models.BatchConsumption
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("product_id = ? AND transaction_id = ? AND quantity > 0", product.ID, transactionID).
+		Order("id desc").Find(&consumptions).Error; err != nil {
+		return 0, err
+	}
+
+	restored := 0
+	for i := range consumptions {
+		if restored == quantity {
+			break
+		}
+		take := consumptions[i].Quantity
+		if take > quantity-restored {
+			take = quantity - restored
+		}
+		if err := tx.Model(&models.ProductBatch{}).Where("id = ?", consumptions[i].ProductBatchID).
+			Update("remaining_quantity", gorm.Expr("remaining_quantity + ?", take)).Error; err != nil {
+			return 0, errors.New("gagal mengembalikan sisa batch")
+		}
+		if err := tx.Model(&consumptions[i]).Update("quantity", consumptions[i].Quantity-take).Error; err != nil {
+			return 0, err
+		}
+		restored += take
+	}
+	return restored, nil
+}
+
+// syncProductBatches menyesuaikan batch setelah perubahan stok di luar penjualan/restock
+// (void, retur, stok opname, edit produk). Tidak melakukan apa pun jika produk tidak melacak batch.
+func syncProductBatches(tx *gorm.DB, product *models.Product, delta int, transactionID *uint) error {
+	if !product.TrackLots || delta == 0 {
+		return nil
+	}
+	if delta < 0 {
+		_, err := consumeProductBatches(tx, product, -delta, transactionID)
+		return err
+	}
+
+	restored := 0
+	if transactionID != nil {
+		var err error
+		if restored, err = restoreBatchConsumptions(tx, product, delta, *transactionID); err != nil {
+			return err
+		}
+	}
+	// Barang masuk tanpa informasi lot menjadi batch tanpa tanggal kedaluwarsa
+	return receiveProductBatch(tx, product, delta-restored, "", nil, transactionID)
+}
+
+// seedProductBatches mengganti batch produk dengan satu batch tanpa lot sebesar stok saat ini
+// (dipakai saat pelacakan batch baru diaktifkan)
+func seedProductBatches(tx *gorm.DB, product *models.Product) error {
+	if err := tx.Where("product_id = ?", product.ID).Delete(&models.ProductBatch{}).Error; err != nil {
+		return err
+	}
+	return receiveProductBatch(tx, product, product.Stock, "", nil, nil)
+}
+
+// BatchService adalah struct untuk layanan batch/lot produk
+type BatchService struct{}
+
+// NewBatchService membuat instance BatchService baru
+func NewBatchService() *BatchService {
+	return &BatchService{}
+}
+
+// GetProductBatches mengambil batch yang masih tersisa untuk satu produk (urut FEFO)
+func (s *BatchService) GetProductBatches(productID uint, userID uint) ([]dto.ProductBatchResponse, error) {
+	if _, err := NewProductService().GetProductByID(productID, userID); err != nil {
+		return nil, err
+	}
+
+	var batches []models.ProductBatch
+	if err := database.DB.Where("product_id = ? AND remaining_quantity > 0", productID).
+		Order(fefoOrder).Find(&batches).Error; err != nil {
+		return nil, err
+	}
+
+	responses := []dto.ProductBatchResponse{}
+	for _, batch := range batches {
+		var expiry *string
+		if batch.ExpiryDate != nil {
+			formatted := batch.ExpiryDate.Format("2006-01-02")
+			expiry = &formatted
+		}
+		responses = append(responses, dto.ProductBatchResponse{
+			ID:                batch.ID,
+			ProductID:         batch.ProductID,
+			LotNumber:         batch.LotNumber,
+			ExpiryDate:        expiry,
+			Quantity:          batch.Quantity,
+			RemainingQuantity: batch.RemainingQuantity,
+			TransactionID:     batch.TransactionID,
+			ReceivedAt:        batch.ReceivedAt.Format(time.RFC3339),
+		})
+	}
+	return responses, nil
+}
+
+// WriteOffBatch memusnahkan sebagian/seluruh sisa batch (kedaluwarsa/rusak).
+// Stok berkurang (kartu stok WRITE_OFF) dan nilainya dijurnal sebagai Beban Selisih Persediaan.
+func (s *BatchService) WriteOffBatch(batchID uint, userID uint, input dto.WriteOffBatchInput) (models.BatchWriteOff, error) {
+	var writeOff models.BatchWriteOff
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var batch models.ProductBatch
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&batch, batchID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("batch tidak ditemukan")
+			}
+			return err
+		}
+		if batch.UserID != userID {
+			return errors.New("akses ditolak: Anda bukan pemilik batch ini")
+		}
+		if batch.RemainingQuantity <= 0 {
+			return errors.New("batch ini sudah habis")
+		}
+		quantity := input.Quantity
+		if quantity == 0 {
+			quantity = batch.RemainingQuantity
+		}
+		if quantity > batch.RemainingQuantity {
+			return fmt.Errorf("jumlah pemusnahan melebihi sisa batch (sisa: %d)", batch.RemainingQuantity)
+		}
+
+		now := time.Now()
+		if err := ensurePeriodOpen(tx, userID, now); err != nil {
+			return err
+		}
+
+		// [BARU] Stok yang dimusnahkan diambil dari lokasi pilihan (default lokasi utama)
+		locationID, err := resolveLocation(tx, userID, input.LocationID)
+		if err != nil {
+			return err
+		}
+		product, err := lockOwnedProduct(tx, batch.ProductID, userID)
+		if err != nil {
+			return err
+		}
+		notes := "Pemusnahan batch: " + input.Reason
+		if batch.LotNumber != "" {
+			notes = fmt.Sprintf("Pemusnahan lot %s: %s", batch.LotNumber, input.Reason)
+		}
+		unitCost, err := moveProductStock(tx, &product, locationID, -quantity, nil, models.MovementWriteOff, nil, now, notes)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&batch).Update("remaining_quantity", batch.RemainingQuantity-quantity).Error; err != nil {
+			return errors.New("gagal memperbarui sisa batch")
+		}
+
+		writeOff = models.BatchWriteOff{
+			UserID:         userID,
+			ProductBatchID: batch.ID,
+			ProductID:      batch.ProductID,
+			LocationID:     &locationID, // [BARU]
+			Quantity:       quantity,
+			UnitCost:       unitCost,
+			Amount:         roundAmount(float64(quantity) * unitCost),
+			Reason:         input.Reason,
+		}
+		if err := tx.Create(&writeOff).Error; err != nil {
+			return errors.New("gagal menyimpan data pemusnahan")
+		}
+
+		return postJournal(tx, userID, now, notes, models.JournalSourceWriteOff, nil, []journalLineInput{
+			{Code: models.AccountCodeShrinkage, Debit: writeOff.Amount},
+			{Code: models.AccountCodeInventory, Credit: writeOff.Amount},
+		})
+	})
+	if err != nil {
+		return models.BatchWriteOff{}, err
+	}
+	return writeOff, nil
+}
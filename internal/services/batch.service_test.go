@@ -0,0 +1,94 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/danishyusrah/go_bisnis/internal/dto"
+	"github.com/danishyusrah/go_bisnis/internal/models"
+)
+
+func TestBatchesConsumedFEFOAndRestored(t *testing.T) {
+	db, userID := useServiceTestDB(t)
+	products := NewProductService()
+	transactions := NewTransactionService()
+	date := func(value string) *string { return &value }
+
+	susu, err := products.CreateProduct(dto.CreateProductInput{Name: "Susu", SKU: "SUSU", SellingPrice: 9000, TrackLots: true}, userID)
+	if err != nil {
+		t.Fatalf("CreateProduct() error = %v", err)
+	}
+	restock := func(quantity float64, lot string, expiry *string) models.Transaction {
+		t.Helper()
+		purchase, err := transactions.CreateTransaction(dto.CreateTransactionInput{
+			Type: models.Expense,
+			Items: []dto.CreateTransactionItemInput{{
+				ProductID: &susu.ID, ProductName: "Susu", Quantity: quantity, UnitPrice: 6000, LotNumber: lot, ExpiryDate: expiry,
+			}},
+		}, userID)
+		if err != nil {
+			t.Fatalf("CreateTransaction() restock %s error = %v", lot, err)
+		}
+		return purchase
+	}
+	// remaining mengembalikan sisa batch per nomor lot ("" = batch tanpa lot)
+	remaining := func() map[string]int {
+		t.Helper()
+		var batches []models.ProductBatch
+		if err := db.Where("product_id = ?", susu.ID).Find(&batches).Error; err != nil {
+			t.Fatal(err)
+		}
+		result := make(map[string]int)
+		for _, batch := range batches {
+			result[batch.LotNumber] += batch.RemainingQuantity
+		}
+		return result
+	}
+	assertRemaining := func(step string, want map[string]int) {
+		t.Helper()
+		got := remaining()
+		for lot, quantity := range want {
+			if got[lot] != quantity {
+				t.Errorf("%s: sisa lot %q = %d, want %d (semua: %v)", step, lot, got[lot], quantity, got)
+			}
+		}
+	}
+
+	// Lot yang diterima belakangan tetapi kedaluwarsa lebih dulu harus keluar lebih dulu
+	late := restock(5, "L-JUNI", date("2027-06-30"))
+	restock(4, "L-JANUARI", date("2027-01-31"))
+	restock(3, "", nil)
+
+	sale, err := transactions.CreateTransaction(dto.CreateTransactionInput{
+		Type:  models.Income,
+		Items: []dto.CreateTransactionItemInput{{ProductID: &susu.ID, ProductName: "Susu", Quantity: 6, UnitPrice: 9000}},
+	}, userID)
+	if err != nil {
+		t.Fatalf("CreateTransaction() penjualan error = %v", err)
+	}
+	assertRemaining("setelah penjualan", map[string]int{"L-JANUARI": 0, "L-JUNI": 3, "": 3})
+
+	// Retur mengembalikan unit ke batch yang terakhir dipakai, void mengembalikan sisanya ke batch asal
+	if _, err := transactions.RefundTransaction(sale.ID, userID, dto.RefundTransactionInput{
+		Items: []dto.RefundItemInput{{TransactionItemID: sale.Items[0].ID, Quantity: 1}},
+	}); err != nil {
+		t.Fatalf("RefundTransaction() error = %v", err)
+	}
+	assertRemaining("setelah retur", map[string]int{"L-JANUARI": 0, "L-JUNI": 4, "": 3})
+
+	if _, err := transactions.VoidTransaction(sale.ID, userID, dto.VoidTransactionInput{Reason: "Batal"}); err != nil {
+		t.Fatalf("VoidTransaction() penjualan error = %v", err)
+	}
+	assertRemaining("setelah void penjualan", map[string]int{"L-JANUARI": 4, "L-JUNI": 5, "": 3})
+
+	// Void pembelian mengurangi batch dari pembelian itu sendiri, bukan batch FEFO
+	if _, err := transactions.VoidTransaction(late.ID, userID, dto.VoidTransactionInput{Reason: "Salah kirim"}); err != nil {
+		t.Fatalf("VoidTransaction() pembelian error = %v", err)
+	}
+	assertRemaining("setelah void pembelian", map[string]int{"L-JANUARI": 4, "L-JUNI": 0, "": 3})
+
+	location, err := defaultLocation(db, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertStock(t, db, susu.ID, location.ID, 7, 7)
+}
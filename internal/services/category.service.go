@@ -27,10 +27,17 @@ func (s *CategoryService) CreateCategory(input dto.CreateCategoryInput, userID u
 		return models.Category{}, errors.New("kategori dengan nama dan tipe yang sama sudah ada")
 	}
 
+	// [BARU] Default bagian arus kas adalah OPERATING
+	cashFlowSection := input.CashFlowSection
+	if cashFlowSection == "" {
+		cashFlowSection = models.CashFlowOperating
+	}
+
 	newCategory := models.Category{
-		Name:   input.Name,
-		Type:   input.Type,
-		UserID: userID,
+		Name:            input.Name,
+		Type:            input.Type,
+		UserID:          userID,
+		CashFlowSection: cashFlowSection,
 	}
 
 	if err := db.Create(&newCategory).Error; err != nil {
@@ -86,6 +93,9 @@ func (s *CategoryService) UpdateCategory(categoryID uint, input dto.UpdateCatego
 	// Update data
 	category.Name = input.Name
 	category.Type = input.Type
+	if input.CashFlowSection != "" {
+		category.CashFlowSection = input.CashFlowSection
+	}
 
 	if err := db.Save(&category).Error; err != nil {
 		return models.Category{}, err
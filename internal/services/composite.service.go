@@ -0,0 +1,245 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/danishyusrah/go_bisnis/internal/database"
+	"github.com/danishyusrah/go_bisnis/internal/dto"
+	"github.com/danishyusrah/go_bisnis/internal/models"
+	"gorm.io/gorm"
+)
+
+// --- [BARU] Produk komposit (resep / bill of materials) ---
+
+// sellCompositeStock mengurangi stok bahan produk komposit yang terjual sebanyak 'quantity' unit.
+// HPP per unit komposit = jumlah (biaya bahan x jumlah bahan per unit). Snapshot bahan dikembalikan
+// agar void/retur bisa mengembalikan stok bahan yang sama.
+func sellCompositeStock(tx *gorm.DB, product *models.Product, userID uint, locationID uint, quantity int) (float64, []models.TransactionItemComponent, []models.StockMovement, []models.BatchConsumption, error) {
+	var recipe []models.ProductComponent
+	if err := tx.Where("product_id = ?", product.ID).Order("id asc").Find(&recipe).Error; err != nil {
+		return 0, nil, nil, nil, err
+	}
+	if len(recipe) == 0 {
+		return 0, nil, nil, nil, fmt.Errorf("produk komposit %s belum memiliki resep", product.Name)
+	}
+
+	var unitCost float64
+	var itemComponents []models.TransactionItemComponent
+	var movements []models.StockMovement
+	var consumptions []models.BatchConsumption
+	for _, line := range recipe {
+		component, err := lockOwnedProduct(tx, line.ComponentID, userID)
+		if err != nil {
+			return 0, nil, nil, nil, err
+		}
+		componentCost, movement, batchUsed, err := sellProductStock(tx, &component, userID, locationID, line.Quantity*quantity, "Bahan: "+product.Name)
+		if err != nil {
+			return 0, nil, nil, nil, err
+		}
+		unitCost += componentCost * float64(line.Quantity)
+		itemComponents = append(itemComponents, models.TransactionItemComponent{
+			ComponentID: component.ID,
+			Quantity:    line.Quantity,
+			UnitCost:    componentCost,
+		})
+		movements = append(movements, movement)
+		consumptions = append(consumptions, batchUsed...)
+	}
+	return unitCost, itemComponents, movements, consumptions, nil
+}
+
+// compositeBuildableCounts menghitung berapa unit setiap produk komposit milik user yang masih bisa
+// dibuat dari stok bahannya (bahan paling sedikit menentukan). locationID nil = stok seluruh lokasi.
+func compositeBuildableCounts(db *gorm.DB, userID uint, locationID *uint) (map[uint]int, error) {
+	type buildableRow struct {
+		ProductID uint
+		Buildable int
+	}
+	var rows []buildableRow
+
+	stockColumn := "components.stock"
+	query := db.Table("product_components").
+		Joins("JOIN products components ON components.id = product_components.component_id")
+	if locationID != nil {
+		stockColumn = "COALESCE(product_stocks.quantity, 0)"
+		query = query.Joins("LEFT JOIN product_stocks ON product_stocks.product_id = product_components.component_id AND product_stocks.location_id = ? AND product_stocks.deleted_at IS NULL", *locationID)
+	}
+	err := query.
+		Select("product_components.product_id, MIN(FLOOR("+stockColumn+" / product_components.quantity)) as buildable").
+		Where("product_components.deleted_at IS NULL AND components.user_id = ?", userID).
+		Group("product_components.product_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uint]int)
+	for _, row := range rows {
+		counts[row.ProductID] = row.Buildable
+	}
+	return counts, nil
+}
+
+// fillCompositeStock mengisi Stock produk komposit (termasuk varian) dengan jumlah yang masih bisa
+// dibuat. Hanya untuk ditampilkan; produk yang sudah diisi tidak boleh disimpan kembali.
+func fillCompositeStock(db *gorm.DB, userID uint, products []models.Product) error {
+	var composites []*models.Product
+	for i := range products {
+		if products[i].IsComposite {
+			composites = append(composites, &products[i])
+		}
+		for j := range products[i].Variants {
+			if products[i].Variants[j].IsComposite {
+				composites = append(composites, &products[i].Variants[j])
+			}
+		}
+	}
+	if len(composites) == 0 {
+		return nil
+	}
+
+	counts, err := compositeBuildableCounts(db, userID, nil)
+	if err != nil {
+		return err
+	}
+	for _, product := range composites {
+		product.Stock = counts[product.ID]
+	}
+	return nil
+}
+
+// CompositeService adalah struct untuk layanan resep produk komposit
+type CompositeService struct{}
+
+// NewCompositeService membuat instance CompositeService baru
+func NewCompositeService() *CompositeService {
+	return &CompositeService{}
+}
+
+// GetProductComponents mengambil resep produk beserta perkiraan HPP per unit dan jumlah
+// yang masih bisa dibuat (di locationID jika diisi)
+func (s *CompositeService) GetProductComponents(productID uint, userID uint, locationID *uint) (dto.ProductRecipeResponse, error) {
+	db := database.DB
+	product, err := NewProductService().GetProductByID(productID, userID)
+	if err != nil {
+		return dto.ProductRecipeResponse{}, err
+	}
+	if locationID != nil {
+		if _, err := findOwnedLocation(db, *locationID, userID); err != nil {
+			return dto.ProductRecipeResponse{}, err
+		}
+	}
+
+	var recipe []models.ProductComponent
+	if err := db.Preload("Component").Where("product_id = ?", product.ID).Order("id asc").Find(&recipe).Error; err != nil {
+		return dto.ProductRecipeResponse{}, err
+	}
+
+	response := dto.ProductRecipeResponse{
+		ProductID:   product.ID,
+		ProductName: product.Name,
+		IsComposite: product.IsComposite,
+		Components:  []dto.ProductComponentResponse{},
+	}
+	for _, line := range recipe {
+		response.UnitCost += line.Component.PurchasePrice * float64(line.Quantity)
+		response.Components = append(response.Components, dto.ProductComponentResponse{
+			ComponentID:    line.ComponentID,
+			ComponentName:  line.Component.Name,
+			Quantity:       line.Quantity,
+			ComponentStock: line.Component.Stock,
+			UnitCost:       line.Component.PurchasePrice,
+		})
+	}
+	response.UnitCost = roundAmount(response.UnitCost)
+
+	if product.IsComposite {
+		counts, err := compositeBuildableCounts(db, userID, locationID)
+		if err != nil {
+			return dto.ProductRecipeResponse{}, err
+		}
+		response.Buildable = counts[product.ID]
+	}
+	return response, nil
+}
+
+// SetProductComponents mengganti seluruh resep produk. Resep kosong mengembalikan produk
+// menjadi produk biasa (stok 0). Satu tingkat saja: bahan tidak boleh berupa produk komposit.
+func (s *CompositeService) SetProductComponents(productID uint, userID uint, input dto.SetProductComponentsInput) (dto.ProductRecipeResponse, error) {
+	if _, err := NewProductService().GetProductByID(productID, userID); err != nil {
+		return dto.ProductRecipeResponse{}, err
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		product, err := lockOwnedProduct(tx, productID, userID)
+		if err != nil {
+			return err
+		}
+		hasVariants, err := productHasVariants(tx, product.ID)
+		if err != nil {
+			return err
+		}
+		if hasVariants {
+			return errors.New("produk induk varian tidak dapat dijadikan komposit, atur resep pada variannya")
+		}
+		if len(input.Components) > 0 && !product.IsComposite {
+			if product.Stock != 0 {
+				return errors.New("stok produk harus 0 sebelum dijadikan komposit")
+			}
+			var usedAsComponent int64
+			if err := tx.Model(&models.ProductComponent{}).Where("component_id = ?", product.ID).Count(&usedAsComponent).Error; err != nil {
+				return err
+			}
+			if usedAsComponent > 0 {
+				return errors.New("produk ini dipakai sebagai bahan produk komposit lain, tidak dapat dijadikan komposit")
+			}
+		}
+
+		recipe := []models.ProductComponent{}
+		seen := make(map[uint]bool)
+		for _, line := range input.Components {
+			if line.ComponentID == product.ID {
+				return errors.New("produk tidak dapat menjadi bahan dirinya sendiri")
+			}
+			if seen[line.ComponentID] {
+				return fmt.Errorf("bahan ID %d tercantum lebih dari sekali", line.ComponentID)
+			}
+			seen[line.ComponentID] = true
+
+			var component models.Product
+			if err := tx.Where("id = ? AND user_id = ?", line.ComponentID, userID).First(&component).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return fmt.Errorf("bahan ID %d tidak ditemukan", line.ComponentID)
+				}
+				return err
+			}
+			if component.IsComposite {
+				return fmt.Errorf("bahan %s adalah produk komposit, gunakan bahan dasarnya", component.Name)
+			}
+			componentHasVariants, err := productHasVariants(tx, component.ID)
+			if err != nil {
+				return err
+			}
+			if componentHasVariants {
+				return fmt.Errorf("bahan %s memiliki varian, pilih variannya", component.Name)
+			}
+			recipe = append(recipe, models.ProductComponent{ProductID: product.ID, ComponentID: component.ID, Quantity: line.Quantity})
+		}
+
+		if err := tx.Where("product_id = ?", product.ID).Delete(&models.ProductComponent{}).Error; err != nil {
+			return err
+		}
+		if len(recipe) > 0 {
+			if err := tx.Create(&recipe).Error; err != nil {
+				return errors.New("gagal menyimpan resep produk")
+			}
+		}
+		return tx.Model(&product).Update("is_composite", len(recipe) > 0).Error
+	})
+	if err != nil {
+		return dto.ProductRecipeResponse{}, err
+	}
+
+	return s.GetProductComponents(productID, userID, nil)
+}
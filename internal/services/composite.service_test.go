@@ -0,0 +1,99 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/danishyusrah/go_bisnis/internal/dto"
+	"github.com/danishyusrah/go_bisnis/internal/models"
+)
+
+func TestCompositeSaleDeductsComponents(t *testing.T) {
+	db, userID := useServiceTestDB(t)
+	products := NewProductService()
+	composites := NewCompositeService()
+	transactions := NewTransactionService()
+
+	kopi, err := products.CreateProduct(dto.CreateProductInput{Name: "Kopi", SKU: "KOPI", PurchasePrice: 2000, SellingPrice: 3000, Stock: 10}, userID)
+	if err != nil {
+		t.Fatalf("CreateProduct() kopi error = %v", err)
+	}
+	susu, err := products.CreateProduct(dto.CreateProductInput{Name: "Susu", SKU: "SUSU", PurchasePrice: 1500, SellingPrice: 2500, Stock: 7}, userID)
+	if err != nil {
+		t.Fatalf("CreateProduct() susu error = %v", err)
+	}
+	kopiSusu, err := products.CreateProduct(dto.CreateProductInput{Name: "Kopi Susu", SKU: "KOPSU", SellingPrice: 12000}, userID)
+	if err != nil {
+		t.Fatalf("CreateProduct() kopi susu error = %v", err)
+	}
+	location, err := defaultLocation(db, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Satu gelas = 1 kopi + 2 susu; susu membatasi jumlah yang bisa dibuat
+	recipe, err := composites.SetProductComponents(kopiSusu.ID, userID, dto.SetProductComponentsInput{
+		Components: []dto.ProductComponentInput{{ComponentID: kopi.ID, Quantity: 1}, {ComponentID: susu.ID, Quantity: 2}},
+	})
+	if err != nil {
+		t.Fatalf("SetProductComponents() error = %v", err)
+	}
+	assertAmount(t, "perkiraan HPP", recipe.UnitCost, 5000)
+	if recipe.Buildable != 3 {
+		t.Errorf("bisa dibuat = %d, want 3", recipe.Buildable)
+	}
+
+	sell := func(quantity float64) (models.Transaction, error) {
+		return transactions.CreateTransaction(dto.CreateTransactionInput{
+			Type:  models.Income,
+			Items: []dto.CreateTransactionItemInput{{ProductID: &kopiSusu.ID, ProductName: "Kopi Susu", Quantity: quantity, UnitPrice: 12000}},
+		}, userID)
+	}
+	sale, err := sell(2)
+	if err != nil {
+		t.Fatalf("CreateTransaction() error = %v", err)
+	}
+	assertStock(t, db, kopi.ID, location.ID, 8, 8)
+	assertStock(t, db, susu.ID, location.ID, 3, 3)
+	assertAmount(t, "HPP per unit item", sale.Items[0].PurchasePrice, 5000)
+	assertAmount(t, "HPP", accountBalances(t, db, userID)[models.AccountCodeCOGS], 10000)
+
+	// Bahan yang kurang membatalkan seluruh penjualan, termasuk bahan yang stoknya cukup
+	if _, err := sell(2); err == nil || !strings.Contains(err.Error(), "Susu") {
+		t.Fatalf("CreateTransaction() error = %v, want stok susu tidak cukup", err)
+	}
+	assertStock(t, db, kopi.ID, location.ID, 8, 8)
+
+	// Resep berubah setelah penjualan; retur & void tetap mengembalikan bahan sesuai resep saat terjual
+	if _, err := composites.SetProductComponents(kopiSusu.ID, userID, dto.SetProductComponentsInput{
+		Components: []dto.ProductComponentInput{{ComponentID: kopi.ID, Quantity: 1}, {ComponentID: susu.ID, Quantity: 1}},
+	}); err != nil {
+		t.Fatalf("SetProductComponents() ubah resep error = %v", err)
+	}
+	if _, err := transactions.RefundTransaction(sale.ID, userID, dto.RefundTransactionInput{
+		Items: []dto.RefundItemInput{{TransactionItemID: sale.Items[0].ID, Quantity: 1}},
+	}); err != nil {
+		t.Fatalf("RefundTransaction() error = %v", err)
+	}
+	assertStock(t, db, kopi.ID, location.ID, 9, 9)
+	assertStock(t, db, susu.ID, location.ID, 5, 5)
+
+	if _, err := transactions.VoidTransaction(sale.ID, userID, dto.VoidTransactionInput{Reason: "Batal"}); err != nil {
+		t.Fatalf("VoidTransaction() error = %v", err)
+	}
+	assertStock(t, db, kopi.ID, location.ID, 10, 10)
+	assertStock(t, db, susu.ID, location.ID, 7, 7)
+
+	balances := accountBalances(t, db, userID)
+	assertAmount(t, "HPP setelah void", balances[models.AccountCodeCOGS], 0)
+	assertAmount(t, "persediaan setelah void", balances[models.AccountCodeInventory], 10*2000+7*1500)
+
+	// Produk komposit tidak menyimpan stok sendiri
+	var saved models.Product
+	if err := db.First(&saved, kopiSusu.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if saved.Stock != 0 {
+		t.Errorf("stok tersimpan produk komposit = %d, want 0", saved.Stock)
+	}
+}
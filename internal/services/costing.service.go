@@ -0,0 +1,278 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/danishyusrah/go_bisnis/internal/database"
+	"github.com/danishyusrah/go_bisnis/internal/dto"
+	"github.com/danishyusrah/go_bisnis/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// --- [BARU] Penilaian persediaan (HPP): rata-rata tertimbang bergerak & FIFO ---
+//
+// Product.PurchasePrice selalu menyimpan biaya per unit persediaan saat ini:
+//   - AVERAGE: rata-rata tertimbang, diperbarui setiap ada barang masuk.
+//   - FIFO: rata-rata sisa lapisan biaya, sehingga Stok * PurchasePrice = nilai lapisan.
+// Dengan begitu stok opname tetap bisa menilai persediaan dari Product.PurchasePrice.
+
+// userCostingMethod mengambil metode penilaian persediaan milik user (default AVERAGE)
+func userCostingMethod(tx *gorm.DB, userID uint) (models.CostingMethod, error) {
+	var user models.User
+	if err := tx.Select("id", "costing_method").First(&user, userID).Error; err != nil {
+		return "", err
+	}
+	if user.CostingMethod == "" {
+		return models.CostingAverage, nil
+	}
+	return user.CostingMethod, nil
+}
+
+// setProductCost menyimpan biaya per unit baru produk
+func setProductCost(tx *gorm.DB, product *models.Product, unitCost float64) error {
+	if err := tx.Model(product).Update("purchase_price", roundAmount(unitCost)).Error; err != nil {
+		return errors.New("gagal memperbarui harga modal produk")
+	}
+	return nil
+}
+
+// refreshFIFOCost menyamakan Product.PurchasePrice dengan rata-rata sisa lapisan biaya.
+// Jika lapisan habis, biaya terakhir dipertahankan.
+func refreshFIFOCost(tx *gorm.DB, product *models.Product) error {
+	var result struct {
+		Value    float64
+		Quantity int
+	}
+	if err := tx.Model(&models.CostLayer{}).
+		Select("COALESCE(SUM(remaining_quantity * unit_cost), 0) as value, COALESCE(SUM(remaining_quantity), 0) as quantity").
+		Where("product_id = ? AND remaining_quantity > 0", product.ID).
+		Scan(&result).Error; err != nil {
+		return err
+	}
+	if result.Quantity <= 0 {
+		return nil
+	}
+	return setProductCost(tx, product, result.Value/float64(result.Quantity))
+}
+
+// seedCostLayers mengganti seluruh lapisan biaya produk dengan satu lapisan sebesar stok saat ini
+// pada Product.PurchasePrice (dipakai saat beralih ke FIFO dan untuk stok awal produk baru)
+func seedCostLayers(tx *gorm.DB, product *models.Product) error {
+	if err := tx.Where("product_id = ?", product.ID).Delete(&models.CostLayer{}).Error; err != nil {
+		return err
+	}
+	if product.Stock <= 0 {
+		return nil
+	}
+	layer := models.CostLayer{
+		UserID:            product.UserID,
+		ProductID:         product.ID,
+		UnitCost:          product.PurchasePrice,
+		Quantity:          product.Stock,
+		RemainingQuantity: product.Stock,
+		ReceivedAt:        time.Now(),
+	}
+	return tx.Create(&layer).Error
+}
+
+// receiveInventoryCost mencatat biaya barang masuk. product.Stock harus berisi stok SEBELUM barang masuk.
+// [DIUBAH] receivedAt adalah tanggal barang masuk (tanggal transaksi), dipakai sebagai urutan lapisan FIFO.
+func receiveInventoryCost(tx *gorm.DB, product *models.Product, quantity int, unitCost float64, transactionID *uint, receivedAt time.Time) error {
+	if quantity <= 0 {
+		return nil
+	}
+	method, err := userCostingMethod(tx, product.UserID)
+	if err != nil {
+		return err
+	}
+
+	if method == models.CostingFIFO {
+		layer := models.CostLayer{
+			UserID:            product.UserID,
+			ProductID:         product.ID,
+			TransactionID:     transactionID,
+			UnitCost:          roundAmount(unitCost),
+			Quantity:          quantity,
+			RemainingQuantity: quantity,
+			ReceivedAt:        receivedAt,
+		}
+		if err := tx.Create(&layer).Error; err != nil {
+			return errors.New("gagal mencatat lapisan biaya")
+		}
+		return refreshFIFOCost(tx, product)
+	}
+
+	// Rata-rata tertimbang: (stok lama * biaya lama + jumlah masuk * biaya masuk) / stok baru
+	prior := product.Stock
+	if prior < 0 {
+		prior = 0
+	}
+	average := (float64(prior)*product.PurchasePrice + float64(quantity)*unitCost) / float64(prior+quantity)
+	return setProductCost(tx, product, average)
+}
+
+// issueInventoryCost menghitung biaya per unit barang keluar. product.Stock harus berisi stok SEBELUM barang keluar.
+// Untuk pembatalan/retur pembelian, sourceTransactionID & sourceUnitCost diisi agar barang keluar
+// pada biaya pembelian asalnya (FIFO: lapisan transaksi tersebut dipakai lebih dulu).
+func issueInventoryCost(tx *gorm.DB, product *models.Product, quantity int, sourceTransactionID *uint, sourceUnitCost *float64) (float64, error) {
+	if quantity <= 0 {
+		return product.PurchasePrice, nil
+	}
+	method, err := userCostingMethod(tx, product.UserID)
+	if err != nil {
+		return 0, err
+	}
+
+	if method != models.CostingFIFO {
+		if sourceUnitCost == nil {
+			return product.PurchasePrice, nil
+		}
+		// Keluarkan pada biaya pembelian asal, sisa persediaan dirata-rata ulang
+		if remaining := product.Stock - quantity; remaining > 0 {
+			average := (float64(product.Stock)*product.PurchasePrice - float64(quantity)**sourceUnitCost) / float64(remaining)
+			if average < 0 {
+				average = 0
+			}
+			if err := setProductCost(tx, product, average); err != nil {
+				return 0, err
+			}
+		}
+		return *sourceUnitCost, nil
+	}
+
+	var layers []models.CostLayer
+	if sourceTransactionID != nil {
+		var sourceLayers []models.CostLayer
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("product_id = ? AND transaction_id = ? AND remaining_quantity > 0", product.ID, *sourceTransactionID).
+			Order("id asc").Find(&sourceLayers).Error; err != nil {
+			return 0, err
+		}
+		layers = append(layers, sourceLayers...)
+	}
+	var fifoLayers []models.CostLayer
+	query := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("product_id = ? AND remaining_quantity > 0", product.ID)
+	if sourceTransactionID != nil {
+		query = query.Where("(transaction_id IS NULL OR transaction_id <> ?)", *sourceTransactionID)
+	}
+	if err := query.Order("received_at asc, id asc").Find(&fifoLayers).Error; err != nil {
+		return 0, err
+	}
+	layers = append(layers, fifoLayers...)
+
+	remaining := quantity
+	var totalCost float64
+	for i := range layers {
+		if remaining == 0 {
+			break
+		}
+		take := layers[i].RemainingQuantity
+		if take > remaining {
+			take = remaining
+		}
+		totalCost += float64(take) * layers[i].UnitCost
+		remaining -= take
+		if err := tx.Model(&layers[i]).Update("remaining_quantity", layers[i].RemainingQuantity-take).Error; err != nil {
+			return 0, errors.New("gagal memperbarui lapisan biaya")
+		}
+	}
+	// Stok tanpa lapisan (data sebelum FIFO aktif) dinilai dengan biaya saat ini
+	if remaining > 0 {
+		totalCost += float64(remaining) * product.PurchasePrice
+	}
+
+	unitCost := roundAmount(totalCost / float64(quantity))
+	if err := refreshFIFOCost(tx, product); err != nil {
+		return 0, err
+	}
+	return unitCost, nil
+}
+
+// itemInventoryCost mengembalikan biaya per unit item transaksi untuk membalik perubahan stoknya:
+// penjualan memakai HPP yang tercatat, pembelian memakai DPP per unit
+func itemInventoryCost(txType models.TransactionType, item models.TransactionItem) float64 {
+	if txType == models.Income {
+		return item.PurchasePrice
+	}
+	if item.Quantity == 0 {
+		return 0
+	}
+	return item.TaxableAmount / float64(item.Quantity)
+}
+
+// CostingService adalah struct untuk layanan pengaturan metode penilaian persediaan
+type CostingService struct{}
+
+// NewCostingService membuat instance CostingService baru
+func NewCostingService() *CostingService {
+	return &CostingService{}
+}
+
+// GetCostingMethod mengambil metode penilaian persediaan user
+func (s *CostingService) GetCostingMethod(userID uint) (models.CostingMethod, error) {
+	return userCostingMethod(database.DB, userID)
+}
+
+// UpdateCostingMethod mengganti metode penilaian persediaan.
+// Saat beralih ke FIFO, stok yang ada dijadikan satu lapisan pada biaya saat ini.
+func (s *CostingService) UpdateCostingMethod(userID uint, input dto.UpdateCostingMethodInput) (models.CostingMethod, error) {
+	method := models.CostingMethod(input.CostingMethod)
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		current, err := userCostingMethod(tx, userID)
+		if err != nil {
+			return err
+		}
+		if current == method {
+			return nil
+		}
+		if err := tx.Model(&models.User{}).Where("id = ?", userID).Update("costing_method", method).Error; err != nil {
+			return err
+		}
+		if method != models.CostingFIFO {
+			return nil
+		}
+
+		var products []models.Product
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ?", userID).Find(&products).Error; err != nil {
+			return err
+		}
+		for i := range products {
+			if err := seedCostLayers(tx, &products[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return method, nil
+}
+
+// GetCostLayers mengambil lapisan biaya FIFO yang masih tersisa untuk satu produk
+func (s *CostingService) GetCostLayers(productID uint, userID uint) ([]dto.CostLayerResponse, error) {
+	if _, err := NewProductService().GetProductByID(productID, userID); err != nil {
+		return nil, err
+	}
+
+	var layers []models.CostLayer
+	if err := database.DB.Where("product_id = ? AND remaining_quantity > 0", productID).
+		Order("received_at asc, id asc").Find(&layers).Error; err != nil {
+		return nil, err
+	}
+
+	responses := []dto.CostLayerResponse{}
+	for _, layer := range layers {
+		responses = append(responses, dto.CostLayerResponse{
+			ID:                layer.ID,
+			TransactionID:     layer.TransactionID,
+			UnitCost:          layer.UnitCost,
+			Quantity:          layer.Quantity,
+			RemainingQuantity: layer.RemainingQuantity,
+			ReceivedAt:        layer.ReceivedAt.Format(time.RFC3339),
+		})
+	}
+	return responses, nil
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/danishyusrah/go_bisnis/internal/models"
+)
+
+// costStep adalah satu barang masuk (receive) atau keluar pada tes penilaian persediaan
+type costStep struct {
+	receive       bool
+	quantity      int
+	unitCost      float64   // Biaya barang masuk
+	transactionID *uint     // Transaksi sumber (barang masuk) / transaksi yang dibalik (barang keluar)
+	sourceCost    *float64  // Biaya pembelian asal untuk retur pembelian
+	receivedAt    time.Time // Tanggal transaksi barang masuk
+	wantCost      float64   // Biaya per unit barang keluar
+}
+
+func TestInventoryCosting(t *testing.T) {
+	purchaseID := uint(7)
+	sourceCost := 2000.0
+	march := func(day int) time.Time { return time.Date(2024, time.March, day, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name          string
+		method        models.CostingMethod
+		stock         int
+		price         float64
+		steps         []costStep
+		wantPrice     float64
+		wantRemaining []int // Sisa lapisan FIFO urut ID
+	}{
+		{
+			name: "rata-rata: barang masuk merata ulang biaya", method: models.CostingAverage, stock: 10, price: 1000,
+			steps:     []costStep{{receive: true, quantity: 10, unitCost: 2000}},
+			wantPrice: 1500,
+		},
+		{
+			name: "rata-rata: stok kosong memakai biaya masuk", method: models.CostingAverage, stock: 0, price: 1000,
+			steps:     []costStep{{receive: true, quantity: 4, unitCost: 1250}},
+			wantPrice: 1250,
+		},
+		{
+			name: "rata-rata: penjualan keluar pada biaya saat ini", method: models.CostingAverage, stock: 10, price: 1500,
+			steps:     []costStep{{quantity: 4, wantCost: 1500}},
+			wantPrice: 1500,
+		},
+		{
+			name: "rata-rata: retur pembelian keluar pada biaya asal", method: models.CostingAverage, stock: 20, price: 1500,
+			steps:     []costStep{{quantity: 10, sourceCost: &sourceCost, wantCost: 2000}},
+			wantPrice: 1000,
+		},
+		{
+			name: "FIFO: lapisan tertua keluar lebih dulu", method: models.CostingFIFO,
+			steps: []costStep{
+				{receive: true, quantity: 10, unitCost: 1000},
+				{receive: true, quantity: 5, unitCost: 1600},
+				{quantity: 12, wantCost: 1100},
+			},
+			wantPrice:     1600,
+			wantRemaining: []int{0, 3},
+		},
+		{
+			name: "FIFO: harga beli = rata-rata sisa lapisan", method: models.CostingFIFO,
+			steps: []costStep{
+				{receive: true, quantity: 10, unitCost: 1000},
+				{receive: true, quantity: 5, unitCost: 1600},
+			},
+			wantPrice:     1200,
+			wantRemaining: []int{10, 5},
+		},
+		{
+			name: "FIFO: retur pembelian memakai lapisan transaksinya", method: models.CostingFIFO,
+			steps: []costStep{
+				{receive: true, quantity: 10, unitCost: 1000},
+				{receive: true, quantity: 5, unitCost: 1600, transactionID: &purchaseID},
+				{quantity: 2, transactionID: &purchaseID, wantCost: 1600},
+			},
+			wantPrice:     1138.46,
+			wantRemaining: []int{10, 3},
+		},
+		{
+			name: "FIFO: pembelian mundur tanggal keluar lebih dulu", method: models.CostingFIFO,
+			steps: []costStep{
+				{receive: true, quantity: 10, unitCost: 1000, receivedAt: march(10)},
+				{receive: true, quantity: 5, unitCost: 1600, receivedAt: march(1)},
+				{quantity: 5, wantCost: 1600},
+			},
+			wantPrice:     1000,
+			wantRemaining: []int{10, 0},
+		},
+		{
+			name: "FIFO: stok tanpa lapisan dinilai dengan biaya saat ini", method: models.CostingFIFO, stock: 5, price: 900,
+			steps:     []costStep{{quantity: 3, wantCost: 900}},
+			wantPrice: 900,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t, &models.User{}, &models.Product{}, &models.CostLayer{})
+			user := models.User{Username: "toko", Email: "toko@example.com", PasswordHash: "x", CostingMethod: tt.method}
+			if err := db.Create(&user).Error; err != nil {
+				t.Fatal(err)
+			}
+			product := models.Product{UserID: user.ID, Name: "Gula", Stock: tt.stock, PurchasePrice: tt.price}
+			if err := db.Create(&product).Error; err != nil {
+				t.Fatal(err)
+			}
+
+			for i, step := range tt.steps {
+				// Seperti pemanggilnya, product.Stock berisi stok SEBELUM perubahan dan diperbarui sesudahnya
+				if step.receive {
+					if err := receiveInventoryCost(db, &product, step.quantity, step.unitCost, step.transactionID, step.receivedAt); err != nil {
+						t.Fatalf("langkah %d: receiveInventoryCost() error = %v", i, err)
+					}
+					product.Stock += step.quantity
+				} else {
+					cost, err := issueInventoryCost(db, &product, step.quantity, step.transactionID, step.sourceCost)
+					if err != nil {
+						t.Fatalf("langkah %d: issueInventoryCost() error = %v", i, err)
+					}
+					assertAmount(t, "biaya keluar", cost, step.wantCost)
+					product.Stock -= step.quantity
+				}
+				if err := db.Model(&product).Update("stock", product.Stock).Error; err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var saved models.Product
+			if err := db.First(&saved, product.ID).Error; err != nil {
+				t.Fatal(err)
+			}
+			assertAmount(t, "harga beli", saved.PurchasePrice, tt.wantPrice)
+
+			var layers []models.CostLayer
+			if err := db.Where("product_id = ?", product.ID).Order("id asc").Find(&layers).Error; err != nil {
+				t.Fatal(err)
+			}
+			if len(layers) != len(tt.wantRemaining) {
+				t.Fatalf("jumlah lapisan = %d, want %d", len(layers), len(tt.wantRemaining))
+			}
+			for i, layer := range layers {
+				if layer.RemainingQuantity != tt.wantRemaining[i] {
+					t.Errorf("sisa lapisan %d = %d, want %d", i, layer.RemainingQuantity, tt.wantRemaining[i])
+				}
+			}
+		})
+	}
+}
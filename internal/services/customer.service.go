@@ -2,11 +2,15 @@ package services
 
 import (
 	"errors"
+	"fmt"
+	"sort"
+	"time"
 
 	"github.com/danishyusrah/go_bisnis/internal/database"
 	"github.com/danishyusrah/go_bisnis/internal/dto"
 	"github.com/danishyusrah/go_bisnis/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // CustomerService adalah struct untuk layanan terkait pelanggan
@@ -27,6 +31,10 @@ func (s *CustomerService) CreateCustomer(input dto.CreateCustomerInput, userID u
 		Phone:   input.Phone,
 		Address: input.Address,
 		UserID:  userID, // Menetapkan pemilik pelanggan
+		// [BARU] Batas kredit
+		CreditLimit:         roundAmount(input.CreditLimit),
+		CreditHoldOnOverdue: input.CreditHoldOnOverdue,
+		MaxOverdueDays:      input.MaxOverdueDays,
 	}
 
 	if err := db.Create(&newCustomer).Error; err != nil {
@@ -92,6 +100,23 @@ func (s *CustomerService) UpdateCustomer(customerID uint, input dto.UpdateCustom
 		return models.Customer{}, err
 	}
 
+	// [BARU] Batas kredit boleh diubah ke 0/false, jadi tidak bisa lewat Updates(struct)
+	creditUpdates := map[string]interface{}{}
+	if input.CreditLimit != nil {
+		creditUpdates["credit_limit"] = roundAmount(*input.CreditLimit)
+	}
+	if input.CreditHoldOnOverdue != nil {
+		creditUpdates["credit_hold_on_overdue"] = *input.CreditHoldOnOverdue
+	}
+	if input.MaxOverdueDays != nil {
+		creditUpdates["max_overdue_days"] = *input.MaxOverdueDays
+	}
+	if len(creditUpdates) > 0 {
+		if err := db.Model(&customer).Updates(creditUpdates).Error; err != nil {
+			return models.Customer{}, err
+		}
+	}
+
 	return customer, nil
 }
 
@@ -112,3 +137,236 @@ func (s *CustomerService) DeleteCustomer(customerID uint, userID uint) error {
 
 	return nil
 }
+
+// GetCustomerCreditExposure menghitung posisi piutang pelanggan terhadap batas kreditnya
+func (s *CustomerService) GetCustomerCreditExposure(customer models.Customer) (dto.CustomerCreditExposure, error) {
+	return customerCreditExposure(database.DB, customer, time.Now())
+}
+
+// customerCreditExposure menjumlahkan piutang terbuka (INCOME BELUM LUNAS, bukan VOID) milik pelanggan
+// dan menentukan apakah penjualan kredit baru akan ditahan
+func customerCreditExposure(tx *gorm.DB, customer models.Customer, now time.Time) (dto.CustomerCreditExposure, error) {
+	var exposure dto.CustomerCreditExposure
+	var openInvoices []models.Transaction
+	if err := tx.Select("id", "total_amount", "refunded_amount", "paid_amount", "due_date").
+		Where("customer_id = ? AND type = ? AND payment_status = ? AND status <> ?", customer.ID, models.Income, models.BelumLunas, models.StatusVoid).
+		Find(&openInvoices).Error; err != nil {
+		return exposure, err
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	for _, invoice := range openInvoices {
+		outstanding := invoice.OutstandingAmount()
+		exposure.Outstanding += outstanding
+		exposure.OpenInvoices++
+
+		days, _ := agingBucketFor(invoice.DueDate, today)
+		if days > 0 {
+			exposure.OverdueAmount += outstanding
+			exposure.OverdueInvoices++
+			if days > exposure.MaxDaysOverdue {
+				exposure.MaxDaysOverdue = days
+			}
+		}
+	}
+	exposure.Outstanding = roundAmount(exposure.Outstanding)
+	exposure.OverdueAmount = roundAmount(exposure.OverdueAmount)
+
+	if customer.CreditLimit > 0 {
+		available := roundAmount(customer.CreditLimit - exposure.Outstanding)
+		exposure.AvailableCredit = &available
+		exposure.IsOverLimit = exposure.Outstanding > customer.CreditLimit
+	}
+	if customer.CreditHoldOnOverdue && exposure.MaxDaysOverdue > customer.MaxOverdueDays {
+		exposure.IsOnHold = true
+		exposure.HoldReason = fmt.Sprintf("%d tagihan lewat jatuh tempo hingga %d hari (toleransi %d hari)",
+			exposure.OverdueInvoices, exposure.MaxDaysOverdue, customer.MaxOverdueDays)
+	} else if exposure.IsOverLimit {
+		exposure.IsOnHold = true
+		exposure.HoldReason = "sisa piutang melebihi batas kredit"
+	}
+	return exposure, nil
+}
+
+// checkCustomerCredit menolak penjualan BELUM LUNAS senilai 'amount' jika pelanggan sedang ditahan
+// (tagihan lewat jatuh tempo) atau piutangnya akan melebihi batas kredit, kecuali 'override' diisi.
+// Baris pelanggan dikunci agar dua penjualan kredit bersamaan tidak sama-sama lolos pengecekan.
+func checkCustomerCredit(tx *gorm.DB, customerID uint, amount float64, override bool) error {
+	var customer models.Customer
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&customer, customerID).Error; err != nil {
+		return errors.New("pelanggan tidak ditemukan")
+	}
+	if override || (customer.CreditLimit <= 0 && !customer.CreditHoldOnOverdue) {
+		return nil
+	}
+
+	exposure, err := customerCreditExposure(tx, customer, time.Now())
+	if err != nil {
+		return errors.New("gagal menghitung piutang pelanggan")
+	}
+	if customer.CreditHoldOnOverdue && exposure.MaxDaysOverdue > customer.MaxOverdueDays {
+		return fmt.Errorf("penjualan kredit ditahan: pelanggan memiliki %s, gunakan credit_override untuk tetap melanjutkan", exposure.HoldReason)
+	}
+	if customer.CreditLimit > 0 && roundAmount(exposure.Outstanding+amount) > customer.CreditLimit {
+		return fmt.Errorf("batas kredit pelanggan terlampaui: sisa piutang %.2f + transaksi %.2f melebihi batas %.2f, gunakan credit_override untuk tetap melanjutkan",
+			exposure.Outstanding, amount, customer.CreditLimit)
+	}
+	return nil
+}
+
+// --- [BARU] Rekening Koran Pelanggan ---
+
+// Jenis baris rekening koran pelanggan
+const (
+	StatementSale    = "SALE"
+	StatementPayment = "PAYMENT"
+	StatementRefund  = "REFUND"
+)
+
+// customerBalanceBefore menghitung saldo piutang pelanggan sebelum 'before':
+// penjualan - retur - pembayaran (pembayaran negatif = dana retur yang dikembalikan)
+func customerBalanceBefore(db *gorm.DB, customerID uint, before time.Time) (float64, error) {
+	var sales, refunds, payments sumResult
+	if err := db.Model(&models.Transaction{}).Select("COALESCE(SUM(total_amount), 0) as total").
+		Where("customer_id = ? AND type = ? AND status <> ? AND created_at < ?", customerID, models.Income, models.StatusVoid, before).
+		Scan(&sales).Error; err != nil {
+		return 0, err
+	}
+	if err := db.Model(&models.Refund{}).Select("COALESCE(SUM(refunds.amount), 0) as total").
+		Joins("JOIN transactions ON transactions.id = refunds.transaction_id").
+		Where("transactions.customer_id = ? AND transactions.type = ? AND transactions.status <> ? AND transactions.deleted_at IS NULL AND refunds.created_at < ?", customerID, models.Income, models.StatusVoid, before).
+		Scan(&refunds).Error; err != nil {
+		return 0, err
+	}
+	if err := db.Model(&models.Payment{}).Select("COALESCE(SUM(payments.amount), 0) as total").
+		Joins("JOIN transactions ON transactions.id = payments.transaction_id").
+		Where("transactions.customer_id = ? AND transactions.type = ? AND transactions.status <> ? AND transactions.deleted_at IS NULL AND payments.payment_date < ?", customerID, models.Income, models.StatusVoid, before).
+		Scan(&payments).Error; err != nil {
+		return 0, err
+	}
+	return roundAmount(sales.Total - refunds.Total - payments.Total), nil
+}
+
+// GetCustomerStatement membuat rekening koran pelanggan: semua penjualan, pembayaran, dan retur
+// dalam rentang waktu beserta saldo awal, saldo berjalan, dan saldo akhir piutang
+func (s *CustomerService) GetCustomerStatement(customerID uint, userID uint, startTime time.Time, endTime time.Time) (dto.CustomerStatement, error) {
+	db := database.DB
+
+	customer, err := s.GetCustomerByID(customerID, userID)
+	if err != nil {
+		return dto.CustomerStatement{}, err
+	}
+
+	statement := dto.CustomerStatement{
+		CustomerID:      customer.ID,
+		CustomerName:    customer.Name,
+		CustomerPhone:   customer.Phone,
+		CustomerAddress: customer.Address,
+		From:            startTime.Format("2006-01-02"),
+		To:              endTime.Format("2006-01-02"),
+		Entries:         []dto.CustomerStatementEntry{},
+	}
+
+	// --- 1. Saldo Awal ---
+	statement.OpeningBalance, err = customerBalanceBefore(db, customer.ID, startTime)
+	if err != nil {
+		return statement, err
+	}
+
+	// --- 2. Ambil mutasi dalam rentang waktu ---
+	var sales []models.Transaction
+	if err := db.Preload("Items").
+		Where("customer_id = ? AND type = ? AND status <> ? AND created_at BETWEEN ? AND ?", customer.ID, models.Income, models.StatusVoid, startTime, endTime).
+		Find(&sales).Error; err != nil {
+		return statement, err
+	}
+
+	var refunds []models.Refund
+	if err := db.Model(&models.Refund{}).Select("refunds.*").
+		Joins("JOIN transactions ON transactions.id = refunds.transaction_id").
+		Where("transactions.customer_id = ? AND transactions.type = ? AND transactions.status <> ? AND transactions.deleted_at IS NULL AND refunds.created_at BETWEEN ? AND ?", customer.ID, models.Income, models.StatusVoid, startTime, endTime).
+		Find(&refunds).Error; err != nil {
+		return statement, err
+	}
+
+	var payments []models.Payment
+	if err := db.Model(&models.Payment{}).Select("payments.*").
+		Joins("JOIN transactions ON transactions.id = payments.transaction_id").
+		Where("transactions.customer_id = ? AND transactions.type = ? AND transactions.status <> ? AND transactions.deleted_at IS NULL AND payments.payment_date BETWEEN ? AND ?", customer.ID, models.Income, models.StatusVoid, startTime, endTime).
+		Find(&payments).Error; err != nil {
+		return statement, err
+	}
+
+	// --- 3. Gabungkan secara kronologis (penjualan, lalu retur, lalu pembayaran di waktu yang sama) ---
+	type statementLine struct {
+		date  time.Time
+		order int
+		id    uint
+		entry dto.CustomerStatementEntry
+	}
+	var lines []statementLine
+	for _, sale := range sales {
+		lines = append(lines, statementLine{date: sale.CreatedAt, order: 0, id: sale.ID, entry: dto.CustomerStatementEntry{
+			Type:          StatementSale,
+			TransactionID: sale.ID,
+			Description:   fmt.Sprintf("Penjualan #%d: %s", sale.ID, ledgerDescription(sale)),
+			Debit:         sale.TotalAmount,
+		}})
+	}
+	for _, refund := range refunds {
+		description := fmt.Sprintf("Retur transaksi #%d", refund.TransactionID)
+		if refund.Reason != "" {
+			description = fmt.Sprintf("%s - %s", description, refund.Reason)
+		}
+		lines = append(lines, statementLine{date: refund.CreatedAt, order: 1, id: refund.ID, entry: dto.CustomerStatementEntry{
+			Type:          StatementRefund,
+			TransactionID: refund.TransactionID,
+			Description:   description,
+			Credit:        refund.Amount,
+		}})
+	}
+	for _, payment := range payments {
+		entry := dto.CustomerStatementEntry{
+			Type:          StatementPayment,
+			TransactionID: payment.TransactionID,
+			Description:   fmt.Sprintf("Pembayaran transaksi #%d (%s)", payment.TransactionID, payment.Method),
+		}
+		if payment.Amount < 0 {
+			// Dana retur yang dikembalikan ke pelanggan menambah kembali saldo piutang
+			entry.Debit = -payment.Amount
+			entry.Description = fmt.Sprintf("Pengembalian dana transaksi #%d", payment.TransactionID)
+		} else {
+			entry.Credit = payment.Amount
+		}
+		if payment.Notes != "" {
+			entry.Description = fmt.Sprintf("%s - %s", entry.Description, payment.Notes)
+		}
+		lines = append(lines, statementLine{date: payment.PaymentDate, order: 2, id: payment.ID, entry: entry})
+	}
+	sort.SliceStable(lines, func(i, j int) bool {
+		if !lines[i].date.Equal(lines[j].date) {
+			return lines[i].date.Before(lines[j].date)
+		}
+		if lines[i].order != lines[j].order {
+			return lines[i].order < lines[j].order
+		}
+		return lines[i].id < lines[j].id
+	})
+
+	// --- 4. Hitung saldo berjalan ---
+	runningBalance := statement.OpeningBalance
+	for _, line := range lines {
+		entry := line.entry
+		entry.Date = line.date.Format("02 Jan 2006 15:04")
+		runningBalance = roundAmount(runningBalance + entry.Debit - entry.Credit)
+		entry.Balance = runningBalance
+		statement.TotalDebit += entry.Debit
+		statement.TotalCredit += entry.Credit
+		statement.Entries = append(statement.Entries, entry)
+	}
+	statement.TotalDebit = roundAmount(statement.TotalDebit)
+	statement.TotalCredit = roundAmount(statement.TotalCredit)
+	statement.ClosingBalance = runningBalance
+
+	return statement, nil
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/danishyusrah/go_bisnis/internal/models"
+)
+
+func TestCustomerCreditExposure(t *testing.T) {
+	now := time.Date(2024, time.March, 31, 15, 0, 0, 0, time.UTC)
+	daysAgo := func(days int) *time.Time {
+		due := now.AddDate(0, 0, -days)
+		return &due
+	}
+	customerID := uint(1)
+	otherCustomerID := uint(2)
+	sale := func(total, refunded, paid float64, due *time.Time) models.Transaction {
+		return models.Transaction{
+			UserID: 1, Type: models.Income, CustomerID: &customerID, PaymentStatus: models.BelumLunas,
+			Status: models.StatusActive, TotalAmount: total, RefundedAmount: refunded, PaidAmount: paid, DueDate: due,
+		}
+	}
+	floatPtr := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name            string
+		customer        models.Customer
+		invoices        []models.Transaction
+		wantOutstanding float64
+		wantOpen        int
+		wantOverdue     float64
+		wantOverdueInv  int
+		wantMaxDays     int
+		wantAvailable   *float64
+		wantOverLimit   bool
+		wantHoldReason  string
+	}{
+		{
+			name:     "tanpa tagihan dan tanpa batas",
+			customer: models.Customer{},
+		},
+		{
+			name:          "tanpa tagihan dengan batas",
+			customer:      models.Customer{CreditLimit: 1000000},
+			wantAvailable: floatPtr(1000000),
+		},
+		{
+			name:     "sisa piutang setelah retur dan cicilan",
+			customer: models.Customer{CreditLimit: 1000000},
+			invoices: []models.Transaction{
+				sale(500000, 50000, 100000, daysAgo(-10)),
+				sale(200000, 0, 0, nil),
+			},
+			wantOutstanding: 550000, wantOpen: 2,
+			wantAvailable: floatPtr(450000),
+		},
+		{
+			name:     "melebihi batas kredit",
+			customer: models.Customer{CreditLimit: 300000},
+			invoices: []models.Transaction{
+				sale(250000, 0, 0, nil),
+				sale(100000, 0, 0, nil),
+			},
+			wantOutstanding: 350000, wantOpen: 2,
+			wantAvailable: floatPtr(-50000), wantOverLimit: true,
+			wantHoldReason: "sisa piutang melebihi batas kredit",
+		},
+		{
+			name:     "lewat jatuh tempo tanpa penahanan",
+			customer: models.Customer{},
+			invoices: []models.Transaction{
+				sale(100000, 0, 0, daysAgo(45)),
+				sale(80000, 0, 30000, daysAgo(0)),
+			},
+			wantOutstanding: 150000, wantOpen: 2,
+			wantOverdue: 100000, wantOverdueInv: 1, wantMaxDays: 45,
+		},
+		{
+			name:     "lewat jatuh tempo melebihi toleransi ditahan",
+			customer: models.Customer{CreditHoldOnOverdue: true, MaxOverdueDays: 7},
+			invoices: []models.Transaction{
+				sale(100000, 0, 0, daysAgo(3)),
+				sale(60000, 0, 0, daysAgo(10)),
+			},
+			wantOutstanding: 160000, wantOpen: 2,
+			wantOverdue: 160000, wantOverdueInv: 2, wantMaxDays: 10,
+			wantHoldReason: "2 tagihan lewat jatuh tempo hingga 10 hari (toleransi 7 hari)",
+		},
+		{
+			name:     "lewat jatuh tempo masih dalam toleransi",
+			customer: models.Customer{CreditHoldOnOverdue: true, MaxOverdueDays: 7},
+			invoices: []models.Transaction{
+				sale(100000, 0, 0, daysAgo(7)),
+			},
+			wantOutstanding: 100000, wantOpen: 1,
+			wantOverdue: 100000, wantOverdueInv: 1, wantMaxDays: 7,
+		},
+		{
+			name:     "lunas, void, pelanggan lain dan pengeluaran diabaikan",
+			customer: models.Customer{CreditLimit: 500000},
+			invoices: func() []models.Transaction {
+				paid := sale(100000, 0, 0, daysAgo(30))
+				paid.PaymentStatus = models.Lunas
+				void := sale(200000, 0, 0, daysAgo(30))
+				void.Status = models.StatusVoid
+				other := sale(300000, 0, 0, daysAgo(30))
+				other.CustomerID = &otherCustomerID
+				expense := sale(400000, 0, 0, daysAgo(30))
+				expense.Type = models.Expense
+				return []models.Transaction{paid, void, other, expense, sale(50000, 0, 0, nil)}
+			}(),
+			wantOutstanding: 50000, wantOpen: 1,
+			wantAvailable: floatPtr(450000),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t, &models.Transaction{})
+			if len(tt.invoices) > 0 {
+				if err := db.Create(&tt.invoices).Error; err != nil {
+					t.Fatal(err)
+				}
+			}
+			customer := tt.customer
+			customer.ID = customerID
+
+			got, err := customerCreditExposure(db, customer, now)
+			if err != nil {
+				t.Fatalf("customerCreditExposure() error = %v", err)
+			}
+			assertAmount(t, "sisa piutang", got.Outstanding, tt.wantOutstanding)
+			assertAmount(t, "piutang lewat jatuh tempo", got.OverdueAmount, tt.wantOverdue)
+			if got.OpenInvoices != tt.wantOpen || got.OverdueInvoices != tt.wantOverdueInv || got.MaxDaysOverdue != tt.wantMaxDays {
+				t.Errorf("tagihan = (%d, %d, %d hari), want (%d, %d, %d hari)",
+					got.OpenInvoices, got.OverdueInvoices, got.MaxDaysOverdue, tt.wantOpen, tt.wantOverdueInv, tt.wantMaxDays)
+			}
+			switch {
+			case tt.wantAvailable == nil && got.AvailableCredit != nil:
+				t.Errorf("sisa plafon = %.2f, want nil", *got.AvailableCredit)
+			case tt.wantAvailable != nil && got.AvailableCredit == nil:
+				t.Errorf("sisa plafon = nil, want %.2f", *tt.wantAvailable)
+			case tt.wantAvailable != nil:
+				assertAmount(t, "sisa plafon", *got.AvailableCredit, *tt.wantAvailable)
+			}
+			if got.IsOverLimit != tt.wantOverLimit {
+				t.Errorf("IsOverLimit = %v, want %v", got.IsOverLimit, tt.wantOverLimit)
+			}
+			if got.IsOnHold != (tt.wantHoldReason != "") || got.HoldReason != tt.wantHoldReason {
+				t.Errorf("penahanan = (%v, %q), want %q", got.IsOnHold, got.HoldReason, tt.wantHoldReason)
+			}
+		})
+	}
+}
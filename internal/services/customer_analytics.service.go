@@ -0,0 +1,283 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/danishyusrah/go_bisnis/internal/database"
+	"github.com/danishyusrah/go_bisnis/internal/dto"
+	"github.com/danishyusrah/go_bisnis/internal/models"
+)
+
+// Segmen pelanggan hasil analisis RFM
+const (
+	SegmentChampions         = "CHAMPIONS"          // Baru belanja, sering, dan nilainya besar
+	SegmentLoyal             = "LOYAL"              // Sering belanja
+	SegmentPotentialLoyalist = "POTENTIAL_LOYALIST" // Baru belanja dengan frekuensi sedang
+	SegmentNew               = "NEW"                // Baru belanja, baru sekali-dua kali
+	SegmentNeedAttention     = "NEED_ATTENTION"     // Mulai jarang belanja
+	SegmentAtRisk            = "AT_RISK"            // Dulu sering belanja, sudah lama tidak kembali
+	SegmentHibernating       = "HIBERNATING"        // Jarang dan sudah cukup lama tidak belanja
+	SegmentLost              = "LOST"               // Tidak belanja lagi dalam rentang analisis
+)
+
+// rfmSegmentOrder adalah urutan tampilan ringkasan segmen
+var rfmSegmentOrder = []string{
+	SegmentChampions, SegmentLoyal, SegmentPotentialLoyalist, SegmentNew,
+	SegmentNeedAttention, SegmentAtRisk, SegmentHibernating, SegmentLost,
+}
+
+// RFMSegments mengembalikan daftar nama segmen yang valid
+func RFMSegments() []string {
+	return append([]string(nil), rfmSegmentOrder...)
+}
+
+// rfmScores memberi skor 1-5 berdasarkan peringkat persentil: nilai yang mengalahkan/menyamai
+// lebih banyak pelanggan mendapat skor lebih tinggi. Nilai yang sama selalu mendapat skor yang sama.
+func rfmScores(values []float64, higherIsBetter bool) []int {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	n := float64(len(values))
+
+	scores := make([]int, len(values))
+	for i, v := range values {
+		var count int
+		if higherIsBetter {
+			count = sort.Search(len(sorted), func(k int) bool { return sorted[k] > v }) // Jumlah nilai <= v
+		} else {
+			count = len(sorted) - sort.SearchFloat64s(sorted, v) // Jumlah nilai >= v
+		}
+		score := int(math.Ceil(5 * float64(count) / n))
+		if score < 1 {
+			score = 1
+		}
+		scores[i] = score
+	}
+	return scores
+}
+
+// rfmSegment memetakan kombinasi skor RFM ke segmen pemasaran
+func rfmSegment(frequency int64, r, f, m int) string {
+	switch {
+	case frequency == 0:
+		return SegmentLost
+	case r >= 4 && f >= 4 && m >= 4:
+		return SegmentChampions
+	case r >= 3 && f >= 4:
+		return SegmentLoyal
+	case r >= 4 && f <= 1:
+		return SegmentNew
+	case r >= 4:
+		return SegmentPotentialLoyalist
+	case r <= 2 && f >= 3:
+		return SegmentAtRisk
+	case r == 3:
+		return SegmentNeedAttention
+	case r == 2:
+		return SegmentHibernating
+	default:
+		return SegmentLost
+	}
+}
+
+// GetCustomerRFM menghitung recency, frequency, monetary per pelanggan dalam rentang waktu,
+// menetapkan segmen, dan menyertakan 'topN' produk yang paling banyak dibeli.
+// Recency dihitung dari pembelian terakhir kapan pun (hingga akhir rentang) agar pelanggan
+// yang sudah lama tidak kembali tetap muncul sebagai LOST / AT_RISK. Filter 'segment' opsional.
+func (s *CustomerService) GetCustomerRFM(userID uint, startTime time.Time, endTime time.Time, segment string, topN int) (dto.CustomerRFMReport, error) {
+	db := database.DB
+	report := dto.CustomerRFMReport{
+		From:      startTime.Format("2006-01-02"),
+		To:        endTime.Format("2006-01-02"),
+		Segments:  []dto.RFMSegmentSummary{},
+		Customers: []dto.CustomerRFM{},
+	}
+
+	// --- 1. Pembelian terakhir (sepanjang waktu) per pelanggan ---
+	type lastPurchaseRow struct {
+		CustomerID   uint
+		LastPurchase time.Time
+	}
+	var lastRows []lastPurchaseRow
+	if err := db.Model(&models.Transaction{}).
+		Select("customer_id, MAX(created_at) as last_purchase").
+		Where("user_id = ? AND type = ? AND status <> ? AND customer_id IS NOT NULL AND created_at <= ?", userID, models.Income, models.StatusVoid, endTime).
+		Group("customer_id").Scan(&lastRows).Error; err != nil {
+		return report, err
+	}
+	if len(lastRows) == 0 {
+		return report, nil
+	}
+
+	// --- 2. Frekuensi & nilai belanja dalam rentang ---
+	type windowRow struct {
+		CustomerID uint
+		Frequency  int64
+		Monetary   float64
+	}
+	var windowRows []windowRow
+	if err := db.Model(&models.Transaction{}).
+		Select("customer_id, COUNT(*) as frequency, COALESCE(SUM(total_amount - refunded_amount), 0) as monetary").
+		Where("user_id = ? AND type = ? AND status <> ? AND customer_id IS NOT NULL AND created_at BETWEEN ? AND ?", userID, models.Income, models.StatusVoid, startTime, endTime).
+		Group("customer_id").Scan(&windowRows).Error; err != nil {
+		return report, err
+	}
+	windowByCustomer := make(map[uint]windowRow, len(windowRows))
+	for _, row := range windowRows {
+		windowByCustomer[row.CustomerID] = row
+	}
+
+	// --- 3. Data pelanggan (yang sudah dihapus tidak dianalisis) ---
+	customerIDs := make([]uint, 0, len(lastRows))
+	for _, row := range lastRows {
+		customerIDs = append(customerIDs, row.CustomerID)
+	}
+	var customers []models.Customer
+	if err := db.Where("user_id = ? AND id IN ?", userID, customerIDs).Find(&customers).Error; err != nil {
+		return report, err
+	}
+	customerByID := make(map[uint]models.Customer, len(customers))
+	for _, customer := range customers {
+		customerByID[customer.ID] = customer
+	}
+
+	endDay := time.Date(endTime.Year(), endTime.Month(), endTime.Day(), 0, 0, 0, 0, endTime.Location())
+	var rows []dto.CustomerRFM
+	for _, last := range lastRows {
+		customer, ok := customerByID[last.CustomerID]
+		if !ok {
+			continue
+		}
+		lastDay := time.Date(last.LastPurchase.Year(), last.LastPurchase.Month(), last.LastPurchase.Day(), 0, 0, 0, 0, endTime.Location())
+		window := windowByCustomer[customer.ID]
+		row := dto.CustomerRFM{
+			CustomerID:   customer.ID,
+			CustomerName: customer.Name,
+			Phone:        customer.Phone,
+			Email:        customer.Email,
+			LastPurchase: last.LastPurchase.Format("2006-01-02"),
+			RecencyDays:  int(endDay.Sub(lastDay).Hours() / 24),
+			Frequency:    window.Frequency,
+			Monetary:     roundAmount(window.Monetary),
+			TopProducts:  []dto.CustomerTopProduct{},
+		}
+		if row.Frequency > 0 {
+			row.AverageOrderValue = roundAmount(row.Monetary / float64(row.Frequency))
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return report, nil
+	}
+
+	// --- 4. Skor & segmen ---
+	// Skor F & M hanya dibandingkan antar pelanggan yang belanja dalam rentang;
+	// yang tidak belanja selalu mendapat skor terendah
+	recencies := make([]float64, len(rows))
+	var activeIdx []int
+	var frequencies, monetaries []float64
+	for i, row := range rows {
+		recencies[i] = float64(row.RecencyDays)
+		rows[i].FrequencyScore = 1
+		rows[i].MonetaryScore = 1
+		if row.Frequency > 0 {
+			activeIdx = append(activeIdx, i)
+			frequencies = append(frequencies, float64(row.Frequency))
+			monetaries = append(monetaries, row.Monetary)
+		}
+	}
+	rScores := rfmScores(recencies, false)
+	fScores := rfmScores(frequencies, true)
+	mScores := rfmScores(monetaries, true)
+	for k, i := range activeIdx {
+		rows[i].FrequencyScore = fScores[k]
+		rows[i].MonetaryScore = mScores[k]
+	}
+	for i := range rows {
+		rows[i].RecencyScore = rScores[i]
+		rows[i].RFMScore = fmt.Sprintf("%d%d%d", rows[i].RecencyScore, rows[i].FrequencyScore, rows[i].MonetaryScore)
+		rows[i].Segment = rfmSegment(rows[i].Frequency, rows[i].RecencyScore, rows[i].FrequencyScore, rows[i].MonetaryScore)
+	}
+
+	// Ringkasan segmen dihitung dari semua pelanggan, sebelum filter
+	summaries := make(map[string]*dto.RFMSegmentSummary)
+	for _, row := range rows {
+		summary, ok := summaries[row.Segment]
+		if !ok {
+			summary = &dto.RFMSegmentSummary{Segment: row.Segment}
+			summaries[row.Segment] = summary
+		}
+		summary.Customers++
+		summary.Monetary += row.Monetary
+	}
+	for _, name := range rfmSegmentOrder {
+		if summary, ok := summaries[name]; ok {
+			summary.Monetary = roundAmount(summary.Monetary)
+			report.Segments = append(report.Segments, *summary)
+		}
+	}
+
+	var selected []dto.CustomerRFM
+	for _, row := range rows {
+		if segment == "" || row.Segment == segment {
+			selected = append(selected, row)
+		}
+	}
+	if len(selected) == 0 {
+		return report, nil
+	}
+
+	// --- 5. Produk teratas per pelanggan dalam rentang ---
+	if topN > 0 {
+		selectedIDs := make([]uint, 0, len(selected))
+		indexByCustomer := make(map[uint]int, len(selected))
+		for i, row := range selected {
+			selectedIDs = append(selectedIDs, row.CustomerID)
+			indexByCustomer[row.CustomerID] = i
+		}
+		type productRow struct {
+			CustomerID  uint
+			ProductID   *uint
+			ProductName string
+			Quantity    int64
+			Revenue     float64
+		}
+		var productRows []productRow
+		if err := db.Model(&models.TransactionItem{}).
+			Select("transactions.customer_id, transaction_items.product_id, transaction_items.product_name, "+
+				"SUM(transaction_items.quantity - transaction_items.refunded_quantity) as quantity, "+
+				"COALESCE(SUM((transaction_items.taxable_amount + transaction_items.tax_amount) * (transaction_items.quantity - transaction_items.refunded_quantity) / transaction_items.quantity), 0) as revenue").
+			Joins("JOIN transactions ON transactions.id = transaction_items.transaction_id").
+			Where("transactions.user_id = ? AND transactions.type = ? AND transactions.status <> ? AND transactions.deleted_at IS NULL AND transactions.customer_id IN ? AND transactions.created_at BETWEEN ? AND ? AND transaction_items.quantity > transaction_items.refunded_quantity",
+				userID, models.Income, models.StatusVoid, selectedIDs, startTime, endTime).
+			Group("transactions.customer_id, transaction_items.product_id, transaction_items.product_name").
+			Order("revenue desc, quantity desc").
+			Scan(&productRows).Error; err != nil {
+			return report, err
+		}
+		for _, product := range productRows {
+			i := indexByCustomer[product.CustomerID]
+			if len(selected[i].TopProducts) >= topN {
+				continue
+			}
+			selected[i].TopProducts = append(selected[i].TopProducts, dto.CustomerTopProduct{
+				ProductID:   product.ProductID,
+				ProductName: product.ProductName,
+				Quantity:    product.Quantity,
+				Revenue:     roundAmount(product.Revenue),
+			})
+		}
+	}
+
+	// Nilai belanja terbesar lebih dulu; yang tidak belanja diurutkan dari yang paling baru hilang
+	sort.SliceStable(selected, func(i, j int) bool {
+		if selected[i].Monetary != selected[j].Monetary {
+			return selected[i].Monetary > selected[j].Monetary
+		}
+		return selected[i].RecencyDays < selected[j].RecencyDays
+	})
+	report.Customers = selected
+	return report, nil
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"log"
+	"sort"
 	"time"
 
 	"github.com/danishyusrah/go_bisnis/internal/database"
@@ -19,7 +20,8 @@ func NewDashboardService() *DashboardService {
 
 // GetDashboardStats adalah logika bisnis untuk mengambil statistik dashboard
 // [DIPERBARUI] Logika query diubah total untuk kalkulasi Laba Kotor dan Laba Bersih
-func (s *DashboardService) GetDashboardStats(userID uint, startTime time.Time, endTime time.Time) (dto.DashboardStats, error) {
+// [DIUBAH] Bisa difilter per lokasi (locationID nil = semua lokasi)
+func (s *DashboardService) GetDashboardStats(userID uint, locationID *uint, startTime time.Time, endTime time.Time) (dto.DashboardStats, error) {
 	db := database.DB
 	var stats dto.DashboardStats
 
@@ -34,10 +36,12 @@ func (s *DashboardService) GetDashboardStats(userID uint, startTime time.Time, e
 
 	// [PERBAIKAN KEDUA]
 	// Query ini disederhanakan untuk memastikan SUM(T_Items.total_cogs) dihitung dengan benar.
+	// [DIUBAH] Transaksi VOID diabaikan dan nilai retur dikurangkan (pendapatan & HPP bersih)
 	err := db.Model(&models.Transaction{}).
-		Select("COALESCE(SUM(transactions.total_amount), 0) as total_revenue, COALESCE(SUM(T_Items.total_cogs), 0) as total_cogs").
-		Joins("LEFT JOIN (SELECT transaction_id, SUM(purchase_price * quantity) as total_cogs FROM transaction_items GROUP BY transaction_id) AS T_Items ON T_Items.transaction_id = transactions.id").
-		Where("transactions.user_id = ? AND transactions.type = ? AND transactions.created_at BETWEEN ? AND ?", userID, models.Income, startTime, endTime).
+		Select("COALESCE(SUM("+netTotalSQL+"), 0) as total_revenue, COALESCE(SUM(T_Items.total_cogs), 0) as total_cogs").
+		Joins("LEFT JOIN (SELECT transaction_id, SUM(purchase_price * (quantity - refunded_quantity)) as total_cogs FROM transaction_items GROUP BY transaction_id) AS T_Items ON T_Items.transaction_id = transactions.id").
+		Scopes(locationScope(locationID)).
+		Where("transactions.user_id = ? AND transactions.type = ? AND transactions.status <> ? AND transactions.created_at BETWEEN ? AND ?", userID, models.Income, models.StatusVoid, startTime, endTime).
 		Scan(&revenueCOGS).Error
 
 	if err != nil {
@@ -53,8 +57,9 @@ func (s *DashboardService) GetDashboardStats(userID uint, startTime time.Time, e
 	}
 	var expenseResult SumResult
 	if err := db.Model(&models.Transaction{}).
-		Select("COALESCE(SUM(total_amount), 0) as total").
-		Where("user_id = ? AND type = ? AND created_at BETWEEN ? AND ?", userID, models.Expense, startTime, endTime).
+		Select("COALESCE(SUM("+netTotalSQL+"), 0) as total").
+		Scopes(locationScope(locationID)).
+		Where("transactions.user_id = ? AND transactions.type = ? AND transactions.status <> ? AND transactions.created_at BETWEEN ? AND ?", userID, models.Expense, models.StatusVoid, startTime, endTime).
 		Scan(&expenseResult).Error; err != nil {
 		log.Printf("Error querying total expense: %v", err)
 		return stats, err
@@ -64,16 +69,40 @@ func (s *DashboardService) GetDashboardStats(userID uint, startTime time.Time, e
 	// --- 3. Query untuk menghitung Jumlah Transaksi (Pemasukan + Pengeluaran) ---
 	var count int64
 	if err := db.Model(&models.Transaction{}).
-		Where("user_id = ? AND type IN (?, ?) AND created_at BETWEEN ? AND ?", userID, models.Income, models.Expense, startTime, endTime).
+		Scopes(locationScope(locationID)).
+		Where("user_id = ? AND type IN (?, ?) AND status <> ? AND created_at BETWEEN ? AND ?", userID, models.Income, models.Expense, models.StatusVoid, startTime, endTime).
 		Count(&count).Error; err != nil {
 		log.Printf("Error querying transaction count: %v", err)
 		return stats, err
 	}
 	stats.TransactionCount = count
 
+	// --- [BARU] 3b. Total diskon penjualan (bersih setelah retur, tanpa PPN) ---
+	var discountResult SumResult
+	if err := db.Model(&models.TransactionItem{}).
+		Select("COALESCE(SUM("+netQuantitySQL+" * "+unitDiscountSQL+"), 0) as total").
+		Joins("JOIN transactions ON transactions.id = transaction_items.transaction_id").
+		Scopes(locationScope(locationID)).
+		Where("transactions.user_id = ? AND transactions.type = ? AND transactions.status <> ? AND transactions.created_at BETWEEN ? AND ?", userID, models.Income, models.StatusVoid, startTime, endTime).
+		Scan(&discountResult).Error; err != nil {
+		log.Printf("Error querying total discount: %v", err)
+		return stats, err
+	}
+	stats.TotalDiscount = roundAmount(discountResult.Total)
+	stats.NetSales = stats.TotalRevenue
+	stats.GrossSales = roundAmount(stats.TotalRevenue + stats.TotalDiscount)
+
+	// --- [BARU] 3c. Beban selisih persediaan dari stok opname ---
+	shrinkage, err := inventoryShrinkageBetween(userID, locationID, startTime, endTime)
+	if err != nil {
+		log.Printf("Error querying inventory shrinkage: %v", err)
+		return stats, err
+	}
+	stats.InventoryShrinkage = shrinkage
+
 	// --- 4. Hitung Laba Kotor dan Laba Bersih ---
 	stats.GrossProfit = stats.TotalRevenue - stats.TotalCOGS
-	stats.NetProfit = stats.GrossProfit - stats.TotalExpense
+	stats.NetProfit = stats.GrossProfit - stats.TotalExpense - stats.InventoryShrinkage // [DIUBAH]
 
 	return stats, nil
 }
@@ -89,7 +118,8 @@ type DailyChartData struct {
 }
 
 // [DIPERBARUI] GetDashboardChartData adalah logika bisnis untuk mengambil data harian untuk grafik
-func (s *DashboardService) GetDashboardChartData(userID uint, startTime time.Time, endTime time.Time) (dto.ChartDataResponse, error) {
+// [DIUBAH] Bisa difilter per lokasi (locationID nil = semua lokasi)
+func (s *DashboardService) GetDashboardChartData(userID uint, locationID *uint, startTime time.Time, endTime time.Time) (dto.ChartDataResponse, error) {
 	db := database.DB
 	var response dto.ChartDataResponse
 
@@ -106,9 +136,10 @@ func (s *DashboardService) GetDashboardChartData(userID uint, startTime time.Tim
 
 	// Query 1: Ambil data Pendapatan (Revenue) dan Modal (COGS) harian
 	err := db.Model(&models.Transaction{}).
-		Select("DATE(transactions.created_at) as day, SUM(transactions.total_amount) as revenue, SUM(T_Items.total_cogs) as cogs").
-		Joins("LEFT JOIN (SELECT transaction_id, SUM(purchase_price * quantity) as total_cogs FROM transaction_items GROUP BY transaction_id) AS T_Items ON T_Items.transaction_id = transactions.id").
-		Where("transactions.user_id = ? AND transactions.type = ? AND transactions.created_at BETWEEN ? AND ?", userID, models.Income, startTime, endTime).
+		Select("DATE(transactions.created_at) as day, SUM("+netTotalSQL+") as revenue, SUM(T_Items.total_cogs) as cogs").
+		Joins("LEFT JOIN (SELECT transaction_id, SUM(purchase_price * (quantity - refunded_quantity)) as total_cogs FROM transaction_items GROUP BY transaction_id) AS T_Items ON T_Items.transaction_id = transactions.id").
+		Scopes(locationScope(locationID)).
+		Where("transactions.user_id = ? AND transactions.type = ? AND transactions.status <> ? AND transactions.created_at BETWEEN ? AND ?", userID, models.Income, models.StatusVoid, startTime, endTime).
 		Group("DATE(transactions.created_at)").
 		Order("day ASC").
 		Scan(&incomeData).Error
@@ -127,9 +158,10 @@ func (s *DashboardService) GetDashboardChartData(userID uint, startTime time.Tim
 
 	// Query 2: Ambil data Pengeluaran (Expense) harian
 	err = db.Model(&models.Transaction{}).
-		Select("DATE(created_at) as day, SUM(total_amount) as expense").
-		Where("user_id = ? AND type = ? AND created_at BETWEEN ? AND ?", userID, models.Expense, startTime, endTime).
-		Group("DATE(created_at)").
+		Select("DATE(transactions.created_at) as day, SUM("+netTotalSQL+") as expense").
+		Scopes(locationScope(locationID)).
+		Where("transactions.user_id = ? AND transactions.type = ? AND transactions.status <> ? AND transactions.created_at BETWEEN ? AND ?", userID, models.Expense, models.StatusVoid, startTime, endTime).
+		Group("DATE(transactions.created_at)").
 		Order("day ASC").
 		Scan(&expenseData).Error
 
@@ -194,25 +226,126 @@ func (s *DashboardService) GetDashboardChartData(userID uint, startTime time.Tim
 // --- [BARU UNTUK FITUR STOK MINIMUM] ---
 
 // GetLowStockProducts mengambil daftar produk yang stoknya menipis
-func (s *DashboardService) GetLowStockProducts(userID uint) ([]dto.LowStockProduct, error) {
+// [DIUBAH] Jika locationID diisi, yang dibandingkan adalah stok di lokasi tersebut
+func (s *DashboardService) GetLowStockProducts(userID uint, locationID *uint) ([]dto.LowStockProduct, error) {
 	db := database.DB
 	var results []dto.LowStockProduct
 
-	// Query ini akan mencari produk di mana:
-	// 1. Dimiliki oleh user_id
-	// 2. Batas stok minimum sudah diatur ( > 0)
-	// 3. Stok saat ini lebih kecil atau sama dengan batas minimum tersebut
-	err := db.Model(&models.Product{}).
-		Select("id as product_id, name, stock, batas_stok_minimum").
-		Where("user_id = ? AND batas_stok_minimum > 0 AND stock <= batas_stok_minimum", userID).
-		Order("name asc").
-		Scan(&results).Error // Scan langsung ke DTO
+	if locationID != nil {
+		err := db.Model(&models.Product{}).
+			Select("products.id as product_id, products.name, COALESCE(product_stocks.quantity, 0) as stock, products.batas_stok_minimum").
+			Joins("LEFT JOIN product_stocks ON product_stocks.product_id = products.id AND product_stocks.location_id = ? AND product_stocks.deleted_at IS NULL", *locationID).
+			Where("products.user_id = ? AND products.is_composite = ? AND products.batas_stok_minimum > 0 AND COALESCE(product_stocks.quantity, 0) <= products.batas_stok_minimum", userID, false).
+			Order("products.name asc").
+			Scan(&results).Error
+		if err != nil {
+			log.Printf("Error querying low stock products by location: %v", err)
+			return nil, err
+		}
+	} else {
+		// Query ini akan mencari produk di mana:
+		// 1. Dimiliki oleh user_id
+		// 2. Batas stok minimum sudah diatur ( > 0)
+		// 3. Stok saat ini lebih kecil atau sama dengan batas minimum tersebut
+		// 4. [BARU] Bukan produk komposit (dicek terpisah di bawah)
+		err := db.Model(&models.Product{}).
+			Select("id as product_id, name, stock, batas_stok_minimum").
+			Where("user_id = ? AND is_composite = ? AND batas_stok_minimum > 0 AND stock <= batas_stok_minimum", userID, false).
+			Order("name asc").
+			Scan(&results).Error // Scan langsung ke DTO
+
+		if err != nil {
+			log.Printf("Error querying low stock products: %v", err)
+			return nil, err
+		}
+	}
 
-	if err != nil {
-		log.Printf("Error querying low stock products: %v", err)
+	// [BARU] Produk komposit menipis jika jumlah yang masih bisa dibuat dari bahannya
+	// sudah mencapai batas minimum
+	var composites []models.Product
+	if err := db.Where("user_id = ? AND is_composite = ? AND batas_stok_minimum > 0", userID, true).Find(&composites).Error; err != nil {
+		log.Printf("Error querying composite products: %v", err)
+		return nil, err
+	}
+	if len(composites) > 0 {
+		counts, err := compositeBuildableCounts(db, userID, locationID)
+		if err != nil {
+			log.Printf("Error counting buildable composite products: %v", err)
+			return nil, err
+		}
+		for _, product := range composites {
+			if buildable := counts[product.ID]; buildable <= product.BatasStokMinimum {
+				results = append(results, dto.LowStockProduct{
+					ProductID:        product.ID,
+					Name:             product.Name,
+					Stock:            buildable,
+					BatasStokMinimum: product.BatasStokMinimum,
+				})
+			}
+		}
+		sort.SliceStable(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+	}
+
+	return results, nil
+}
+
+// [BARU] GetNearExpiryBatches mengambil batch yang masih bersisa dan kedaluwarsa dalam 'days' hari ke depan
+// (termasuk yang sudah lewat tanggal kedaluwarsa), urut dari yang paling cepat kedaluwarsa
+// [DIUBAH] Jika locationID diisi, hanya batch produk yang masih punya stok di lokasi tersebut.
+// Batch dilacak per produk (bukan per lokasi), jadi stok lokasinya disertakan sebagai pembanding.
+func (s *DashboardService) GetNearExpiryBatches(userID uint, locationID *uint, days int) ([]dto.NearExpiryBatch, error) {
+	db := database.DB
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	limit := today.AddDate(0, 0, days)
+
+	query := db.Preload("Product").
+		Where("user_id = ? AND remaining_quantity > 0 AND expiry_date IS NOT NULL AND expiry_date <= ?", userID, limit)
+	if locationID != nil {
+		query = query.Where("EXISTS (SELECT 1 FROM product_stocks WHERE product_stocks.product_id = product_batches.product_id AND product_stocks.location_id = ? AND product_stocks.quantity > 0 AND product_stocks.deleted_at IS NULL)", *locationID)
+	}
+	var batches []models.ProductBatch
+	if err := query.Order("expiry_date asc, id asc").Find(&batches).Error; err != nil {
+		log.Printf("Error querying near expiry batches: %v", err)
 		return nil, err
 	}
 
+	locationStock := make(map[uint]int)
+	if locationID != nil && len(batches) > 0 {
+		productIDs := make([]uint, 0, len(batches))
+		for _, batch := range batches {
+			productIDs = append(productIDs, batch.ProductID)
+		}
+		var stocks []models.ProductStock
+		if err := db.Where("location_id = ? AND product_id IN ?", *locationID, productIDs).Find(&stocks).Error; err != nil {
+			log.Printf("Error querying location stock for near expiry batches: %v", err)
+			return nil, err
+		}
+		for _, stock := range stocks {
+			locationStock[stock.ProductID] = stock.Quantity
+		}
+	}
+
+	results := []dto.NearExpiryBatch{}
+	for _, batch := range batches {
+		expiry := time.Date(batch.ExpiryDate.Year(), batch.ExpiryDate.Month(), batch.ExpiryDate.Day(), 0, 0, 0, 0, now.Location())
+		daysToExpiry := int(expiry.Sub(today).Hours() / 24)
+		results = append(results, dto.NearExpiryBatch{
+			BatchID:           batch.ID,
+			ProductID:         batch.ProductID,
+			ProductName:       batch.Product.Name,
+			LotNumber:         batch.LotNumber,
+			ExpiryDate:        expiry.Format("2006-01-02"),
+			RemainingQuantity: batch.RemainingQuantity,
+			DaysToExpiry:      daysToExpiry,
+			IsExpired:         daysToExpiry < 0,
+		})
+		if locationID != nil {
+			stock := locationStock[batch.ProductID]
+			results[len(results)-1].LocationStock = &stock
+		}
+	}
 	return results, nil
 }
 
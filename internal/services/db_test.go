@@ -0,0 +1,113 @@
+package services
+
+import (
+	"math"
+	"testing"
+
+	"github.com/danishyusrah/go_bisnis/internal/database"
+	"github.com/danishyusrah/go_bisnis/internal/models"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestDB membuka database SQLite in-memory yang terpisah untuk setiap tes dan membuat tabel
+// untuk model yang diberikan. Dipakai untuk fungsi yang menerima *gorm.DB (tanpa MySQL).
+func newTestDB(t *testing.T, models ...interface{}) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger:                                   logger.Default.LogMode(logger.Silent),
+		DisableForeignKeyConstraintWhenMigrating: true,
+	})
+	if err != nil {
+		t.Fatalf("gagal membuka database tes: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("gagal mengambil koneksi database tes: %v", err)
+	}
+	// Setiap koneksi ":memory:" adalah database sendiri, jadi pool dibatasi satu koneksi
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if err := db.AutoMigrate(models...); err != nil {
+		t.Fatalf("gagal migrasi database tes: %v", err)
+	}
+	return db
+}
+
+// useTestDB seperti newTestDB, tetapi juga memasang database tes sebagai database.DB untuk
+// method service yang memakai koneksi global. database.DB dikembalikan setelah tes selesai.
+func useTestDB(t *testing.T, models ...interface{}) *gorm.DB {
+	t.Helper()
+	db := newTestDB(t, models...)
+	previous := database.DB
+	database.DB = db
+	t.Cleanup(func() { database.DB = previous })
+	return db
+}
+
+// assertAmount membandingkan dua nilai rupiah dengan toleransi pembulatan float
+func assertAmount(t *testing.T, label string, got float64, want float64) {
+	t.Helper()
+	if math.Abs(got-want) > 0.0001 {
+		t.Errorf("%s = %.4f, want %.4f", label, got, want)
+	}
+}
+
+// useServiceTestDB memasang database tes berisi seluruh tabel aplikasi dan satu user (rata-rata
+// tertimbang), untuk tes skenario yang melewati method service lengkap. Mengembalikan ID user.
+func useServiceTestDB(t *testing.T) (*gorm.DB, uint) {
+	t.Helper()
+	db := useTestDB(t, database.Models...)
+	user := models.User{Username: "toko", Email: "toko@example.com", PasswordHash: "x", CostingMethod: models.CostingAverage}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	return db, user.ID
+}
+
+// accountBalances menjumlahkan saldo (debit - kredit) seluruh jurnal user per kode akun,
+// dan menggagalkan tes jika total debit dan kredit tidak sama
+func accountBalances(t *testing.T, db *gorm.DB, userID uint) map[string]float64 {
+	t.Helper()
+	var rows []struct {
+		Code    string
+		Balance float64
+	}
+	if err := db.Table("journal_lines").
+		Select("accounts.code, SUM(journal_lines.debit - journal_lines.credit) as balance").
+		Joins("JOIN journal_entries ON journal_entries.id = journal_lines.journal_entry_id").
+		Joins("JOIN accounts ON accounts.id = journal_lines.account_id").
+		Where("journal_entries.user_id = ? AND journal_entries.deleted_at IS NULL", userID).
+		Group("accounts.code").
+		Scan(&rows).Error; err != nil {
+		t.Fatal(err)
+	}
+	balances := make(map[string]float64)
+	var total float64
+	for _, row := range rows {
+		balances[row.Code] = row.Balance
+		total += row.Balance
+	}
+	if math.Abs(total) > 0.0001 {
+		t.Errorf("buku besar tidak seimbang: selisih debit - kredit %.4f", total)
+	}
+	return balances
+}
+
+// assertStock membandingkan stok total produk dan stoknya di lokasi tertentu
+func assertStock(t *testing.T, db *gorm.DB, productID uint, locationID uint, want int, wantAtLocation int) {
+	t.Helper()
+	var product models.Product
+	if err := db.First(&product, productID).Error; err != nil {
+		t.Fatal(err)
+	}
+	var stock models.ProductStock
+	if err := db.Where("product_id = ? AND location_id = ?", productID, locationID).Limit(1).Find(&stock).Error; err != nil {
+		t.Fatal(err)
+	}
+	if product.Stock != want || stock.Quantity != wantAtLocation {
+		t.Errorf("stok %s = %d (lokasi %d: %d), want %d (lokasi: %d)", product.Name, product.Stock, locationID, stock.Quantity, want, wantAtLocation)
+	}
+}
@@ -0,0 +1,251 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/danishyusrah/go_bisnis/internal/database"
+	"github.com/danishyusrah/go_bisnis/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// errPeriodClosed dikembalikan saat perubahan jatuh pada periode akuntansi yang sudah ditutup
+var errPeriodClosed = errors.New("periode akuntansi sudah ditutup")
+
+// periodKeySQL menghitung key periode (tahun*12 + bulan-1) di sisi database,
+// sama dengan models.FiscalPeriodKey
+const periodKeySQL = "(year * 12 + month - 1)"
+
+// FiscalPeriodService adalah struct untuk layanan penutupan periode akuntansi
+type FiscalPeriodService struct{}
+
+// NewFiscalPeriodService membuat instance FiscalPeriodService baru
+func NewFiscalPeriodService() *FiscalPeriodService {
+	return &FiscalPeriodService{}
+}
+
+// periodBounds mengembalikan awal periode dan awal periode berikutnya (eksklusif)
+func periodBounds(year int, month time.Month) (time.Time, time.Time) {
+	start := time.Date(year, month, 1, 0, 0, 0, 0, time.Local)
+	return start, start.AddDate(0, 1, 0)
+}
+
+// ensurePeriodOpen menolak perubahan yang jatuh pada tanggal di dalam periode yang sudah ditutup.
+// Menutup sebuah periode otomatis mengunci semua tanggal sebelumnya.
+func ensurePeriodOpen(tx *gorm.DB, userID uint, date time.Time) error {
+	var count int64
+	err := tx.Model(&models.FiscalPeriod{}).
+		Where("user_id = ? AND status = ? AND "+periodKeySQL+" >= ?", userID, models.PeriodClosed, models.FiscalPeriodKey(date)).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return errPeriodClosed
+	}
+	return nil
+}
+
+// latestClosedPeriodBefore mencari periode tertutup terakhir yang berakhir sebelum 'date'.
+// Mengembalikan nil jika belum ada periode yang ditutup.
+func latestClosedPeriodBefore(tx *gorm.DB, userID uint, date time.Time) (*models.FiscalPeriod, error) {
+	var period models.FiscalPeriod
+	err := tx.Where("user_id = ? AND status = ? AND "+periodKeySQL+" < ?", userID, models.PeriodClosed, models.FiscalPeriodKey(date)).
+		Order("year desc, month desc").
+		First(&period).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &period, nil
+}
+
+// accrualMovement menghitung perubahan saldo buku besar basis akrual untuk
+// transaksi & retur dengan after <= created_at < before (after nil = sejak awal)
+func accrualMovement(tx *gorm.DB, userID uint, locationID *uint, after *time.Time, before time.Time) (float64, error) {
+	transactionQuery := tx.Model(&models.Transaction{}).
+		Scopes(locationScope(locationID)).
+		Select("COALESCE(SUM(CASE WHEN type = ? THEN total_amount WHEN type = ? THEN total_amount WHEN type = ? THEN -total_amount ELSE 0 END), 0) as total",
+			models.Income, models.Capital, models.Expense).
+		Where("user_id = ? AND status <> ? AND created_at < ?", userID, models.StatusVoid, before)
+	refundQuery := tx.Model(&models.Refund{}).
+		Select("COALESCE(SUM(CASE WHEN transactions.type = ? THEN -refunds.amount WHEN transactions.type = ? THEN refunds.amount ELSE 0 END), 0) as total",
+			models.Income, models.Expense).
+		Joins("JOIN transactions ON transactions.id = refunds.transaction_id").
+		Scopes(locationScope(locationID)).
+		Where("refunds.user_id = ? AND transactions.status <> ? AND refunds.created_at < ?", userID, models.StatusVoid, before)
+	if after != nil {
+		transactionQuery = transactionQuery.Where("created_at >= ?", *after)
+		refundQuery = refundQuery.Where("refunds.created_at >= ?", *after)
+	}
+
+	var transactions, refunds sumResult
+	if err := transactionQuery.Scan(&transactions).Error; err != nil {
+		return 0, err
+	}
+	if err := refundQuery.Scan(&refunds).Error; err != nil {
+		return 0, err
+	}
+	return transactions.Total + refunds.Total, nil
+}
+
+// cashMovement menghitung perubahan saldo kas dari pembayaran dengan after <= payment_date < before
+func cashMovement(tx *gorm.DB, userID uint, locationID *uint, after *time.Time, before time.Time) (float64, error) {
+	query := tx.Model(&models.Payment{}).
+		Select("COALESCE(SUM(CASE WHEN transactions.type = ? THEN -payments.amount ELSE payments.amount END), 0) as total", models.Expense).
+		Joins("JOIN transactions ON transactions.id = payments.transaction_id").
+		Scopes(locationScope(locationID)).
+		Where("payments.user_id = ? AND transactions.status <> ? AND transactions.deleted_at IS NULL AND payments.payment_date < ?", userID, models.StatusVoid, before)
+	if after != nil {
+		query = query.Where("payments.payment_date >= ?", *after)
+	}
+	var result sumResult
+	err := query.Scan(&result).Error
+	return result.Total, err
+}
+
+// ledgerBalanceBefore menghitung saldo buku besar sesaat sebelum 'before'.
+// Jika ada periode tertutup sebelumnya, snapshot saldo akhirnya dipakai sebagai titik awal
+// sehingga hanya transaksi setelah periode tersebut yang perlu dijumlahkan.
+// [DIUBAH] locationID diisi = saldo transaksi satu lokasi; snapshot periode adalah saldo konsolidasi,
+// jadi untuk satu lokasi seluruh riwayat dijumlahkan.
+func ledgerBalanceBefore(tx *gorm.DB, userID uint, locationID *uint, basis string, before time.Time) (float64, error) {
+	var period *models.FiscalPeriod
+	var err error
+	if locationID == nil {
+		period, err = latestClosedPeriodBefore(tx, userID, before)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	var base float64
+	var after *time.Time
+	if period != nil {
+		_, nextStart := periodBounds(period.Year, time.Month(period.Month))
+		after = &nextStart
+		base = period.AccrualEndingBalance
+		if basis == LedgerBasisCash {
+			base = period.CashEndingBalance
+		}
+	}
+
+	var movement float64
+	if basis == LedgerBasisCash {
+		movement, err = cashMovement(tx, userID, locationID, after, before)
+	} else {
+		movement, err = accrualMovement(tx, userID, locationID, after, before)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return base + movement, nil
+}
+
+// GetPeriods mengambil semua periode akuntansi milik user (terbaru di atas)
+func (s *FiscalPeriodService) GetPeriods(userID uint) ([]models.FiscalPeriod, error) {
+	var periods []models.FiscalPeriod
+	err := database.DB.Where("user_id = ?", userID).Order("year desc, month desc").Find(&periods).Error
+	return periods, err
+}
+
+// ClosePeriod menutup satu bulan akuntansi dan menyimpan snapshot saldo akhirnya
+func (s *FiscalPeriodService) ClosePeriod(userID uint, year int, month int) (models.FiscalPeriod, error) {
+	db := database.DB
+	if month < 1 || month > 12 {
+		return models.FiscalPeriod{}, errors.New("bulan tidak valid")
+	}
+	start, nextStart := periodBounds(year, time.Month(month))
+	if nextStart.After(time.Now()) {
+		return models.FiscalPeriod{}, errors.New("periode yang belum berakhir tidak dapat ditutup")
+	}
+
+	var period models.FiscalPeriod
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ? AND year = ? AND month = ?", userID, year, month).
+			First(&period).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if period.Status == models.PeriodClosed {
+			return errors.New("periode ini sudah ditutup")
+		}
+		// Periode yang sudah terkunci oleh penutupan periode setelahnya tidak perlu ditutup lagi
+		if err := ensurePeriodOpen(tx, userID, start); err != nil {
+			if errors.Is(err, errPeriodClosed) {
+				return errors.New("periode ini sudah terkunci oleh periode setelahnya")
+			}
+			return err
+		}
+
+		accrual, err := ledgerBalanceBefore(tx, userID, nil, LedgerBasisAccrual, nextStart)
+		if err != nil {
+			return err
+		}
+		cash, err := ledgerBalanceBefore(tx, userID, nil, LedgerBasisCash, nextStart)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		period.UserID = userID
+		period.Year = year
+		period.Month = month
+		period.StartDate = start
+		period.EndDate = nextStart.AddDate(0, 0, -1)
+		period.Status = models.PeriodClosed
+		period.ClosedAt = &now
+		period.AccrualEndingBalance = accrual
+		period.CashEndingBalance = cash
+		return tx.Save(&period).Error
+	})
+	if err != nil {
+		log.Printf("Error closing fiscal period %d-%02d for user %d: %v", year, month, userID, err)
+		return models.FiscalPeriod{}, err
+	}
+	return period, nil
+}
+
+// ReopenPeriod membuka kembali periode yang sudah ditutup.
+// Hanya periode tertutup terakhir yang bisa dibuka agar snapshot periode lain tetap valid.
+func (s *FiscalPeriodService) ReopenPeriod(userID uint, year int, month int) (models.FiscalPeriod, error) {
+	db := database.DB
+	var period models.FiscalPeriod
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ? AND year = ? AND month = ?", userID, year, month).
+			First(&period).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("periode tidak ditemukan")
+			}
+			return err
+		}
+		if period.Status != models.PeriodClosed {
+			return errors.New("periode ini belum ditutup")
+		}
+
+		var laterClosed int64
+		if err := tx.Model(&models.FiscalPeriod{}).
+			Where("user_id = ? AND status = ? AND "+periodKeySQL+" > ?", userID, models.PeriodClosed, period.Key()).
+			Count(&laterClosed).Error; err != nil {
+			return err
+		}
+		if laterClosed > 0 {
+			return errors.New("buka kembali periode setelahnya terlebih dahulu")
+		}
+
+		period.Status = models.PeriodOpen
+		period.ClosedAt = nil
+		return tx.Save(&period).Error
+	})
+	if err != nil {
+		return models.FiscalPeriod{}, err
+	}
+	return period, nil
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/danishyusrah/go_bisnis/internal/dto"
+	"github.com/danishyusrah/go_bisnis/internal/models"
+)
+
+func TestClosedPeriodRejectsChanges(t *testing.T) {
+	db, userID := useServiceTestDB(t)
+	products := NewProductService()
+	transactions := NewTransactionService()
+	periods := NewFiscalPeriodService()
+	date := func(value string) *string { return &value }
+
+	teh, err := products.CreateProduct(dto.CreateProductInput{Name: "Teh", SKU: "TEH", PurchasePrice: 500, SellingPrice: 800, Stock: 20}, userID)
+	if err != nil {
+		t.Fatalf("CreateProduct() error = %v", err)
+	}
+	capital, err := transactions.CreateTransaction(dto.CreateTransactionInput{
+		Type: models.Capital, TotalAmount: 1000000, TransactionDate: date("2024-01-10"),
+	}, userID)
+	if err != nil {
+		t.Fatalf("CreateTransaction() modal error = %v", err)
+	}
+	sale, err := transactions.CreateTransaction(dto.CreateTransactionInput{
+		Type:            models.Income,
+		TransactionDate: date("2024-01-20"),
+		Items:           []dto.CreateTransactionItemInput{{ProductID: &teh.ID, ProductName: "Teh", Quantity: 5, UnitPrice: 800}},
+	}, userID)
+	if err != nil {
+		t.Fatalf("CreateTransaction() penjualan error = %v", err)
+	}
+
+	// Menutup Februari ikut mengunci Januari, dan snapshot saldo mencakup transaksi Januari
+	february, err := periods.ClosePeriod(userID, 2024, 2)
+	if err != nil {
+		t.Fatalf("ClosePeriod() error = %v", err)
+	}
+	assertAmount(t, "saldo akhir kas Februari", february.CashEndingBalance, 1004000)
+	if _, err := periods.ClosePeriod(userID, 2024, 1); err == nil || !strings.Contains(err.Error(), "terkunci") {
+		t.Errorf("ClosePeriod() Januari error = %v, want periode sudah terkunci", err)
+	}
+
+	// Transaksi baru, void dan retur pada tanggal terkunci ditolak tanpa mengubah stok
+	if _, err := transactions.CreateTransaction(dto.CreateTransactionInput{
+		Type: models.Capital, TotalAmount: 50000, TransactionDate: date("2024-01-31"),
+	}, userID); !errors.Is(err, errPeriodClosed) {
+		t.Errorf("CreateTransaction() bertanggal Januari error = %v, want %v", err, errPeriodClosed)
+	}
+	if _, err := transactions.VoidTransaction(capital.ID, userID, dto.VoidTransactionInput{Reason: "Salah"}); !errors.Is(err, errPeriodClosed) {
+		t.Errorf("VoidTransaction() error = %v, want %v", err, errPeriodClosed)
+	}
+	if _, err := transactions.RefundTransaction(sale.ID, userID, dto.RefundTransactionInput{
+		Items: []dto.RefundItemInput{{TransactionItemID: sale.Items[0].ID, Quantity: 2}},
+	}); !errors.Is(err, errPeriodClosed) {
+		t.Errorf("RefundTransaction() error = %v, want %v", err, errPeriodClosed)
+	}
+	location, err := defaultLocation(db, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertStock(t, db, teh.ID, location.ID, 15, 15)
+
+	// Tanggal setelah periode tertutup tetap bisa dicatat
+	if _, err := transactions.CreateTransaction(dto.CreateTransactionInput{
+		Type: models.Capital, TotalAmount: 50000, TransactionDate: date("2024-03-01"),
+	}, userID); err != nil {
+		t.Errorf("CreateTransaction() bertanggal Maret error = %v", err)
+	}
+
+	// Setelah Februari dibuka kembali, retur Januari diterima
+	if _, err := periods.ReopenPeriod(userID, 2024, 1); err == nil {
+		t.Error("ReopenPeriod() Januari yang tidak pernah ditutup berhasil, want error")
+	}
+	if _, err := periods.ReopenPeriod(userID, 2024, 2); err != nil {
+		t.Fatalf("ReopenPeriod() error = %v", err)
+	}
+	if _, err := transactions.RefundTransaction(sale.ID, userID, dto.RefundTransactionInput{
+		Items: []dto.RefundItemInput{{TransactionItemID: sale.Items[0].ID, Quantity: 2}},
+	}); err != nil {
+		t.Fatalf("RefundTransaction() setelah dibuka error = %v", err)
+	}
+	assertStock(t, db, teh.ID, location.ID, 17, 17)
+}
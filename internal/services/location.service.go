@@ -0,0 +1,342 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/danishyusrah/go_bisnis/internal/database"
+	"github.com/danishyusrah/go_bisnis/internal/dto"
+	"github.com/danishyusrah/go_bisnis/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// --- [BARU] Multi lokasi (gudang/outlet) & stok per lokasi ---
+//
+// Product.Stock tetap menyimpan total stok seluruh lokasi (dipakai penilaian persediaan, batch & neraca),
+// sedangkan rincian per lokasi ada di tabel product_stocks. Setiap perubahan stok melewati
+// changeLocationStock sehingga keduanya selalu sinkron.
+
+// defaultLocationName adalah nama lokasi default yang dibuat otomatis
+const defaultLocationName = "Lokasi Utama"
+
+// defaultLocation mengambil lokasi default user, dan membuatnya jika belum ada
+func defaultLocation(tx *gorm.DB, userID uint) (models.Location, error) {
+	var location models.Location
+	if err := tx.Where("user_id = ? AND is_default = ?", userID, true).Order("id asc").Limit(1).Find(&location).Error; err != nil {
+		return models.Location{}, err
+	}
+	if location.ID != 0 {
+		return location, nil
+	}
+	location = models.Location{UserID: userID, Name: defaultLocationName, IsDefault: true}
+	if err := tx.Create(&location).Error; err != nil {
+		return models.Location{}, errors.New("gagal membuat lokasi default")
+	}
+	return location, nil
+}
+
+// findOwnedLocation mengambil lokasi dan memvalidasi kepemilikan
+func findOwnedLocation(tx *gorm.DB, locationID uint, userID uint) (models.Location, error) {
+	var location models.Location
+	if err := tx.First(&location, locationID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Location{}, errors.New("lokasi tidak ditemukan")
+		}
+		return models.Location{}, err
+	}
+	if location.UserID != userID {
+		return models.Location{}, errors.New("akses ditolak: Anda bukan pemilik lokasi ini")
+	}
+	return location, nil
+}
+
+// resolveLocation memvalidasi lokasi pilihan user; nil berarti lokasi default
+func resolveLocation(tx *gorm.DB, userID uint, locationID *uint) (uint, error) {
+	if locationID == nil {
+		location, err := defaultLocation(tx, userID)
+		return location.ID, err
+	}
+	location, err := findOwnedLocation(tx, *locationID, userID)
+	return location.ID, err
+}
+
+// changeLocationStock mengubah stok produk di satu lokasi sebesar delta (baris dikunci FOR UPDATE).
+// Stok lokasi tidak boleh menjadi negatif. Mengembalikan stok lokasi setelah perubahan.
+func changeLocationStock(tx *gorm.DB, product *models.Product, locationID uint, delta int) (int, error) {
+	var stock models.ProductStock
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("product_id = ? AND location_id = ?", product.ID, locationID).
+		Limit(1).Find(&stock).Error; err != nil {
+		return 0, err
+	}
+	if delta == 0 {
+		return stock.Quantity, nil
+	}
+	// [BARU] Stok produk komposit dihitung dari bahannya, tidak pernah disimpan
+	if product.IsComposite {
+		return 0, fmt.Errorf("produk %s adalah produk komposit, stoknya mengikuti stok bahan", product.Name)
+	}
+	// [BARU] Produk induk tidak menyimpan stok sendiri; stok selalu dicatat pada variannya
+	if product.ParentID == nil {
+		hasVariants, err := productHasVariants(tx, product.ID)
+		if err != nil {
+			return 0, err
+		}
+		if hasVariants {
+			return 0, fmt.Errorf("produk %s memiliki varian, pilih varian yang akan diproses", product.Name)
+		}
+	}
+	newQuantity := stock.Quantity + delta
+	if newQuantity < 0 {
+		return 0, fmt.Errorf("stok tidak cukup untuk produk: %s di lokasi ini (sisa: %d)", product.Name, stock.Quantity)
+	}
+	if stock.ID == 0 {
+		stock = models.ProductStock{UserID: product.UserID, ProductID: product.ID, LocationID: locationID}
+	}
+	stock.Quantity = newQuantity
+	if err := tx.Save(&stock).Error; err != nil {
+		return 0, errors.New("gagal memperbarui stok lokasi")
+	}
+	return newQuantity, nil
+}
+
+// locationScope membatasi query yang melibatkan tabel transactions ke satu lokasi (nil = semua lokasi).
+// Dipakai oleh laporan operasional & buku besar; neraca tetap konsolidasi seluruh lokasi.
+func locationScope(locationID *uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if locationID == nil {
+			return db
+		}
+		return db.Where("transactions.location_id = ?", *locationID)
+	}
+}
+
+// LocationService adalah struct untuk layanan lokasi & mutasi stok antar lokasi
+type LocationService struct{}
+
+// NewLocationService membuat instance LocationService baru
+func NewLocationService() *LocationService {
+	return &LocationService{}
+}
+
+// GetLocations mengambil semua lokasi milik user (lokasi default dibuat jika belum ada)
+func (s *LocationService) GetLocations(userID uint) ([]models.Location, error) {
+	if _, err := defaultLocation(database.DB, userID); err != nil {
+		return nil, err
+	}
+	var locations []models.Location
+	err := database.DB.Where("user_id = ?", userID).Order("is_default desc, name asc").Find(&locations).Error
+	return locations, err
+}
+
+// saveLocation menyimpan lokasi; hanya boleh ada satu lokasi default per user
+func saveLocation(location *models.Location) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if location.IsDefault {
+			if err := tx.Model(&models.Location{}).
+				Where("user_id = ? AND id <> ?", location.UserID, location.ID).
+				Update("is_default", false).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Save(location).Error
+	})
+}
+
+// CreateLocation membuat lokasi baru
+func (s *LocationService) CreateLocation(input dto.CreateLocationInput, userID uint) (models.Location, error) {
+	location := models.Location{
+		UserID:    userID,
+		Name:      input.Name,
+		Address:   input.Address,
+		IsDefault: input.IsDefault,
+	}
+	if err := saveLocation(&location); err != nil {
+		return models.Location{}, err
+	}
+	return location, nil
+}
+
+// UpdateLocation memperbarui lokasi. Lokasi default hanya bisa diganti dengan menjadikan lokasi lain default.
+func (s *LocationService) UpdateLocation(locationID uint, input dto.CreateLocationInput, userID uint) (models.Location, error) {
+	location, err := findOwnedLocation(database.DB, locationID, userID)
+	if err != nil {
+		return models.Location{}, err
+	}
+	if location.IsDefault && !input.IsDefault {
+		return models.Location{}, errors.New("jadikan lokasi lain sebagai default terlebih dahulu")
+	}
+	location.Name = input.Name
+	location.Address = input.Address
+	location.IsDefault = input.IsDefault
+	if err := saveLocation(&location); err != nil {
+		return models.Location{}, err
+	}
+	return location, nil
+}
+
+// DeleteLocation menghapus lokasi yang bukan default dan sudah tidak memiliki stok
+func (s *LocationService) DeleteLocation(locationID uint, userID uint) error {
+	location, err := findOwnedLocation(database.DB, locationID, userID)
+	if err != nil {
+		return err
+	}
+	if location.IsDefault {
+		return errors.New("lokasi default tidak dapat dihapus")
+	}
+	var count int64
+	if err := database.DB.Model(&models.ProductStock{}).
+		Where("location_id = ? AND quantity <> 0", location.ID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("lokasi masih memiliki stok, pindahkan stok terlebih dahulu")
+	}
+	return database.DB.Delete(&location).Error
+}
+
+// productStockResponses mengubah baris stok per lokasi (dengan relasi) menjadi DTO
+func productStockResponses(stocks []models.ProductStock) []dto.ProductStockResponse {
+	responses := []dto.ProductStockResponse{}
+	for _, stock := range stocks {
+		responses = append(responses, dto.ProductStockResponse{
+			ProductID:    stock.ProductID,
+			ProductName:  stock.Product.Name,
+			LocationID:   stock.LocationID,
+			LocationName: stock.Location.Name,
+			Quantity:     stock.Quantity,
+		})
+	}
+	return responses
+}
+
+// GetProductStocks mengambil rincian stok satu produk di setiap lokasi
+func (s *LocationService) GetProductStocks(productID uint, userID uint) ([]dto.ProductStockResponse, error) {
+	if _, err := NewProductService().GetProductByID(productID, userID); err != nil {
+		return nil, err
+	}
+	var stocks []models.ProductStock
+	if err := database.DB.Preload("Product").Preload("Location").
+		Joins("JOIN locations ON locations.id = product_stocks.location_id AND locations.deleted_at IS NULL").
+		Where("product_stocks.product_id = ?", productID).
+		Order("locations.is_default desc, locations.name asc").
+		Find(&stocks).Error; err != nil {
+		return nil, err
+	}
+	return productStockResponses(stocks), nil
+}
+
+// GetLocationStocks mengambil stok seluruh produk di satu lokasi
+func (s *LocationService) GetLocationStocks(locationID uint, userID uint) ([]dto.ProductStockResponse, error) {
+	if _, err := findOwnedLocation(database.DB, locationID, userID); err != nil {
+		return nil, err
+	}
+	var stocks []models.ProductStock
+	if err := database.DB.Preload("Product").Preload("Location").
+		Joins("JOIN products ON products.id = product_stocks.product_id AND products.deleted_at IS NULL").
+		Where("product_stocks.location_id = ?", locationID).
+		Order("products.name asc").
+		Find(&stocks).Error; err != nil {
+		return nil, err
+	}
+	return productStockResponses(stocks), nil
+}
+
+// --- Mutasi stok antar lokasi ---
+
+// GetStockTransfers mengambil semua dokumen mutasi stok milik user
+func (s *LocationService) GetStockTransfers(userID uint) ([]models.StockTransfer, error) {
+	var transfers []models.StockTransfer
+	err := database.DB.Preload("FromLocation").Preload("ToLocation").Preload("Items").
+		Where("user_id = ?", userID).
+		Order("transfer_date desc, id desc").
+		Find(&transfers).Error
+	return transfers, err
+}
+
+// GetStockTransferByID mengambil satu dokumen mutasi stok dan memvalidasi kepemilikan
+func (s *LocationService) GetStockTransferByID(transferID uint, userID uint) (models.StockTransfer, error) {
+	var transfer models.StockTransfer
+	if err := database.DB.Preload("FromLocation").Preload("ToLocation").Preload("Items").First(&transfer, transferID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.StockTransfer{}, errors.New("mutasi stok tidak ditemukan")
+		}
+		return models.StockTransfer{}, err
+	}
+	if transfer.UserID != userID {
+		return models.StockTransfer{}, errors.New("akses ditolak: Anda bukan pemilik mutasi stok ini")
+	}
+	return transfer, nil
+}
+
+// CreateStockTransfer memindahkan stok antar lokasi secara atomik. Total stok produk (dan nilai
+// persediaannya) tidak berubah; kartu stok mencatat satu baris keluar dan satu baris masuk per item.
+func (s *LocationService) CreateStockTransfer(input dto.CreateStockTransferInput, userID uint) (models.StockTransfer, error) {
+	if input.FromLocationID == input.ToLocationID {
+		return models.StockTransfer{}, errors.New("lokasi asal dan tujuan tidak boleh sama")
+	}
+	transferDate, err := parseTransactionDate(input.TransferDate)
+	if err != nil {
+		return models.StockTransfer{}, err
+	}
+
+	var transfer models.StockTransfer
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		from, err := findOwnedLocation(tx, input.FromLocationID, userID)
+		if err != nil {
+			return err
+		}
+		to, err := findOwnedLocation(tx, input.ToLocationID, userID)
+		if err != nil {
+			return err
+		}
+
+		transfer = models.StockTransfer{
+			UserID:         userID,
+			FromLocationID: from.ID,
+			ToLocationID:   to.ID,
+			TransferDate:   transferDate,
+			Notes:          input.Notes,
+		}
+		var movements []models.StockMovement
+		for _, item := range input.Items {
+			product, err := lockOwnedProduct(tx, item.ProductID, userID)
+			if err != nil {
+				return err
+			}
+			if _, err := changeLocationStock(tx, &product, from.ID, -item.Quantity); err != nil {
+				return err
+			}
+			if _, err := changeLocationStock(tx, &product, to.ID, item.Quantity); err != nil {
+				return err
+			}
+			transfer.Items = append(transfer.Items, models.StockTransferItem{
+				ProductID:   product.ID,
+				ProductName: product.Name,
+				Quantity:    item.Quantity,
+			})
+
+			out := newStockMovement(userID, product.ID, -item.Quantity, product.Stock, models.MovementTransfer, nil, "Mutasi ke "+to.Name)
+			out.LocationID = &from.ID
+			in := newStockMovement(userID, product.ID, item.Quantity, product.Stock, models.MovementTransfer, nil, "Mutasi dari "+from.Name)
+			in.LocationID = &to.ID
+			movements = append(movements, out, in)
+		}
+
+		if err := tx.Create(&transfer).Error; err != nil {
+			return errors.New("gagal menyimpan mutasi stok")
+		}
+		if err := tx.Create(&movements).Error; err != nil {
+			return errors.New("gagal mencatat kartu stok")
+		}
+		return nil
+	})
+	if err != nil {
+		return models.StockTransfer{}, err
+	}
+	return s.GetStockTransferByID(transfer.ID, userID)
+}
+
+// --- [AKHIR BARU] ---
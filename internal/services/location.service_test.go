@@ -0,0 +1,95 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/danishyusrah/go_bisnis/internal/dto"
+	"github.com/danishyusrah/go_bisnis/internal/models"
+)
+
+func TestStockTransferBetweenLocations(t *testing.T) {
+	db, userID := useServiceTestDB(t)
+	products := NewProductService()
+	locations := NewLocationService()
+	transactions := NewTransactionService()
+
+	roti, err := products.CreateProduct(dto.CreateProductInput{Name: "Roti", SKU: "ROTI", PurchasePrice: 3000, SellingPrice: 5000, Stock: 10}, userID)
+	if err != nil {
+		t.Fatalf("CreateProduct() error = %v", err)
+	}
+	warehouse, err := defaultLocation(db, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outlet, err := locations.CreateLocation(dto.CreateLocationInput{Name: "Outlet Pasar"}, userID)
+	if err != nil {
+		t.Fatalf("CreateLocation() error = %v", err)
+	}
+	inventoryBefore := accountBalances(t, db, userID)[models.AccountCodeInventory]
+
+	// Mutasi memindahkan stok antar lokasi tanpa mengubah stok total maupun jurnal
+	transfer, err := locations.CreateStockTransfer(dto.CreateStockTransferInput{
+		FromLocationID: warehouse.ID, ToLocationID: outlet.ID,
+		Items: []dto.StockTransferItemInput{{ProductID: roti.ID, Quantity: 4}},
+	}, userID)
+	if err != nil {
+		t.Fatalf("CreateStockTransfer() error = %v", err)
+	}
+	if len(transfer.Items) != 1 || transfer.Items[0].Quantity != 4 {
+		t.Errorf("item mutasi = %+v, want 1 item berjumlah 4", transfer.Items)
+	}
+	assertStock(t, db, roti.ID, warehouse.ID, 10, 6)
+	assertStock(t, db, roti.ID, outlet.ID, 10, 4)
+	assertAmount(t, "persediaan setelah mutasi", accountBalances(t, db, userID)[models.AccountCodeInventory], inventoryBefore)
+
+	var movements []models.StockMovement
+	if err := db.Where("product_id = ? AND reason = ?", roti.ID, models.MovementTransfer).Order("id asc").Find(&movements).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(movements) != 2 || movements[0].Quantity != -4 || *movements[0].LocationID != warehouse.ID ||
+		movements[1].Quantity != 4 || *movements[1].LocationID != outlet.ID {
+		t.Errorf("kartu stok mutasi = %+v, want -4 di gudang dan +4 di outlet", movements)
+	}
+
+	// Penjualan di outlet hanya boleh memakai stok outlet, meskipun stok total masih cukup
+	sellAtOutlet := func(quantity float64) (models.Transaction, error) {
+		return transactions.CreateTransaction(dto.CreateTransactionInput{
+			Type: models.Income, LocationID: &outlet.ID,
+			Items: []dto.CreateTransactionItemInput{{ProductID: &roti.ID, ProductName: "Roti", Quantity: quantity, UnitPrice: 5000}},
+		}, userID)
+	}
+	if _, err := sellAtOutlet(5); err == nil || !strings.Contains(err.Error(), "di lokasi ini") {
+		t.Fatalf("CreateTransaction() error = %v, want stok lokasi tidak cukup", err)
+	}
+	sale, err := sellAtOutlet(3)
+	if err != nil {
+		t.Fatalf("CreateTransaction() error = %v", err)
+	}
+	assertStock(t, db, roti.ID, outlet.ID, 7, 1)
+
+	// Retur kembali ke lokasi penjualan
+	if _, err := transactions.RefundTransaction(sale.ID, userID, dto.RefundTransactionInput{
+		Items: []dto.RefundItemInput{{TransactionItemID: sale.Items[0].ID, Quantity: 2}},
+	}); err != nil {
+		t.Fatalf("RefundTransaction() error = %v", err)
+	}
+	assertStock(t, db, roti.ID, outlet.ID, 9, 3)
+	assertStock(t, db, roti.ID, warehouse.ID, 9, 6)
+
+	// Mutasi yang melebihi stok lokasi asal ditolak seluruhnya, begitu pula lokasi yang sama
+	if _, err := locations.CreateStockTransfer(dto.CreateStockTransferInput{
+		FromLocationID: outlet.ID, ToLocationID: warehouse.ID,
+		Items: []dto.StockTransferItemInput{{ProductID: roti.ID, Quantity: 4}},
+	}, userID); err == nil || !strings.Contains(err.Error(), "stok tidak cukup") {
+		t.Errorf("CreateStockTransfer() error = %v, want stok tidak cukup", err)
+	}
+	if _, err := locations.CreateStockTransfer(dto.CreateStockTransferInput{
+		FromLocationID: outlet.ID, ToLocationID: outlet.ID,
+		Items: []dto.StockTransferItemInput{{ProductID: roti.ID, Quantity: 1}},
+	}, userID); err == nil {
+		t.Error("CreateStockTransfer() ke lokasi yang sama berhasil, want error")
+	}
+	assertStock(t, db, roti.ID, outlet.ID, 9, 3)
+	assertStock(t, db, roti.ID, warehouse.ID, 9, 6)
+}
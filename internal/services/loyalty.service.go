@@ -0,0 +1,580 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/danishyusrah/go_bisnis/internal/database"
+	"github.com/danishyusrah/go_bisnis/internal/dto"
+	"github.com/danishyusrah/go_bisnis/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// loyaltyExpiryWindow adalah rentang "akan hangus" pada saldo poin & laporan liabilitas
+const loyaltyExpiryWindow = 30 * 24 * time.Hour
+
+// LoyaltyService adalah struct untuk layanan program poin loyalitas pelanggan
+type LoyaltyService struct{}
+
+// NewLoyaltyService membuat instance LoyaltyService baru
+func NewLoyaltyService() *LoyaltyService {
+	return &LoyaltyService{}
+}
+
+// findLoyaltyProgram mengambil pengaturan program poin user. Jika belum pernah diatur,
+// dikembalikan program nonaktif (belum tersimpan).
+func findLoyaltyProgram(tx *gorm.DB, userID uint) (models.LoyaltyProgram, error) {
+	var program models.LoyaltyProgram
+	err := tx.Where("user_id = ?", userID).First(&program).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.LoyaltyProgram{UserID: userID}, nil
+	}
+	return program, err
+}
+
+// loyaltyExpiryFor menghitung tanggal kedaluwarsa poin yang diperoleh pada 'earnedAt'
+func loyaltyExpiryFor(program models.LoyaltyProgram, earnedAt time.Time) *time.Time {
+	if program.ExpiryMonths <= 0 {
+		return nil
+	}
+	expiresAt := earnedAt.AddDate(0, program.ExpiryMonths, 0)
+	return &expiresAt
+}
+
+// activeLoyaltyLots membatasi query ke lot poin yang masih punya sisa dan belum kedaluwarsa pada 'at'
+func activeLoyaltyLots(at time.Time) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("remaining_points > 0 AND (expires_at IS NULL OR expires_at > ?)", at)
+	}
+}
+
+// postLoyaltyJournal mencatat perubahan liabilitas poin. Nilai positif menambah liabilitas
+// (Beban Program Loyalitas D - Liabilitas Poin K), nilai negatif melepasnya.
+func postLoyaltyJournal(tx *gorm.DB, userID uint, date time.Time, description string, transactionID *uint, value float64) error {
+	return postJournal(tx, userID, date, description, models.JournalSourceLoyalty, transactionID, []journalLineInput{
+		{Code: models.AccountCodeLoyaltyExpense, Debit: value},
+		{Code: models.AccountCodeLoyaltyLiability, Credit: value},
+	})
+}
+
+// addLoyaltyLot menyimpan entri poin positif sebagai lot baru yang bisa dipakai/hangus
+func addLoyaltyLot(tx *gorm.DB, entry models.LoyaltyLedgerEntry) error {
+	entry.RemainingPoints = entry.Points
+	if err := tx.Create(&entry).Error; err != nil {
+		return errors.New("gagal mencatat mutasi poin")
+	}
+	return nil
+}
+
+// takeFromLoyaltyLots mengurangi sisa poin lot hasil 'query' (sudah terurut) hingga 'points'.
+// Mengembalikan jumlah poin yang berhasil diambil dan nilai tercatatnya.
+func takeFromLoyaltyLots(tx *gorm.DB, query *gorm.DB, points int) (int, float64, error) {
+	var lots []models.LoyaltyLedgerEntry
+	if err := query.Clauses(clause.Locking{Strength: "UPDATE"}).Find(&lots).Error; err != nil {
+		return 0, 0, err
+	}
+	taken := 0
+	var value float64
+	for _, lot := range lots {
+		if taken >= points {
+			break
+		}
+		take := lot.RemainingPoints
+		if take > points-taken {
+			take = points - taken
+		}
+		if err := tx.Model(&models.LoyaltyLedgerEntry{}).Where("id = ?", lot.ID).
+			Update("remaining_points", lot.RemainingPoints-take).Error; err != nil {
+			return 0, 0, err
+		}
+		taken += take
+		value += lot.UnitValue() * float64(take)
+	}
+	return taken, roundAmount(value), nil
+}
+
+// consumeLoyaltyPoints memakai poin pelanggan secara FIFO (yang paling cepat hangus lebih dulu).
+// Jika allowPartial = false, gagal bila saldo tidak cukup.
+func consumeLoyaltyPoints(tx *gorm.DB, customerID uint, points int, at time.Time, allowPartial bool) (int, float64, error) {
+	query := tx.Scopes(activeLoyaltyLots(at)).Where("customer_id = ?", customerID).
+		Order("expires_at IS NULL, expires_at asc, id asc")
+	taken, value, err := takeFromLoyaltyLots(tx, query, points)
+	if err != nil {
+		return 0, 0, errors.New("gagal memakai poin pelanggan")
+	}
+	if taken < points && !allowPartial {
+		return 0, 0, fmt.Errorf("poin pelanggan tidak cukup (sisa: %d)", taken)
+	}
+	return taken, value, nil
+}
+
+// customerPointBalance menghitung saldo poin pelanggan yang masih berlaku pada 'at'
+func customerPointBalance(tx *gorm.DB, customerID uint, at time.Time) (int, error) {
+	var total struct{ Total int }
+	err := tx.Model(&models.LoyaltyLedgerEntry{}).Select("COALESCE(SUM(remaining_points), 0) as total").
+		Scopes(activeLoyaltyLots(at)).Where("customer_id = ?", customerID).Scan(&total).Error
+	return total.Total, err
+}
+
+// expireLoyaltyPoints menghanguskan lot poin yang sudah lewat masa berlaku (semua pelanggan user,
+// atau satu pelanggan jika customerID diisi) dan melepas liabilitasnya
+func expireLoyaltyPoints(tx *gorm.DB, userID uint, customerID *uint, now time.Time) (dto.ExpireLoyaltyResult, error) {
+	var result dto.ExpireLoyaltyResult
+	query := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND remaining_points > 0 AND expires_at IS NOT NULL AND expires_at <= ?", userID, now)
+	if customerID != nil {
+		query = query.Where("customer_id = ?", *customerID)
+	}
+	var lots []models.LoyaltyLedgerEntry
+	if err := query.Order("customer_id asc, id asc").Find(&lots).Error; err != nil {
+		return result, err
+	}
+
+	for _, lot := range lots {
+		value := roundAmount(lot.UnitValue() * float64(lot.RemainingPoints))
+		expired := models.LoyaltyLedgerEntry{
+			UserID:        lot.UserID,
+			CustomerID:    lot.CustomerID,
+			TransactionID: lot.TransactionID,
+			Type:          models.LoyaltyExpire,
+			EntryDate:     now,
+			Points:        -lot.RemainingPoints,
+			Value:         -value,
+			Notes:         fmt.Sprintf("Poin hangus dari mutasi #%d (berlaku s/d %s)", lot.ID, lot.ExpiresAt.Format("2006-01-02")),
+		}
+		if err := tx.Create(&expired).Error; err != nil {
+			return result, errors.New("gagal mencatat poin hangus")
+		}
+		if err := tx.Model(&models.LoyaltyLedgerEntry{}).Where("id = ?", lot.ID).Update("remaining_points", 0).Error; err != nil {
+			return result, err
+		}
+		result.ExpiredPoints += lot.RemainingPoints
+		result.ExpiredValue += value
+	}
+
+	result.ExpiredValue = roundAmount(result.ExpiredValue)
+	if result.ExpiredPoints > 0 {
+		if err := postLoyaltyJournal(tx, userID, now, "Poin loyalitas hangus", nil, -result.ExpiredValue); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// prepareLoyaltyRedemption memvalidasi penukaran poin sebelum transaksi dihitung,
+// lalu mengembalikan program poin dan nilai potongannya
+func prepareLoyaltyRedemption(tx *gorm.DB, userID uint, customerID uint, points int, at time.Time) (*models.LoyaltyProgram, float64, error) {
+	program, err := findLoyaltyProgram(tx, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !program.IsActive || program.PointValue <= 0 {
+		return nil, 0, errors.New("program poin loyalitas tidak aktif")
+	}
+	if points < program.MinRedeemPoints {
+		return nil, 0, fmt.Errorf("penukaran minimal %d poin", program.MinRedeemPoints)
+	}
+
+	if _, err := expireLoyaltyPoints(tx, userID, &customerID, time.Now()); err != nil {
+		return nil, 0, err
+	}
+	balance, err := customerPointBalance(tx, customerID, at)
+	if err != nil {
+		return nil, 0, err
+	}
+	if balance < points {
+		return nil, 0, fmt.Errorf("poin pelanggan tidak cukup (sisa: %d)", balance)
+	}
+	return &program, roundAmount(float64(points) * program.PointValue), nil
+}
+
+// recordTransactionLoyalty mencatat poin yang ditukar dan poin yang diperoleh dari penjualan ber-pelanggan.
+// Poin diperoleh dari total akhir transaksi (setelah semua potongan, termasuk potongan poin).
+func recordTransactionLoyalty(tx *gorm.DB, transaction *models.Transaction, program *models.LoyaltyProgram) error {
+	if program == nil {
+		loaded, err := findLoyaltyProgram(tx, transaction.UserID)
+		if err != nil {
+			return err
+		}
+		program = &loaded
+	}
+	customerID := *transaction.CustomerID
+
+	if transaction.LoyaltyPointsRedeemed > 0 {
+		_, value, err := consumeLoyaltyPoints(tx, customerID, transaction.LoyaltyPointsRedeemed, transaction.CreatedAt, false)
+		if err != nil {
+			return err
+		}
+		redeemed := models.LoyaltyLedgerEntry{
+			UserID:        transaction.UserID,
+			CustomerID:    customerID,
+			TransactionID: &transaction.ID,
+			Type:          models.LoyaltyRedeem,
+			EntryDate:     transaction.CreatedAt,
+			Points:        -transaction.LoyaltyPointsRedeemed,
+			Value:         -value,
+			Notes:         fmt.Sprintf("Ditukar sebagai potongan %.2f di transaksi #%d", transaction.LoyaltyDiscountAmount, transaction.ID),
+		}
+		if err := tx.Create(&redeemed).Error; err != nil {
+			return errors.New("gagal mencatat penukaran poin")
+		}
+		description := fmt.Sprintf("Penukaran poin transaksi #%d", transaction.ID)
+		if err := postLoyaltyJournal(tx, transaction.UserID, transaction.CreatedAt, description, &transaction.ID, -value); err != nil {
+			return err
+		}
+	}
+
+	if !program.IsActive || program.SpendPerPoint <= 0 {
+		return nil
+	}
+	earned := int(math.Floor(transaction.TotalAmount/program.SpendPerPoint + amountEpsilon))
+	if earned <= 0 {
+		return nil
+	}
+	value := roundAmount(float64(earned) * program.PointValue)
+	if err := addLoyaltyLot(tx, models.LoyaltyLedgerEntry{
+		UserID:        transaction.UserID,
+		CustomerID:    customerID,
+		TransactionID: &transaction.ID,
+		Type:          models.LoyaltyEarn,
+		EntryDate:     transaction.CreatedAt,
+		Points:        earned,
+		Value:         value,
+		ExpiresAt:     loyaltyExpiryFor(*program, transaction.CreatedAt),
+		Notes:         fmt.Sprintf("Poin dari transaksi #%d", transaction.ID),
+	}); err != nil {
+		return err
+	}
+	description := fmt.Sprintf("Poin loyalitas transaksi #%d", transaction.ID)
+	if err := postLoyaltyJournal(tx, transaction.UserID, transaction.CreatedAt, description, &transaction.ID, value); err != nil {
+		return err
+	}
+	transaction.LoyaltyPointsEarned = earned
+	return tx.Model(transaction).Update("loyalty_points_earned", earned).Error
+}
+
+// reverseTransactionLoyalty membalik poin transaksi yang dibatalkan: poin yang diperoleh ditarik
+// (dari lot transaksi itu dulu, sisanya dari saldo lain sebatas yang tersedia) dan poin yang ditukar dikembalikan
+func reverseTransactionLoyalty(tx *gorm.DB, transaction *models.Transaction, now time.Time) error {
+	if transaction.CustomerID == nil || (transaction.LoyaltyPointsEarned == 0 && transaction.LoyaltyPointsRedeemed == 0) {
+		return nil
+	}
+	customerID := *transaction.CustomerID
+	description := fmt.Sprintf("Pembalikan poin transaksi #%d (void)", transaction.ID)
+
+	if transaction.LoyaltyPointsEarned > 0 {
+		ownLots := tx.Where("transaction_id = ? AND type = ? AND remaining_points > 0", transaction.ID, models.LoyaltyEarn)
+		taken, value, err := takeFromLoyaltyLots(tx, ownLots, transaction.LoyaltyPointsEarned)
+		if err != nil {
+			return errors.New("gagal membalik poin transaksi")
+		}
+		if taken < transaction.LoyaltyPointsEarned {
+			// Poin transaksi ini sudah terpakai/hangus, tarik dari saldo lain sebatas yang ada
+			more, moreValue, err := consumeLoyaltyPoints(tx, customerID, transaction.LoyaltyPointsEarned-taken, now, true)
+			if err != nil {
+				return err
+			}
+			taken += more
+			value += moreValue
+		}
+		if taken > 0 {
+			reversed := models.LoyaltyLedgerEntry{
+				UserID:        transaction.UserID,
+				CustomerID:    customerID,
+				TransactionID: &transaction.ID,
+				Type:          models.LoyaltyReverse,
+				EntryDate:     now,
+				Points:        -taken,
+				Value:         -roundAmount(value),
+				Notes:         fmt.Sprintf("Penarikan %d dari %d poin transaksi #%d yang dibatalkan", taken, transaction.LoyaltyPointsEarned, transaction.ID),
+			}
+			if err := tx.Create(&reversed).Error; err != nil {
+				return errors.New("gagal mencatat pembalikan poin")
+			}
+			if err := postLoyaltyJournal(tx, transaction.UserID, now, description, &transaction.ID, -roundAmount(value)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if transaction.LoyaltyPointsRedeemed > 0 {
+		// Kembalikan poin dengan nilai tercatat saat ditukar, masa berlaku dihitung ulang dari sekarang
+		var redeemed models.LoyaltyLedgerEntry
+		if err := tx.Where("transaction_id = ? AND type = ?", transaction.ID, models.LoyaltyRedeem).First(&redeemed).Error; err != nil {
+			return errors.New("catatan penukaran poin transaksi tidak ditemukan")
+		}
+		program, err := findLoyaltyProgram(tx, transaction.UserID)
+		if err != nil {
+			return err
+		}
+		if err := addLoyaltyLot(tx, models.LoyaltyLedgerEntry{
+			UserID:        transaction.UserID,
+			CustomerID:    customerID,
+			TransactionID: &transaction.ID,
+			Type:          models.LoyaltyReverse,
+			EntryDate:     now,
+			Points:        -redeemed.Points,
+			Value:         -redeemed.Value,
+			ExpiresAt:     loyaltyExpiryFor(program, now),
+			Notes:         fmt.Sprintf("Pengembalian poin yang ditukar di transaksi #%d", transaction.ID),
+		}); err != nil {
+			return err
+		}
+		if err := postLoyaltyJournal(tx, transaction.UserID, now, description, &transaction.ID, -redeemed.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// toLoyaltyProgramResponse mengubah model program poin menjadi DTO
+func toLoyaltyProgramResponse(program models.LoyaltyProgram) dto.LoyaltyProgramResponse {
+	return dto.LoyaltyProgramResponse{
+		IsActive:        program.IsActive,
+		SpendPerPoint:   program.SpendPerPoint,
+		PointValue:      program.PointValue,
+		MinRedeemPoints: program.MinRedeemPoints,
+		ExpiryMonths:    program.ExpiryMonths,
+	}
+}
+
+// GetProgram mengambil pengaturan program poin user
+func (s *LoyaltyService) GetProgram(userID uint) (dto.LoyaltyProgramResponse, error) {
+	program, err := findLoyaltyProgram(database.DB, userID)
+	if err != nil {
+		return dto.LoyaltyProgramResponse{}, err
+	}
+	return toLoyaltyProgramResponse(program), nil
+}
+
+// UpdateProgram menyimpan pengaturan program poin user. Perubahan tidak mengubah poin yang sudah beredar.
+func (s *LoyaltyService) UpdateProgram(userID uint, input dto.LoyaltyProgramInput) (dto.LoyaltyProgramResponse, error) {
+	if input.IsActive && (input.SpendPerPoint <= 0 || input.PointValue <= 0) {
+		return dto.LoyaltyProgramResponse{}, errors.New("program aktif memerlukan spend_per_point dan point_value lebih dari 0")
+	}
+
+	program, err := findLoyaltyProgram(database.DB, userID)
+	if err != nil {
+		return dto.LoyaltyProgramResponse{}, err
+	}
+	program.IsActive = input.IsActive
+	program.SpendPerPoint = roundAmount(input.SpendPerPoint)
+	program.PointValue = roundAmount(input.PointValue)
+	program.MinRedeemPoints = input.MinRedeemPoints
+	program.ExpiryMonths = input.ExpiryMonths
+	if err := database.DB.Save(&program).Error; err != nil {
+		return dto.LoyaltyProgramResponse{}, err
+	}
+	return toLoyaltyProgramResponse(program), nil
+}
+
+// toLoyaltyLedgerEntryResponse mengubah mutasi poin menjadi DTO
+func toLoyaltyLedgerEntryResponse(entry models.LoyaltyLedgerEntry) dto.LoyaltyLedgerEntryResponse {
+	response := dto.LoyaltyLedgerEntryResponse{
+		ID:              entry.ID,
+		Type:            string(entry.Type),
+		TransactionID:   entry.TransactionID,
+		Date:            entry.EntryDate.Format("2006-01-02 15:04:05"),
+		Points:          entry.Points,
+		Value:           entry.Value,
+		RemainingPoints: entry.RemainingPoints,
+		Notes:           entry.Notes,
+	}
+	if entry.ExpiresAt != nil {
+		formatted := entry.ExpiresAt.Format("2006-01-02")
+		response.ExpiresAt = &formatted
+	}
+	return response
+}
+
+// GetCustomerLoyalty mengambil saldo poin yang masih berlaku dan riwayat mutasi poin pelanggan
+func (s *LoyaltyService) GetCustomerLoyalty(customerID uint, userID uint) (dto.CustomerLoyaltyResponse, error) {
+	db := database.DB
+	customer, err := NewCustomerService().GetCustomerByID(customerID, userID)
+	if err != nil {
+		return dto.CustomerLoyaltyResponse{}, err
+	}
+	program, err := findLoyaltyProgram(db, userID)
+	if err != nil {
+		return dto.CustomerLoyaltyResponse{}, err
+	}
+
+	response := dto.CustomerLoyaltyResponse{
+		CustomerID:   customer.ID,
+		CustomerName: customer.Name,
+		Entries:      []dto.LoyaltyLedgerEntryResponse{},
+	}
+
+	now := time.Now()
+	var lots []models.LoyaltyLedgerEntry
+	if err := db.Scopes(activeLoyaltyLots(now)).Where("customer_id = ?", customer.ID).
+		Order("expires_at IS NULL, expires_at asc").Find(&lots).Error; err != nil {
+		return response, err
+	}
+	for _, lot := range lots {
+		response.Points += lot.RemainingPoints
+		if lot.ExpiresAt != nil && lot.ExpiresAt.Before(now.Add(loyaltyExpiryWindow)) {
+			response.ExpiringPoints += lot.RemainingPoints
+		}
+	}
+	if len(lots) > 0 && lots[0].ExpiresAt != nil {
+		formatted := lots[0].ExpiresAt.Format("2006-01-02")
+		response.NextExpiry = &formatted
+	}
+	response.Value = roundAmount(float64(response.Points) * program.PointValue)
+
+	var entries []models.LoyaltyLedgerEntry
+	if err := db.Where("customer_id = ?", customer.ID).Order("entry_date desc, id desc").Find(&entries).Error; err != nil {
+		return response, err
+	}
+	for _, entry := range entries {
+		response.Entries = append(response.Entries, toLoyaltyLedgerEntryResponse(entry))
+	}
+	return response, nil
+}
+
+// AdjustCustomerPoints mencatat koreksi manual poin pelanggan
+func (s *LoyaltyService) AdjustCustomerPoints(customerID uint, userID uint, input dto.LoyaltyAdjustmentInput) (dto.CustomerLoyaltyResponse, error) {
+	customer, err := NewCustomerService().GetCustomerByID(customerID, userID)
+	if err != nil {
+		return dto.CustomerLoyaltyResponse{}, err
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := ensurePeriodOpen(tx, userID, now); err != nil {
+			return err
+		}
+		if _, err := expireLoyaltyPoints(tx, userID, &customer.ID, now); err != nil {
+			return err
+		}
+		program, err := findLoyaltyProgram(tx, userID)
+		if err != nil {
+			return err
+		}
+
+		entry := models.LoyaltyLedgerEntry{
+			UserID:     userID,
+			CustomerID: customer.ID,
+			Type:       models.LoyaltyAdjust,
+			EntryDate:  now,
+			Points:     input.Points,
+			Notes:      input.Notes,
+		}
+		if input.Points > 0 {
+			entry.Value = roundAmount(float64(input.Points) * program.PointValue)
+			entry.ExpiresAt = loyaltyExpiryFor(program, now)
+			if err := addLoyaltyLot(tx, entry); err != nil {
+				return err
+			}
+		} else {
+			_, value, err := consumeLoyaltyPoints(tx, customer.ID, -input.Points, now, false)
+			if err != nil {
+				return err
+			}
+			entry.Value = -value
+			if err := tx.Create(&entry).Error; err != nil {
+				return errors.New("gagal mencatat mutasi poin")
+			}
+		}
+		description := fmt.Sprintf("Koreksi poin pelanggan %s", customer.Name)
+		return postLoyaltyJournal(tx, userID, now, description, nil, entry.Value)
+	})
+	if err != nil {
+		return dto.CustomerLoyaltyResponse{}, err
+	}
+	return s.GetCustomerLoyalty(customer.ID, userID)
+}
+
+// ExpirePoints menghanguskan semua poin pelanggan user yang sudah lewat masa berlaku
+func (s *LoyaltyService) ExpirePoints(userID uint) (dto.ExpireLoyaltyResult, error) {
+	var result dto.ExpireLoyaltyResult
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := ensurePeriodOpen(tx, userID, now); err != nil {
+			return err
+		}
+		var err error
+		result, err = expireLoyaltyPoints(tx, userID, nil, now)
+		return err
+	})
+	return result, err
+}
+
+// GetLiabilityReport membuat laporan poin beredar (belum ditukar & masih berlaku) per pelanggan
+func (s *LoyaltyService) GetLiabilityReport(userID uint) (dto.LoyaltyLiabilityReport, error) {
+	db := database.DB
+	report := dto.LoyaltyLiabilityReport{Customers: []dto.LoyaltyLiabilityRow{}}
+
+	program, err := findLoyaltyProgram(db, userID)
+	if err != nil {
+		return report, err
+	}
+	report.PointValue = program.PointValue
+
+	now := time.Now()
+	var lots []models.LoyaltyLedgerEntry
+	if err := db.Where("user_id = ? AND remaining_points > 0", userID).
+		Order("expires_at IS NULL, expires_at asc").Find(&lots).Error; err != nil {
+		return report, err
+	}
+
+	var customerIDs []uint
+	rows := map[uint]*dto.LoyaltyLiabilityRow{}
+	for _, lot := range lots {
+		if lot.ExpiresAt != nil && !lot.ExpiresAt.After(now) {
+			report.PendingExpiryPoints += lot.RemainingPoints
+			continue
+		}
+		row, ok := rows[lot.CustomerID]
+		if !ok {
+			row = &dto.LoyaltyLiabilityRow{CustomerID: lot.CustomerID}
+			rows[lot.CustomerID] = row
+			customerIDs = append(customerIDs, lot.CustomerID)
+		}
+		row.Points += lot.RemainingPoints
+		row.CarryingValue += lot.UnitValue() * float64(lot.RemainingPoints)
+		if lot.ExpiresAt != nil {
+			if row.NextExpiry == nil {
+				formatted := lot.ExpiresAt.Format("2006-01-02")
+				row.NextExpiry = &formatted
+			}
+			if lot.ExpiresAt.Before(now.Add(loyaltyExpiryWindow)) {
+				row.ExpiringPoints += lot.RemainingPoints
+			}
+		}
+	}
+
+	// Nama pelanggan (termasuk yang sudah dihapus, poinnya tetap tercatat sebagai liabilitas)
+	var customers []models.Customer
+	if len(customerIDs) > 0 {
+		if err := db.Unscoped().Select("id", "name").Where("id IN ?", customerIDs).Find(&customers).Error; err != nil {
+			return report, err
+		}
+	}
+	for _, customer := range customers {
+		rows[customer.ID].CustomerName = customer.Name
+	}
+
+	for _, id := range customerIDs {
+		row := rows[id]
+		row.Value = roundAmount(float64(row.Points) * program.PointValue)
+		row.CarryingValue = roundAmount(row.CarryingValue)
+		report.TotalPoints += row.Points
+		report.TotalValue += row.Value
+		report.TotalCarryingValue += row.CarryingValue
+		report.ExpiringPoints += row.ExpiringPoints
+		report.Customers = append(report.Customers, *row)
+	}
+	report.TotalValue = roundAmount(report.TotalValue)
+	report.TotalCarryingValue = roundAmount(report.TotalCarryingValue)
+
+	sort.SliceStable(report.Customers, func(i, j int) bool {
+		return report.Customers[i].Points > report.Customers[j].Points
+	})
+	return report, nil
+}
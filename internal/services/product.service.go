@@ -3,11 +3,14 @@ package services
 import (
 	"errors"
 	"fmt" // <-- Impor 'fmt' untuk string formatting
+	"strings"
+	"time"
 
 	"github.com/danishyusrah/go_bisnis/internal/database"
 	"github.com/danishyusrah/go_bisnis/internal/dto"
 	"github.com/danishyusrah/go_bisnis/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // ProductService adalah struct untuk layanan terkait produk
@@ -18,11 +21,95 @@ func NewProductService() *ProductService {
 	return &ProductService{}
 }
 
+// [BARU] validateProductCategory memastikan kategori produk milik user dan bertipe INCOME
+func validateProductCategory(db *gorm.DB, categoryID *uint, userID uint) error {
+	if categoryID == nil {
+		return nil
+	}
+	var category models.Category
+	if err := db.Where("id = ? AND user_id = ?", *categoryID, userID).First(&category).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("kategori produk tidak ditemukan")
+		}
+		return err
+	}
+	if category.Type != models.IncomeCategory {
+		return errors.New("kategori produk harus bertipe INCOME")
+	}
+	return nil
+}
+
+// [BARU] validateVariantParent memastikan produk induk milik user, bukan varian,
+// dan tidak lagi menyimpan stok sendiri (stok dipindah ke varian).
+func validateVariantParent(db *gorm.DB, parentID uint, userID uint) (models.Product, error) {
+	var parent models.Product
+	if err := db.First(&parent, parentID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Product{}, errors.New("produk induk tidak ditemukan")
+		}
+		return models.Product{}, err
+	}
+	if parent.UserID != userID {
+		return models.Product{}, errors.New("akses ditolak: Anda bukan pemilik produk induk ini")
+	}
+	if parent.ParentID != nil {
+		return models.Product{}, errors.New("varian tidak dapat menjadi produk induk")
+	}
+	if parent.Stock != 0 {
+		return models.Product{}, errors.New("stok produk induk harus 0 sebelum ditambah varian")
+	}
+	return parent, nil
+}
+
+// [BARU] productHasVariants mengecek apakah produk adalah induk yang memiliki varian
+func productHasVariants(db *gorm.DB, productID uint) (bool, error) {
+	var count int64
+	if err := db.Model(&models.Product{}).Where("parent_id = ?", productID).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// [BARU] toProductAttributes mengubah input atribut varian menjadi model
+func toProductAttributes(inputs []dto.ProductAttributeInput) []models.ProductAttribute {
+	attributes := []models.ProductAttribute{}
+	for _, input := range inputs {
+		attributes = append(attributes, models.ProductAttribute{Name: input.Name, Value: input.Value})
+	}
+	return attributes
+}
+
 // CreateProduct adalah logika bisnis untuk membuat produk
 // Perhatikan bagaimana kita menerima userID untuk memastikan kepemilikan
 func (s *ProductService) CreateProduct(input dto.CreateProductInput, userID uint) (models.Product, error) {
 	db := database.DB
 
+	// [BARU] Varian: validasi produk induk, kategori mengikuti induk jika tidak diisi
+	if input.ParentID != nil {
+		parent, err := validateVariantParent(db, *input.ParentID, userID)
+		if err != nil {
+			return models.Product{}, err
+		}
+		if len(input.Attributes) == 0 {
+			return models.Product{}, errors.New("varian harus memiliki minimal satu atribut (cth: ukuran, warna)")
+		}
+		if input.CategoryID == nil {
+			input.CategoryID = parent.CategoryID
+		}
+	} else if len(input.Attributes) > 0 {
+		return models.Product{}, errors.New("atribut hanya berlaku untuk varian, isi parent_id")
+	}
+
+	if err := validateProductCategory(db, input.CategoryID, userID); err != nil {
+		return models.Product{}, err
+	}
+
+	// [BARU] Satuan dasar stok
+	baseUnit := strings.TrimSpace(input.BaseUnit)
+	if baseUnit == "" {
+		baseUnit = defaultBaseUnit
+	}
+
 	newProduct := models.Product{
 		Name:          input.Name,
 		SKU:           input.SKU,
@@ -34,9 +121,56 @@ func (s *ProductService) CreateProduct(input dto.CreateProductInput, userID uint
 		// --- [BARU] ---
 		BatasStokMinimum: input.BatasStokMinimum,
 		// --- [AKHIR BARU] ---
+		TaxExempt:  input.TaxExempt,  // [BARU]
+		CategoryID: input.CategoryID, // [BARU]
+		TrackLots:  input.TrackLots,  // [BARU]
+		ParentID:   input.ParentID,   // [BARU]
+		Attributes: toProductAttributes(input.Attributes),
+		BaseUnit:   baseUnit, // [BARU]
 	}
 
-	if err := db.Create(&newProduct).Error; err != nil {
+	// [DIUBAH] Stok awal dicatat di kartu stok
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&newProduct).Error; err != nil {
+			return err
+		}
+		if newProduct.Stock == 0 {
+			return nil
+		}
+		// [BARU] Stok awal ditempatkan di lokasi default
+		location, err := defaultLocation(tx, userID)
+		if err != nil {
+			return err
+		}
+		if _, err := changeLocationStock(tx, &newProduct, location.ID, newProduct.Stock); err != nil {
+			return err
+		}
+		movement := newStockMovement(userID, newProduct.ID, newProduct.Stock, newProduct.Stock, models.MovementAdjustment, nil, "Stok awal")
+		movement.LocationID = &location.ID
+		if err := tx.Create(&movement).Error; err != nil {
+			return err
+		}
+		// [BARU] Nilai stok awal masuk ke akun Persediaan agar neraca tetap seimbang
+		if err := postStockAdjustmentJournal(tx, &newProduct, float64(newProduct.Stock)*newProduct.PurchasePrice, time.Now(), "Stok awal produk"); err != nil {
+			return err
+		}
+		// [BARU] Stok awal produk yang dilacak menjadi batch pertama (tanpa lot)
+		if newProduct.TrackLots {
+			if err := receiveProductBatch(tx, &newProduct, newProduct.Stock, "", nil, nil); err != nil {
+				return err
+			}
+		}
+		// [BARU] Stok awal menjadi lapisan biaya pertama jika memakai FIFO
+		method, err := userCostingMethod(tx, userID)
+		if err != nil {
+			return err
+		}
+		if method == models.CostingFIFO {
+			return seedCostLayers(tx, &newProduct)
+		}
+		return nil
+	})
+	if err != nil {
 		return models.Product{}, err
 	}
 
@@ -45,12 +179,23 @@ func (s *ProductService) CreateProduct(input dto.CreateProductInput, userID uint
 
 // GetUserProducts mengambil semua produk yang dimiliki oleh user
 // [DIPERBARUI] Sekarang menerima 'searchQuery'
-func (s *ProductService) GetUserProducts(userID uint, searchQuery string) ([]models.Product, error) {
+// [DIUBAH] Varian dikelompokkan di bawah produk induknya. Jika sellable = true, yang dikembalikan
+// adalah daftar datar produk yang bisa dijual (varian + produk tanpa varian, tanpa produk induk).
+func (s *ProductService) GetUserProducts(userID uint, searchQuery string, sellable bool) ([]models.Product, error) {
 	var products []models.Product
 	db := database.DB
 
 	// Mulai query dengan filter UserID dan urutkan berdasarkan nama
-	query := db.Where("user_id = ?", userID).Order("name asc")
+	query := db.Where("user_id = ?", userID).Order("name asc").Preload("Attributes").Preload("Units", func(db *gorm.DB) *gorm.DB { return db.Order("factor asc") })
+
+	if sellable {
+		query = query.Where("NOT EXISTS (SELECT 1 FROM products variants WHERE variants.parent_id = products.id AND variants.deleted_at IS NULL)")
+	} else {
+		query = query.Where("parent_id IS NULL").
+			Preload("Variants", func(db *gorm.DB) *gorm.DB { return db.Order("name asc") }).
+			Preload("Variants.Attributes").
+			Preload("Variants.Units", func(db *gorm.DB) *gorm.DB { return db.Order("factor asc") })
+	}
 
 	// [BARU] Tambahkan kondisi WHERE jika ada searchQuery
 	if searchQuery != "" {
@@ -58,13 +203,23 @@ func (s *ProductService) GetUserProducts(userID uint, searchQuery string) ([]mod
 		searchTerm := fmt.Sprintf("%%%s%%", searchQuery)
 
 		// Cari di kolom 'name' ATAU 'sku'
-		query = query.Where("name LIKE ? OR sku LIKE ?", searchTerm, searchTerm)
+		if sellable {
+			query = query.Where("name LIKE ? OR sku LIKE ?", searchTerm, searchTerm)
+		} else {
+			// [BARU] Produk induk ikut tampil jika salah satu variannya cocok
+			query = query.Where("name LIKE ? OR sku LIKE ? OR id IN (SELECT parent_id FROM products WHERE user_id = ? AND parent_id IS NOT NULL AND deleted_at IS NULL AND (name LIKE ? OR sku LIKE ?))",
+				searchTerm, searchTerm, userID, searchTerm, searchTerm)
+		}
 	}
 
 	// Eksekusi query
 	if err := query.Find(&products).Error; err != nil {
 		return nil, err
 	}
+	// [BARU] Stok produk komposit = jumlah yang masih bisa dibuat dari bahannya
+	if err := fillCompositeStock(db, userID, products); err != nil {
+		return nil, err
+	}
 	return products, nil
 }
 
@@ -88,6 +243,31 @@ func (s *ProductService) GetProductByID(productID uint, userID uint) (models.Pro
 	return product, nil
 }
 
+// [BARU] GetProductDetail mengambil satu produk beserta atribut dan variannya (untuk ditampilkan)
+func (s *ProductService) GetProductDetail(productID uint, userID uint) (models.Product, error) {
+	product, err := s.GetProductByID(productID, userID)
+	if err != nil {
+		return models.Product{}, err
+	}
+
+	db := database.DB
+	if err := db.Where("product_id = ?", product.ID).Find(&product.Attributes).Error; err != nil {
+		return models.Product{}, err
+	}
+	if err := db.Where("product_id = ?", product.ID).Order("factor asc").Find(&product.Units).Error; err != nil {
+		return models.Product{}, err
+	}
+	if err := db.Where("parent_id = ?", product.ID).Order("name asc").Preload("Attributes").Preload("Units").Find(&product.Variants).Error; err != nil {
+		return models.Product{}, err
+	}
+	// [BARU] Produk komposit
+	products := []models.Product{product}
+	if err := fillCompositeStock(db, userID, products); err != nil {
+		return models.Product{}, err
+	}
+	return products[0], nil
+}
+
 // UpdateProduct memperbarui produk, dan memvalidasi kepemilikan
 func (s *ProductService) UpdateProduct(productID uint, input dto.UpdateProductInput, userID uint) (models.Product, error) {
 	db := database.DB
@@ -108,14 +288,232 @@ func (s *ProductService) UpdateProduct(productID uint, input dto.UpdateProductIn
 	// --- [BARU] ---
 	product.BatasStokMinimum = input.BatasStokMinimum
 	// --- [AKHIR BARU] ---
+	product.TaxExempt = input.TaxExempt // [BARU]
+	// [BARU] Kategori produk
+	if err := validateProductCategory(db, input.CategoryID, userID); err != nil {
+		return models.Product{}, err
+	}
+	product.CategoryID = input.CategoryID
+	product.Category = nil
+	product.TrackLots = input.TrackLots // [BARU]
+	// [BARU] Satuan dasar hanya boleh diganti saat stok kosong, agar stok tidak berubah makna
+	if baseUnit := strings.TrimSpace(input.BaseUnit); baseUnit != "" && baseUnit != product.BaseUnit {
+		if product.Stock != 0 {
+			return models.Product{}, errors.New("satuan dasar hanya dapat diubah saat stok 0")
+		}
+		product.BaseUnit = baseUnit
+	}
+	// [BARU] Atribut hanya untuk varian; stok produk induk selalu 0 (stok ada di varian)
+	if input.Attributes != nil && product.ParentID == nil {
+		return models.Product{}, errors.New("atribut hanya berlaku untuk varian, isi parent_id")
+	}
+	if product.ParentID != nil && input.Attributes != nil && len(input.Attributes) == 0 {
+		return models.Product{}, errors.New("varian harus memiliki minimal satu atribut (cth: ukuran, warna)")
+	}
+	hasVariants, err := productHasVariants(db, product.ID)
+	if err != nil {
+		return models.Product{}, err
+	}
 
-	if err := db.Save(&product).Error; err != nil {
+	// [DIUBAH] Perubahan stok manual dicatat sebagai penyesuaian di kartu stok.
+	// Stok terkini dibaca ulang dengan lock agar selisihnya tidak tertimpa transaksi lain.
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var current models.Product
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, product.ID).Error; err != nil {
+			return err
+		}
+		if hasVariants || current.IsComposite {
+			product.Stock = current.Stock
+		}
+		delta := product.Stock - current.Stock
+
+		// [BARU] FIFO: perubahan stok manual mengikuti lapisan biaya. Harga beli adalah rata-rata
+		// sisa lapisan, jadi hanya boleh diubah manual saat stok kosong (menjadi biaya stok baru).
+		method, err := userCostingMethod(tx, userID)
+		if err != nil {
+			return err
+		}
+		// [BARU] Perubahan nilai persediaan: rata-rata = nilai baru - nilai lama (termasuk revaluasi harga),
+		// FIFO = nilai barang yang masuk/keluar lapisan biaya
+		valueChange := float64(product.Stock)*product.PurchasePrice - float64(current.Stock)*current.PurchasePrice
+		if method == models.CostingFIFO {
+			if product.PurchasePrice != current.PurchasePrice && current.Stock != 0 {
+				return errors.New("harga beli produk FIFO hanya dapat diubah saat stok 0")
+			}
+			valueChange = float64(delta) * product.PurchasePrice
+			if delta > 0 {
+				err = receiveInventoryCost(tx, &current, delta, product.PurchasePrice, nil, time.Now())
+			} else if delta < 0 {
+				var issuedCost float64
+				issuedCost, err = issueInventoryCost(tx, &current, -delta, nil, nil)
+				valueChange = float64(delta) * issuedCost
+			}
+			if err != nil {
+				return err
+			}
+			if delta != 0 {
+				product.PurchasePrice = current.PurchasePrice
+			}
+		}
+		if err := postStockAdjustmentJournal(tx, &product, valueChange, time.Now(), "Edit stok/harga produk"); err != nil {
+			return err
+		}
+
+		if err := tx.Save(&product).Error; err != nil {
+			return err
+		}
+		// [BARU] Atribut varian yang dikirim menggantikan seluruh atribut lama
+		if input.Attributes != nil {
+			if err := tx.Where("product_id = ?", product.ID).Delete(&models.ProductAttribute{}).Error; err != nil {
+				return err
+			}
+			attributes := toProductAttributes(input.Attributes)
+			for i := range attributes {
+				attributes[i].ProductID = product.ID
+			}
+			if err := tx.Create(&attributes).Error; err != nil {
+				return err
+			}
+			product.Attributes = attributes
+		}
+		// [BARU] Batch: pelacakan baru diaktifkan -> seluruh stok jadi satu batch, selain itu ikuti selisih stok
+		if product.TrackLots && !current.TrackLots {
+			if err := seedProductBatches(tx, &product); err != nil {
+				return err
+			}
+		} else if err := syncProductBatches(tx, &product, delta, nil); err != nil {
+			return err
+		}
+		if delta == 0 {
+			return nil
+		}
+		// [BARU] Perubahan stok manual berlaku di lokasi default
+		location, err := defaultLocation(tx, userID)
+		if err != nil {
+			return err
+		}
+		if _, err := changeLocationStock(tx, &product, location.ID, delta); err != nil {
+			return err
+		}
+		movement := newStockMovement(userID, product.ID, delta, product.Stock, models.MovementAdjustment, nil, "Edit produk")
+		movement.LocationID = &location.ID
+		return tx.Create(&movement).Error
+	})
+	if err != nil {
 		return models.Product{}, err
 	}
 
 	return product, nil
 }
 
+// [BARU] GetStockCard mengambil kartu stok (riwayat perubahan stok) satu produk dalam rentang waktu.
+// [DIUBAH] Jika locationID diisi, hanya pergerakan di lokasi tersebut yang ditampilkan dan saldonya
+// dihitung kumulatif per lokasi (BalanceAfter di tabel adalah saldo total seluruh lokasi).
+func (s *ProductService) GetStockCard(productID uint, userID uint, locationID *uint, startTime time.Time, endTime time.Time) (dto.StockCardResponse, error) {
+	db := database.DB
+
+	product, err := s.GetProductByID(productID, userID)
+	if err != nil {
+		return dto.StockCardResponse{}, err
+	}
+
+	card := dto.StockCardResponse{
+		ProductID:   product.ID,
+		ProductName: product.Name,
+		From:        startTime.Format("2006-01-02"),
+		To:          endTime.Format("2006-01-02"),
+		Movements:   []dto.StockMovementResponse{},
+	}
+
+	if locationID != nil {
+		return stockCardAtLocation(card, productID, userID, *locationID, startTime, endTime)
+	}
+
+	// Saldo awal = saldo setelah pergerakan terakhir sebelum 'from'
+	var previous models.StockMovement
+	err = db.Where("product_id = ? AND user_id = ? AND created_at < ?", productID, userID, startTime).
+		Order("created_at desc, id desc").
+		Limit(1).
+		Find(&previous).Error
+	if err != nil {
+		return card, err
+	}
+	card.OpeningBalance = previous.BalanceAfter
+	card.ClosingBalance = previous.BalanceAfter
+
+	var movements []models.StockMovement
+	if err := db.Where("product_id = ? AND user_id = ? AND created_at BETWEEN ? AND ?", productID, userID, startTime, endTime).
+		Order("created_at asc, id asc").
+		Find(&movements).Error; err != nil {
+		return card, err
+	}
+
+	for _, m := range movements {
+		if m.Quantity > 0 {
+			card.TotalIn += m.Quantity
+		} else {
+			card.TotalOut += -m.Quantity
+		}
+		card.ClosingBalance = m.BalanceAfter
+		card.Movements = append(card.Movements, dto.StockMovementResponse{
+			ID:            m.ID,
+			Date:          m.CreatedAt.Format("2006-01-02 15:04:05"),
+			Reason:        string(m.Reason),
+			Quantity:      m.Quantity,
+			BalanceAfter:  m.BalanceAfter,
+			TransactionID: m.TransactionID,
+			Notes:         m.Notes,
+		})
+	}
+
+	return card, nil
+}
+
+// [BARU] stockCardAtLocation mengisi kartu stok untuk satu lokasi: saldo awal = jumlah pergerakan
+// di lokasi tersebut sebelum 'from', lalu saldo berjalan dari setiap pergerakan dalam rentang waktu
+func stockCardAtLocation(card dto.StockCardResponse, productID uint, userID uint, locationID uint, startTime time.Time, endTime time.Time) (dto.StockCardResponse, error) {
+	db := database.DB
+
+	var opening struct {
+		Total int
+	}
+	if err := db.Model(&models.StockMovement{}).
+		Select("COALESCE(SUM(quantity), 0) as total").
+		Where("product_id = ? AND user_id = ? AND location_id = ? AND created_at < ?", productID, userID, locationID, startTime).
+		Scan(&opening).Error; err != nil {
+		return card, err
+	}
+	card.OpeningBalance = opening.Total
+	card.ClosingBalance = opening.Total
+
+	var movements []models.StockMovement
+	if err := db.Where("product_id = ? AND user_id = ? AND location_id = ? AND created_at BETWEEN ? AND ?", productID, userID, locationID, startTime, endTime).
+		Order("created_at asc, id asc").
+		Find(&movements).Error; err != nil {
+		return card, err
+	}
+
+	for _, m := range movements {
+		if m.Quantity > 0 {
+			card.TotalIn += m.Quantity
+		} else {
+			card.TotalOut += -m.Quantity
+		}
+		card.ClosingBalance += m.Quantity
+		card.Movements = append(card.Movements, dto.StockMovementResponse{
+			ID:            m.ID,
+			Date:          m.CreatedAt.Format("2006-01-02 15:04:05"),
+			Reason:        string(m.Reason),
+			Quantity:      m.Quantity,
+			BalanceAfter:  card.ClosingBalance,
+			TransactionID: m.TransactionID,
+			Notes:         m.Notes,
+		})
+	}
+
+	return card, nil
+}
+
 // DeleteProduct menghapus produk, dan memvalidasi kepemilikan
 func (s *ProductService) DeleteProduct(productID uint, userID uint) error {
 	db := database.DB
@@ -126,9 +524,33 @@ func (s *ProductService) DeleteProduct(productID uint, userID uint) error {
 		return err // Error (tidak ditemukan / bukan pemilik) sudah ditangani
 	}
 
+	// [BARU] Produk induk tidak boleh dihapus selama masih memiliki varian
+	hasVariants, err := productHasVariants(db, product.ID)
+	if err != nil {
+		return err
+	}
+	if hasVariants {
+		return errors.New("produk masih memiliki varian, hapus varian terlebih dahulu")
+	}
+	// [BARU] Produk yang dipakai sebagai bahan produk komposit tidak boleh dihapus
+	var usedAsComponent int64
+	if err := db.Model(&models.ProductComponent{}).Where("component_id = ?", product.ID).Count(&usedAsComponent).Error; err != nil {
+		return err
+	}
+	if usedAsComponent > 0 {
+		return errors.New("produk dipakai sebagai bahan produk komposit, hapus dari resep terlebih dahulu")
+	}
+
 	// Hapus produk
 	// Kita gunakan Unscoped() untuk Hard Delete, atau biarkan saja untuk Soft Delete (jika gorm.Model)
-	if err := db.Delete(&product).Error; err != nil {
+	// [DIUBAH] Resep produk komposit ikut dihapus
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("product_id = ?", product.ID).Delete(&models.ProductComponent{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&product).Error
+	})
+	if err != nil {
 		return err
 	}
 
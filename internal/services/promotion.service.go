@@ -0,0 +1,438 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/danishyusrah/go_bisnis/internal/database"
+	"github.com/danishyusrah/go_bisnis/internal/dto"
+	"github.com/danishyusrah/go_bisnis/internal/models"
+	"gorm.io/gorm"
+)
+
+// PromotionService adalah struct untuk layanan terkait promo & aturan harga
+type PromotionService struct{}
+
+// NewPromotionService membuat instance PromotionService baru
+func NewPromotionService() *PromotionService {
+	return &PromotionService{}
+}
+
+// findOwnedPromotion mengambil promo dan memvalidasi kepemilikan
+func findOwnedPromotion(tx *gorm.DB, promotionID uint, userID uint) (models.Promotion, error) {
+	var promotion models.Promotion
+	if err := tx.First(&promotion, promotionID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Promotion{}, errors.New("promo tidak ditemukan")
+		}
+		return models.Promotion{}, err
+	}
+	if promotion.UserID != userID {
+		return models.Promotion{}, errors.New("akses ditolak: Anda bukan pemilik promo ini")
+	}
+	return promotion, nil
+}
+
+// GetPromotions mengambil semua promo milik user
+func (s *PromotionService) GetPromotions(userID uint) ([]models.Promotion, error) {
+	var promotions []models.Promotion
+	err := database.DB.Where("user_id = ?", userID).Order("name asc").Find(&promotions).Error
+	return promotions, err
+}
+
+// applyPromotionInput mengisi promo dari input dan memvalidasi parameter sesuai jenisnya
+func applyPromotionInput(db *gorm.DB, promotion *models.Promotion, input dto.CreatePromotionInput, userID uint) error {
+	promotion.Name = input.Name
+	promotion.Type = input.Type
+	promotion.IsActive = input.IsActive == nil || *input.IsActive
+	promotion.StartTime = input.StartTime
+	promotion.EndTime = input.EndTime
+	promotion.ProductID = input.ProductID
+	promotion.CategoryID = input.CategoryID
+	promotion.BuyQuantity = input.BuyQuantity
+	promotion.GetQuantity = input.GetQuantity
+	promotion.BundlePrice = input.BundlePrice
+	promotion.MinSpend = input.MinSpend
+	promotion.DiscountType = input.DiscountType
+	promotion.DiscountValue = input.DiscountValue
+
+	// Masa berlaku
+	promotion.StartDate, promotion.EndDate = nil, nil
+	if input.StartDate != nil && *input.StartDate != "" {
+		startDate, err := time.ParseInLocation("2006-01-02", *input.StartDate, time.Local)
+		if err != nil {
+			return errors.New("format tanggal mulai tidak valid, gunakan YYYY-MM-DD")
+		}
+		promotion.StartDate = &startDate
+	}
+	if input.EndDate != nil && *input.EndDate != "" {
+		endDate, err := time.ParseInLocation("2006-01-02", *input.EndDate, time.Local)
+		if err != nil {
+			return errors.New("format tanggal selesai tidak valid, gunakan YYYY-MM-DD")
+		}
+		promotion.EndDate = &endDate
+	}
+	if promotion.StartDate != nil && promotion.EndDate != nil && promotion.EndDate.Before(*promotion.StartDate) {
+		return errors.New("tanggal selesai promo tidak boleh sebelum tanggal mulai")
+	}
+	if (promotion.StartTime == "") != (promotion.EndTime == "") {
+		return errors.New("jam mulai dan jam selesai promo harus diisi keduanya")
+	}
+
+	// Target promo harus milik user
+	if promotion.ProductID != nil {
+		var count int64
+		if err := db.Model(&models.Product{}).Where("id = ? AND user_id = ?", *promotion.ProductID, userID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return errors.New("produk promo tidak ditemukan")
+		}
+	}
+	if err := validateProductCategory(db, promotion.CategoryID, userID); err != nil {
+		return err
+	}
+
+	// Parameter wajib per jenis promo
+	needsDiscount := false
+	switch promotion.Type {
+	case models.PromoBuyXGetY:
+		if promotion.ProductID == nil || promotion.BuyQuantity <= 0 || promotion.GetQuantity <= 0 {
+			return errors.New("promo beli X gratis Y membutuhkan produk, buy_quantity, dan get_quantity")
+		}
+	case models.PromoBundle:
+		if promotion.ProductID == nil || promotion.BuyQuantity < 2 || promotion.BundlePrice <= 0 {
+			return errors.New("promo harga paket membutuhkan produk, buy_quantity (minimal 2), dan bundle_price")
+		}
+	case models.PromoHappyHour:
+		if promotion.StartTime == "" {
+			return errors.New("promo happy hour membutuhkan jam mulai dan jam selesai")
+		}
+		needsDiscount = true
+	case models.PromoMinSpend:
+		if promotion.MinSpend <= 0 {
+			return errors.New("promo minimal belanja membutuhkan min_spend")
+		}
+		needsDiscount = true
+	case models.PromoCategory:
+		if promotion.CategoryID == nil {
+			return errors.New("promo kategori membutuhkan category_id")
+		}
+		needsDiscount = true
+	}
+	if needsDiscount {
+		if promotion.DiscountType == "" || promotion.DiscountValue <= 0 {
+			return errors.New("promo ini membutuhkan discount_type dan discount_value")
+		}
+		if promotion.DiscountType == models.DiscountPercent && promotion.DiscountValue > 100 {
+			return errors.New("diskon persen tidak boleh lebih dari 100")
+		}
+	}
+	return nil
+}
+
+// CreatePromotion membuat promo baru
+func (s *PromotionService) CreatePromotion(input dto.CreatePromotionInput, userID uint) (models.Promotion, error) {
+	db := database.DB
+	promotion := models.Promotion{UserID: userID}
+	if err := applyPromotionInput(db, &promotion, input, userID); err != nil {
+		return models.Promotion{}, err
+	}
+	if err := db.Create(&promotion).Error; err != nil {
+		return models.Promotion{}, err
+	}
+	return promotion, nil
+}
+
+// UpdatePromotion memperbarui promo. Transaksi lama tidak berubah karena promo sudah di-snapshot.
+func (s *PromotionService) UpdatePromotion(promotionID uint, input dto.CreatePromotionInput, userID uint) (models.Promotion, error) {
+	db := database.DB
+	promotion, err := findOwnedPromotion(db, promotionID, userID)
+	if err != nil {
+		return models.Promotion{}, err
+	}
+	if err := applyPromotionInput(db, &promotion, input, userID); err != nil {
+		return models.Promotion{}, err
+	}
+	if err := db.Save(&promotion).Error; err != nil {
+		return models.Promotion{}, err
+	}
+	return promotion, nil
+}
+
+// DeletePromotion menghapus promo
+func (s *PromotionService) DeletePromotion(promotionID uint, userID uint) error {
+	promotion, err := findOwnedPromotion(database.DB, promotionID, userID)
+	if err != nil {
+		return err
+	}
+	return database.DB.Delete(&promotion).Error
+}
+
+// --- Mesin Promo ---
+
+// findActivePromotions mengambil promo aktif milik user yang berlaku pada waktu 'at'
+func findActivePromotions(tx *gorm.DB, userID uint, at time.Time) ([]models.Promotion, error) {
+	var promotions []models.Promotion
+	date := at.Format("2006-01-02")
+	if err := tx.Where("user_id = ? AND is_active = ? AND (start_date IS NULL OR start_date <= ?) AND (end_date IS NULL OR end_date >= ?)",
+		userID, true, date, date).
+		Order("id asc").
+		Find(&promotions).Error; err != nil {
+		return nil, err
+	}
+
+	active := []models.Promotion{}
+	for _, promotion := range promotions {
+		if withinPromotionHours(promotion, at) {
+			active = append(active, promotion)
+		}
+	}
+	return active, nil
+}
+
+// withinPromotionHours memeriksa jam berlaku harian promo (jika ada)
+func withinPromotionHours(promotion models.Promotion, at time.Time) bool {
+	if promotion.StartTime == "" || promotion.EndTime == "" {
+		return true
+	}
+	clock := at.Format("15:04")
+	if promotion.StartTime <= promotion.EndTime {
+		return clock >= promotion.StartTime && clock < promotion.EndTime
+	}
+	// Melewati tengah malam, cth: 22:00 - 02:00
+	return clock >= promotion.StartTime || clock < promotion.EndTime
+}
+
+// promotionDiscount menghitung potongan persen/nominal promo dari 'base', tidak melebihi base
+func promotionDiscount(promotion models.Promotion, base float64) float64 {
+	if base <= 0 {
+		return 0
+	}
+	switch promotion.DiscountType {
+	case models.DiscountPercent:
+		return roundAmount(base * math.Min(promotion.DiscountValue, 100) / 100)
+	case models.DiscountNominal:
+		return roundAmount(math.Min(promotion.DiscountValue, base))
+	}
+	return 0
+}
+
+// itemPromotionAmount menghitung potongan sebuah promo level item untuk satu baris.
+// 'lineNet' adalah nilai baris setelah diskon manual; potongan tidak pernah melebihinya.
+func itemPromotionAmount(promotion models.Promotion, item models.TransactionItem, productCategories map[uint]*uint, lineNet float64) float64 {
+	if item.ProductID == nil || lineNet <= 0 {
+		return 0
+	}
+	matchesProduct := promotion.ProductID != nil && *promotion.ProductID == *item.ProductID
+
+	var amount float64
+	switch promotion.Type {
+	case models.PromoBuyXGetY:
+		if !matchesProduct {
+			return 0
+		}
+		sets := item.Quantity / (promotion.BuyQuantity + promotion.GetQuantity)
+		amount = float64(sets*promotion.GetQuantity) * item.BaseUnitPrice() // [DIUBAH] Promo dihitung per satuan dasar
+	case models.PromoBundle:
+		if !matchesProduct {
+			return 0
+		}
+		sets := item.Quantity / promotion.BuyQuantity
+		amount = float64(sets) * (float64(promotion.BuyQuantity)*item.BaseUnitPrice() - promotion.BundlePrice)
+	case models.PromoHappyHour:
+		if promotion.ProductID != nil && !matchesProduct {
+			return 0
+		}
+		amount = promotionDiscount(promotion, lineNet)
+	case models.PromoCategory:
+		categoryID := productCategories[*item.ProductID]
+		if categoryID == nil || promotion.CategoryID == nil || *categoryID != *promotion.CategoryID {
+			return 0
+		}
+		amount = promotionDiscount(promotion, lineNet)
+	default:
+		return 0
+	}
+	return roundAmount(math.Max(math.Min(amount, lineNet), 0))
+}
+
+// bestItemPromotion memilih promo level item dengan potongan terbesar untuk satu baris (promo tidak ditumpuk)
+func bestItemPromotion(promotions []models.Promotion, item models.TransactionItem, productCategories map[uint]*uint, lineNet float64) (*models.Promotion, float64) {
+	var best *models.Promotion
+	var bestAmount float64
+	for i := range promotions {
+		amount := itemPromotionAmount(promotions[i], item, productCategories, lineNet)
+		if amount > bestAmount {
+			best, bestAmount = &promotions[i], amount
+		}
+	}
+	return best, bestAmount
+}
+
+// bestOrderPromotion memilih promo minimal belanja dengan potongan terbesar untuk subtotal transaksi
+func bestOrderPromotion(promotions []models.Promotion, subtotal float64) (*models.Promotion, float64) {
+	var best *models.Promotion
+	var bestAmount float64
+	for i := range promotions {
+		if promotions[i].Type != models.PromoMinSpend || subtotal < promotions[i].MinSpend {
+			continue
+		}
+		amount := promotionDiscount(promotions[i], subtotal)
+		if amount > bestAmount {
+			best, bestAmount = &promotions[i], amount
+		}
+	}
+	return best, bestAmount
+}
+
+// appliedPromotions mengumpulkan potongan per promo (satu baris per promo, urut sesuai pemakaian pertama)
+type appliedPromotions struct {
+	list  []models.TransactionPromotion
+	index map[uint]int
+}
+
+func newAppliedPromotions() *appliedPromotions {
+	return &appliedPromotions{list: []models.TransactionPromotion{}, index: make(map[uint]int)}
+}
+
+func (a *appliedPromotions) add(promotion models.Promotion, amount float64) {
+	idx, ok := a.index[promotion.ID]
+	if !ok {
+		a.list = append(a.list, models.TransactionPromotion{
+			PromotionID: promotion.ID,
+			Name:        promotion.Name,
+			Type:        promotion.Type,
+		})
+		idx = len(a.list) - 1
+		a.index[promotion.ID] = idx
+	}
+	a.list[idx].DiscountAmount = roundAmount(a.list[idx].DiscountAmount + amount)
+}
+
+// --- Pratinjau Harga ---
+
+// PreviewPrice menghitung harga keranjang penjualan (diskon, promo, pajak) tanpa menyimpan transaksi.
+// Perhitungannya sama persis dengan CreateTransaction untuk tipe INCOME.
+func (s *PromotionService) PreviewPrice(input dto.PricePreviewInput, userID uint) (dto.PricePreviewResponse, error) {
+	db := database.DB
+	response := dto.PricePreviewResponse{
+		Items:      []dto.PricePreviewItem{},
+		Promotions: []dto.AppliedPromotionResponse{},
+	}
+
+	at, err := parseTransactionDate(input.TransactionDate)
+	if err != nil {
+		return response, err
+	}
+
+	taxRates := make(map[uint]models.TaxRate)
+	productCategories := make(map[uint]*uint)
+	var items []models.TransactionItem
+	for _, itemInput := range input.Items {
+		taxExempt := false
+		var product *models.Product // [BARU] Untuk konversi satuan
+		if itemInput.ProductID != nil {
+			product = &models.Product{}
+			if err := db.Where("id = ? AND user_id = ?", *itemInput.ProductID, userID).First(product).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return response, fmt.Errorf("produk ID %d tidak ditemukan", *itemInput.ProductID)
+				}
+				return response, err
+			}
+			taxExempt = product.TaxExempt
+			productCategories[product.ID] = product.CategoryID
+		}
+		unit, err := resolveItemUnit(db, product, itemInput.ProductName, itemInput.Unit, itemInput.Quantity)
+		if err != nil {
+			return response, err
+		}
+
+		rate, appliedTaxRateID, err := resolveItemTaxRate(db, taxRates, itemInput.TaxRateID, input.TaxRateID, taxExempt, userID)
+		if err != nil {
+			return response, err
+		}
+		itemDiscount, err := calculateDiscount(itemInput.DiscountType, itemInput.DiscountValue, itemInput.UnitPrice*itemInput.Quantity)
+		if err != nil {
+			return response, fmt.Errorf("%s: %v", itemInput.ProductName, err)
+		}
+		items = append(items, models.TransactionItem{
+			ProductID:      itemInput.ProductID,
+			ProductName:    itemInput.ProductName,
+			Quantity:       unit.BaseQuantity, // [DIUBAH]
+			UnitPrice:      itemInput.UnitPrice,
+			UnitName:       unit.Name,
+			UnitFactor:     unit.Factor,
+			UnitQuantity:   unit.Quantity,
+			TaxRateID:      appliedTaxRateID,
+			TaxRate:        rate,
+			TaxInclusive:   itemInput.TaxInclusive,
+			DiscountType:   itemInput.DiscountType,
+			DiscountValue:  itemInput.DiscountValue,
+			DiscountAmount: itemDiscount,
+		})
+	}
+
+	pricing, err := priceTransactionItems(db, userID, models.Income, at, items, productCategories, input.DiscountType, input.DiscountValue, 0)
+	if err != nil {
+		return response, err
+	}
+
+	for _, item := range items {
+		response.Items = append(response.Items, dto.PricePreviewItem{
+			ProductID:                 item.ProductID,
+			ProductName:               item.ProductName,
+			Quantity:                  item.Quantity,
+			UnitPrice:                 item.UnitPrice,
+			Unit:                      item.UnitName,     // [BARU]
+			UnitQuantity:              item.UnitQuantity, // [BARU]
+			GrossAmount:               item.LineGross(),
+			DiscountAmount:            item.DiscountAmount,
+			PromotionDiscountAmount:   item.PromotionDiscountAmount,
+			TransactionDiscountAmount: item.TransactionDiscountAmount,
+			TaxableAmount:             item.TaxableAmount,
+			TaxAmount:                 item.TaxAmount,
+			TotalAmount:               item.TaxableAmount + item.TaxAmount,
+		})
+		response.ItemDiscount += item.DiscountAmount
+	}
+	for _, promotion := range pricing.Promotions {
+		response.Promotions = append(response.Promotions, dto.AppliedPromotionResponse{
+			PromotionID:    promotion.PromotionID,
+			Name:           promotion.Name,
+			Type:           promotion.Type,
+			DiscountAmount: promotion.DiscountAmount,
+		})
+	}
+	response.GrossAmount = pricing.GrossAmount
+	response.ItemDiscount = roundAmount(response.ItemDiscount)
+	response.DiscountAmount = pricing.DiscountAmount
+	response.PromotionAmount = pricing.PromotionAmount
+	response.TaxAmount = pricing.TaxAmount
+	response.TotalAmount = pricing.TotalAmount
+	return response, nil
+}
+
+// --- Laporan Promo ---
+
+// GetPromotionReport merekap pemakaian promo (jumlah transaksi & total potongan) dalam rentang waktu
+// [DIUBAH] locationID diisi = hanya promo yang dipakai di lokasi tersebut
+func (s *PromotionService) GetPromotionReport(userID uint, locationID *uint, startTime time.Time, endTime time.Time) ([]dto.PromotionReportLine, error) {
+	results := []dto.PromotionReportLine{}
+	err := database.DB.Model(&models.TransactionPromotion{}).
+		Select("transaction_promotions.promotion_id, MAX(transaction_promotions.name) as name, MAX(transaction_promotions.type) as type, COUNT(DISTINCT transaction_promotions.transaction_id) as transaction_count, SUM(transaction_promotions.discount_amount) as total_discount").
+		Joins("JOIN transactions ON transactions.id = transaction_promotions.transaction_id").
+		Scopes(locationScope(locationID)).
+		Where("transactions.user_id = ? AND transactions.status <> ? AND transactions.created_at BETWEEN ? AND ?", userID, models.StatusVoid, startTime, endTime).
+		Group("transaction_promotions.promotion_id").
+		Order("total_discount desc").
+		Scan(&results).Error
+	if err != nil {
+		log.Printf("Error querying promotion report: %v", err)
+		return nil, err
+	}
+	return results, nil
+}
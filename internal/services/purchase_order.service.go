@@ -0,0 +1,504 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/danishyusrah/go_bisnis/internal/database"
+	"github.com/danishyusrah/go_bisnis/internal/dto"
+	"github.com/danishyusrah/go_bisnis/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Error status PO yang dipetakan ke 409 oleh handler
+var (
+	errPurchaseOrderNotDraft      = errors.New("PO hanya dapat diubah saat masih DRAFT")
+	errPurchaseOrderNotReceivable = errors.New("barang hanya dapat diterima untuk PO yang sudah dipesan dan belum diterima seluruhnya")
+	errPurchaseOrderNotBillable   = errors.New("PO belum memiliki barang diterima atau sudah ditagih")
+)
+
+// PurchaseOrderService adalah struct untuk layanan pesanan pembelian (PO) & penerimaan barang
+type PurchaseOrderService struct{}
+
+// NewPurchaseOrderService membuat instance PurchaseOrderService baru
+func NewPurchaseOrderService() *PurchaseOrderService {
+	return &PurchaseOrderService{}
+}
+
+// findOwnedPurchaseOrder mengambil PO beserta itemnya dan memvalidasi kepemilikan.
+// Jika lock = true, baris PO dikunci (FOR UPDATE) agar tidak diterima/ditagih bersamaan.
+func findOwnedPurchaseOrder(tx *gorm.DB, orderID uint, userID uint, lock bool) (models.PurchaseOrder, error) {
+	var order models.PurchaseOrder
+	query := tx
+	if lock {
+		query = query.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+	if err := query.First(&order, orderID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.PurchaseOrder{}, errors.New("PO tidak ditemukan")
+		}
+		return models.PurchaseOrder{}, err
+	}
+	if order.UserID != userID {
+		return models.PurchaseOrder{}, errors.New("akses ditolak: Anda bukan pemilik PO ini")
+	}
+	if err := tx.Where("purchase_order_id = ?", order.ID).Order("id asc").Find(&order.Items).Error; err != nil {
+		return models.PurchaseOrder{}, err
+	}
+	return order, nil
+}
+
+// purchaseOrderItems memvalidasi baris PO: produk milik user, bukan produk komposit
+// atau produk induk varian, dan tiap produk hanya muncul sekali
+func purchaseOrderItems(tx *gorm.DB, userID uint, inputs []dto.PurchaseOrderItemInput) ([]models.PurchaseOrderItem, error) {
+	seen := make(map[uint]bool)
+	var items []models.PurchaseOrderItem
+	for _, input := range inputs {
+		var product models.Product
+		if err := tx.First(&product, input.ProductID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("produk ID %d tidak ditemukan", input.ProductID)
+			}
+			return nil, err
+		}
+		if product.UserID != userID {
+			return nil, fmt.Errorf("akses ditolak: produk ID %d bukan milik Anda", input.ProductID)
+		}
+		if product.IsComposite {
+			return nil, fmt.Errorf("produk %s adalah produk komposit, pesan bahan-bahannya", product.Name)
+		}
+		hasVariants, err := productHasVariants(tx, product.ID)
+		if err != nil {
+			return nil, err
+		}
+		if hasVariants {
+			return nil, fmt.Errorf("produk %s memiliki varian, pilih varian yang akan dipesan", product.Name)
+		}
+		if seen[product.ID] {
+			return nil, fmt.Errorf("produk %s tercantum lebih dari sekali", product.Name)
+		}
+		seen[product.ID] = true
+
+		items = append(items, models.PurchaseOrderItem{
+			ProductID:   product.ID,
+			ProductName: product.Name,
+			Quantity:    input.Quantity,
+			UnitCost:    roundAmount(input.UnitCost),
+		})
+	}
+	return items, nil
+}
+
+// applyPurchaseOrderInput memvalidasi header & item PO lalu mengisinya ke 'order'
+func applyPurchaseOrderInput(tx *gorm.DB, order *models.PurchaseOrder, input dto.PurchaseOrderInput, userID uint) error {
+	supplier, err := findOwnedSupplier(tx, input.SupplierID, userID)
+	if err != nil {
+		return err
+	}
+
+	locationID, err := resolveLocation(tx, userID, input.LocationID)
+	if err != nil {
+		return err
+	}
+	if input.TaxRateID != nil {
+		if _, err := findOwnedTaxRate(tx, *input.TaxRateID, userID); err != nil {
+			return err
+		}
+	}
+
+	orderDate, err := parseTransactionDate(input.OrderDate)
+	if err != nil {
+		return err
+	}
+	var expectedDate *time.Time
+	if input.ExpectedDate != nil && *input.ExpectedDate != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", *input.ExpectedDate, time.Local)
+		if err != nil {
+			return errors.New("format tanggal perkiraan datang tidak valid, gunakan YYYY-MM-DD")
+		}
+		expectedDate = &parsed
+	}
+
+	items, err := purchaseOrderItems(tx, userID, input.Items)
+	if err != nil {
+		return err
+	}
+
+	order.SupplierID = supplier.ID
+	order.LocationID = locationID
+	order.TaxRateID = input.TaxRateID
+	order.OrderDate = orderDate
+	order.ExpectedDate = expectedDate
+	order.Notes = input.Notes
+	order.Items = items
+	return nil
+}
+
+// GetPurchaseOrders mengambil semua PO milik user (terbaru dulu), bisa difilter per status
+func (s *PurchaseOrderService) GetPurchaseOrders(userID uint, status string) ([]models.PurchaseOrder, error) {
+	var orders []models.PurchaseOrder
+	query := database.DB.Preload("Supplier", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).Preload("Items").Where("user_id = ?", userID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Order("order_date desc, id desc").Find(&orders).Error
+	return orders, err
+}
+
+// GetPurchaseOrderByID mengambil satu PO beserta item dan riwayat penerimaannya
+func (s *PurchaseOrderService) GetPurchaseOrderByID(orderID uint, userID uint) (models.PurchaseOrder, error) {
+	db := database.DB
+	order, err := findOwnedPurchaseOrder(db, orderID, userID, false)
+	if err != nil {
+		return models.PurchaseOrder{}, err
+	}
+	if err := db.Unscoped().First(&order.Supplier, order.SupplierID).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.PurchaseOrder{}, err
+	}
+	receipts, err := s.GetGoodsReceipts(order.ID, userID)
+	if err != nil {
+		return models.PurchaseOrder{}, err
+	}
+	order.Receipts = receipts
+	return order, nil
+}
+
+// CreatePurchaseOrder membuat PO baru berstatus DRAFT
+func (s *PurchaseOrderService) CreatePurchaseOrder(input dto.PurchaseOrderInput, userID uint) (models.PurchaseOrder, error) {
+	order := models.PurchaseOrder{UserID: userID, Status: models.PODraft}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := applyPurchaseOrderInput(tx, &order, input, userID); err != nil {
+			return err
+		}
+		return tx.Create(&order).Error
+	})
+	if err != nil {
+		return models.PurchaseOrder{}, err
+	}
+	return s.GetPurchaseOrderByID(order.ID, userID)
+}
+
+// UpdatePurchaseOrder mengubah PO yang masih DRAFT. Seluruh item diganti dengan item baru.
+func (s *PurchaseOrderService) UpdatePurchaseOrder(orderID uint, userID uint, input dto.PurchaseOrderInput) (models.PurchaseOrder, error) {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		order, err := findOwnedPurchaseOrder(tx, orderID, userID, true)
+		if err != nil {
+			return err
+		}
+		if order.Status != models.PODraft {
+			return errPurchaseOrderNotDraft
+		}
+		if err := applyPurchaseOrderInput(tx, &order, input, userID); err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("purchase_order_id = ?", order.ID).Delete(&models.PurchaseOrderItem{}).Error; err != nil {
+			return err
+		}
+		for i := range order.Items {
+			order.Items[i].PurchaseOrderID = order.ID
+		}
+		if err := tx.Create(&order.Items).Error; err != nil {
+			return errors.New("gagal menyimpan item PO")
+		}
+		return tx.Omit(clause.Associations).Save(&order).Error
+	})
+	if err != nil {
+		return models.PurchaseOrder{}, err
+	}
+	return s.GetPurchaseOrderByID(orderID, userID)
+}
+
+// DeletePurchaseOrder menghapus PO yang masih DRAFT
+func (s *PurchaseOrderService) DeletePurchaseOrder(orderID uint, userID uint) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		order, err := findOwnedPurchaseOrder(tx, orderID, userID, true)
+		if err != nil {
+			return err
+		}
+		if order.Status != models.PODraft {
+			return errPurchaseOrderNotDraft
+		}
+		if err := tx.Where("purchase_order_id = ?", order.ID).Delete(&models.PurchaseOrderItem{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&order).Error
+	})
+}
+
+// OrderPurchaseOrder mengirim PO DRAFT ke supplier (status ORDERED). Setelah ini PO tidak bisa diubah.
+func (s *PurchaseOrderService) OrderPurchaseOrder(orderID uint, userID uint) (models.PurchaseOrder, error) {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		order, err := findOwnedPurchaseOrder(tx, orderID, userID, true)
+		if err != nil {
+			return err
+		}
+		if order.Status != models.PODraft {
+			return errPurchaseOrderNotDraft
+		}
+		now := time.Now()
+		return tx.Model(&order).Updates(map[string]interface{}{
+			"status":     models.POOrdered,
+			"ordered_at": &now,
+		}).Error
+	})
+	if err != nil {
+		return models.PurchaseOrder{}, err
+	}
+	return s.GetPurchaseOrderByID(orderID, userID)
+}
+
+// CancelPurchaseOrder membatalkan PO yang belum ada penerimaan barangnya
+func (s *PurchaseOrderService) CancelPurchaseOrder(orderID uint, userID uint) (models.PurchaseOrder, error) {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		order, err := findOwnedPurchaseOrder(tx, orderID, userID, true)
+		if err != nil {
+			return err
+		}
+		if order.Status != models.PODraft && order.Status != models.POOrdered {
+			return errors.New("PO yang sudah ada penerimaan barang atau sudah dibatalkan tidak dapat dibatalkan")
+		}
+		return tx.Model(&order).Update("status", models.POCancelled).Error
+	})
+	if err != nil {
+		return models.PurchaseOrder{}, err
+	}
+	return s.GetPurchaseOrderByID(orderID, userID)
+}
+
+// GetGoodsReceipts mengambil riwayat penerimaan barang sebuah PO
+func (s *PurchaseOrderService) GetGoodsReceipts(orderID uint, userID uint) ([]models.GoodsReceipt, error) {
+	var receipts []models.GoodsReceipt
+	err := database.DB.Preload("Items").
+		Where("purchase_order_id = ? AND user_id = ?", orderID, userID).
+		Order("receipt_date asc, id asc").
+		Find(&receipts).Error
+	return receipts, err
+}
+
+// ReceiveGoods mencatat penerimaan barang (boleh sebagian). Stok bertambah di lokasi PO dengan
+// biaya harga PO, lalu dijurnal Persediaan (D) - Barang Diterima Belum Ditagih (K)
+// sampai tagihan supplier dicatat.
+func (s *PurchaseOrderService) ReceiveGoods(orderID uint, userID uint, input dto.CreateGoodsReceiptInput) (models.GoodsReceipt, error) {
+	var receipt models.GoodsReceipt
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		order, err := findOwnedPurchaseOrder(tx, orderID, userID, true)
+		if err != nil {
+			return err
+		}
+		if order.Status != models.POOrdered && order.Status != models.POPartiallyReceived {
+			return errPurchaseOrderNotReceivable
+		}
+
+		receiptDate, err := parseTransactionDate(input.ReceiptDate)
+		if err != nil {
+			return err
+		}
+		if err := ensurePeriodOpen(tx, userID, receiptDate); err != nil {
+			return err
+		}
+
+		itemsByID := make(map[uint]*models.PurchaseOrderItem)
+		for i := range order.Items {
+			itemsByID[order.Items[i].ID] = &order.Items[i]
+		}
+
+		receipt = models.GoodsReceipt{
+			UserID:          userID,
+			PurchaseOrderID: order.ID,
+			LocationID:      order.LocationID,
+			ReceiptDate:     receiptDate,
+			Notes:           input.Notes,
+		}
+		notes := fmt.Sprintf("Penerimaan PO #%d", order.ID)
+
+		for _, itemInput := range input.Items {
+			item, ok := itemsByID[itemInput.PurchaseOrderItemID]
+			if !ok {
+				return fmt.Errorf("item PO ID %d bukan bagian dari PO ini", itemInput.PurchaseOrderItemID)
+			}
+			if itemInput.Quantity > item.RemainingQuantity() {
+				return fmt.Errorf("jumlah diterima untuk %s melebihi sisa pesanan (sisa: %d)", item.ProductName, item.RemainingQuantity())
+			}
+			expiryDate, err := parseExpiryDate(itemInput.ExpiryDate)
+			if err != nil {
+				return err
+			}
+
+			product, err := lockOwnedProduct(tx, item.ProductID, userID)
+			if err != nil {
+				return err
+			}
+			unitCost := item.UnitCost
+			if _, err := moveProductStock(tx, &product, order.LocationID, itemInput.Quantity, &unitCost, models.MovementReceipt, nil, receiptDate, notes); err != nil {
+				return err
+			}
+			if product.TrackLots {
+				if err := receiveProductBatch(tx, &product, itemInput.Quantity, itemInput.LotNumber, expiryDate, nil); err != nil {
+					return err
+				}
+			}
+
+			item.ReceivedQuantity += itemInput.Quantity
+			if err := tx.Model(item).Update("received_quantity", item.ReceivedQuantity).Error; err != nil {
+				return errors.New("gagal memperbarui jumlah diterima")
+			}
+
+			receipt.TotalAmount += roundAmount(float64(itemInput.Quantity) * item.UnitCost)
+			receipt.Items = append(receipt.Items, models.GoodsReceiptItem{
+				PurchaseOrderItemID: item.ID,
+				ProductID:           item.ProductID,
+				ProductName:         item.ProductName,
+				Quantity:            itemInput.Quantity,
+				UnitCost:            item.UnitCost,
+				LotNumber:           itemInput.LotNumber,
+				ExpiryDate:          expiryDate,
+			})
+		}
+		receipt.TotalAmount = roundAmount(receipt.TotalAmount)
+
+		if err := tx.Create(&receipt).Error; err != nil {
+			return errors.New("gagal menyimpan penerimaan barang")
+		}
+		if err := postJournal(tx, userID, receiptDate, fmt.Sprintf("%s (penerimaan #%d)", notes, receipt.ID), models.JournalSourceReceipt, nil, []journalLineInput{
+			{Code: models.AccountCodeInventory, Debit: receipt.TotalAmount},
+			{Code: models.AccountCodeGoodsReceived, Credit: receipt.TotalAmount},
+		}); err != nil {
+			return err
+		}
+
+		return tx.Model(&order).Update("status", receivedStatus(order.Items)).Error
+	})
+	if err != nil {
+		return models.GoodsReceipt{}, err
+	}
+	return receipt, nil
+}
+
+// receivedStatus menentukan status PO dari jumlah yang sudah diterima
+func receivedStatus(items []models.PurchaseOrderItem) models.PurchaseOrderStatus {
+	for _, item := range items {
+		if item.RemainingQuantity() > 0 {
+			return models.POPartiallyReceived
+		}
+	}
+	return models.POReceived
+}
+
+// BillPurchaseOrder mencatat tagihan supplier untuk barang yang sudah diterima sebagai transaksi
+// EXPENSE BELUM LUNAS (utang usaha). PO yang baru diterima sebagian ditutup: sisa pesanan
+// tidak bisa diterima lagi.
+func (s *PurchaseOrderService) BillPurchaseOrder(orderID uint, userID uint, input dto.BillPurchaseOrderInput) (models.Transaction, error) {
+	order, err := findOwnedPurchaseOrder(database.DB, orderID, userID, false)
+	if err != nil {
+		return models.Transaction{}, err
+	}
+	if order.Status != models.POReceived && order.Status != models.POPartiallyReceived {
+		return models.Transaction{}, errPurchaseOrderNotBillable
+	}
+
+	var items []dto.CreateTransactionItemInput
+	for _, item := range order.Items {
+		if item.ReceivedQuantity == 0 {
+			continue
+		}
+		productID := item.ProductID
+		items = append(items, dto.CreateTransactionItemInput{
+			ProductID:   &productID,
+			ProductName: item.ProductName,
+			Quantity:    float64(item.ReceivedQuantity),
+			UnitPrice:   item.UnitCost,
+		})
+	}
+
+	notes := fmt.Sprintf("Tagihan PO #%d", order.ID)
+	if input.InvoiceNumber != "" {
+		notes += " - Faktur " + input.InvoiceNumber
+	}
+	if input.Notes != "" {
+		notes += ": " + input.Notes
+	}
+
+	return NewTransactionService().CreateTransaction(dto.CreateTransactionInput{
+		Type:            models.Expense,
+		Notes:           notes,
+		Items:           items,
+		SupplierID:      &order.SupplierID,
+		PaymentStatus:   models.BelumLunas,
+		DueDate:         input.DueDate,
+		TransactionDate: input.TransactionDate,
+		CategoryID:      input.CategoryID,
+		TaxRateID:       order.TaxRateID,
+		LocationID:      &order.LocationID,
+		PurchaseOrderID: &order.ID,
+	}, userID)
+}
+
+// markPurchaseOrderBilled menandai PO sebagai BILLED oleh transaksi tagihan yang baru dibuat.
+// Dipanggil di dalam database transaction CreateTransaction agar keduanya atomik.
+func markPurchaseOrderBilled(tx *gorm.DB, orderID uint, userID uint, transaction *models.Transaction) error {
+	order, err := findOwnedPurchaseOrder(tx, orderID, userID, true)
+	if err != nil {
+		return err
+	}
+	if order.Status != models.POReceived && order.Status != models.POPartiallyReceived {
+		return errPurchaseOrderNotBillable
+	}
+	if transaction.SupplierID == nil || *transaction.SupplierID != order.SupplierID {
+		return errors.New("supplier tagihan tidak sesuai dengan supplier PO")
+	}
+	// Penerimaan bisa bertambah di antara penyusunan tagihan dan lock di atas
+	var received, billed int
+	for _, item := range order.Items {
+		received += item.ReceivedQuantity
+	}
+	for _, item := range transaction.Items {
+		billed += item.Quantity
+	}
+	if received != billed {
+		return errors.New("penerimaan barang PO berubah, silakan ulangi penagihan")
+	}
+	return tx.Model(&order).Updates(map[string]interface{}{
+		"status":              models.POBilled,
+		"bill_transaction_id": transaction.ID,
+		"billed_at":           transaction.CreatedAt,
+	}).Error
+}
+
+// reopenBilledPurchaseOrder mengembalikan PO ke status diterima saat transaksi tagihannya di-void,
+// sehingga PO bisa ditagih ulang
+func reopenBilledPurchaseOrder(tx *gorm.DB, transaction *models.Transaction) error {
+	if transaction.RefundedAmount > 0 {
+		return errors.New("tagihan PO yang sudah diretur tidak dapat dibatalkan")
+	}
+	order, err := findOwnedPurchaseOrder(tx, *transaction.PurchaseOrderID, transaction.UserID, true)
+	if err != nil {
+		return err
+	}
+	return tx.Model(&order).Updates(map[string]interface{}{
+		"status":              receivedStatus(order.Items),
+		"bill_transaction_id": nil,
+		"billed_at":           nil,
+	}).Error
+}
+
+// goodsReceivedNotBilledAsOf menghitung nilai barang PO yang sudah diterima tetapi belum ditagih
+// per 'asOf': total penerimaan dikurangi DPP produk pada tagihan PO yang tidak di-void
+func goodsReceivedNotBilledAsOf(userID uint, asOf time.Time) (float64, error) {
+	db := database.DB
+	var received, billed sumResult
+	if err := db.Model(&models.GoodsReceipt{}).
+		Select("COALESCE(SUM(total_amount), 0) as total").
+		Where("user_id = ? AND receipt_date <= ?", userID, asOf).
+		Scan(&received).Error; err != nil {
+		return 0, err
+	}
+	err := db.Model(&models.TransactionItem{}).
+		Select("COALESCE(SUM(transaction_items.taxable_amount), 0) as total").
+		Joins("JOIN transactions ON transactions.id = transaction_items.transaction_id").
+		Where("transactions.user_id = ? AND transactions.purchase_order_id IS NOT NULL AND transactions.status <> ? AND transactions.deleted_at IS NULL AND transactions.created_at <= ? AND transaction_items.product_id IS NOT NULL",
+			userID, models.StatusVoid, asOf).
+		Scan(&billed).Error
+	return received.Total - billed.Total, err
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/danishyusrah/go_bisnis/internal/dto"
+	"github.com/danishyusrah/go_bisnis/internal/models"
+)
+
+func TestPurchaseOrderReceiptAndBilling(t *testing.T) {
+	db, userID := useServiceTestDB(t)
+	products := NewProductService()
+	orders := NewPurchaseOrderService()
+	transactions := NewTransactionService()
+
+	beras, err := products.CreateProduct(dto.CreateProductInput{Name: "Beras", SKU: "BRS", SellingPrice: 12000}, userID)
+	if err != nil {
+		t.Fatalf("CreateProduct() beras error = %v", err)
+	}
+	minyak, err := products.CreateProduct(dto.CreateProductInput{Name: "Minyak", SKU: "MNY", SellingPrice: 18000}, userID)
+	if err != nil {
+		t.Fatalf("CreateProduct() minyak error = %v", err)
+	}
+	supplier, err := NewSupplierService().CreateSupplier(dto.CreateSupplierInput{Name: "CV Sumber Pangan"}, userID)
+	if err != nil {
+		t.Fatalf("CreateSupplier() error = %v", err)
+	}
+	location, err := defaultLocation(db, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	order, err := orders.CreatePurchaseOrder(dto.PurchaseOrderInput{
+		SupplierID: supplier.ID,
+		Items: []dto.PurchaseOrderItemInput{
+			{ProductID: beras.ID, Quantity: 10, UnitCost: 9000},
+			{ProductID: minyak.ID, Quantity: 5, UnitCost: 14000},
+		},
+	}, userID)
+	if err != nil {
+		t.Fatalf("CreatePurchaseOrder() error = %v", err)
+	}
+	itemIDs := make(map[uint]uint) // ID produk -> ID item PO
+	for _, item := range order.Items {
+		itemIDs[item.ProductID] = item.ID
+	}
+
+	// PO yang masih draft belum bisa diterima
+	receive := func(items ...dto.GoodsReceiptItemInput) (models.GoodsReceipt, error) {
+		return orders.ReceiveGoods(order.ID, userID, dto.CreateGoodsReceiptInput{Items: items})
+	}
+	if _, err := receive(dto.GoodsReceiptItemInput{PurchaseOrderItemID: itemIDs[beras.ID], Quantity: 1}); !errors.Is(err, errPurchaseOrderNotReceivable) {
+		t.Fatalf("ReceiveGoods() PO draft error = %v, want %v", err, errPurchaseOrderNotReceivable)
+	}
+	if _, err := orders.OrderPurchaseOrder(order.ID, userID); err != nil {
+		t.Fatalf("OrderPurchaseOrder() error = %v", err)
+	}
+
+	// Penerimaan sebagian menambah stok & Persediaan, dengan lawan Barang Diterima Belum Ditagih
+	receipt, err := receive(
+		dto.GoodsReceiptItemInput{PurchaseOrderItemID: itemIDs[beras.ID], Quantity: 6},
+		dto.GoodsReceiptItemInput{PurchaseOrderItemID: itemIDs[minyak.ID], Quantity: 5},
+	)
+	if err != nil {
+		t.Fatalf("ReceiveGoods() error = %v", err)
+	}
+	assertAmount(t, "nilai penerimaan", receipt.TotalAmount, 6*9000+5*14000)
+	assertStock(t, db, beras.ID, location.ID, 6, 6)
+	assertStock(t, db, minyak.ID, location.ID, 5, 5)
+
+	balances := accountBalances(t, db, userID)
+	assertAmount(t, "persediaan setelah penerimaan", balances[models.AccountCodeInventory], 124000)
+	assertAmount(t, "barang diterima belum ditagih", balances[models.AccountCodeGoodsReceived], -124000)
+
+	if _, err := receive(dto.GoodsReceiptItemInput{PurchaseOrderItemID: itemIDs[beras.ID], Quantity: 5}); err == nil || !strings.Contains(err.Error(), "melebihi sisa") {
+		t.Fatalf("ReceiveGoods() error = %v, want melebihi sisa pesanan", err)
+	}
+	if saved, err := orders.GetPurchaseOrderByID(order.ID, userID); err != nil || saved.Status != models.POPartiallyReceived {
+		t.Fatalf("status PO = %v (error %v), want %s", saved.Status, err, models.POPartiallyReceived)
+	}
+
+	// Tagihan memindahkan saldo ke Utang Usaha tanpa menambah stok lagi
+	bill, err := orders.BillPurchaseOrder(order.ID, userID, dto.BillPurchaseOrderInput{InvoiceNumber: "INV-001"})
+	if err != nil {
+		t.Fatalf("BillPurchaseOrder() error = %v", err)
+	}
+	if bill.PaymentStatus != models.BelumLunas || bill.SupplierID == nil || *bill.SupplierID != supplier.ID {
+		t.Errorf("tagihan = (%s, supplier %v), want BELUM LUNAS untuk supplier %d", bill.PaymentStatus, bill.SupplierID, supplier.ID)
+	}
+	assertAmount(t, "nilai tagihan", bill.TotalAmount, 124000)
+	assertStock(t, db, beras.ID, location.ID, 6, 6)
+	assertStock(t, db, minyak.ID, location.ID, 5, 5)
+
+	balances = accountBalances(t, db, userID)
+	assertAmount(t, "persediaan setelah tagihan", balances[models.AccountCodeInventory], 124000)
+	assertAmount(t, "barang diterima belum ditagih setelah tagihan", balances[models.AccountCodeGoodsReceived], 0)
+	assertAmount(t, "utang usaha", balances[models.AccountCodePayable], -124000)
+
+	if _, err := orders.BillPurchaseOrder(order.ID, userID, dto.BillPurchaseOrderInput{}); !errors.Is(err, errPurchaseOrderNotBillable) {
+		t.Errorf("BillPurchaseOrder() kedua error = %v, want %v", err, errPurchaseOrderNotBillable)
+	}
+	if _, err := receive(dto.GoodsReceiptItemInput{PurchaseOrderItemID: itemIDs[beras.ID], Quantity: 1}); !errors.Is(err, errPurchaseOrderNotReceivable) {
+		t.Errorf("ReceiveGoods() setelah ditagih error = %v, want %v", err, errPurchaseOrderNotReceivable)
+	}
+
+	// Void tagihan mengembalikan PO ke status diterima; stok tetap karena barang sudah diterima
+	if _, err := transactions.VoidTransaction(bill.ID, userID, dto.VoidTransactionInput{Reason: "Faktur salah"}); err != nil {
+		t.Fatalf("VoidTransaction() tagihan error = %v", err)
+	}
+	if saved, err := orders.GetPurchaseOrderByID(order.ID, userID); err != nil || saved.Status != models.POPartiallyReceived {
+		t.Errorf("status PO setelah void = %v (error %v), want %s", saved.Status, err, models.POPartiallyReceived)
+	}
+	assertStock(t, db, beras.ID, location.ID, 6, 6)
+
+	balances = accountBalances(t, db, userID)
+	assertAmount(t, "barang diterima belum ditagih setelah void", balances[models.AccountCodeGoodsReceived], -124000)
+	assertAmount(t, "utang usaha setelah void", balances[models.AccountCodePayable], 0)
+
+	if _, err := orders.BillPurchaseOrder(order.ID, userID, dto.BillPurchaseOrderInput{InvoiceNumber: "INV-002"}); err != nil {
+		t.Fatalf("BillPurchaseOrder() ulang error = %v", err)
+	}
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"fmt" // [BARU] Impor fmt untuk format deskripsi
 	"log"
+	"sort"
 	"time" // [BARU] Impor time
 
 	"github.com/danishyusrah/go_bisnis/internal/database"
@@ -20,10 +21,20 @@ func NewReportService() *ReportService {
 }
 
 // GetProductPerformanceReport mengambil data performa produk berdasarkan rentang waktu
-func (s *ReportService) GetProductPerformanceReport(userID uint, startTime time.Time, endTime time.Time) ([]dto.ProductPerformanceReport, error) {
+// [DIUBAH] locationID (opsional) membatasi laporan ke transaksi satu lokasi
+// [DIUBAH] rollupParent = true menggabungkan penjualan seluruh varian ke baris produk induknya
+func (s *ReportService) GetProductPerformanceReport(userID uint, locationID *uint, rollupParent bool, startTime time.Time, endTime time.Time) ([]dto.ProductPerformanceReport, error) {
 	db := database.DB
 	var results []dto.ProductPerformanceReport
 
+	// [BARU] Kolom pengelompokan: per item (default) atau per produk induk (varian digabung)
+	productIDColumn := "transaction_items.product_id"
+	productNameColumn := "transaction_items.product_name"
+	if rollupParent {
+		productIDColumn = "COALESCE(products.parent_id, transaction_items.product_id)"
+		productNameColumn = "COALESCE(parents.name, transaction_items.product_name)"
+	}
+
 	// Query ini akan:
 	// 1. Mengambil dari tabel transaction_items
 	// 2. Bergabung (JOIN) dengan tabel transactions untuk memfilter
@@ -31,14 +42,24 @@ func (s *ReportService) GetProductPerformanceReport(userID uint, startTime time.
 	//    - user_id (pemilik)
 	//    - transaction.type = 'INCOME' (hanya penjualan)
 	//    - Rentang waktu (created_at)
+	//    - [BARU] Transaksi VOID diabaikan
+	//    - [BARU] Pendapatan dihitung tanpa PPN (DPP)
+	//    - [BARU] Diskon (item + alokasi diskon transaksi) dihitung terpisah
 	// 4. Mengelompokkan (GROUP BY) berdasarkan nama produk dan ID produk
-	// 5. Menghitung (SUM) total kuantitas terjual dan total pendapatan
+	// 5. Menghitung (SUM) total kuantitas terjual dan total pendapatan (bersih setelah retur)
 	// 6. Mengurutkan (ORDER BY) berdasarkan pendapatan tertinggi
-	err := db.Model(&models.TransactionItem{}).
-		Select("transaction_items.product_id, transaction_items.product_name, SUM(transaction_items.quantity) as total_sold, SUM(transaction_items.quantity * transaction_items.unit_price) as total_revenue").
-		Joins("JOIN transactions ON transactions.id = transaction_items.transaction_id").
-		Where("transactions.user_id = ? AND transactions.type = ? AND transactions.created_at BETWEEN ? AND ?", userID, models.Income, startTime, endTime).
-		Group("transaction_items.product_name, transaction_items.product_id").
+	query := db.Model(&models.TransactionItem{}).
+		Select(productIDColumn + " as product_id, " + productNameColumn + " as product_name, SUM(" + netQuantitySQL + ") as total_sold, SUM(" + netQuantitySQL + " * " + unitNetPriceSQL + ") as total_revenue, SUM(" + netQuantitySQL + " * " + unitDiscountSQL + ") as total_discount").
+		Joins("JOIN transactions ON transactions.id = transaction_items.transaction_id")
+	if rollupParent {
+		query = query.
+			Joins("LEFT JOIN products ON products.id = transaction_items.product_id").
+			Joins("LEFT JOIN products parents ON parents.id = products.parent_id")
+	}
+	err := query.
+		Scopes(locationScope(locationID)).
+		Where("transactions.user_id = ? AND transactions.type = ? AND transactions.status <> ? AND transactions.created_at BETWEEN ? AND ?", userID, models.Income, models.StatusVoid, startTime, endTime).
+		Group(productNameColumn + ", " + productIDColumn).
 		Order("total_revenue desc").
 		Scan(&results).Error
 
@@ -47,42 +68,76 @@ func (s *ReportService) GetProductPerformanceReport(userID uint, startTime time.
 		return nil, err
 	}
 
+	// [BARU] Penjualan kotor = penjualan bersih + diskon
+	for i := range results {
+		results[i].TotalDiscount = roundAmount(results[i].TotalDiscount)
+		results[i].NetSales = results[i].TotalRevenue
+		results[i].GrossSales = roundAmount(results[i].TotalRevenue + results[i].TotalDiscount)
+	}
+
 	return results, nil
 }
 
 // --- [BARU] FUNGSI UNTUK BUKU BESAR (GENERAL LEDGER) ---
 
-// GetGeneralLedgerReport membuat laporan buku besar yang mirip buku kas manual
-func (s *ReportService) GetGeneralLedgerReport(userID uint, startTime time.Time, endTime time.Time) (dto.GeneralLedgerReport, error) {
-	db := database.DB
-	var report dto.GeneralLedgerReport
-	var transactions []models.Transaction
+// [BARU] Basis pencatatan buku besar
+const (
+	LedgerBasisAccrual = "accrual" // Transaksi diakui saat dibuat (default)
+	LedgerBasisCash    = "cash"    // Transaksi diakui saat uang benar-benar diterima/dibayar
+)
 
-	// --- 1. Hitung Saldo Awal (Beginning Balance) ---
-	// Saldo awal adalah total (Pemasukan + Modal - Pengeluaran) SEBELUM startTime
-	type BalanceResult struct {
-		Balance float64
+// ledgerDescription membuat deskripsi entri buku besar dari sebuah transaksi
+func ledgerDescription(tx models.Transaction) string {
+	var description string
+	if tx.Type == models.Capital {
+		description = "Setoran Modal" // Deskripsi default untuk modal
+	} else if len(tx.Items) > 0 {
+		// Ambil nama item pertama sebagai deskripsi utama
+		description = tx.Items[0].ProductName
+		if len(tx.Items) > 1 {
+			description = fmt.Sprintf("%s (dan %d item lainnya)", description, len(tx.Items)-1)
+		}
+	} else {
+		// Fallback jika tidak ada item
+		description = "Transaksi " + string(tx.Type)
+	}
+	// Tambahkan catatan jika ada
+	if tx.Notes != "" {
+		description = fmt.Sprintf("%s - %s", description, tx.Notes)
+	}
+	return description
+}
+
+// GetGeneralLedgerReport membuat laporan buku besar yang mirip buku kas manual.
+// [DIUBAH] basis "cash" hanya mencatat uang yang benar-benar diterima/dibayarkan,
+// selain itu (default) transaksi dicatat penuh saat dibuat (basis akrual).
+// [DIUBAH] locationID (opsional) membatasi buku besar ke transaksi satu lokasi;
+// setoran modal tidak terikat lokasi sehingga hanya muncul pada buku besar konsolidasi.
+func (s *ReportService) GetGeneralLedgerReport(userID uint, locationID *uint, startTime time.Time, endTime time.Time, basis string) (dto.GeneralLedgerReport, error) {
+	if basis == LedgerBasisCash {
+		return s.getCashLedgerReport(userID, locationID, startTime, endTime)
 	}
-	var balanceResult BalanceResult
 
-	// [PERUBAHAN DI SINI]
-	// Kita ubah query CASE agar menyertakan CAPITAL sebagai kas masuk
-	err := db.Model(&models.Transaction{}).
-		Select("COALESCE(SUM(CASE WHEN type = ? THEN total_amount WHEN type = ? THEN total_amount WHEN type = ? THEN -total_amount ELSE 0 END), 0) as balance",
-			models.Income, models.Capital, models.Expense).
-		Where("user_id = ? AND created_at < ?", userID, startTime).
-		Scan(&balanceResult).Error
+	db := database.DB
+	report := dto.GeneralLedgerReport{Basis: LedgerBasisAccrual}
+	var transactions []models.Transaction
 
+	// --- 1. Hitung Saldo Awal (Beginning Balance) ---
+	// Saldo awal adalah total (Pemasukan + Modal - Pengeluaran, setelah retur) SEBELUM startTime.
+	// [DIUBAH] Snapshot periode tertutup terakhir dipakai sebagai titik awal agar tidak menjumlahkan seluruh riwayat.
+	beginning, err := ledgerBalanceBefore(db, userID, locationID, LedgerBasisAccrual, startTime)
 	if err != nil {
 		log.Printf("Error calculating beginning balance: %v", err)
 		return report, err
 	}
-	report.BeginningBalance = balanceResult.Balance
+	report.BeginningBalance = beginning
 
 	// --- 2. Ambil Semua Transaksi DALAM Rentang Waktu ---
 	// Query ini sudah benar, karena kita ambil SEMUA tipe
+	// [DIUBAH] Transaksi VOID tidak ditampilkan di buku besar
 	err = db.Preload("Items").
-		Where("user_id = ? AND created_at BETWEEN ? AND ?", userID, startTime, endTime).
+		Scopes(locationScope(locationID)).
+		Where("user_id = ? AND status <> ? AND created_at BETWEEN ? AND ?", userID, models.StatusVoid, startTime, endTime).
 		Order("created_at asc, id asc"). // Urutkan berdasarkan tanggal, lalu ID
 		Find(&transactions).Error
 
@@ -91,33 +146,67 @@ func (s *ReportService) GetGeneralLedgerReport(userID uint, startTime time.Time,
 		return report, err
 	}
 
+	// [BARU] Ambil retur dalam rentang waktu (akan disisipkan sebagai entri pembalik)
+	type refundRow struct {
+		models.Refund
+		TransactionType models.TransactionType
+	}
+	var refunds []refundRow
+	err = db.Model(&models.Refund{}).
+		Select("refunds.*, transactions.type as transaction_type").
+		Joins("JOIN transactions ON transactions.id = refunds.transaction_id").
+		Scopes(locationScope(locationID)).
+		Where("refunds.user_id = ? AND transactions.status <> ? AND refunds.created_at BETWEEN ? AND ?", userID, models.StatusVoid, startTime, endTime).
+		Order("refunds.created_at asc, refunds.id asc").
+		Scan(&refunds).Error
+	if err != nil {
+		log.Printf("Error fetching refunds for ledger: %v", err)
+		return report, err
+	}
+
 	// --- 3. Proses Entri dan Hitung Saldo Berjalan (Running Balance) ---
 	runningBalance := report.BeginningBalance
 	var totalDebit float64 = 0
 	var totalCredit float64 = 0
 
 	var entries []dto.LedgerEntry
+	refundIdx := 0
+	// appendRefundsBefore menyisipkan entri retur yang terjadi sebelum 'limit' (urut kronologis)
+	appendRefundsBefore := func(limit *time.Time) {
+		for ; refundIdx < len(refunds); refundIdx++ {
+			r := refunds[refundIdx]
+			if limit != nil && !r.CreatedAt.Before(*limit) {
+				return
+			}
+			entry := dto.LedgerEntry{
+				Date:        r.CreatedAt.Format("02 Jan 2006 15:04"),
+				Description: fmt.Sprintf("Retur transaksi #%d", r.TransactionID),
+			}
+			if r.Reason != "" {
+				entry.Description = fmt.Sprintf("%s - %s", entry.Description, r.Reason)
+			}
+			if r.TransactionType == models.Income {
+				entry.Debit = r.Amount
+				runningBalance -= r.Amount
+				totalDebit += r.Amount
+			} else {
+				entry.Credit = r.Amount
+				runningBalance += r.Amount
+				totalCredit += r.Amount
+			}
+			entry.Balance = runningBalance
+			entries = append(entries, entry)
+		}
+	}
+
 	for _, tx := range transactions {
+		appendRefundsBefore(&tx.CreatedAt)
+
 		var entry dto.LedgerEntry
 		entry.Date = tx.CreatedAt.Format("02 Jan 2006 15:04") // Format tanggal
 
-		// [PERUBAHAN DI SINI] Buat deskripsi yang bagus
-		if tx.Type == models.Capital {
-			entry.Description = "Setoran Modal" // Deskripsi default untuk modal
-		} else if len(tx.Items) > 0 {
-			// Ambil nama item pertama sebagai deskripsi utama
-			entry.Description = tx.Items[0].ProductName
-			if len(tx.Items) > 1 {
-				entry.Description = fmt.Sprintf("%s (dan %d item lainnya)", entry.Description, len(tx.Items)-1)
-			}
-		} else {
-			// Fallback jika tidak ada item
-			entry.Description = "Transaksi " + string(tx.Type)
-		}
-		// Tambahkan catatan jika ada
-		if tx.Notes != "" {
-			entry.Description = fmt.Sprintf("%s - %s", entry.Description, tx.Notes)
-		}
+		// [DIUBAH] Deskripsi dibuat oleh helper ledgerDescription
+		entry.Description = ledgerDescription(tx)
 
 		// [PERUBAHAN DI SINI] Tentukan Debet (Keluar) atau Kredit (Masuk)
 		if tx.Type == models.Income || tx.Type == models.Capital {
@@ -138,6 +227,8 @@ func (s *ReportService) GetGeneralLedgerReport(userID uint, startTime time.Time,
 		entries = append(entries, entry)
 	}
 
+	appendRefundsBefore(nil) // Sisa retur setelah transaksi terakhir
+
 	// --- 4. Selesaikan Laporan ---
 	report.Entries = entries
 	report.TotalDebit = totalDebit
@@ -147,10 +238,172 @@ func (s *ReportService) GetGeneralLedgerReport(userID uint, startTime time.Time,
 	return report, nil
 }
 
+// getCashLedgerReport membuat buku besar basis kas dari tabel pembayaran.
+// Penjualan BELUM LUNAS baru muncul saat cicilan/pelunasannya diterima.
+func (s *ReportService) getCashLedgerReport(userID uint, locationID *uint, startTime time.Time, endTime time.Time) (dto.GeneralLedgerReport, error) {
+	db := database.DB
+	report := dto.GeneralLedgerReport{Basis: LedgerBasisCash}
+
+	// --- 1. Saldo awal = saldo kas riil sesaat sebelum startTime (memakai snapshot periode tertutup) ---
+	beginning, err := ledgerBalanceBefore(db, userID, locationID, LedgerBasisCash, startTime)
+	if err != nil {
+		log.Printf("Error calculating beginning cash balance: %v", err)
+		return report, err
+	}
+	report.BeginningBalance = beginning
+
+	// --- 2. Ambil pembayaran dalam rentang waktu beserta transaksinya ---
+	var payments []models.Payment
+	err = db.Model(&models.Payment{}).
+		Select("payments.*").
+		Joins("JOIN transactions ON transactions.id = payments.transaction_id").
+		Scopes(locationScope(locationID)).
+		Where("payments.user_id = ? AND transactions.status <> ? AND transactions.deleted_at IS NULL AND payments.payment_date BETWEEN ? AND ?", userID, models.StatusVoid, startTime, endTime).
+		Order("payments.payment_date asc, payments.id asc").
+		Find(&payments).Error
+	if err != nil {
+		log.Printf("Error fetching payments for cash ledger: %v", err)
+		return report, err
+	}
+
+	transactionIDs := make([]uint, 0, len(payments))
+	for _, p := range payments {
+		transactionIDs = append(transactionIDs, p.TransactionID)
+	}
+	transactionsByID := make(map[uint]models.Transaction)
+	if len(transactionIDs) > 0 {
+		var transactions []models.Transaction
+		if err := db.Preload("Items").Where("id IN ?", transactionIDs).Find(&transactions).Error; err != nil {
+			log.Printf("Error fetching transactions for cash ledger: %v", err)
+			return report, err
+		}
+		for _, tx := range transactions {
+			transactionsByID[tx.ID] = tx
+		}
+	}
+
+	// --- 3. Proses entri: Kredit = kas masuk, Debet = kas keluar ---
+	runningBalance := report.BeginningBalance
+	entries := []dto.LedgerEntry{}
+	for _, p := range payments {
+		tx := transactionsByID[p.TransactionID]
+
+		entry := dto.LedgerEntry{Date: p.PaymentDate.Format("02 Jan 2006 15:04")}
+		if p.Method == models.MethodRetur {
+			entry.Description = fmt.Sprintf("Pengembalian dana retur transaksi #%d", p.TransactionID)
+		} else {
+			entry.Description = ledgerDescription(tx)
+			if p.Notes != "" {
+				entry.Description = fmt.Sprintf("%s - %s", entry.Description, p.Notes)
+			}
+		}
+
+		cashIn := p.Amount
+		if tx.Type == models.Expense {
+			cashIn = -p.Amount
+		}
+		if cashIn >= 0 {
+			entry.Credit = cashIn
+			report.TotalCredit += cashIn
+		} else {
+			entry.Debit = -cashIn
+			report.TotalDebit += -cashIn
+		}
+		runningBalance += cashIn
+		entry.Balance = runningBalance
+		entries = append(entries, entry)
+	}
+
+	report.Entries = entries
+	report.EndingBalance = runningBalance
+	return report, nil
+}
+
 // --- [BARU] FUNGSI UNTUK LAPORAN UTANG & PIUTANG ---
 
+// [BARU] Kelompok umur utang/piutang
+const (
+	AgingCurrent = "CURRENT"
+	Aging1To30   = "1-30"
+	Aging31To60  = "31-60"
+	Aging61To90  = "61-90"
+	Aging90Plus  = "90+"
+)
+
+// agingBucketFor menghitung berapa hari sebuah tagihan lewat jatuh tempo dan kelompok umurnya.
+// Tagihan tanpa jatuh tempo dianggap belum jatuh tempo.
+func agingBucketFor(dueDate *time.Time, today time.Time) (int, string) {
+	if dueDate == nil {
+		return 0, AgingCurrent
+	}
+	dueDay := time.Date(dueDate.Year(), dueDate.Month(), dueDate.Day(), 0, 0, 0, 0, today.Location())
+	days := int(today.Sub(dueDay).Hours() / 24)
+	switch {
+	case days <= 0:
+		return 0, AgingCurrent
+	case days <= 30:
+		return days, Aging1To30
+	case days <= 60:
+		return days, Aging31To60
+	case days <= 90:
+		return days, Aging61To90
+	default:
+		return days, Aging90Plus
+	}
+}
+
+// addToAging menambahkan nilai ke kelompok umur yang sesuai
+func addToAging(buckets *dto.AgingBuckets, bucket string, amount float64) {
+	switch bucket {
+	case AgingCurrent:
+		buckets.Current += amount
+	case Aging1To30:
+		buckets.Days1To30 += amount
+	case Aging31To60:
+		buckets.Days31To60 += amount
+	case Aging61To90:
+		buckets.Days61To90 += amount
+	default:
+		buckets.Days90Plus += amount
+	}
+	buckets.Total += amount
+}
+
+// agingAccumulator mengumpulkan saldo umur per pelanggan/supplier
+type agingAccumulator struct {
+	index map[uint]int // partyID (0 = Umum) -> posisi di rows
+	rows  []dto.AgingByParty
+}
+
+func newAgingAccumulator() *agingAccumulator {
+	return &agingAccumulator{index: make(map[uint]int), rows: []dto.AgingByParty{}}
+}
+
+func (a *agingAccumulator) add(partyID *uint, partyName string, bucket string, amount float64) {
+	var key uint
+	if partyID != nil {
+		key = *partyID
+	}
+	pos, ok := a.index[key]
+	if !ok {
+		pos = len(a.rows)
+		a.index[key] = pos
+		a.rows = append(a.rows, dto.AgingByParty{PartyID: partyID, PartyName: partyName})
+	}
+	addToAging(&a.rows[pos].Aging, bucket, amount)
+}
+
+// sorted mengembalikan ringkasan per pihak, saldo terbesar lebih dulu
+func (a *agingAccumulator) sorted() []dto.AgingByParty {
+	sort.SliceStable(a.rows, func(i, j int) bool {
+		return a.rows[i].Aging.Total > a.rows[j].Aging.Total
+	})
+	return a.rows
+}
+
 // GetUnpaidReport membuat laporan transaksi yang belum lunas
-func (s *ReportService) GetUnpaidReport(userID uint) (dto.UnpaidReport, error) {
+// [DIUBAH] locationID (opsional) membatasi laporan ke transaksi satu lokasi
+func (s *ReportService) GetUnpaidReport(userID uint, locationID *uint) (dto.UnpaidReport, error) {
 	db := database.DB
 	var report dto.UnpaidReport
 	report.Receivables = []dto.UnpaidTransactionItem{}
@@ -163,7 +416,8 @@ func (s *ReportService) GetUnpaidReport(userID uint) (dto.UnpaidReport, error) {
 	// 1. Ambil Piutang (Receivables)
 	var unpaidIncomes []models.Transaction
 	err := db.Preload("Customer").Preload("Items").
-		Where("user_id = ? AND type = ? AND payment_status = ?", userID, models.Income, models.BelumLunas).
+		Scopes(locationScope(locationID)).
+		Where("user_id = ? AND type = ? AND payment_status = ? AND status <> ?", userID, models.Income, models.BelumLunas, models.StatusVoid).
 		Order("created_at asc").Find(&unpaidIncomes).Error
 	if err != nil {
 		log.Printf("Error fetching unpaid incomes: %v", err)
@@ -171,8 +425,11 @@ func (s *ReportService) GetUnpaidReport(userID uint) (dto.UnpaidReport, error) {
 	}
 
 	// 2. Proses Piutang
+	receivableAcc := newAgingAccumulator()
 	for _, tx := range unpaidIncomes {
-		report.TotalReceivable += tx.TotalAmount
+		// [DIUBAH] Sisa piutang sudah dikurangi retur dan cicilan
+		outstanding := tx.OutstandingAmount()
+		report.TotalReceivable += outstanding
 
 		customerName := "Umum"
 		if tx.Customer != nil {
@@ -191,23 +448,33 @@ func (s *ReportService) GetUnpaidReport(userID uint) (dto.UnpaidReport, error) {
 			dueDateStr = &formatted
 			isOverdue = tx.DueDate.Before(today) // Cek apakah sudah lewat hari ini
 		}
+		daysOverdue, bucket := agingBucketFor(tx.DueDate, today)
 
 		item := dto.UnpaidTransactionItem{
 			TransactionID: tx.ID,
 			CustomerName:  customerName,
-			Amount:        tx.TotalAmount,
+			TotalAmount:   tx.TotalAmount - tx.RefundedAmount,
+			PaidAmount:    tx.PaidAmount,
+			Amount:        outstanding,
 			CreatedAt:     tx.CreatedAt.Format("02 Jan 2006"),
 			DueDate:       dueDateStr,
 			IsOverdue:     isOverdue,
 			PrimaryItem:   primaryItem,
+			DaysOverdue:   daysOverdue,
+			AgingBucket:   bucket,
 		}
 		report.Receivables = append(report.Receivables, item)
+
+		// [BARU] Akumulasi umur per kelompok dan per pihak
+		addToAging(&report.ReceivableAging, bucket, outstanding)
+		receivableAcc.add(tx.CustomerID, customerName, bucket, outstanding)
 	}
 
 	// 3. Ambil Utang (Payables)
 	var unpaidExpenses []models.Transaction
-	err = db.Preload("Customer").Preload("Items").
-		Where("user_id = ? AND type = ? AND payment_status = ?", userID, models.Expense, models.BelumLunas).
+	err = db.Preload("Supplier").Preload("Items").
+		Scopes(locationScope(locationID)).
+		Where("user_id = ? AND type = ? AND payment_status = ? AND status <> ?", userID, models.Expense, models.BelumLunas, models.StatusVoid).
 		Order("created_at asc").Find(&unpaidExpenses).Error
 	if err != nil {
 		log.Printf("Error fetching unpaid expenses: %v", err)
@@ -215,12 +482,15 @@ func (s *ReportService) GetUnpaidReport(userID uint) (dto.UnpaidReport, error) {
 	}
 
 	// 4. Proses Utang
+	payableAcc := newAgingAccumulator()
 	for _, tx := range unpaidExpenses {
-		report.TotalPayable += tx.TotalAmount
+		outstanding := tx.OutstandingAmount()
+		report.TotalPayable += outstanding
 
-		customerName := "Umum" // Di sini berarti "Supplier"
-		if tx.Customer != nil {
-			customerName = tx.Customer.Name
+		// [DIUBAH] Pihak lawan utang adalah supplier
+		supplierName := "Tanpa Supplier"
+		if tx.Supplier != nil {
+			supplierName = tx.Supplier.Name
 		}
 
 		primaryItem := "Biaya Operasional"
@@ -235,18 +505,536 @@ func (s *ReportService) GetUnpaidReport(userID uint) (dto.UnpaidReport, error) {
 			dueDateStr = &formatted
 			isOverdue = tx.DueDate.Before(today)
 		}
+		daysOverdue, bucket := agingBucketFor(tx.DueDate, today)
 
 		item := dto.UnpaidTransactionItem{
 			TransactionID: tx.ID,
-			CustomerName:  customerName,
-			Amount:        tx.TotalAmount,
+			CustomerName:  supplierName,
+			TotalAmount:   tx.TotalAmount - tx.RefundedAmount,
+			PaidAmount:    tx.PaidAmount,
+			Amount:        outstanding,
 			CreatedAt:     tx.CreatedAt.Format("02 Jan 2006"),
 			DueDate:       dueDateStr,
 			IsOverdue:     isOverdue,
 			PrimaryItem:   primaryItem,
+			DaysOverdue:   daysOverdue,
+			AgingBucket:   bucket,
 		}
 		report.Payables = append(report.Payables, item)
+
+		// [BARU] Akumulasi umur per kelompok dan per pihak
+		addToAging(&report.PayableAging, bucket, outstanding)
+		payableAcc.add(tx.SupplierID, supplierName, bucket, outstanding)
+	}
+
+	report.ReceivablesByCustomer = receivableAcc.sorted()
+	report.PayablesBySupplier = payableAcc.sorted()
+
+	return report, nil
+}
+
+// --- [BARU] FUNGSI UNTUK LAPORAN LABA RUGI ---
+
+// uncategorisedName adalah label untuk transaksi tanpa kategori
+const uncategorisedName = "Tanpa Kategori"
+
+// percentOf menghitung persentase part terhadap whole (0 jika whole = 0)
+func percentOf(part float64, whole float64) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return part / whole * 100
+}
+
+// Beban operasional laba rugi = item pengeluaran non-produk, nilai DPP setelah dikurangi retur.
+// Pembelian produk menambah persediaan dan baru dibebankan sebagai HPP saat terjual.
+const (
+	operatingExpenseSQL        = netQuantitySQL + " * " + unitNetPriceSQL
+	operatingExpenseItemFilter = "transaction_items.product_id IS NULL"
+)
+
+// categoryTotals menjumlahkan nilai bersih transaksi per kategori untuk satu tipe transaksi.
+// [DIUBAH] Untuk EXPENSE hanya beban operasional (item non-produk) yang dihitung
+func categoryTotals(userID uint, locationID *uint, txType models.TransactionType, startTime time.Time, endTime time.Time) ([]dto.ProfitLossLine, error) {
+	db := database.DB
+
+	type categoryRow struct {
+		CategoryID   *uint
+		CategoryName *string
+		Amount       float64
+	}
+	query := db.Model(&models.Transaction{}).
+		Select("transactions.category_id, categories.name as category_name, COALESCE(SUM(" + netTotalSQL + "), 0) as amount")
+	if txType == models.Expense {
+		query = db.Model(&models.TransactionItem{}).
+			Select("transactions.category_id, categories.name as category_name, COALESCE(SUM(" + operatingExpenseSQL + "), 0) as amount").
+			Joins("JOIN transactions ON transactions.id = transaction_items.transaction_id").
+			Where("transactions.deleted_at IS NULL AND " + operatingExpenseItemFilter)
+	}
+	var rows []categoryRow
+	err := query.
+		Joins("LEFT JOIN categories ON categories.id = transactions.category_id").
+		Scopes(locationScope(locationID)).
+		Where("transactions.user_id = ? AND transactions.type = ? AND transactions.status <> ? AND transactions.created_at BETWEEN ? AND ?", userID, txType, models.StatusVoid, startTime, endTime).
+		Group("transactions.category_id, categories.name").
+		Order("amount desc").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	lines := []dto.ProfitLossLine{}
+	for _, r := range rows {
+		name := uncategorisedName
+		if r.CategoryID != nil && r.CategoryName != nil {
+			name = *r.CategoryName
+		}
+		lines = append(lines, dto.ProfitLossLine{CategoryID: r.CategoryID, CategoryName: name, Amount: r.Amount})
+	}
+	return lines, nil
+}
+
+// mergePreviousAmounts mengisi kolom PreviousAmount dan menambahkan kategori yang hanya muncul di periode sebelumnya
+func mergePreviousAmounts(current []dto.ProfitLossLine, previous []dto.ProfitLossLine) []dto.ProfitLossLine {
+	keyOf := func(id *uint) uint {
+		if id == nil {
+			return 0
+		}
+		return *id
+	}
+
+	index := make(map[uint]int)
+	for i := range current {
+		zero := 0.0
+		current[i].PreviousAmount = &zero
+		index[keyOf(current[i].CategoryID)] = i
+	}
+	for _, p := range previous {
+		amount := p.Amount
+		if i, ok := index[keyOf(p.CategoryID)]; ok {
+			current[i].PreviousAmount = &amount
+			continue
+		}
+		p.Amount = 0
+		p.PreviousAmount = &amount
+		current = append(current, p)
+	}
+	return current
+}
+
+// shrinkageLineName adalah label baris beban selisih stok opname & pemusnahan batch pada laporan laba rugi
+const shrinkageLineName = "Selisih & Pemusnahan Persediaan"
+
+// profitLossForPeriod menghitung ringkasan dan rincian per kategori untuk satu periode
+func profitLossForPeriod(userID uint, locationID *uint, startTime time.Time, endTime time.Time) ([]dto.ProfitLossLine, []dto.ProfitLossLine, dto.ProfitLossSummary, error) {
+	var summary dto.ProfitLossSummary
+
+	// Pendapatan, HPP & selisih persediaan memakai perhitungan yang sama dengan dashboard
+	stats, err := NewDashboardService().GetDashboardStats(userID, locationID, startTime, endTime)
+	if err != nil {
+		return nil, nil, summary, err
+	}
+
+	revenue, err := categoryTotals(userID, locationID, models.Income, startTime, endTime)
+	if err != nil {
+		return nil, nil, summary, err
+	}
+	expenses, err := categoryTotals(userID, locationID, models.Expense, startTime, endTime)
+	if err != nil {
+		return nil, nil, summary, err
+	}
+
+	// [DIUBAH] Beban operasional dihitung dari baris kategori (tanpa pembelian produk), bukan
+	// dari total pengeluaran dashboard, agar pembelian persediaan tidak terhitung dua kali dengan HPP
+	operatingExpense := stats.InventoryShrinkage
+	for _, line := range expenses {
+		operatingExpense += line.Amount
+	}
+	netProfit := stats.GrossProfit - operatingExpense
+	summary = dto.ProfitLossSummary{
+		TotalRevenue:          stats.TotalRevenue,
+		TotalCOGS:             stats.TotalCOGS,
+		GrossProfit:           stats.GrossProfit,
+		TotalOperatingExpense: operatingExpense,
+		InventoryShrinkage:    stats.InventoryShrinkage,
+		NetProfit:             netProfit,
+		GrossMarginPercent:    percentOf(stats.GrossProfit, stats.TotalRevenue),
+		NetMarginPercent:      percentOf(netProfit, stats.TotalRevenue),
+	}
+	return revenue, expenses, summary, nil
+}
+
+// GetProfitLossReport membuat laporan laba rugi per kategori, opsional dengan pembanding periode sebelumnya
+// [DIUBAH] locationID (opsional) membatasi laporan ke transaksi satu lokasi
+func (s *ReportService) GetProfitLossReport(userID uint, locationID *uint, startTime time.Time, endTime time.Time, compare bool) (dto.ProfitLossReport, error) {
+	report := dto.ProfitLossReport{
+		From: startTime.Format("2006-01-02"),
+		To:   endTime.Format("2006-01-02"),
+	}
+
+	revenue, expenses, summary, err := profitLossForPeriod(userID, locationID, startTime, endTime)
+	if err != nil {
+		log.Printf("Error building profit & loss report: %v", err)
+		return report, err
+	}
+
+	if compare {
+		// Periode pembanding: durasi yang sama, tepat sebelum startTime
+		prevEnd := startTime.Add(-time.Nanosecond)
+		prevStart := prevEnd.Add(-endTime.Sub(startTime))
+
+		prevRevenue, prevExpenses, prevSummary, err := profitLossForPeriod(userID, locationID, prevStart, prevEnd)
+		if err != nil {
+			log.Printf("Error building comparison profit & loss report: %v", err)
+			return report, err
+		}
+		revenue = mergePreviousAmounts(revenue, prevRevenue)
+		expenses = mergePreviousAmounts(expenses, prevExpenses)
+
+		fromStr, toStr := prevStart.Format("2006-01-02"), prevEnd.Format("2006-01-02")
+		report.ComparisonFrom = &fromStr
+		report.ComparisonTo = &toStr
+		report.Comparison = &prevSummary
+
+		// [BARU] Baris selisih stok opname tidak punya kategori, jadi ditambahkan setelah penggabungan
+		if summary.InventoryShrinkage != 0 || prevSummary.InventoryShrinkage != 0 {
+			previous := prevSummary.InventoryShrinkage
+			expenses = append(expenses, dto.ProfitLossLine{CategoryName: shrinkageLineName, Amount: summary.InventoryShrinkage, PreviousAmount: &previous})
+		}
+	} else if summary.InventoryShrinkage != 0 {
+		expenses = append(expenses, dto.ProfitLossLine{CategoryName: shrinkageLineName, Amount: summary.InventoryShrinkage})
+	}
+
+	for i := range revenue {
+		revenue[i].PercentOfRevenue = percentOf(revenue[i].Amount, summary.TotalRevenue)
+	}
+	for i := range expenses {
+		expenses[i].PercentOfRevenue = percentOf(expenses[i].Amount, summary.TotalRevenue)
+	}
+
+	report.Revenue = revenue
+	report.OperatingExpenses = expenses
+	report.Summary = summary
+	return report, nil
+}
+
+// --- [BARU] FUNGSI UNTUK LAPORAN NERACA ---
+
+// sumResult adalah helper untuk menampung hasil SUM tunggal
+type sumResult struct {
+	Total float64
+}
+
+// cashBalanceAsOf menghitung saldo kas riil per 'asOf' dari seluruh pembayaran yang benar-benar
+// diterima/dibayarkan (termasuk pengembalian dana retur), transaksi VOID diabaikan
+// [DIUBAH] locationID diisi = kas dari transaksi lokasi tersebut saja (neraca selalu memakai nil)
+func cashBalanceAsOf(userID uint, locationID *uint, asOf time.Time) (float64, error) {
+	db := database.DB
+	var result sumResult
+	err := db.Model(&models.Payment{}).
+		Select("COALESCE(SUM(CASE WHEN transactions.type = ? THEN -payments.amount ELSE payments.amount END), 0) as total", models.Expense).
+		Joins("JOIN transactions ON transactions.id = payments.transaction_id").
+		Scopes(locationScope(locationID)).
+		Where("payments.user_id = ? AND transactions.status <> ? AND transactions.deleted_at IS NULL AND payments.payment_date <= ?", userID, models.StatusVoid, asOf).
+		Scan(&result).Error
+	return result.Total, err
+}
+
+// netTransactionTotalAsOf menghitung total transaksi satu tipe per 'asOf' setelah dikurangi retur s.d. 'asOf'
+func netTransactionTotalAsOf(userID uint, txType models.TransactionType, asOf time.Time) (float64, error) {
+	db := database.DB
+	var gross, refunded sumResult
+	if err := db.Model(&models.Transaction{}).
+		Select("COALESCE(SUM(total_amount), 0) as total").
+		Where("user_id = ? AND type = ? AND status <> ? AND created_at <= ?", userID, txType, models.StatusVoid, asOf).
+		Scan(&gross).Error; err != nil {
+		return 0, err
+	}
+	if err := db.Model(&models.Refund{}).
+		Select("COALESCE(SUM(refunds.amount), 0) as total").
+		Joins("JOIN transactions ON transactions.id = refunds.transaction_id").
+		Where("refunds.user_id = ? AND transactions.type = ? AND transactions.status <> ? AND refunds.created_at <= ?", userID, txType, models.StatusVoid, asOf).
+		Scan(&refunded).Error; err != nil {
+		return 0, err
+	}
+	return gross.Total - refunded.Total, nil
+}
+
+// taxAmountAsOf menghitung PPN bersih (setelah retur) untuk satu tipe transaksi per 'asOf'
+func taxAmountAsOf(userID uint, txType models.TransactionType, asOf time.Time) (float64, error) {
+	db := database.DB
+	var gross, refunded sumResult
+	if err := db.Model(&models.Transaction{}).
+		Select("COALESCE(SUM(tax_amount), 0) as total").
+		Where("user_id = ? AND type = ? AND status <> ? AND created_at <= ?", userID, txType, models.StatusVoid, asOf).
+		Scan(&gross).Error; err != nil {
+		return 0, err
+	}
+	if err := db.Model(&models.Refund{}).
+		Select("COALESCE(SUM(refunds.tax_amount), 0) as total").
+		Joins("JOIN transactions ON transactions.id = refunds.transaction_id").
+		Where("refunds.user_id = ? AND transactions.type = ? AND transactions.status <> ? AND refunds.created_at <= ?", userID, txType, models.StatusVoid, asOf).
+		Scan(&refunded).Error; err != nil {
+		return 0, err
+	}
+	return gross.Total - refunded.Total, nil
+}
+
+// paymentsAsOf menghitung total pembayaran untuk satu tipe transaksi per 'asOf'
+func paymentsAsOf(userID uint, txType models.TransactionType, asOf time.Time) (float64, error) {
+	db := database.DB
+	var result sumResult
+	err := db.Model(&models.Payment{}).
+		Select("COALESCE(SUM(payments.amount), 0) as total").
+		Joins("JOIN transactions ON transactions.id = payments.transaction_id").
+		Where("payments.user_id = ? AND transactions.type = ? AND transactions.status <> ? AND transactions.deleted_at IS NULL AND payments.payment_date <= ?", userID, txType, models.StatusVoid, asOf).
+		Scan(&result).Error
+	return result.Total, err
+}
+
+// itemAmountAsOf menjumlahkan nilai item transaksi (dikurangi retur s.d. 'asOf').
+// valueSQL menentukan nilai per unit (cth: harga modal atau harga satuan), productItems memilih
+// item produk (true) atau item non-produk (false)
+func itemAmountAsOf(userID uint, txType models.TransactionType, valueSQL string, productItems bool, asOf time.Time) (float64, error) {
+	db := database.DB
+	productFilter := "transaction_items.product_id IS NULL"
+	if productItems {
+		productFilter = "transaction_items.product_id IS NOT NULL"
+	}
+
+	var gross, refunded sumResult
+	if err := db.Model(&models.TransactionItem{}).
+		Select("COALESCE(SUM(transaction_items.quantity * "+valueSQL+"), 0) as total").
+		Joins("JOIN transactions ON transactions.id = transaction_items.transaction_id").
+		Where("transactions.user_id = ? AND transactions.type = ? AND transactions.status <> ? AND transactions.created_at <= ? AND "+productFilter, userID, txType, models.StatusVoid, asOf).
+		Scan(&gross).Error; err != nil {
+		return 0, err
+	}
+	if err := db.Model(&models.RefundItem{}).
+		Select("COALESCE(SUM(refund_items.quantity * "+valueSQL+"), 0) as total").
+		Joins("JOIN refunds ON refunds.id = refund_items.refund_id").
+		Joins("JOIN transaction_items ON transaction_items.id = refund_items.transaction_item_id").
+		Joins("JOIN transactions ON transactions.id = transaction_items.transaction_id").
+		Where("transactions.user_id = ? AND transactions.type = ? AND transactions.status <> ? AND refunds.created_at <= ? AND "+productFilter, userID, txType, models.StatusVoid, asOf).
+		Scan(&refunded).Error; err != nil {
+		return 0, err
+	}
+	return gross.Total - refunded.Total, nil
+}
+
+// inventoryValueAsOf menilai persediaan per 'asOf' = Stock * PurchasePrice, dengan stok per 'asOf'
+// = stok saat ini dikurangi pergerakan kartu stok setelah 'asOf'. Harga beli adalah biaya saat ini.
+func inventoryValueAsOf(userID uint, asOf time.Time) (float64, error) {
+	var result sumResult
+	err := database.DB.Model(&models.Product{}).
+		Select(`COALESCE(SUM((products.stock - COALESCE((SELECT SUM(m.quantity) FROM stock_movements m
+			WHERE m.product_id = products.id AND m.created_at > ? AND m.deleted_at IS NULL), 0)) * products.purchase_price), 0) as total`, asOf).
+		Where("products.user_id = ?", userID).
+		Scan(&result).Error
+	return result.Total, err
+}
+
+// GetBalanceSheetReport membuat laporan neraca per tanggal 'asOf'
+func (s *ReportService) GetBalanceSheetReport(userID uint, asOf time.Time) (dto.BalanceSheetReport, error) {
+	report := dto.BalanceSheetReport{AsOf: asOf.Format("2006-01-02")}
+
+	fail := func(err error) (dto.BalanceSheetReport, error) {
+		log.Printf("Error building balance sheet: %v", err)
+		return report, err
+	}
+
+	// --- 1. Aset ---
+	cash, err := cashBalanceAsOf(userID, nil, asOf)
+	if err != nil {
+		return fail(err)
+	}
+
+	// Piutang = penjualan bersih - pembayaran yang sudah diterima (hanya tersisa dari INCOME BELUM LUNAS)
+	netSales, err := netTransactionTotalAsOf(userID, models.Income, asOf)
+	if err != nil {
+		return fail(err)
+	}
+	salesPaid, err := paymentsAsOf(userID, models.Income, asOf)
+	if err != nil {
+		return fail(err)
+	}
+	receivables := netSales - salesPaid
+
+	// [DIUBAH] Persediaan dinilai dengan harga beli x stok per 'asOf' (dari kartu stok)
+	inventory, err := inventoryValueAsOf(userID, asOf)
+	if err != nil {
+		return fail(err)
+	}
+
+	report.Assets.Lines = []dto.BalanceSheetLine{
+		{Name: "Kas", Amount: cash},
+		{Name: "Piutang Usaha", Amount: receivables},
+		{Name: "Persediaan Barang", Amount: inventory},
+	}
+
+	// --- 2. Kewajiban ---
+	netPurchases, err := netTransactionTotalAsOf(userID, models.Expense, asOf)
+	if err != nil {
+		return fail(err)
+	}
+	purchasesPaid, err := paymentsAsOf(userID, models.Expense, asOf)
+	if err != nil {
+		return fail(err)
+	}
+	// [BARU] PPN Keluaran yang dipungut dikurangi PPN Masukan yang bisa dikreditkan
+	outputVAT, err := taxAmountAsOf(userID, models.Income, asOf)
+	if err != nil {
+		return fail(err)
+	}
+	inputVAT, err := taxAmountAsOf(userID, models.Expense, asOf)
+	if err != nil {
+		return fail(err)
+	}
+	// [BARU] Barang PO yang sudah masuk persediaan tetapi tagihan supplier-nya belum dicatat
+	goodsReceived, err := goodsReceivedNotBilledAsOf(userID, asOf)
+	if err != nil {
+		return fail(err)
+	}
+	report.Liabilities.Lines = []dto.BalanceSheetLine{
+		{Name: "Utang Usaha", Amount: netPurchases - purchasesPaid},
+		{Name: "Barang Diterima Belum Ditagih", Amount: goodsReceived},
+		{Name: "Utang PPN (Keluaran - Masukan)", Amount: outputVAT - inputVAT},
+	}
+
+	// --- 3. Ekuitas ---
+	capital, err := netTransactionTotalAsOf(userID, models.Capital, asOf)
+	if err != nil {
+		return fail(err)
+	}
+	// Laba ditahan = penjualan bersih (tanpa PPN) - HPP - beban operasional (DPP).
+	// Pembelian produk tidak dibebankan karena sudah tercatat sebagai persediaan.
+	cogs, err := itemAmountAsOf(userID, models.Income, "transaction_items.purchase_price", true, asOf)
+	if err != nil {
+		return fail(err)
+	}
+	operatingExpense, err := itemAmountAsOf(userID, models.Expense, unitNetPriceSQL, false, asOf)
+	if err != nil {
+		return fail(err)
+	}
+	// [BARU] Selisih stok opname mengurangi persediaan sekaligus laba
+	shrinkage, err := inventoryShrinkageAsOf(userID, asOf)
+	if err != nil {
+		return fail(err)
+	}
+	// [BARU] Lawan dari nilai stok awal & edit stok manual (saldo normal kredit)
+	stockAdjustment, err := accountBalanceAsOf(userID, models.AccountCodeStockAdjustment, asOf)
+	if err != nil {
+		return fail(err)
+	}
+	report.Equity.Lines = []dto.BalanceSheetLine{
+		{Name: "Modal Pemilik", Amount: capital},
+		{Name: "Penyesuaian Persediaan", Amount: -stockAdjustment},
+		{Name: "Laba Ditahan", Amount: netSales - outputVAT - cogs - operatingExpense - shrinkage},
+	}
+
+	// --- 4. Total & Pengecekan Keseimbangan ---
+	for _, section := range []*dto.BalanceSheetSection{&report.Assets, &report.Liabilities, &report.Equity} {
+		for _, line := range section.Lines {
+			section.Total += line.Amount
+		}
+	}
+	report.TotalLiabilitiesAndEquity = report.Liabilities.Total + report.Equity.Total
+	report.Discrepancy = report.Assets.Total - report.TotalLiabilitiesAndEquity
+	report.IsBalanced = report.Discrepancy <= amountEpsilon && report.Discrepancy >= -amountEpsilon
+
+	return report, nil
+}
+
+// --- [BARU] FUNGSI UNTUK LAPORAN ARUS KAS ---
+
+// cashFlowSectionFor menentukan bagian arus kas sebuah baris.
+// Setoran modal (CAPITAL) selalu masuk pendanaan, lainnya mengikuti pengaturan kategori.
+func cashFlowSectionFor(txType models.TransactionType, section *models.CashFlowSectionType) models.CashFlowSectionType {
+	if txType == models.Capital {
+		return models.CashFlowFinancing
+	}
+	if section == nil || *section == "" {
+		return models.CashFlowOperating
+	}
+	return *section
+}
+
+// GetCashFlowReport membuat laporan arus kas basis kas: hanya uang yang benar-benar
+// diterima/dibayarkan (tabel payments) dalam rentang waktu yang dihitung
+// [DIUBAH] locationID (opsional) membatasi laporan ke transaksi satu lokasi
+func (s *ReportService) GetCashFlowReport(userID uint, locationID *uint, startTime time.Time, endTime time.Time) (dto.CashFlowReport, error) {
+	db := database.DB
+	report := dto.CashFlowReport{
+		From: startTime.Format("2006-01-02"),
+		To:   endTime.Format("2006-01-02"),
+	}
+
+	beginning, err := cashBalanceAsOf(userID, locationID, startTime.Add(-time.Nanosecond))
+	if err != nil {
+		log.Printf("Error calculating beginning cash for cash flow: %v", err)
+		return report, err
+	}
+	report.BeginningCash = beginning
+
+	type cashFlowRow struct {
+		Type            models.TransactionType
+		CategoryID      *uint
+		CategoryName    *string
+		CashFlowSection *models.CashFlowSectionType
+		Amount          float64
+	}
+	var rows []cashFlowRow
+	err = db.Model(&models.Payment{}).
+		Select("transactions.type, transactions.category_id, categories.name as category_name, categories.cash_flow_section, "+
+			"COALESCE(SUM(CASE WHEN transactions.type = ? THEN -payments.amount ELSE payments.amount END), 0) as amount", models.Expense).
+		Joins("JOIN transactions ON transactions.id = payments.transaction_id").
+		Joins("LEFT JOIN categories ON categories.id = transactions.category_id").
+		Scopes(locationScope(locationID)).
+		Where("payments.user_id = ? AND transactions.status <> ? AND transactions.deleted_at IS NULL AND payments.payment_date BETWEEN ? AND ?", userID, models.StatusVoid, startTime, endTime).
+		Group("transactions.type, transactions.category_id, categories.name, categories.cash_flow_section").
+		Order("amount desc").
+		Scan(&rows).Error
+	if err != nil {
+		log.Printf("Error querying cash flow: %v", err)
+		return report, err
+	}
+
+	sections := map[models.CashFlowSectionType]*dto.CashFlowSection{
+		models.CashFlowOperating: &report.Operating,
+		models.CashFlowInvesting: &report.Investing,
+		models.CashFlowFinancing: &report.Financing,
+	}
+	for _, section := range sections {
+		section.Lines = []dto.CashFlowLine{}
+	}
+
+	for _, r := range rows {
+		name := uncategorisedName
+		if r.Type == models.Capital && r.CategoryID == nil {
+			name = "Setoran Modal"
+		} else if r.CategoryID != nil && r.CategoryName != nil {
+			name = *r.CategoryName
+		}
+
+		section, ok := sections[cashFlowSectionFor(r.Type, r.CashFlowSection)]
+		if !ok {
+			section = &report.Operating
+		}
+		section.Lines = append(section.Lines, dto.CashFlowLine{
+			CategoryID:   r.CategoryID,
+			CategoryName: name,
+			Type:         string(r.Type),
+			Amount:       r.Amount,
+		})
+		if r.Amount >= 0 {
+			section.Inflow += r.Amount
+		} else {
+			section.Outflow += -r.Amount
+		}
+		section.NetCash += r.Amount
 	}
 
+	report.NetChange = report.Operating.NetCash + report.Investing.NetCash + report.Financing.NetCash
+	report.EndingCash = report.BeginningCash + report.NetChange
 	return report, nil
 }
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/danishyusrah/go_bisnis/internal/models"
+)
+
+func TestAgingBucketFor(t *testing.T) {
+	today := time.Date(2024, time.March, 31, 0, 0, 0, 0, time.UTC)
+	daysAgo := func(days int) *time.Time {
+		due := today.AddDate(0, 0, -days)
+		return &due
+	}
+	lateEvening := time.Date(2024, time.March, 30, 23, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		dueDate    *time.Time
+		wantDays   int
+		wantBucket string
+	}{
+		{name: "tanpa jatuh tempo", dueDate: nil, wantDays: 0, wantBucket: AgingCurrent},
+		{name: "jatuh tempo hari ini", dueDate: daysAgo(0), wantDays: 0, wantBucket: AgingCurrent},
+		{name: "belum jatuh tempo", dueDate: daysAgo(-5), wantDays: 0, wantBucket: AgingCurrent},
+		{name: "lewat 1 hari", dueDate: daysAgo(1), wantDays: 1, wantBucket: Aging1To30},
+		{name: "jam pada tanggal jatuh tempo diabaikan", dueDate: &lateEvening, wantDays: 1, wantBucket: Aging1To30},
+		{name: "batas atas 1-30", dueDate: daysAgo(30), wantDays: 30, wantBucket: Aging1To30},
+		{name: "batas bawah 31-60", dueDate: daysAgo(31), wantDays: 31, wantBucket: Aging31To60},
+		{name: "batas atas 31-60", dueDate: daysAgo(60), wantDays: 60, wantBucket: Aging31To60},
+		{name: "batas bawah 61-90", dueDate: daysAgo(61), wantDays: 61, wantBucket: Aging61To90},
+		{name: "batas atas 61-90", dueDate: daysAgo(90), wantDays: 90, wantBucket: Aging61To90},
+		{name: "lebih dari 90", dueDate: daysAgo(91), wantDays: 91, wantBucket: Aging90Plus},
+		{name: "lebih dari setahun", dueDate: daysAgo(400), wantDays: 400, wantBucket: Aging90Plus},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			days, bucket := agingBucketFor(tt.dueDate, today)
+			if days != tt.wantDays || bucket != tt.wantBucket {
+				t.Errorf("agingBucketFor() = (%d, %q), want (%d, %q)", days, bucket, tt.wantDays, tt.wantBucket)
+			}
+		})
+	}
+}
+
+func TestInventoryValueAsOf(t *testing.T) {
+	db := useTestDB(t, &models.Product{}, &models.StockMovement{})
+	march := func(day int) time.Time { return time.Date(2024, time.March, day, 12, 0, 0, 0, time.UTC) }
+
+	product := models.Product{UserID: 1, SKU: "BRS", Name: "Beras", Stock: 12, PurchasePrice: 1000}
+	other := models.Product{UserID: 2, SKU: "LAIN", Name: "Milik user lain", Stock: 50, PurchasePrice: 1000}
+	if err := db.Create(&[]*models.Product{&product, &other}).Error; err != nil {
+		t.Fatal(err)
+	}
+	movements := []models.StockMovement{
+		{UserID: 1, ProductID: product.ID, Reason: models.MovementAdjustment, Quantity: 10, BalanceAfter: 10},
+		{UserID: 1, ProductID: product.ID, Reason: models.MovementPurchase, Quantity: 4, BalanceAfter: 14},
+		{UserID: 1, ProductID: product.ID, Reason: models.MovementSale, Quantity: -2, BalanceAfter: 12},
+	}
+	for i, day := range []int{1, 5, 10} {
+		movements[i].CreatedAt = march(day)
+	}
+	if err := db.Create(&movements).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// Stok per tanggal mengikuti kartu stok, dinilai dengan harga beli saat ini
+	for _, check := range []struct {
+		asOf time.Time
+		want float64
+	}{
+		{asOf: march(1).AddDate(0, 0, -1), want: 0},
+		{asOf: march(3), want: 10000},
+		{asOf: march(7), want: 14000},
+		{asOf: march(20), want: 12000},
+	} {
+		got, err := inventoryValueAsOf(1, check.asOf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertAmount(t, "persediaan per "+check.asOf.Format("2006-01-02"), got, check.want)
+	}
+}
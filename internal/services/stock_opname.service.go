@@ -0,0 +1,443 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/danishyusrah/go_bisnis/internal/database"
+	"github.com/danishyusrah/go_bisnis/internal/dto"
+	"github.com/danishyusrah/go_bisnis/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// errOpnameNotOpen dikembalikan jika sesi stok opname sudah difinalisasi/dibatalkan
+var errOpnameNotOpen = errors.New("stok opname sudah tidak terbuka")
+
+// StockOpnameService adalah struct untuk layanan stok opname (hitung fisik persediaan)
+type StockOpnameService struct{}
+
+// NewStockOpnameService membuat instance StockOpnameService baru
+func NewStockOpnameService() *StockOpnameService {
+	return &StockOpnameService{}
+}
+
+// findOwnedStockOpname mengambil sesi stok opname dan memvalidasi kepemilikan.
+// Jika lock = true, baris dikunci (FOR UPDATE) agar tidak difinalisasi bersamaan.
+func findOwnedStockOpname(tx *gorm.DB, opnameID uint, userID uint, lock bool) (models.StockOpname, error) {
+	var opname models.StockOpname
+	query := tx
+	if lock {
+		query = query.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+	if err := query.First(&opname, opnameID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.StockOpname{}, errors.New("stok opname tidak ditemukan")
+		}
+		return models.StockOpname{}, err
+	}
+	if opname.UserID != userID {
+		return models.StockOpname{}, errors.New("akses ditolak: Anda bukan pemilik stok opname ini")
+	}
+	return opname, nil
+}
+
+// GetStockOpnames mengambil semua sesi stok opname milik user (terbaru dulu)
+func (s *StockOpnameService) GetStockOpnames(userID uint) ([]models.StockOpname, error) {
+	var opnames []models.StockOpname
+	err := database.DB.Preload("Counts").Where("user_id = ?", userID).Order("created_at desc").Find(&opnames).Error
+	return opnames, err
+}
+
+// GetStockOpnameByID mengambil satu sesi stok opname beserta entri hitungnya
+func (s *StockOpnameService) GetStockOpnameByID(opnameID uint, userID uint) (models.StockOpname, error) {
+	opname, err := findOwnedStockOpname(database.DB, opnameID, userID, false)
+	if err != nil {
+		return models.StockOpname{}, err
+	}
+	if err := database.DB.Where("stock_opname_id = ?", opname.ID).Order("id asc").Find(&opname.Counts).Error; err != nil {
+		return models.StockOpname{}, err
+	}
+	return opname, nil
+}
+
+// CreateStockOpname membuka sesi stok opname baru. [DIUBAH] Hanya boleh ada satu sesi terbuka per lokasi.
+func (s *StockOpnameService) CreateStockOpname(input dto.CreateStockOpnameInput, userID uint) (models.StockOpname, error) {
+	db := database.DB
+
+	locationID, err := resolveLocation(db, userID, input.LocationID)
+	if err != nil {
+		return models.StockOpname{}, err
+	}
+
+	var openCount int64
+	if err := db.Model(&models.StockOpname{}).Where("user_id = ? AND location_id = ? AND status = ?", userID, locationID, models.OpnameOpen).Count(&openCount).Error; err != nil {
+		return models.StockOpname{}, err
+	}
+	if openCount > 0 {
+		return models.StockOpname{}, errors.New("masih ada stok opname yang belum diselesaikan")
+	}
+
+	opname := models.StockOpname{
+		UserID:     userID,
+		LocationID: &locationID, // [BARU]
+		Notes:      input.Notes,
+		Status:     models.OpnameOpen,
+		Counts:     []models.StockOpnameCount{},
+	}
+	if err := db.Create(&opname).Error; err != nil {
+		return models.StockOpname{}, err
+	}
+	return opname, nil
+}
+
+// AddCounts menambahkan hasil hitung fisik. Hitungan untuk produk yang sama dijumlahkan.
+// [DIUBAH] Stok sistem saat ini ikut disimpan sebagai pembanding selisih.
+func (s *StockOpnameService) AddCounts(opnameID uint, userID uint, input dto.AddStockOpnameCountsInput) (models.StockOpname, error) {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		opname, err := findOwnedStockOpname(tx, opnameID, userID, true)
+		if err != nil {
+			return err
+		}
+		if opname.Status != models.OpnameOpen {
+			return errOpnameNotOpen
+		}
+		locationID, err := resolveLocation(tx, userID, opname.LocationID)
+		if err != nil {
+			return err
+		}
+
+		var counts []models.StockOpnameCount
+		for _, item := range input.Items {
+			var product models.Product
+			if err := tx.Where("id = ? AND user_id = ?", item.ProductID, userID).First(&product).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return fmt.Errorf("produk ID %d tidak ditemukan", item.ProductID)
+				}
+				return err
+			}
+			// [BARU] Produk komposit & produk induk varian tidak menyimpan stok sendiri
+			if product.IsComposite {
+				return fmt.Errorf("produk %s adalah produk komposit, hitung bahan-bahannya", product.Name)
+			}
+			hasVariants, err := productHasVariants(tx, product.ID)
+			if err != nil {
+				return err
+			}
+			if hasVariants {
+				return fmt.Errorf("produk %s memiliki varian, hitung per varian", product.Name)
+			}
+			var stock models.ProductStock
+			if err := tx.Where("product_id = ? AND location_id = ?", product.ID, locationID).Limit(1).Find(&stock).Error; err != nil {
+				return err
+			}
+			systemQuantity := stock.Quantity
+			counts = append(counts, models.StockOpnameCount{
+				StockOpnameID:  opname.ID,
+				ProductID:      item.ProductID,
+				Quantity:       item.Quantity,
+				SessionLabel:   input.SessionLabel,
+				SystemQuantity: &systemQuantity,
+			})
+		}
+		return tx.Create(&counts).Error
+	})
+	if err != nil {
+		return models.StockOpname{}, err
+	}
+	return s.GetStockOpnameByID(opnameID, userID)
+}
+
+// DeleteCount menghapus satu entri hitung yang salah input (hanya saat sesi masih terbuka)
+func (s *StockOpnameService) DeleteCount(opnameID uint, countID uint, userID uint) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		opname, err := findOwnedStockOpname(tx, opnameID, userID, true)
+		if err != nil {
+			return err
+		}
+		if opname.Status != models.OpnameOpen {
+			return errOpnameNotOpen
+		}
+		result := tx.Where("id = ? AND stock_opname_id = ?", countID, opname.ID).Delete(&models.StockOpnameCount{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("entri hitung tidak ditemukan")
+		}
+		return nil
+	})
+}
+
+// countedVarianceLines membandingkan total hitung per produk dengan stok sistem.
+// Produk yang tidak dihitung sama sekali tidak ikut (stok opname boleh parsial).
+// [DIUBAH] Stok sistem adalah stok produk di lokasi yang dihitung, diambil dari snapshot saat
+// hitungan terakhir produk tersebut dimasukkan (entri lama tanpa snapshot memakai stok saat ini).
+func countedVarianceLines(tx *gorm.DB, opnameID uint, userID uint, locationID uint, lockProducts bool) ([]dto.StockOpnameVarianceLine, error) {
+	type countRow struct {
+		ProductID uint
+		Counted   int
+	}
+	var rows []countRow
+	if err := tx.Model(&models.StockOpnameCount{}).
+		Select("product_id, SUM(quantity) as counted").
+		Where("stock_opname_id = ?", opnameID).
+		Group("product_id").
+		Order("product_id asc").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	lines := []dto.StockOpnameVarianceLine{}
+	for _, r := range rows {
+		var product models.Product
+		query := tx
+		if lockProducts {
+			query = query.Clauses(clause.Locking{Strength: "UPDATE"})
+		}
+		if err := query.Where("id = ? AND user_id = ?", r.ProductID, userID).First(&product).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				// Produk sudah dihapus setelah dihitung, abaikan
+				continue
+			}
+			return nil, err
+		}
+		var latest models.StockOpnameCount
+		if err := tx.Where("stock_opname_id = ? AND product_id = ?", opnameID, product.ID).Order("id desc").First(&latest).Error; err != nil {
+			return nil, err
+		}
+		var systemQuantity int
+		if latest.SystemQuantity != nil {
+			systemQuantity = *latest.SystemQuantity
+		} else {
+			var stock models.ProductStock
+			if err := tx.Where("product_id = ? AND location_id = ?", product.ID, locationID).Limit(1).Find(&stock).Error; err != nil {
+				return nil, err
+			}
+			systemQuantity = stock.Quantity
+		}
+		variance := r.Counted - systemQuantity
+		lines = append(lines, dto.StockOpnameVarianceLine{
+			ProductID:       product.ID,
+			ProductName:     product.Name,
+			SystemQuantity:  systemQuantity,
+			CountedQuantity: r.Counted,
+			Variance:        variance,
+			UnitCost:        product.PurchasePrice,
+			VarianceValue:   roundAmount(float64(variance) * product.PurchasePrice),
+		})
+	}
+	return lines, nil
+}
+
+// summariseVariance mengisi total selisih kurang/lebih pada laporan
+func summariseVariance(report *dto.StockOpnameVarianceReport) {
+	for _, line := range report.Lines {
+		if line.VarianceValue < 0 {
+			report.ShrinkageAmount += -line.VarianceValue
+		} else {
+			report.SurplusAmount += line.VarianceValue
+		}
+	}
+	report.ShrinkageAmount = roundAmount(report.ShrinkageAmount)
+	report.SurplusAmount = roundAmount(report.SurplusAmount)
+	report.NetVariance = roundAmount(report.SurplusAmount - report.ShrinkageAmount)
+}
+
+// GetVarianceReport membuat laporan selisih hitung fisik vs stok sistem, dinilai dengan harga beli.
+// Selama sesi masih terbuka nilainya perkiraan; saat finalisasi selisih kurang dinilai pada biaya keluar.
+func (s *StockOpnameService) GetVarianceReport(opnameID uint, userID uint) (dto.StockOpnameVarianceReport, error) {
+	db := database.DB
+	opname, err := findOwnedStockOpname(db, opnameID, userID, false)
+	if err != nil {
+		return dto.StockOpnameVarianceReport{}, err
+	}
+
+	report := dto.StockOpnameVarianceReport{
+		StockOpnameID: opname.ID,
+		Status:        string(opname.Status),
+		Lines:         []dto.StockOpnameVarianceLine{},
+	}
+
+	if opname.Status == models.OpnameFinalized {
+		// Setelah finalisasi, pakai snapshot agar laporan tidak berubah oleh transaksi berikutnya
+		var items []models.StockOpnameItem
+		if err := db.Where("stock_opname_id = ?", opname.ID).Order("product_id asc").Find(&items).Error; err != nil {
+			return report, err
+		}
+		for _, item := range items {
+			report.Lines = append(report.Lines, dto.StockOpnameVarianceLine{
+				ProductID:       item.ProductID,
+				ProductName:     item.ProductName,
+				SystemQuantity:  item.SystemQuantity,
+				CountedQuantity: item.CountedQuantity,
+				Variance:        item.Variance,
+				UnitCost:        item.UnitCost,
+				VarianceValue:   item.VarianceValue,
+			})
+		}
+	} else {
+		locationID, err := resolveLocation(db, userID, opname.LocationID)
+		if err != nil {
+			return report, err
+		}
+		lines, err := countedVarianceLines(db, opname.ID, userID, locationID, false)
+		if err != nil {
+			return report, err
+		}
+		report.Lines = lines
+	}
+
+	summariseVariance(&report)
+	return report, nil
+}
+
+// FinalizeStockOpname memposting hasil stok opname: [DIUBAH] selisih (hitung - stok saat dihitung)
+// ditambahkan ke stok produk (tercatat di kartu stok sebagai OPNAME), sehingga transaksi setelah
+// penghitungan tetap tercermin di stok, lalu selisih nilainya dijurnal sebagai
+// Beban Selisih Persediaan (D) - Persediaan (K). Selisih lebih mengurangi beban.
+func (s *StockOpnameService) FinalizeStockOpname(opnameID uint, userID uint) (models.StockOpname, error) {
+	var opname models.StockOpname
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		opname, err = findOwnedStockOpname(tx, opnameID, userID, true)
+		if err != nil {
+			return err
+		}
+		if opname.Status != models.OpnameOpen {
+			return errOpnameNotOpen
+		}
+		now := time.Now()
+		if err := ensurePeriodOpen(tx, userID, now); err != nil {
+			return err
+		}
+
+		locationID, err := resolveLocation(tx, userID, opname.LocationID)
+		if err != nil {
+			return err
+		}
+		lines, err := countedVarianceLines(tx, opname.ID, userID, locationID, true)
+		if err != nil {
+			return err
+		}
+		if len(lines) == 0 {
+			return errors.New("belum ada hasil hitung untuk difinalisasi")
+		}
+
+		notes := fmt.Sprintf("Stok opname #%d", opname.ID)
+		var items []models.StockOpnameItem
+		for i := range lines {
+			line := &lines[i]
+			if line.Variance != 0 {
+				// [DIUBAH] Selisih dinilai pada biaya barang yang benar-benar keluar/masuk persediaan
+				// (FIFO: biaya lapisan), agar jurnal sama dengan nilai persediaan yang berubah
+				product, err := lockOwnedProduct(tx, line.ProductID, userID)
+				if err != nil {
+					return err
+				}
+				unitCost, err := moveProductStock(tx, &product, locationID, line.Variance, nil, models.MovementOpname, nil, now, notes)
+				if err != nil {
+					return err
+				}
+				if err := syncProductBatches(tx, &product, line.Variance, nil); err != nil {
+					return err
+				}
+				line.UnitCost = unitCost
+				line.VarianceValue = roundAmount(float64(line.Variance) * unitCost)
+			}
+			items = append(items, models.StockOpnameItem{
+				StockOpnameID:   opname.ID,
+				ProductID:       line.ProductID,
+				ProductName:     line.ProductName,
+				SystemQuantity:  line.SystemQuantity,
+				CountedQuantity: line.CountedQuantity,
+				Variance:        line.Variance,
+				UnitCost:        line.UnitCost,
+				VarianceValue:   line.VarianceValue,
+			})
+		}
+		if err := tx.Create(&items).Error; err != nil {
+			return errors.New("gagal menyimpan hasil stok opname")
+		}
+
+		report := dto.StockOpnameVarianceReport{Lines: lines}
+		summariseVariance(&report)
+
+		netShrinkage := report.ShrinkageAmount - report.SurplusAmount
+		if err := postJournal(tx, userID, now, notes, models.JournalSourceOpname, nil, []journalLineInput{
+			{Code: models.AccountCodeShrinkage, Debit: netShrinkage},
+			{Code: models.AccountCodeInventory, Credit: netShrinkage},
+		}); err != nil {
+			return err
+		}
+
+		opname.Status = models.OpnameFinalized
+		opname.FinalizedAt = &now
+		opname.ShrinkageAmount = report.ShrinkageAmount
+		opname.SurplusAmount = report.SurplusAmount
+		return tx.Save(&opname).Error
+	})
+	if err != nil {
+		return models.StockOpname{}, err
+	}
+	return s.GetStockOpnameByID(opname.ID, userID)
+}
+
+// CancelStockOpname membatalkan sesi yang masih terbuka tanpa mengubah stok
+func (s *StockOpnameService) CancelStockOpname(opnameID uint, userID uint) (models.StockOpname, error) {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		opname, err := findOwnedStockOpname(tx, opnameID, userID, true)
+		if err != nil {
+			return err
+		}
+		if opname.Status != models.OpnameOpen {
+			return errOpnameNotOpen
+		}
+		return tx.Model(&opname).Update("status", models.OpnameCancelled).Error
+	})
+	if err != nil {
+		return models.StockOpname{}, err
+	}
+	return s.GetStockOpnameByID(opnameID, userID)
+}
+
+// inventoryShrinkageBetween menjumlahkan beban selisih persediaan bersih (kurang - lebih)
+// dari stok opname yang difinalisasi, [DIUBAH] ditambah nilai pemusnahan batch, dalam rentang waktu
+func inventoryShrinkageBetween(userID uint, locationID *uint, startTime time.Time, endTime time.Time) (float64, error) {
+	db := database.DB
+	var opname, writeOff sumResult
+	opnameQuery := db.Model(&models.StockOpname{}).
+		Select("COALESCE(SUM(shrinkage_amount - surplus_amount), 0) as total").
+		Where("user_id = ? AND status = ? AND finalized_at BETWEEN ? AND ?", userID, models.OpnameFinalized, startTime, endTime)
+	writeOffQuery := db.Model(&models.BatchWriteOff{}).
+		Select("COALESCE(SUM(amount), 0) as total").
+		Where("user_id = ? AND created_at BETWEEN ? AND ?", userID, startTime, endTime)
+	// [BARU] Filter lokasi stok yang dihitung/dimusnahkan
+	if locationID != nil {
+		opnameQuery = opnameQuery.Where("location_id = ?", *locationID)
+		writeOffQuery = writeOffQuery.Where("location_id = ?", *locationID)
+	}
+	if err := opnameQuery.Scan(&opname).Error; err != nil {
+		return 0, err
+	}
+	err := writeOffQuery.Scan(&writeOff).Error
+	return opname.Total + writeOff.Total, err
+}
+
+// inventoryShrinkageAsOf menjumlahkan beban selisih & pemusnahan persediaan s.d. 'asOf' (untuk neraca)
+func inventoryShrinkageAsOf(userID uint, asOf time.Time) (float64, error) {
+	db := database.DB
+	var opname, writeOff sumResult
+	if err := db.Model(&models.StockOpname{}).
+		Select("COALESCE(SUM(shrinkage_amount - surplus_amount), 0) as total").
+		Where("user_id = ? AND status = ? AND finalized_at <= ?", userID, models.OpnameFinalized, asOf).
+		Scan(&opname).Error; err != nil {
+		return 0, err
+	}
+	err := db.Model(&models.BatchWriteOff{}).
+		Select("COALESCE(SUM(amount), 0) as total").
+		Where("user_id = ? AND created_at <= ?", userID, asOf).
+		Scan(&writeOff).Error
+	return opname.Total + writeOff.Total, err
+}
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/danishyusrah/go_bisnis/internal/database"
+	"github.com/danishyusrah/go_bisnis/internal/dto"
+	"github.com/danishyusrah/go_bisnis/internal/models"
+	"gorm.io/gorm"
+)
+
+// SupplierService adalah struct untuk layanan terkait supplier
+type SupplierService struct{}
+
+// NewSupplierService membuat instance SupplierService baru
+func NewSupplierService() *SupplierService {
+	return &SupplierService{}
+}
+
+// findOwnedSupplier mengambil supplier dan memvalidasi kepemilikan
+func findOwnedSupplier(tx *gorm.DB, supplierID uint, userID uint) (models.Supplier, error) {
+	var supplier models.Supplier
+	if err := tx.First(&supplier, supplierID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Supplier{}, errors.New("supplier tidak ditemukan")
+		}
+		return models.Supplier{}, err
+	}
+	if supplier.UserID != userID {
+		return models.Supplier{}, errors.New("akses ditolak: Anda bukan pemilik supplier ini")
+	}
+	return supplier, nil
+}
+
+// CreateSupplier adalah logika bisnis untuk membuat supplier baru
+func (s *SupplierService) CreateSupplier(input dto.CreateSupplierInput, userID uint) (models.Supplier, error) {
+	newSupplier := models.Supplier{
+		Name:    input.Name,
+		Email:   input.Email,
+		Phone:   input.Phone,
+		Address: input.Address,
+		UserID:  userID,
+	}
+	if err := database.DB.Create(&newSupplier).Error; err != nil {
+		return models.Supplier{}, err
+	}
+	return newSupplier, nil
+}
+
+// GetUserSuppliers mengambil semua supplier milik user, diurutkan berdasarkan nama A-Z
+func (s *SupplierService) GetUserSuppliers(userID uint) ([]models.Supplier, error) {
+	var suppliers []models.Supplier
+	if err := database.DB.Where("user_id = ?", userID).Order("name asc").Find(&suppliers).Error; err != nil {
+		return nil, err
+	}
+	return suppliers, nil
+}
+
+// GetSupplierByID mengambil satu supplier, dan memvalidasi kepemilikan
+func (s *SupplierService) GetSupplierByID(supplierID uint, userID uint) (models.Supplier, error) {
+	return findOwnedSupplier(database.DB, supplierID, userID)
+}
+
+// UpdateSupplier memperbarui supplier (hanya field yang diisi), dan memvalidasi kepemilikan
+func (s *SupplierService) UpdateSupplier(supplierID uint, input dto.UpdateSupplierInput, userID uint) (models.Supplier, error) {
+	supplier, err := s.GetSupplierByID(supplierID, userID)
+	if err != nil {
+		return models.Supplier{}, err
+	}
+
+	updateData := models.Supplier{
+		Name:    input.Name,
+		Email:   input.Email,
+		Phone:   input.Phone,
+		Address: input.Address,
+	}
+	if err := database.DB.Model(&supplier).Updates(updateData).Error; err != nil {
+		return models.Supplier{}, err
+	}
+	return supplier, nil
+}
+
+// DeleteSupplier menghapus supplier (soft delete). Supplier dengan PO yang masih berjalan tidak boleh dihapus.
+func (s *SupplierService) DeleteSupplier(supplierID uint, userID uint) error {
+	db := database.DB
+	supplier, err := s.GetSupplierByID(supplierID, userID)
+	if err != nil {
+		return err
+	}
+
+	var openOrders int64
+	if err := db.Model(&models.PurchaseOrder{}).
+		Where("supplier_id = ? AND status IN ?", supplier.ID, []models.PurchaseOrderStatus{models.POOrdered, models.POPartiallyReceived, models.POReceived}).
+		Count(&openOrders).Error; err != nil {
+		return err
+	}
+	if openOrders > 0 {
+		return errors.New("supplier masih memiliki PO yang belum selesai")
+	}
+
+	return db.Delete(&supplier).Error
+}
+
+// supplierPurchaseSummaries menjumlahkan pembelian (EXPENSE, bukan VOID) per supplier.
+// supplierID diisi = hanya supplier tersebut. Rentang waktu opsional (zero time = tanpa batas).
+func supplierPurchaseSummaries(userID uint, supplierID *uint, startTime time.Time, endTime time.Time) ([]dto.SupplierPurchaseSummary, error) {
+	type summaryRow struct {
+		SupplierID       *uint
+		SupplierName     *string
+		TransactionCount int64
+		TotalPurchases   float64
+		TotalPaid        float64
+	}
+	query := database.DB.Model(&models.Transaction{}).
+		Select(`transactions.supplier_id, suppliers.name as supplier_name, COUNT(transactions.id) as transaction_count,
+			COALESCE(SUM(transactions.total_amount - transactions.refunded_amount), 0) as total_purchases,
+			COALESCE(SUM(transactions.paid_amount), 0) as total_paid`).
+		Joins("LEFT JOIN suppliers ON suppliers.id = transactions.supplier_id").
+		Where("transactions.user_id = ? AND transactions.type = ? AND transactions.status <> ?", userID, models.Expense, models.StatusVoid)
+	if supplierID != nil {
+		query = query.Where("transactions.supplier_id = ?", *supplierID)
+	}
+	if !startTime.IsZero() {
+		query = query.Where("transactions.created_at BETWEEN ? AND ?", startTime, endTime)
+	}
+
+	var rows []summaryRow
+	if err := query.Group("transactions.supplier_id, suppliers.name").
+		Order("total_purchases desc").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	summaries := []dto.SupplierPurchaseSummary{}
+	for _, row := range rows {
+		name := "Tanpa Supplier"
+		if row.SupplierName != nil {
+			name = *row.SupplierName
+		}
+		summaries = append(summaries, dto.SupplierPurchaseSummary{
+			SupplierID:       row.SupplierID,
+			SupplierName:     name,
+			TransactionCount: row.TransactionCount,
+			TotalPurchases:   roundAmount(row.TotalPurchases),
+			TotalPaid:        roundAmount(row.TotalPaid),
+			Outstanding:      roundAmount(row.TotalPurchases - row.TotalPaid),
+		})
+	}
+	return summaries, nil
+}
+
+// GetSupplierPurchases mengambil riwayat pembelian dari satu supplier (terbaru dulu) beserta ringkasannya
+func (s *SupplierService) GetSupplierPurchases(supplierID uint, userID uint) (dto.SupplierPurchaseSummary, []models.Transaction, error) {
+	db := database.DB
+	supplier, err := s.GetSupplierByID(supplierID, userID)
+	if err != nil {
+		return dto.SupplierPurchaseSummary{}, nil, err
+	}
+
+	summary := dto.SupplierPurchaseSummary{SupplierID: &supplier.ID, SupplierName: supplier.Name}
+	summaries, err := supplierPurchaseSummaries(userID, &supplier.ID, time.Time{}, time.Time{})
+	if err != nil {
+		return summary, nil, err
+	}
+	if len(summaries) > 0 {
+		summary = summaries[0]
+	}
+
+	var transactions []models.Transaction
+	err = db.Preload("Items").Preload("Supplier").Preload("Category").Preload("Location").Preload("Refunds.Items").Preload("Payments").Preload("Promotions").
+		Where("user_id = ? AND type = ? AND supplier_id = ?", userID, models.Expense, supplier.ID).
+		Order("created_at desc").
+		Find(&transactions).Error
+	return summary, transactions, err
+}
+
+// GetPurchasesBySupplier membuat laporan total pembelian & sisa utang per supplier dalam rentang waktu
+func (s *SupplierService) GetPurchasesBySupplier(userID uint, startTime time.Time, endTime time.Time) ([]dto.SupplierPurchaseSummary, error) {
+	return supplierPurchaseSummaries(userID, nil, startTime, endTime)
+}
@@ -0,0 +1,218 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"math"
+	"time"
+
+	"github.com/danishyusrah/go_bisnis/internal/database"
+	"github.com/danishyusrah/go_bisnis/internal/dto"
+	"github.com/danishyusrah/go_bisnis/internal/models"
+	"gorm.io/gorm"
+)
+
+// TaxService adalah struct untuk layanan terkait pajak (PPN)
+type TaxService struct{}
+
+// NewTaxService membuat instance TaxService baru
+func NewTaxService() *TaxService {
+	return &TaxService{}
+}
+
+// roundAmount membulatkan nilai uang ke 2 angka desimal
+func roundAmount(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// calculateItemTax menghitung DPP dan pajak untuk satu baris bernilai 'lineAmount'.
+// Jika inclusive, lineAmount sudah termasuk pajak; jika tidak, pajak ditambahkan di atasnya.
+func calculateItemTax(lineAmount float64, rate float64, inclusive bool) (float64, float64) {
+	if rate <= 0 {
+		return lineAmount, 0
+	}
+	if inclusive {
+		tax := roundAmount(lineAmount * rate / (100 + rate))
+		return lineAmount - tax, tax
+	}
+	return lineAmount, roundAmount(lineAmount * rate / 100)
+}
+
+// findOwnedTaxRate mengambil tarif pajak dan memvalidasi kepemilikan
+func findOwnedTaxRate(tx *gorm.DB, taxRateID uint, userID uint) (models.TaxRate, error) {
+	var taxRate models.TaxRate
+	if err := tx.First(&taxRate, taxRateID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.TaxRate{}, errors.New("tarif pajak tidak ditemukan")
+		}
+		return models.TaxRate{}, err
+	}
+	if taxRate.UserID != userID {
+		return models.TaxRate{}, errors.New("akses ditolak: Anda bukan pemilik tarif pajak ini")
+	}
+	return taxRate, nil
+}
+
+// resolveItemTaxRate menentukan tarif pajak sebuah item: tarif item diutamakan, lalu tarif level transaksi.
+// Produk bebas pajak tidak dikenakan PPN. 'cache' menyimpan tarif yang sudah divalidasi.
+func resolveItemTaxRate(tx *gorm.DB, cache map[uint]models.TaxRate, itemTaxRateID *uint, defaultTaxRateID *uint, taxExempt bool, userID uint) (float64, *uint, error) {
+	taxRateID := itemTaxRateID
+	if taxRateID == nil {
+		taxRateID = defaultTaxRateID
+	}
+	if taxRateID == nil || taxExempt {
+		return 0, nil, nil
+	}
+	taxRate, ok := cache[*taxRateID]
+	if !ok {
+		found, err := findOwnedTaxRate(tx, *taxRateID, userID)
+		if err != nil {
+			return 0, nil, err
+		}
+		taxRate = found
+		cache[*taxRateID] = taxRate
+	}
+	return taxRate.Rate, &taxRate.ID, nil
+}
+
+// GetTaxRates mengambil semua tarif pajak milik user
+func (s *TaxService) GetTaxRates(userID uint) ([]models.TaxRate, error) {
+	var taxRates []models.TaxRate
+	err := database.DB.Where("user_id = ?", userID).Order("name asc").Find(&taxRates).Error
+	return taxRates, err
+}
+
+// saveTaxRate menyimpan tarif pajak; hanya boleh ada satu tarif default per user
+func saveTaxRate(taxRate *models.TaxRate) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if taxRate.IsDefault {
+			if err := tx.Model(&models.TaxRate{}).
+				Where("user_id = ? AND id <> ?", taxRate.UserID, taxRate.ID).
+				Update("is_default", false).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Save(taxRate).Error
+	})
+}
+
+// CreateTaxRate membuat tarif pajak baru
+func (s *TaxService) CreateTaxRate(input dto.CreateTaxRateInput, userID uint) (models.TaxRate, error) {
+	taxRate := models.TaxRate{
+		UserID:    userID,
+		Name:      input.Name,
+		Rate:      input.Rate,
+		IsDefault: input.IsDefault,
+	}
+	if err := saveTaxRate(&taxRate); err != nil {
+		return models.TaxRate{}, err
+	}
+	return taxRate, nil
+}
+
+// UpdateTaxRate memperbarui tarif pajak. Transaksi lama tidak berubah karena tarifnya sudah di-snapshot.
+func (s *TaxService) UpdateTaxRate(taxRateID uint, input dto.CreateTaxRateInput, userID uint) (models.TaxRate, error) {
+	taxRate, err := findOwnedTaxRate(database.DB, taxRateID, userID)
+	if err != nil {
+		return models.TaxRate{}, err
+	}
+	taxRate.Name = input.Name
+	taxRate.Rate = input.Rate
+	taxRate.IsDefault = input.IsDefault
+	if err := saveTaxRate(&taxRate); err != nil {
+		return models.TaxRate{}, err
+	}
+	return taxRate, nil
+}
+
+// DeleteTaxRate menghapus tarif pajak
+func (s *TaxService) DeleteTaxRate(taxRateID uint, userID uint) error {
+	taxRate, err := findOwnedTaxRate(database.DB, taxRateID, userID)
+	if err != nil {
+		return err
+	}
+	return database.DB.Delete(&taxRate).Error
+}
+
+// GetTaxReport membuat rekap PPN Keluaran vs PPN Masukan untuk satu periode (cth: satu bulan masa pajak)
+// [DIUBAH] locationID diisi = hanya PPN dari transaksi di lokasi tersebut
+func (s *TaxService) GetTaxReport(userID uint, locationID *uint, startTime time.Time, endTime time.Time) (dto.TaxReport, error) {
+	db := database.DB
+	report := dto.TaxReport{
+		From:   startTime.Format("2006-01-02"),
+		To:     endTime.Format("2006-01-02"),
+		ByRate: []dto.TaxReportLine{},
+	}
+
+	// 1. Pajak dari item transaksi dalam periode, per tipe & tarif
+	type taxRow struct {
+		Type          models.TransactionType
+		TaxRate       float64
+		TaxableAmount float64
+		TaxAmount     float64
+	}
+	var rows []taxRow
+	err := db.Model(&models.TransactionItem{}).
+		Select("transactions.type, transaction_items.tax_rate, SUM(transaction_items.taxable_amount) as taxable_amount, SUM(transaction_items.tax_amount) as tax_amount").
+		Joins("JOIN transactions ON transactions.id = transaction_items.transaction_id").
+		Scopes(locationScope(locationID)).
+		Where("transactions.user_id = ? AND transactions.status <> ? AND transactions.type IN ? AND transactions.created_at BETWEEN ? AND ? AND transaction_items.tax_amount <> 0",
+			userID, models.StatusVoid, []models.TransactionType{models.Income, models.Expense}, startTime, endTime).
+		Group("transactions.type, transaction_items.tax_rate").
+		Order("transaction_items.tax_rate asc").
+		Scan(&rows).Error
+	if err != nil {
+		log.Printf("Error querying tax report: %v", err)
+		return report, err
+	}
+
+	lineIndex := make(map[float64]int)
+	for _, r := range rows {
+		idx, ok := lineIndex[r.TaxRate]
+		if !ok {
+			report.ByRate = append(report.ByRate, dto.TaxReportLine{TaxRate: r.TaxRate})
+			idx = len(report.ByRate) - 1
+			lineIndex[r.TaxRate] = idx
+		}
+		line := &report.ByRate[idx]
+		if r.Type == models.Income {
+			line.TaxableSales += r.TaxableAmount
+			line.OutputVAT += r.TaxAmount
+			report.OutputVAT += r.TaxAmount
+		} else {
+			line.TaxablePurchases += r.TaxableAmount
+			line.InputVAT += r.TaxAmount
+			report.InputVAT += r.TaxAmount
+		}
+	}
+
+	// 2. Retur dalam periode mengurangi PPN pada periode retur terjadi
+	type returnRow struct {
+		Type      models.TransactionType
+		TaxAmount float64
+	}
+	var returns []returnRow
+	err = db.Model(&models.Refund{}).
+		Select("transactions.type, COALESCE(SUM(refunds.tax_amount), 0) as tax_amount").
+		Joins("JOIN transactions ON transactions.id = refunds.transaction_id").
+		Scopes(locationScope(locationID)).
+		Where("refunds.user_id = ? AND transactions.status <> ? AND refunds.created_at BETWEEN ? AND ?", userID, models.StatusVoid, startTime, endTime).
+		Group("transactions.type").
+		Scan(&returns).Error
+	if err != nil {
+		log.Printf("Error querying tax returns: %v", err)
+		return report, err
+	}
+	for _, r := range returns {
+		if r.Type == models.Income {
+			report.OutputVATReturned += r.TaxAmount
+		} else if r.Type == models.Expense {
+			report.InputVATReturned += r.TaxAmount
+		}
+	}
+
+	report.NetOutputVAT = report.OutputVAT - report.OutputVATReturned
+	report.NetInputVAT = report.InputVAT - report.InputVATReturned
+	report.VATPayable = report.NetOutputVAT - report.NetInputVAT
+	return report, nil
+}
@@ -0,0 +1,63 @@
+package services
+
+import "testing"
+
+func TestCalculateItemTax(t *testing.T) {
+	tests := []struct {
+		name      string
+		amount    float64
+		rate      float64
+		inclusive bool
+		wantBase  float64
+		wantTax   float64
+	}{
+		{name: "tanpa pajak", amount: 10000, rate: 0, wantBase: 10000, wantTax: 0},
+		{name: "tarif negatif diabaikan", amount: 10000, rate: -11, inclusive: true, wantBase: 10000, wantTax: 0},
+		{name: "eksklusif", amount: 10000, rate: 11, wantBase: 10000, wantTax: 1100},
+		{name: "eksklusif dibulatkan ke sen", amount: 999.99, rate: 11, wantBase: 999.99, wantTax: 110},
+		{name: "inklusif", amount: 11100, rate: 11, inclusive: true, wantBase: 10000, wantTax: 1100},
+		{name: "inklusif dibulatkan, DPP + pajak = nilai baris", amount: 10000, rate: 11, inclusive: true, wantBase: 9009.01, wantTax: 990.99},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base, tax := calculateItemTax(tt.amount, tt.rate, tt.inclusive)
+			assertAmount(t, "DPP", base, tt.wantBase)
+			assertAmount(t, "pajak", tax, tt.wantTax)
+		})
+	}
+}
+
+func TestProportionalAmount(t *testing.T) {
+	tests := []struct {
+		name   string
+		total  float64
+		splits []int // Jumlah unit per porsi, berurutan
+		want   []float64
+	}{
+		{name: "porsi sama", total: 300, splits: []int{1, 1, 1}, want: []float64{100, 100, 100}},
+		{name: "pembulatan kumulatif", total: 100, splits: []int{1, 1, 1}, want: []float64{33.33, 33.34, 33.33}},
+		{name: "porsi berbeda", total: 1000, splits: []int{3, 7}, want: []float64{300, 700}},
+		{name: "tanpa unit", total: 1000, splits: []int{0}, want: []float64{0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			totalQuantity := 0
+			for _, quantity := range tt.splits {
+				totalQuantity += quantity
+			}
+			before := 0
+			var sum float64
+			for i, quantity := range tt.splits {
+				got := proportionalAmount(tt.total, before, quantity, totalQuantity)
+				assertAmount(t, "porsi", got, tt.want[i])
+				before += quantity
+				sum += got
+			}
+			if totalQuantity > 0 {
+				assertAmount(t, "jumlah porsi", sum, tt.total)
+			}
+		})
+	}
+}
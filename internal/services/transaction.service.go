@@ -4,12 +4,28 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"math"
 	"time" // [BARU] Pastikan 'time' di-import
 
 	"github.com/danishyusrah/go_bisnis/internal/database"
 	"github.com/danishyusrah/go_bisnis/internal/dto"
 	"github.com/danishyusrah/go_bisnis/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// [BARU] Potongan SQL bersama agar dashboard & laporan menghitung nilai bersih
+// (setelah retur) dengan cara yang sama
+const (
+	// netTotalSQL adalah total transaksi setelah dikurangi nilai retur
+	// [DIUBAH] PPN tidak termasuk (PPN adalah titipan pajak, bukan pendapatan/beban)
+	netTotalSQL = "(transactions.total_amount - transactions.tax_amount - (transactions.refunded_amount - transactions.refunded_tax_amount))"
+	// netQuantitySQL adalah kuantitas item setelah dikurangi unit yang diretur
+	netQuantitySQL = "(transaction_items.quantity - transaction_items.refunded_quantity)"
+	// [BARU] unitNetPriceSQL adalah harga per unit tanpa pajak (DPP / kuantitas)
+	unitNetPriceSQL = "(transaction_items.taxable_amount / transaction_items.quantity)"
+	// [BARU] unitDiscountSQL adalah diskon per unit (item + promo + alokasi diskon transaksi) tanpa pajak
+	unitDiscountSQL = "((transaction_items.discount_amount + transaction_items.promotion_discount_amount + transaction_items.transaction_discount_amount) / transaction_items.quantity * CASE WHEN transaction_items.tax_inclusive THEN 100 / (100 + transaction_items.tax_rate) ELSE 1 END)"
 )
 
 // TransactionService adalah struct untuk layanan terkait transaksi
@@ -24,6 +40,13 @@ func NewTransactionService() *TransactionService {
 func (s *TransactionService) CreateTransaction(input dto.CreateTransactionInput, userID uint) (models.Transaction, error) {
 	db := database.DB
 	var totalAmount float64 = 0
+	var taxAmount float64 = 0                           // [BARU] Total PPN dalam transaksi
+	var grossAmount float64 = 0                         // [BARU] Total sebelum diskon
+	var discountAmount float64 = 0                      // [BARU] Diskon level transaksi
+	var promotionAmount float64 = 0                     // [BARU] Total potongan promo otomatis
+	var appliedPromotions []models.TransactionPromotion // [BARU] Promo yang diterapkan
+	var stockMovements []models.StockMovement           // [BARU] Kartu stok
+	var batchConsumptions []models.BatchConsumption     // [BARU] Pemakaian batch (FEFO)
 	var transactionItems []models.TransactionItem
 
 	tx := db.Begin()
@@ -37,10 +60,25 @@ func (s *TransactionService) CreateTransaction(input dto.CreateTransactionInput,
 	}()
 
 	// --- [BARU] Validasi Pelanggan untuk Utang/Piutang ---
-	// Jika status "BELUM LUNAS", CustomerID wajib diisi
-	if input.PaymentStatus == models.BelumLunas && input.CustomerID == nil {
+	// [DIUBAH] Pihak lawan EXPENSE adalah supplier, pihak lawan INCOME adalah pelanggan
+	if input.Type == models.Expense && input.CustomerID != nil {
 		tx.Rollback()
-		return models.Transaction{}, errors.New("pelanggan/supplier wajib diisi untuk transaksi yang belum lunas")
+		return models.Transaction{}, errors.New("transaksi pengeluaran memakai supplier_id, bukan customer_id")
+	}
+	if input.Type != models.Expense && input.SupplierID != nil {
+		tx.Rollback()
+		return models.Transaction{}, errors.New("supplier hanya dapat diisi untuk transaksi pengeluaran")
+	}
+	// Jika status "BELUM LUNAS", pelanggan/supplier wajib diisi
+	if input.PaymentStatus == models.BelumLunas {
+		if input.Type == models.Expense && input.SupplierID == nil {
+			tx.Rollback()
+			return models.Transaction{}, errors.New("supplier wajib diisi untuk pengeluaran yang belum lunas")
+		}
+		if input.Type != models.Expense && input.CustomerID == nil {
+			tx.Rollback()
+			return models.Transaction{}, errors.New("pelanggan wajib diisi untuk transaksi yang belum lunas")
+		}
 	}
 	// --- [AKHIR BARU] ---
 
@@ -57,6 +95,14 @@ func (s *TransactionService) CreateTransaction(input dto.CreateTransactionInput,
 		}
 	}
 
+	// [BARU] Validasi SupplierID jika ada
+	if input.SupplierID != nil {
+		if _, err := findOwnedSupplier(tx, *input.SupplierID, userID); err != nil {
+			tx.Rollback()
+			return models.Transaction{}, err
+		}
+	}
+
 	// --- [BARU] Validasi Kategori ---
 	if input.CategoryID != nil {
 		var category models.Category
@@ -88,6 +134,45 @@ func (s *TransactionService) CreateTransaction(input dto.CreateTransactionInput,
 	}
 	// --- [AKHIR BARU] ---
 
+	// --- [BARU] Tanggal Transaksi & Kunci Periode ---
+	transactionDate, err := parseTransactionDate(input.TransactionDate)
+	if err != nil {
+		tx.Rollback()
+		return models.Transaction{}, err
+	}
+	if err := ensurePeriodOpen(tx, userID, transactionDate); err != nil {
+		tx.Rollback()
+		return models.Transaction{}, err
+	}
+	// --- [AKHIR BARU] ---
+
+	// --- [BARU] Lokasi (gudang/outlet) tempat stok keluar/masuk, default lokasi utama ---
+	var locationID *uint
+	if input.Type == models.Income || input.Type == models.Expense {
+		resolved, err := resolveLocation(tx, userID, input.LocationID)
+		if err != nil {
+			tx.Rollback()
+			return models.Transaction{}, err
+		}
+		locationID = &resolved
+	}
+	// --- [AKHIR BARU] ---
+
+	// [BARU] Penukaran poin loyalitas: validasi saldo dan hitung nilai potongannya
+	var loyaltyProgram *models.LoyaltyProgram
+	var redeemValue float64
+	if input.RedeemPoints > 0 {
+		if input.Type != models.Income || input.CustomerID == nil {
+			tx.Rollback()
+			return models.Transaction{}, errors.New("penukaran poin hanya untuk penjualan dengan pelanggan")
+		}
+		loyaltyProgram, redeemValue, err = prepareLoyaltyRedemption(tx, userID, *input.CustomerID, input.RedeemPoints, transactionDate)
+		if err != nil {
+			tx.Rollback()
+			return models.Transaction{}, err
+		}
+	}
+
 	// --- Logika Baru Berdasarkan Tipe Transaksi ---
 
 	if input.Type == models.Capital {
@@ -103,6 +188,7 @@ func (s *TransactionService) CreateTransaction(input dto.CreateTransactionInput,
 		}
 
 		totalAmount = input.TotalAmount
+		grossAmount = input.TotalAmount // [BARU]
 
 	} else if input.Type == models.Income || input.Type == models.Expense {
 		// --- LOGIKA UNTUK PEMASUKAN (INCOME) & PENGELUARAN (EXPENSE) ---
@@ -112,9 +198,15 @@ func (s *TransactionService) CreateTransaction(input dto.CreateTransactionInput,
 			return models.Transaction{}, errors.New("transaksi 'Pemasukan' atau 'Pengeluaran' harus memiliki minimal 1 item")
 		}
 
+		// [BARU] Cache tarif pajak yang sudah divalidasi
+		taxRates := make(map[uint]models.TaxRate)
+		productCategories := make(map[uint]*uint) // [BARU] Kategori produk untuk promo per kategori
+
 		for _, itemInput := range input.Items {
-			totalAmount += itemInput.UnitPrice * float64(itemInput.Quantity)
 			var itemPurchasePrice float64 = 0
+			taxExempt := false                                   // [BARU]
+			var itemComponents []models.TransactionItemComponent // [BARU] Bahan produk komposit
+			var unit itemUnit                                    // [BARU] Satuan transaksi & jumlah satuan dasar
 
 			if itemInput.ProductID != nil {
 				var product models.Product
@@ -130,38 +222,113 @@ func (s *TransactionService) CreateTransaction(input dto.CreateTransactionInput,
 					tx.Rollback()
 					return models.Transaction{}, fmt.Errorf("akses ditolak: produk ID %d bukan milik Anda", *itemInput.ProductID)
 				}
+				taxExempt = product.TaxExempt                      // [BARU]
+				productCategories[product.ID] = product.CategoryID // [BARU]
+				// [BARU] Stok selalu berubah dalam satuan dasar
+				if unit, err = resolveItemUnit(tx, &product, itemInput.ProductName, itemInput.Unit, itemInput.Quantity); err != nil {
+					tx.Rollback()
+					return models.Transaction{}, err
+				}
 
 				if input.Type == models.Income {
-					if product.Stock < itemInput.Quantity {
-						tx.Rollback()
-						return models.Transaction{}, fmt.Errorf("stok tidak cukup untuk produk: %s (sisa: %d)", product.Name, product.Stock)
+					// [DIUBAH] Stok, HPP & batch dikurangi lewat sellProductStock.
+					// Produk komposit mengurangi stok bahan-bahannya sesuai resep.
+					var movements []models.StockMovement
+					var consumptions []models.BatchConsumption
+					var unitCost float64
+					if product.IsComposite {
+						unitCost, itemComponents, movements, consumptions, err = sellCompositeStock(tx, &product, userID, *locationID, unit.BaseQuantity)
+					} else {
+						var movement models.StockMovement
+						unitCost, movement, consumptions, err = sellProductStock(tx, &product, userID, *locationID, unit.BaseQuantity, "")
+						movements = []models.StockMovement{movement}
 					}
-					itemPurchasePrice = product.PurchasePrice
-					newStock := product.Stock - itemInput.Quantity
-					if err := tx.Model(&product).Update("stock", newStock).Error; err != nil {
+					if err != nil {
 						tx.Rollback()
-						return models.Transaction{}, fmt.Errorf("gagal memperbarui stok untuk produk ID %d", *itemInput.ProductID)
+						return models.Transaction{}, err
 					}
+					itemPurchasePrice = unitCost
+					// [BARU] Kartu stok dicatat setelah transaksi tersimpan (butuh ID transaksi)
+					stockMovements = append(stockMovements, movements...)
+					batchConsumptions = append(batchConsumptions, consumptions...)
 				}
-				if input.Type == models.Expense {
-					newStock := product.Stock + itemInput.Quantity
-					if err := tx.Model(&product).Update("stock", newStock).Error; err != nil {
-						tx.Rollback()
-						return models.Transaction{}, fmt.Errorf("gagal memperbarui stok (restock) untuk produk ID %d", *itemInput.ProductID)
-					}
+				// [DIUBAH] Stok restock (EXPENSE) ditambahkan setelah transaksi tersimpan,
+				// karena biaya per unit baru diketahui setelah diskon & pajak dihitung
+			} else if unit, err = resolveItemUnit(tx, nil, itemInput.ProductName, itemInput.Unit, itemInput.Quantity); err != nil {
+				tx.Rollback()
+				return models.Transaction{}, err
+			}
+
+			// --- [BARU] Tarif pajak item ---
+			rate, appliedTaxRateID, err := resolveItemTaxRate(tx, taxRates, itemInput.TaxRateID, input.TaxRateID, taxExempt, userID)
+			if err != nil {
+				tx.Rollback()
+				return models.Transaction{}, err
+			}
+			// --- [AKHIR BARU] ---
+
+			// --- [BARU] Diskon per item ---
+			lineGross := itemInput.UnitPrice * itemInput.Quantity
+			itemDiscount, err := calculateDiscount(itemInput.DiscountType, itemInput.DiscountValue, lineGross)
+			if err != nil {
+				tx.Rollback()
+				return models.Transaction{}, fmt.Errorf("%s: %v", itemInput.ProductName, err)
+			}
+			// --- [AKHIR BARU] ---
+
+			// --- [BARU] Lot & kedaluwarsa (hanya dicatat untuk pembelian) ---
+			var lotNumber string
+			var expiryDate *time.Time
+			if input.Type == models.Expense {
+				lotNumber = itemInput.LotNumber
+				if expiryDate, err = parseExpiryDate(itemInput.ExpiryDate); err != nil {
+					tx.Rollback()
+					return models.Transaction{}, err
 				}
 			}
+			// --- [AKHIR BARU] ---
 
+			// DPP & pajak dihitung setelah diskon transaksi dialokasikan (lihat di bawah)
 			newItem := models.TransactionItem{
 				ProductID:     itemInput.ProductID,
 				ProductName:   itemInput.ProductName,
-				Quantity:      itemInput.Quantity,
+				Quantity:      unit.BaseQuantity, // [DIUBAH]
 				UnitPrice:     itemInput.UnitPrice,
 				PurchasePrice: itemPurchasePrice,
+				// [BARU] Satuan transaksi
+				UnitName:     unit.Name,
+				UnitFactor:   unit.Factor,
+				UnitQuantity: unit.Quantity,
+				// [BARU] Pajak
+				TaxRateID:    appliedTaxRateID,
+				TaxRate:      rate,
+				TaxInclusive: itemInput.TaxInclusive,
+				// [BARU] Diskon
+				DiscountType:   itemInput.DiscountType,
+				DiscountValue:  itemInput.DiscountValue,
+				DiscountAmount: itemDiscount,
+				// [BARU] Lot
+				LotNumber:  lotNumber,
+				ExpiryDate: expiryDate,
+				Components: itemComponents, // [BARU]
 			}
 			transactionItems = append(transactionItems, newItem)
 		}
 
+		// --- [BARU] Hitung promo, diskon transaksi, DPP & pajak ---
+		pricing, err := priceTransactionItems(tx, userID, input.Type, transactionDate, transactionItems, productCategories, input.DiscountType, input.DiscountValue, redeemValue)
+		if err != nil {
+			tx.Rollback()
+			return models.Transaction{}, err
+		}
+		totalAmount = pricing.TotalAmount
+		taxAmount = pricing.TaxAmount
+		grossAmount = pricing.GrossAmount
+		discountAmount = pricing.DiscountAmount
+		promotionAmount = pricing.PromotionAmount
+		appliedPromotions = pricing.Promotions
+		// --- [AKHIR BARU] ---
+
 	} else {
 		tx.Rollback()
 		return models.Transaction{}, errors.New("tipe transaksi tidak valid")
@@ -180,17 +347,63 @@ func (s *TransactionService) CreateTransaction(input dto.CreateTransactionInput,
 		dueDate = nil
 	}
 
+	// [BARU] Transaksi yang langsung LUNAS otomatis tercatat sebagai satu pembayaran penuh
+	var payments []models.Payment
+	var paidAmount float64 = 0
+	if paymentStatus == models.Lunas {
+		paymentMethod := input.PaymentMethod
+		if paymentMethod == "" {
+			paymentMethod = models.MethodTunai
+		}
+		paidAmount = totalAmount
+		payments = append(payments, models.Payment{
+			UserID:      userID,
+			Amount:      totalAmount,
+			PaymentDate: transactionDate, // [DIUBAH]
+			Method:      paymentMethod,
+		})
+	}
+
+	// [BARU] Penjualan kredit: cek batas kredit & tagihan lewat jatuh tempo pelanggan
+	creditOverride := false
+	if input.Type == models.Income && paymentStatus == models.BelumLunas && input.CustomerID != nil {
+		if err := checkCustomerCredit(tx, *input.CustomerID, totalAmount, input.CreditOverride); err != nil {
+			tx.Rollback()
+			return models.Transaction{}, err
+		}
+		creditOverride = input.CreditOverride
+	}
+
 	newTransaction := models.Transaction{
 		UserID:      userID,
 		Type:        input.Type,
 		TotalAmount: totalAmount,
 		CustomerID:  input.CustomerID,
+		SupplierID:  input.SupplierID, // [BARU]
 		Notes:       input.Notes,
+		CreatedAt:   transactionDate, // [BARU]
 		Items:       transactionItems,
 		// [BARU] Simpan data baru
 		PaymentStatus: paymentStatus,
 		DueDate:       dueDate,
 		CategoryID:    input.CategoryID, // [BARU]
+		LocationID:    locationID,       // [BARU]
+		PaidAmount:    paidAmount,       // [BARU]
+		Payments:      payments,         // [BARU]
+		TaxAmount:     taxAmount,        // [BARU]
+		// [BARU] Diskon
+		GrossAmount:    grossAmount,
+		DiscountType:   input.DiscountType,
+		DiscountValue:  input.DiscountValue,
+		DiscountAmount: discountAmount,
+		// [BARU] Promo
+		PromotionAmount: promotionAmount,
+		Promotions:      appliedPromotions,
+		PurchaseOrderID: input.PurchaseOrderID, // [BARU]
+		CreditOverride:  creditOverride,        // [BARU]
+		// [BARU] Poin loyalitas
+		LoyaltyPointsRedeemed: input.RedeemPoints,
+		LoyaltyDiscountAmount: redeemValue,
 	}
 
 	if err := tx.Create(&newTransaction).Error; err != nil {
@@ -198,6 +411,76 @@ func (s *TransactionService) CreateTransaction(input dto.CreateTransactionInput,
 		return models.Transaction{}, errors.New("gagal menyimpan data transaksi")
 	}
 
+	// [BARU] Simpan kartu stok dengan referensi transaksi
+	if len(stockMovements) > 0 {
+		for i := range stockMovements {
+			stockMovements[i].TransactionID = &newTransaction.ID
+		}
+		if err := tx.Create(&stockMovements).Error; err != nil {
+			tx.Rollback()
+			return models.Transaction{}, errors.New("gagal mencatat kartu stok")
+		}
+	}
+
+	// [BARU] Simpan pemakaian batch agar void/retur bisa mengembalikan ke batch asal
+	if len(batchConsumptions) > 0 {
+		for i := range batchConsumptions {
+			batchConsumptions[i].TransactionID = &newTransaction.ID
+		}
+		if err := tx.Create(&batchConsumptions).Error; err != nil {
+			tx.Rollback()
+			return models.Transaction{}, errors.New("gagal mencatat pemakaian batch")
+		}
+	}
+
+	// [BARU] Tagihan PO: barang sudah masuk stok saat penerimaan, cukup tandai PO sebagai ditagih
+	if input.PurchaseOrderID != nil {
+		if err := markPurchaseOrderBilled(tx, *input.PurchaseOrderID, userID, &newTransaction); err != nil {
+			tx.Rollback()
+			return models.Transaction{}, err
+		}
+	}
+
+	// [BARU] Restock: tambah stok dan perbarui biaya persediaan pada DPP per unit
+	if input.Type == models.Expense && input.PurchaseOrderID == nil {
+		for _, item := range newTransaction.Items {
+			if item.ProductID == nil {
+				continue
+			}
+			product, err := lockOwnedProduct(tx, *item.ProductID, userID)
+			if err != nil {
+				tx.Rollback()
+				return models.Transaction{}, err
+			}
+			unitCost := itemInventoryCost(models.Expense, item)
+			if _, err := moveProductStock(tx, &product, *locationID, item.Quantity, &unitCost, models.MovementPurchase, &newTransaction.ID, transactionDate, ""); err != nil {
+				tx.Rollback()
+				return models.Transaction{}, err
+			}
+			// [BARU] Setiap restock produk yang dilacak membentuk batch baru
+			if product.TrackLots {
+				if err := receiveProductBatch(tx, &product, item.Quantity, item.LotNumber, item.ExpiryDate, &newTransaction.ID); err != nil {
+					tx.Rollback()
+					return models.Transaction{}, err
+				}
+			}
+		}
+	}
+
+	// [BARU] Posting jurnal double-entry otomatis
+	if err := postTransactionJournal(tx, &newTransaction); err != nil {
+		tx.Rollback()
+		return models.Transaction{}, err
+	}
+
+	// [BARU] Catat poin yang ditukar & poin yang diperoleh pelanggan
+	if newTransaction.Type == models.Income && newTransaction.CustomerID != nil {
+		if err := recordTransactionLoyalty(tx, &newTransaction, loyaltyProgram); err != nil {
+			tx.Rollback()
+			return models.Transaction{}, err
+		}
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		return models.Transaction{}, errors.New("gagal meng-commit database transaction")
 	}
@@ -211,7 +494,7 @@ func (s *TransactionService) GetUserTransactions(userID uint, searchQuery string
 	db := database.DB
 
 	// [DIUBAH] Selalu Preload Items, Customer, dan Category
-	query := db.Preload("Items").Preload("Customer").Preload("Category").Where("transactions.user_id = ?", userID)
+	query := db.Preload("Items").Preload("Customer").Preload("Supplier").Preload("Category").Preload("Location").Preload("Refunds.Items").Preload("Payments").Preload("Promotions").Where("transactions.user_id = ?", userID)
 
 	if searchQuery != "" {
 		searchTerm := "%" + searchQuery + "%"
@@ -219,7 +502,8 @@ func (s *TransactionService) GetUserTransactions(userID uint, searchQuery string
 
 		query = query.Joins("LEFT JOIN transaction_items ON transaction_items.transaction_id = transactions.id").
 			Joins("LEFT JOIN customers ON customers.id = transactions.customer_id").
-			Where("transaction_items.product_name LIKE ? OR customers.name LIKE ? OR transactions.notes LIKE ?", searchTerm, searchTerm, searchTerm).
+			Joins("LEFT JOIN suppliers ON suppliers.id = transactions.supplier_id"). // [BARU]
+			Where("transaction_items.product_name LIKE ? OR customers.name LIKE ? OR suppliers.name LIKE ? OR transactions.notes LIKE ?", searchTerm, searchTerm, searchTerm, searchTerm).
 			Group("transactions.id")
 	}
 
@@ -237,7 +521,7 @@ func (s *TransactionService) GetTransactionByID(transactionID uint, userID uint)
 	db := database.DB
 
 	// [DIUBAH] Preload Items, Customer, dan Category
-	err := db.Preload("Items").Preload("Customer").Preload("Category").First(&transaction, transactionID).Error
+	err := db.Preload("Items").Preload("Customer").Preload("Supplier").Preload("Category").Preload("Location").Preload("Refunds.Items").Preload("Payments").Preload("Promotions").First(&transaction, transactionID).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return models.Transaction{}, errors.New("transaksi tidak ditemukan")
@@ -266,17 +550,608 @@ func (s *TransactionService) MarkTransactionPaid(transactionID uint, userID uint
 		return err // Mengembalikan error (cth: "transaksi tidak ditemukan" atau "akses ditolak")
 	}
 
-	// 2. Cek apakah sudah lunas
+	// 2. Cek apakah sudah lunas (atau sudah dibatalkan)
+	if tx.Status == models.StatusVoid {
+		return errors.New("transaksi ini sudah dibatalkan")
+	}
 	if tx.PaymentStatus == models.Lunas {
 		return errors.New("transaksi ini sudah lunas")
 	}
 
-	// 3. Update status menjadi LUNAS
-	// Kita gunakan Update() untuk mengubah satu kolom
-	if err := db.Model(&tx).Update("payment_status", models.Lunas).Error; err != nil {
+	// 3. [DIUBAH] Catat pembayaran sebesar sisa tagihan, status otomatis menjadi LUNAS
+	err = db.Transaction(func(dbTx *gorm.DB) error {
+		locked, err := lockOwnedTransaction(dbTx, transactionID, userID)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		if err := ensurePeriodOpen(dbTx, userID, now); err != nil {
+			return err
+		}
+		return recordPayment(dbTx, &locked, locked.OutstandingAmount(), now, models.MethodTunai, "Pelunasan")
+	})
+	if errors.Is(err, errPeriodClosed) {
+		return err
+	}
+	if err != nil {
 		log.Printf("Error updating payment status for tx %d: %v", transactionID, err)
 		return errors.New("gagal memperbarui status pembayaran")
 	}
 
 	return nil
 }
+
+// --- [BARU] FUNGSI UNTUK PEMBAYARAN CICILAN ---
+
+// amountEpsilon adalah toleransi pembulatan saat membandingkan nilai uang
+const amountEpsilon = 0.005
+
+// recordPayment mencatat satu pembayaran (negatif = uang dikembalikan), memperbarui PaidAmount,
+// dan menyesuaikan PaymentStatus berdasarkan sisa tagihan
+func recordPayment(tx *gorm.DB, transaction *models.Transaction, amount float64, paymentDate time.Time, method models.PaymentMethodType, notes string) error {
+	payment := models.Payment{
+		TransactionID: transaction.ID,
+		UserID:        transaction.UserID,
+		Amount:        amount,
+		PaymentDate:   paymentDate,
+		Method:        method,
+		Notes:         notes,
+	}
+	if err := tx.Create(&payment).Error; err != nil {
+		return errors.New("gagal menyimpan data pembayaran")
+	}
+	if err := postPaymentJournal(tx, transaction, &payment); err != nil {
+		return err
+	}
+
+	transaction.PaidAmount += amount
+	transaction.PaymentStatus = models.BelumLunas
+	if transaction.OutstandingAmount() <= amountEpsilon {
+		transaction.PaymentStatus = models.Lunas
+	}
+	return tx.Model(transaction).Updates(map[string]interface{}{
+		"paid_amount":    transaction.PaidAmount,
+		"payment_status": transaction.PaymentStatus,
+	}).Error
+}
+
+// AddPayment mencatat pembayaran cicilan atas transaksi yang belum lunas
+func (s *TransactionService) AddPayment(transactionID uint, userID uint, input dto.CreatePaymentInput) (models.Transaction, error) {
+	db := database.DB
+
+	paymentDate := time.Now()
+	if input.PaymentDate != nil && *input.PaymentDate != "" {
+		parsedDate, err := time.ParseInLocation("2006-01-02", *input.PaymentDate, time.Local)
+		if err != nil {
+			return models.Transaction{}, errors.New("format tanggal pembayaran tidak valid, gunakan YYYY-MM-DD")
+		}
+		paymentDate = parsedDate
+	}
+	method := input.Method
+	if method == "" {
+		method = models.MethodTunai
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		transaction, err := lockOwnedTransaction(tx, transactionID, userID)
+		if err != nil {
+			return err
+		}
+		if transaction.Status == models.StatusVoid {
+			return errors.New("transaksi ini sudah dibatalkan")
+		}
+		if transaction.PaymentStatus == models.Lunas {
+			return errors.New("transaksi ini sudah lunas")
+		}
+		// Tanggal pembayaran tidak boleh sebelum hari transaksi dibuat
+		txDay := time.Date(transaction.CreatedAt.Year(), transaction.CreatedAt.Month(), transaction.CreatedAt.Day(), 0, 0, 0, 0, paymentDate.Location())
+		if paymentDate.Before(txDay) {
+			return errors.New("tanggal pembayaran tidak boleh sebelum tanggal transaksi")
+		}
+
+		// [BARU] Pembayaran tidak boleh dicatat di periode yang sudah ditutup
+		if err := ensurePeriodOpen(tx, userID, paymentDate); err != nil {
+			return err
+		}
+
+		outstanding := transaction.OutstandingAmount()
+		if input.Amount > outstanding+amountEpsilon {
+			return fmt.Errorf("jumlah pembayaran melebihi sisa tagihan (sisa: %.2f)", outstanding)
+		}
+
+		return recordPayment(tx, &transaction, input.Amount, paymentDate, method, input.Notes)
+	})
+	if err != nil {
+		return models.Transaction{}, err
+	}
+
+	return s.GetTransactionByID(transactionID, userID)
+}
+
+// --- [BARU] FUNGSI UNTUK VOID & RETUR ---
+
+// lockOwnedTransaction mengambil transaksi (beserta item) dengan row lock dan memvalidasi kepemilikan
+func lockOwnedTransaction(tx *gorm.DB, transactionID uint, userID uint) (models.Transaction, error) {
+	var transaction models.Transaction
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Items").First(&transaction, transactionID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Transaction{}, errors.New("transaksi tidak ditemukan")
+		}
+		return models.Transaction{}, err
+	}
+	if transaction.UserID != userID {
+		return models.Transaction{}, errors.New("akses ditolak: Anda bukan pemilik transaksi ini")
+	}
+	return transaction, nil
+}
+
+// adjustProductStockAtCost mengubah stok produk sebesar delta (positif = masuk, negatif = keluar)
+// di locationID (nil = lokasi default). Stok tidak boleh menjadi negatif dan setiap perubahan dicatat
+// di kartu stok. Barang masuk/keluar dinilai pada unitCost (nil = biaya persediaan saat ini)
+// sesuai metode penilaian persediaan user. movedAt adalah tanggal barang masuk untuk lapisan FIFO.
+func adjustProductStockAtCost(tx *gorm.DB, productID uint, userID uint, locationID *uint, delta int, unitCost *float64, reason models.StockMovementReason, transactionID *uint, movedAt time.Time, notes string) error {
+	location, err := resolveLocation(tx, userID, locationID)
+	if err != nil {
+		return err
+	}
+	product, err := lockOwnedProduct(tx, productID, userID)
+	if err != nil {
+		return err
+	}
+	if _, err := moveProductStock(tx, &product, location, delta, unitCost, reason, transactionID, movedAt, notes); err != nil {
+		return err
+	}
+	// [BARU] Produk dengan pelacakan batch: sesuaikan sisa batch (FEFO)
+	return syncProductBatches(tx, &product, delta, transactionID)
+}
+
+// [BARU] reverseItemStock membalik perubahan stok untuk 'quantity' unit item transaksi (void/retur)
+// pada biaya saat transaksi dibuat. Item produk komposit mengembalikan stok bahan yang tercatat
+// saat penjualan, bukan resep yang berlaku sekarang. Barang yang kembali masuk memakai tanggal
+// transaksi asal sebagai urutan lapisan FIFO.
+func reverseItemStock(tx *gorm.DB, transaction *models.Transaction, item models.TransactionItem, quantity int, reason models.StockMovementReason, notes string) error {
+	var components []models.TransactionItemComponent
+	if err := tx.Where("transaction_item_id = ?", item.ID).Find(&components).Error; err != nil {
+		return err
+	}
+	if len(components) == 0 {
+		unitCost := itemInventoryCost(transaction.Type, item)
+		return adjustProductStockAtCost(tx, *item.ProductID, transaction.UserID, transaction.LocationID, stockReversalDelta(transaction.Type, quantity), &unitCost,
+			reason, &transaction.ID, transaction.CreatedAt, notes)
+	}
+	for _, component := range components {
+		unitCost := component.UnitCost
+		if err := adjustProductStockAtCost(tx, component.ComponentID, transaction.UserID, transaction.LocationID, stockReversalDelta(transaction.Type, quantity*component.Quantity), &unitCost,
+			reason, &transaction.ID, transaction.CreatedAt, notes+" (bahan "+item.ProductName+")"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// [BARU] sellProductStock mengurangi stok produk yang sudah di-lock karena terjual di locationID:
+// stok lokasi, biaya persediaan, batch (FEFO) dan stok total. Mengembalikan HPP per unit, kartu stok
+// (belum disimpan karena ID transaksi belum ada) dan pemakaian batch.
+func sellProductStock(tx *gorm.DB, product *models.Product, userID uint, locationID uint, quantity int, notes string) (float64, models.StockMovement, []models.BatchConsumption, error) {
+	if product.Stock < quantity {
+		return 0, models.StockMovement{}, nil, fmt.Errorf("stok tidak cukup untuk produk: %s (sisa: %d)", product.Name, product.Stock)
+	}
+	// Stok di lokasi penjualan juga harus cukup
+	if _, err := changeLocationStock(tx, product, locationID, -quantity); err != nil {
+		return 0, models.StockMovement{}, nil, err
+	}
+	// HPP mengikuti metode penilaian persediaan (rata-rata / FIFO)
+	unitCost, err := issueInventoryCost(tx, product, quantity, nil, nil)
+	if err != nil {
+		return 0, models.StockMovement{}, nil, err
+	}
+	// Produk dengan pelacakan batch: kurangi batch yang paling cepat kedaluwarsa
+	var consumptions []models.BatchConsumption
+	if product.TrackLots {
+		if consumptions, err = consumeProductBatches(tx, product, quantity, nil); err != nil {
+			return 0, models.StockMovement{}, nil, err
+		}
+	}
+	newStock := product.Stock - quantity
+	if err := tx.Model(product).Update("stock", newStock).Error; err != nil {
+		return 0, models.StockMovement{}, nil, fmt.Errorf("gagal memperbarui stok untuk produk ID %d", product.ID)
+	}
+	movement := newStockMovement(userID, product.ID, -quantity, newStock, models.MovementSale, nil, notes)
+	movement.LocationID = &locationID
+	return unitCost, movement, consumptions, nil
+}
+
+// [BARU] lockOwnedProduct mengambil produk dengan lock (FOR UPDATE) dan memvalidasi kepemilikan
+func lockOwnedProduct(tx *gorm.DB, productID uint, userID uint) (models.Product, error) {
+	var product models.Product
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&product, productID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Product{}, fmt.Errorf("produk ID %d tidak ditemukan", productID)
+		}
+		return models.Product{}, err
+	}
+	if product.UserID != userID {
+		return models.Product{}, fmt.Errorf("akses ditolak: produk ID %d bukan milik Anda", productID)
+	}
+	return product, nil
+}
+
+// [BARU] moveProductStock mengubah stok produk yang sudah di-lock, memperbarui biaya persediaan
+// dan mencatat kartu stok. Mengembalikan biaya per unit barang yang masuk/keluar.
+// [DIUBAH] Stok di locationID ikut berubah sebesar delta. movedAt adalah tanggal barang masuk/keluar
+// (tanggal transaksi), dipakai sebagai urutan lapisan FIFO barang masuk.
+func moveProductStock(tx *gorm.DB, product *models.Product, locationID uint, delta int, unitCost *float64, reason models.StockMovementReason, transactionID *uint, movedAt time.Time, notes string) (float64, error) {
+	newStock := product.Stock + delta
+	if newStock < 0 {
+		return 0, fmt.Errorf("stok tidak cukup untuk produk: %s (sisa: %d)", product.Name, product.Stock)
+	}
+	if _, err := changeLocationStock(tx, product, locationID, delta); err != nil {
+		return 0, err
+	}
+	// Perbarui biaya persediaan (rata-rata / lapisan FIFO) sebelum stok berubah
+	cost := product.PurchasePrice
+	if delta > 0 {
+		if unitCost != nil {
+			cost = *unitCost
+		}
+		if err := receiveInventoryCost(tx, product, delta, cost, transactionID, movedAt); err != nil {
+			return 0, err
+		}
+	} else if delta < 0 {
+		issued, err := issueInventoryCost(tx, product, -delta, transactionID, unitCost)
+		if err != nil {
+			return 0, err
+		}
+		cost = issued
+	}
+	if err := tx.Model(product).Update("stock", newStock).Error; err != nil {
+		return 0, fmt.Errorf("gagal memperbarui stok untuk produk ID %d", product.ID)
+	}
+	movement := newStockMovement(product.UserID, product.ID, delta, newStock, reason, transactionID, notes)
+	movement.LocationID = &locationID
+	if err := tx.Create(&movement).Error; err != nil {
+		return 0, errors.New("gagal mencatat kartu stok")
+	}
+	return cost, nil
+}
+
+// [BARU] newStockMovement membuat baris kartu stok untuk perubahan 'delta' yang menghasilkan saldo 'balanceAfter'
+func newStockMovement(userID uint, productID uint, delta int, balanceAfter int, reason models.StockMovementReason, transactionID *uint, notes string) models.StockMovement {
+	return models.StockMovement{
+		UserID:        userID,
+		ProductID:     productID,
+		Reason:        reason,
+		Quantity:      delta,
+		BalanceAfter:  balanceAfter,
+		TransactionID: transactionID,
+		Notes:         notes,
+	}
+}
+
+// stockReversalDelta menghitung perubahan stok untuk membalik 'quantity' unit sebuah item.
+// Penjualan (INCOME) yang dibatalkan mengembalikan stok, pembelian (EXPENSE) mengurangi stok.
+func stockReversalDelta(txType models.TransactionType, quantity int) int {
+	if txType == models.Income {
+		return quantity
+	}
+	return -quantity
+}
+
+// parseTransactionDate mengubah tanggal transaksi (YYYY-MM-DD, opsional) menjadi waktu transaksi.
+// Tanggal lampau dicatat dengan jam saat ini agar urutan di hari yang sama tetap wajar.
+func parseTransactionDate(value *string) (time.Time, error) {
+	now := time.Now()
+	if value == nil || *value == "" {
+		return now, nil
+	}
+	parsedDate, err := time.ParseInLocation("2006-01-02", *value, time.Local)
+	if err != nil {
+		return time.Time{}, errors.New("format tanggal transaksi tidak valid, gunakan YYYY-MM-DD")
+	}
+	if parsedDate.After(now) {
+		return time.Time{}, errors.New("tanggal transaksi tidak boleh di masa depan")
+	}
+	return time.Date(parsedDate.Year(), parsedDate.Month(), parsedDate.Day(),
+		now.Hour(), now.Minute(), now.Second(), now.Nanosecond(), time.Local), nil
+}
+
+// calculateDiscount menghitung nominal diskon dari 'base' (nilai sebelum diskon).
+// Diskon tidak boleh membuat nilai menjadi negatif.
+func calculateDiscount(discountType models.DiscountType, value float64, base float64) (float64, error) {
+	if discountType == "" || value <= 0 {
+		return 0, nil
+	}
+	switch discountType {
+	case models.DiscountPercent:
+		if value > 100 {
+			return 0, errors.New("diskon persen tidak boleh lebih dari 100")
+		}
+		return roundAmount(base * value / 100), nil
+	case models.DiscountNominal:
+		if value > base+amountEpsilon {
+			return 0, errors.New("diskon nominal melebihi nilai sebelum diskon")
+		}
+		return roundAmount(value), nil
+	}
+	return 0, errors.New("tipe diskon tidak valid")
+}
+
+// pricingResult adalah hasil perhitungan harga sebuah transaksi (dipakai saat simpan & pratinjau harga)
+type pricingResult struct {
+	GrossAmount     float64 // Total sebelum semua diskon
+	DiscountAmount  float64 // Diskon manual level transaksi
+	PromotionAmount float64 // Total potongan promo otomatis
+	LoyaltyAmount   float64 // [BARU] Potongan dari penukaran poin
+	TaxAmount       float64
+	TotalAmount     float64
+	Promotions      []models.TransactionPromotion
+}
+
+// priceTransactionItems menghitung promo, diskon transaksi, DPP & pajak untuk item yang sudah berisi
+// harga, tarif pajak, dan diskon item. Urutan: diskon item -> promo item -> diskon transaksi & promo
+// minimal belanja (dialokasikan proporsional) -> pajak per baris. Item diubah langsung (in-place).
+// [DIUBAH] loyaltyDiscount adalah nilai rupiah poin yang ditukar, dipotong setelah diskon transaksi & promo.
+func priceTransactionItems(tx *gorm.DB, userID uint, txType models.TransactionType, at time.Time, items []models.TransactionItem, productCategories map[uint]*uint, discountType models.DiscountType, discountValue float64, loyaltyDiscount float64) (pricingResult, error) {
+	var result pricingResult
+
+	// Promo otomatis hanya berlaku untuk penjualan
+	var promotions []models.Promotion
+	if txType == models.Income {
+		found, err := findActivePromotions(tx, userID, at)
+		if err != nil {
+			return result, err
+		}
+		promotions = found
+	}
+	applied := newAppliedPromotions()
+
+	// 1. Promo level item (dipilih yang paling menguntungkan pelanggan per baris)
+	lineNets := make([]float64, len(items))
+	var subtotal float64
+	for i := range items {
+		item := &items[i]
+		lineGross := item.LineGross() // [DIUBAH] Harga x jumlah dalam satuan transaksi
+		result.GrossAmount += lineGross
+
+		promo, amount := bestItemPromotion(promotions, *item, productCategories, lineGross-item.DiscountAmount)
+		if promo != nil {
+			item.PromotionDiscountAmount = amount
+			applied.add(*promo, amount)
+		}
+		lineNets[i] = lineGross - item.DiscountAmount - item.PromotionDiscountAmount
+		subtotal += lineNets[i]
+	}
+
+	// 2. Diskon level transaksi (manual) dan promo minimal belanja, dari subtotal setelah diskon item
+	manualDiscount, err := calculateDiscount(discountType, discountValue, subtotal)
+	if err != nil {
+		return result, fmt.Errorf("diskon transaksi: %v", err)
+	}
+	var orderPromotion float64
+	if promo, amount := bestOrderPromotion(promotions, subtotal); promo != nil {
+		// Total diskon transaksi tidak boleh melebihi subtotal
+		orderPromotion = roundAmount(math.Min(amount, subtotal-manualDiscount))
+		if orderPromotion > 0 {
+			applied.add(*promo, orderPromotion)
+		}
+	}
+	// [BARU] Penukaran poin tidak boleh melebihi sisa belanja setelah diskon & promo
+	if remaining := roundAmount(subtotal - manualDiscount - orderPromotion); loyaltyDiscount > remaining+amountEpsilon {
+		return result, fmt.Errorf("nilai penukaran poin (%.2f) melebihi total belanja (%.2f)", loyaltyDiscount, remaining)
+	}
+	allocated := allocateAmount(manualDiscount+orderPromotion+loyaltyDiscount, lineNets)
+
+	// 3. DPP & pajak dari nilai bersih setiap baris
+	for i := range items {
+		item := &items[i]
+		item.TransactionDiscountAmount = allocated[i]
+		item.TaxableAmount, item.TaxAmount = calculateItemTax(lineNets[i]-allocated[i], item.TaxRate, item.TaxInclusive)
+		result.TotalAmount += item.TaxableAmount + item.TaxAmount
+		result.TaxAmount += item.TaxAmount
+		result.PromotionAmount += item.PromotionDiscountAmount
+	}
+	result.DiscountAmount = manualDiscount
+	result.LoyaltyAmount = loyaltyDiscount
+	result.PromotionAmount = roundAmount(result.PromotionAmount + orderPromotion)
+	result.Promotions = applied.list
+	return result, nil
+}
+
+// allocateAmount membagi 'total' ke setiap baris secara proporsional terhadap 'weights'.
+// Pembulatan kumulatif memastikan jumlah hasil alokasi tepat sama dengan total.
+func allocateAmount(total float64, weights []float64) []float64 {
+	allocated := make([]float64, len(weights))
+	var weightSum float64
+	for _, w := range weights {
+		weightSum += w
+	}
+	if total == 0 || weightSum <= 0 {
+		return allocated
+	}
+	var cumulativeWeight, allocatedSoFar float64
+	for i, w := range weights {
+		cumulativeWeight += w
+		upTo := roundAmount(total * cumulativeWeight / weightSum)
+		allocated[i] = upTo - allocatedSoFar
+		allocatedSoFar = upTo
+	}
+	return allocated
+}
+
+// proportionalAmount menghitung porsi 'total' untuk 'quantity' unit berikutnya dari 'totalQuantity' unit,
+// setelah 'before' unit sebelumnya. Selisih dua pembulatan kumulatif memastikan jumlah seluruh porsi = total.
+func proportionalAmount(total float64, before int, quantity int, totalQuantity int) float64 {
+	if totalQuantity <= 0 {
+		return 0
+	}
+	upTo := roundAmount(total * float64(before+quantity) / float64(totalQuantity))
+	prior := roundAmount(total * float64(before) / float64(totalQuantity))
+	return upTo - prior
+}
+
+// VoidTransaction membatalkan seluruh transaksi dan mengembalikan stok yang belum diretur
+func (s *TransactionService) VoidTransaction(transactionID uint, userID uint, input dto.VoidTransactionInput) (models.Transaction, error) {
+	db := database.DB
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		transaction, err := lockOwnedTransaction(tx, transactionID, userID)
+		if err != nil {
+			return err
+		}
+		if transaction.Status == models.StatusVoid {
+			return errors.New("transaksi ini sudah dibatalkan")
+		}
+		// [BARU] Void mengubah angka periode asal transaksi, jadi periode itu harus masih terbuka
+		if err := ensurePeriodOpen(tx, userID, transaction.CreatedAt); err != nil {
+			return err
+		}
+
+		// [BARU] Tagihan PO dibatalkan: stok tetap (barang sudah diterima), PO kembali bisa ditagih
+		if transaction.PurchaseOrderID != nil {
+			if err := reopenBilledPurchaseOrder(tx, &transaction); err != nil {
+				return err
+			}
+		}
+
+		// Balik perubahan stok hanya untuk unit yang belum diretur
+		for _, item := range transaction.Items {
+			remaining := item.Quantity - item.RefundedQuantity
+			if item.ProductID == nil || remaining <= 0 || transaction.PurchaseOrderID != nil {
+				continue
+			}
+			if err := reverseItemStock(tx, &transaction, item, remaining, models.MovementVoid, "Void: "+input.Reason); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		if err := postVoidJournal(tx, &transaction, now); err != nil {
+			return err
+		}
+		// [BARU] Tarik poin yang diperoleh & kembalikan poin yang ditukar
+		if err := reverseTransactionLoyalty(tx, &transaction, now); err != nil {
+			return err
+		}
+		return tx.Model(&transaction).Updates(map[string]interface{}{
+			"status":      models.StatusVoid,
+			"void_reason": input.Reason,
+			"voided_at":   &now,
+		}).Error
+	})
+	if err != nil {
+		return models.Transaction{}, err
+	}
+
+	return s.GetTransactionByID(transactionID, userID)
+}
+
+// RefundTransaction meretur sebagian item transaksi dan mengembalikan stoknya
+func (s *TransactionService) RefundTransaction(transactionID uint, userID uint, input dto.RefundTransactionInput) (models.Transaction, error) {
+	db := database.DB
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		transaction, err := lockOwnedTransaction(tx, transactionID, userID)
+		if err != nil {
+			return err
+		}
+		if transaction.Status == models.StatusVoid {
+			return errors.New("transaksi ini sudah dibatalkan")
+		}
+		if transaction.Type == models.Capital {
+			return errors.New("transaksi 'Modal' tidak dapat diretur")
+		}
+		// [BARU] Retur mengurangi nilai bersih transaksi asal, jadi periodenya harus masih terbuka
+		if err := ensurePeriodOpen(tx, userID, transaction.CreatedAt); err != nil {
+			return err
+		}
+
+		itemsByID := make(map[uint]*models.TransactionItem)
+		for i := range transaction.Items {
+			itemsByID[transaction.Items[i].ID] = &transaction.Items[i]
+		}
+
+		refund := models.Refund{
+			TransactionID: transaction.ID,
+			UserID:        userID,
+			Reason:        input.Reason,
+		}
+
+		for _, itemInput := range input.Items {
+			item, ok := itemsByID[itemInput.TransactionItemID]
+			if !ok {
+				return fmt.Errorf("item ID %d bukan bagian dari transaksi ini", itemInput.TransactionItemID)
+			}
+			remaining := item.Quantity - item.RefundedQuantity
+			if itemInput.Quantity > remaining {
+				return fmt.Errorf("jumlah retur untuk %s melebihi sisa yang bisa diretur (sisa: %d)", item.ProductName, remaining)
+			}
+
+			if item.ProductID != nil {
+				if err := reverseItemStock(tx, &transaction, *item, itemInput.Quantity, models.MovementRefund, "Retur: "+input.Reason); err != nil {
+					return err
+				}
+			}
+
+			item.RefundedQuantity += itemInput.Quantity
+			if err := tx.Model(item).Update("refunded_quantity", item.RefundedQuantity).Error; err != nil {
+				return errors.New("gagal memperbarui jumlah retur item")
+			}
+
+			// [DIUBAH] Nilai retur proporsional terhadap nilai baris (DPP + pajak).
+			// Dihitung kumulatif agar total retur seluruh unit tepat sama dengan nilai baris.
+			refundedBefore := item.RefundedQuantity - itemInput.Quantity
+			itemTax := proportionalAmount(item.TaxAmount, refundedBefore, itemInput.Quantity, item.Quantity)
+			amount := proportionalAmount(item.TaxableAmount, refundedBefore, itemInput.Quantity, item.Quantity) + itemTax
+			refund.Amount += amount
+			refund.TaxAmount += itemTax
+			refund.Items = append(refund.Items, models.RefundItem{
+				TransactionItemID: item.ID,
+				Quantity:          itemInput.Quantity,
+				Amount:            amount,
+				TaxAmount:         itemTax,
+			})
+		}
+
+		// Retur lebih dulu mengurangi sisa utang/piutang yang belum dibayar.
+		// Hanya kelebihannya yang dikembalikan sebagai uang (dicatat sebagai pembayaran negatif).
+		outstanding := transaction.OutstandingAmount()
+		if outstanding < 0 {
+			outstanding = 0
+		}
+		if refund.Amount > outstanding {
+			refund.CashAmount = refund.Amount - outstanding
+		}
+
+		if err := tx.Create(&refund).Error; err != nil {
+			return errors.New("gagal menyimpan data retur")
+		}
+		if err := postRefundJournal(tx, &transaction, &refund, itemsByID); err != nil {
+			return err
+		}
+
+		transaction.Status = models.StatusRefunded
+		transaction.RefundedAmount += refund.Amount
+		transaction.RefundedTaxAmount += refund.TaxAmount // [BARU]
+		if err := tx.Model(&transaction).Updates(map[string]interface{}{
+			"status":              transaction.Status,
+			"refunded_amount":     transaction.RefundedAmount,
+			"refunded_tax_amount": transaction.RefundedTaxAmount,
+		}).Error; err != nil {
+			return err
+		}
+
+		if refund.CashAmount > 0 {
+			return recordPayment(tx, &transaction, -refund.CashAmount, refund.CreatedAt, models.MethodRetur, fmt.Sprintf("Pengembalian dana retur #%d", refund.ID))
+		}
+		if transaction.OutstandingAmount() <= amountEpsilon {
+			return tx.Model(&transaction).Update("payment_status", models.Lunas).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return models.Transaction{}, err
+	}
+
+	return s.GetTransactionByID(transactionID, userID)
+}
@@ -0,0 +1,231 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/danishyusrah/go_bisnis/internal/dto"
+	"github.com/danishyusrah/go_bisnis/internal/models"
+)
+
+func TestAllocateAmount(t *testing.T) {
+	tests := []struct {
+		name    string
+		total   float64
+		weights []float64
+		want    []float64
+	}{
+		{name: "bagi rata dengan pembulatan kumulatif", total: 100, weights: []float64{1, 1, 1}, want: []float64{33.33, 33.34, 33.33}},
+		{name: "proporsional terhadap bobot", total: 10000, weights: []float64{30000, 10000, 60000}, want: []float64{3000, 1000, 6000}},
+		{name: "bobot nol tidak mendapat bagian", total: 10, weights: []float64{3, 0, 7}, want: []float64{3, 0, 7}},
+		{name: "pembulatan sen", total: 0.05, weights: []float64{1, 1}, want: []float64{0.03, 0.02}},
+		{name: "total nol", total: 0, weights: []float64{1, 2}, want: []float64{0, 0}},
+		{name: "semua bobot nol", total: 50, weights: []float64{0, 0}, want: []float64{0, 0}},
+		{name: "tanpa baris", total: 50, weights: nil, want: []float64{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := allocateAmount(tt.total, tt.weights)
+			if len(got) != len(tt.want) {
+				t.Fatalf("len = %d, want %d", len(got), len(tt.want))
+			}
+			var sum float64
+			for i := range got {
+				assertAmount(t, "baris", got[i], tt.want[i])
+				sum += got[i]
+			}
+			// Jumlah alokasi harus selalu sama persis dengan total (kecuali tidak ada bobot)
+			var weightSum float64
+			for _, w := range tt.weights {
+				weightSum += w
+			}
+			if weightSum > 0 {
+				assertAmount(t, "jumlah alokasi", sum, tt.total)
+			}
+		})
+	}
+}
+
+func TestCalculateDiscount(t *testing.T) {
+	tests := []struct {
+		name         string
+		discountType models.DiscountType
+		value        float64
+		base         float64
+		want         float64
+		wantErr      bool
+	}{
+		{name: "tanpa tipe diskon", discountType: "", value: 10, base: 1000, want: 0},
+		{name: "nilai nol", discountType: models.DiscountPercent, value: 0, base: 1000, want: 0},
+		{name: "nilai negatif diabaikan", discountType: models.DiscountNominal, value: -500, base: 1000, want: 0},
+		{name: "persen", discountType: models.DiscountPercent, value: 10, base: 15000, want: 1500},
+		{name: "persen dibulatkan ke sen", discountType: models.DiscountPercent, value: 12.5, base: 999, want: 124.88},
+		{name: "persen 100", discountType: models.DiscountPercent, value: 100, base: 2500, want: 2500},
+		{name: "persen lebih dari 100", discountType: models.DiscountPercent, value: 101, base: 2500, wantErr: true},
+		{name: "nominal", discountType: models.DiscountNominal, value: 5000, base: 20000, want: 5000},
+		{name: "nominal sama dengan dasar", discountType: models.DiscountNominal, value: 20000, base: 20000, want: 20000},
+		{name: "nominal melebihi dasar", discountType: models.DiscountNominal, value: 25000, base: 20000, wantErr: true},
+		{name: "tipe tidak valid", discountType: "BONUS", value: 10, base: 1000, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := calculateDiscount(tt.discountType, tt.value, tt.base)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("calculateDiscount() = %.2f, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("calculateDiscount() error = %v", err)
+			}
+			assertAmount(t, "diskon", got, tt.want)
+		})
+	}
+}
+
+func TestRefundAndVoidReverseStockAndJournal(t *testing.T) {
+	db, userID := useServiceTestDB(t)
+	products := NewProductService()
+	transactions := NewTransactionService()
+
+	gula, err := products.CreateProduct(dto.CreateProductInput{Name: "Gula", SKU: "GULA", PurchasePrice: 1000, SellingPrice: 1500, Stock: 10}, userID)
+	if err != nil {
+		t.Fatalf("CreateProduct() error = %v", err)
+	}
+	location, err := defaultLocation(db, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Jual 4, lalu retur 1: stok kembali, pendapatan & HPP berkurang, uang dikembalikan karena sudah lunas
+	sale, err := transactions.CreateTransaction(dto.CreateTransactionInput{
+		Type:  models.Income,
+		Items: []dto.CreateTransactionItemInput{{ProductID: &gula.ID, ProductName: "Gula", Quantity: 4, UnitPrice: 1500}},
+	}, userID)
+	if err != nil {
+		t.Fatalf("CreateTransaction() error = %v", err)
+	}
+	assertStock(t, db, gula.ID, location.ID, 6, 6)
+
+	sale, err = transactions.RefundTransaction(sale.ID, userID, dto.RefundTransactionInput{
+		Items:  []dto.RefundItemInput{{TransactionItemID: sale.Items[0].ID, Quantity: 1}},
+		Reason: "Kemasan sobek",
+	})
+	if err != nil {
+		t.Fatalf("RefundTransaction() error = %v", err)
+	}
+	assertStock(t, db, gula.ID, location.ID, 7, 7)
+	if sale.Status != models.StatusRefunded {
+		t.Errorf("status = %s, want %s", sale.Status, models.StatusRefunded)
+	}
+	assertAmount(t, "nilai retur", sale.RefundedAmount, 1500)
+
+	balances := accountBalances(t, db, userID)
+	assertAmount(t, "kas setelah retur", balances[models.AccountCodeCash], 4500)
+	assertAmount(t, "pendapatan setelah retur", balances[models.AccountCodeSales], -4500)
+	assertAmount(t, "HPP setelah retur", balances[models.AccountCodeCOGS], 3000)
+	assertAmount(t, "persediaan setelah retur", balances[models.AccountCodeInventory], 7000)
+
+	// Retur melebihi sisa item ditolak
+	if _, err := transactions.RefundTransaction(sale.ID, userID, dto.RefundTransactionInput{
+		Items: []dto.RefundItemInput{{TransactionItemID: sale.Items[0].ID, Quantity: 4}},
+	}); err == nil || !strings.Contains(err.Error(), "melebihi sisa") {
+		t.Fatalf("RefundTransaction() error = %v, want jumlah retur melebihi sisa", err)
+	}
+
+	// Void hanya mengembalikan 3 unit yang belum diretur dan membalik seluruh jurnal transaksi
+	sale, err = transactions.VoidTransaction(sale.ID, userID, dto.VoidTransactionInput{Reason: "Salah input"})
+	if err != nil {
+		t.Fatalf("VoidTransaction() error = %v", err)
+	}
+	if sale.Status != models.StatusVoid {
+		t.Errorf("status = %s, want %s", sale.Status, models.StatusVoid)
+	}
+	assertStock(t, db, gula.ID, location.ID, 10, 10)
+
+	balances = accountBalances(t, db, userID)
+	for _, code := range []string{models.AccountCodeCash, models.AccountCodeSales, models.AccountCodeCOGS} {
+		assertAmount(t, "saldo akun "+code+" setelah void", balances[code], 0)
+	}
+	assertAmount(t, "persediaan setelah void", balances[models.AccountCodeInventory], 10000)
+
+	var movements []models.StockMovement
+	if err := db.Where("product_id = ?", gula.ID).Order("id asc").Find(&movements).Error; err != nil {
+		t.Fatal(err)
+	}
+	wantReasons := []models.StockMovementReason{models.MovementAdjustment, models.MovementSale, models.MovementRefund, models.MovementVoid}
+	if len(movements) != len(wantReasons) {
+		t.Fatalf("jumlah kartu stok = %d, want %d", len(movements), len(wantReasons))
+	}
+	for i, movement := range movements {
+		if movement.Reason != wantReasons[i] {
+			t.Errorf("kartu stok %d = %s, want %s", i, movement.Reason, wantReasons[i])
+		}
+	}
+
+	// Transaksi yang sudah dibatalkan tidak bisa dibatalkan atau diretur lagi
+	if _, err := transactions.VoidTransaction(sale.ID, userID, dto.VoidTransactionInput{Reason: "Lagi"}); err == nil {
+		t.Error("VoidTransaction() kedua berhasil, want error")
+	}
+	if _, err := transactions.RefundTransaction(sale.ID, userID, dto.RefundTransactionInput{
+		Items: []dto.RefundItemInput{{TransactionItemID: sale.Items[0].ID, Quantity: 1}},
+	}); err == nil {
+		t.Error("RefundTransaction() setelah void berhasil, want error")
+	}
+}
+
+func TestVoidPurchaseRemovesRestockedStock(t *testing.T) {
+	db, userID := useServiceTestDB(t)
+	products := NewProductService()
+	transactions := NewTransactionService()
+
+	kopi, err := products.CreateProduct(dto.CreateProductInput{Name: "Kopi", SKU: "KOPI", PurchasePrice: 2000, SellingPrice: 3000, Stock: 2}, userID)
+	if err != nil {
+		t.Fatalf("CreateProduct() error = %v", err)
+	}
+	location, err := defaultLocation(db, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	purchase, err := transactions.CreateTransaction(dto.CreateTransactionInput{
+		Type:  models.Expense,
+		Items: []dto.CreateTransactionItemInput{{ProductID: &kopi.ID, ProductName: "Kopi", Quantity: 6, UnitPrice: 2400}},
+	}, userID)
+	if err != nil {
+		t.Fatalf("CreateTransaction() error = %v", err)
+	}
+	assertStock(t, db, kopi.ID, location.ID, 8, 8)
+
+	// Setelah 5 unit terjual, void pembelian 6 unit akan membuat stok negatif
+	if _, err := transactions.CreateTransaction(dto.CreateTransactionInput{
+		Type:  models.Income,
+		Items: []dto.CreateTransactionItemInput{{ProductID: &kopi.ID, ProductName: "Kopi", Quantity: 5, UnitPrice: 3000}},
+	}, userID); err != nil {
+		t.Fatalf("CreateTransaction() penjualan error = %v", err)
+	}
+	if _, err := transactions.VoidTransaction(purchase.ID, userID, dto.VoidTransactionInput{Reason: "Batal"}); err == nil || !strings.Contains(err.Error(), "stok tidak cukup") {
+		t.Fatalf("VoidTransaction() error = %v, want stok tidak cukup", err)
+	}
+	assertStock(t, db, kopi.ID, location.ID, 3, 3)
+
+	// Setelah penjualannya dibatalkan, pembelian bisa dibatalkan dan kas kembali ke saldo awal
+	var sale models.Transaction
+	if err := db.Where("user_id = ? AND type = ?", userID, models.Income).First(&sale).Error; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := transactions.VoidTransaction(sale.ID, userID, dto.VoidTransactionInput{Reason: "Batal"}); err != nil {
+		t.Fatalf("VoidTransaction() penjualan error = %v", err)
+	}
+	if _, err := transactions.VoidTransaction(purchase.ID, userID, dto.VoidTransactionInput{Reason: "Batal"}); err != nil {
+		t.Fatalf("VoidTransaction() pembelian error = %v", err)
+	}
+	assertStock(t, db, kopi.ID, location.ID, 2, 2)
+
+	balances := accountBalances(t, db, userID)
+	assertAmount(t, "kas", balances[models.AccountCodeCash], 0)
+	assertAmount(t, "persediaan", balances[models.AccountCodeInventory], 4000)
+}
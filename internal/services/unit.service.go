@@ -0,0 +1,143 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/danishyusrah/go_bisnis/internal/database"
+	"github.com/danishyusrah/go_bisnis/internal/dto"
+	"github.com/danishyusrah/go_bisnis/internal/models"
+	"gorm.io/gorm"
+)
+
+// --- [BARU] Satuan produk & konversi ke satuan dasar ---
+
+// defaultBaseUnit adalah satuan dasar produk yang tidak menyebutkan satuannya
+const defaultBaseUnit = "pcs"
+
+// quantityEpsilon adalah toleransi pembulatan saat mengonversi jumlah desimal ke satuan dasar
+const quantityEpsilon = 0.000001
+
+// itemUnit adalah hasil konversi jumlah item transaksi ke satuan dasar
+type itemUnit struct {
+	Name         string  // Satuan transaksi (kosong = satuan dasar)
+	Factor       int     // Jumlah satuan dasar per 1 satuan transaksi
+	Quantity     float64 // Jumlah dalam satuan transaksi
+	BaseQuantity int     // Jumlah dalam satuan dasar (yang mengubah stok)
+}
+
+// formatQuantity menampilkan jumlah tanpa nol desimal yang tidak perlu (cth: 1.5, 24)
+func formatQuantity(quantity float64) string {
+	return strconv.FormatFloat(quantity, 'f', -1, 64)
+}
+
+// resolveItemUnit mengonversi jumlah item dalam satuan 'unitName' ke satuan dasar produk.
+// Item tanpa produk (biaya lain-lain) tidak punya konversi dan jumlahnya harus bilangan bulat.
+func resolveItemUnit(db *gorm.DB, product *models.Product, itemName string, unitName string, quantity float64) (itemUnit, error) {
+	unitName = strings.TrimSpace(unitName)
+	unit := itemUnit{Name: unitName, Factor: 1, Quantity: quantity}
+	allowDecimal := false
+
+	if product != nil && unitName != "" && !strings.EqualFold(unitName, product.BaseUnit) {
+		var productUnit models.ProductUnit
+		if err := db.Where("product_id = ? AND name = ?", product.ID, unitName).First(&productUnit).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return itemUnit{}, fmt.Errorf("satuan %s tidak tersedia untuk produk %s", unitName, product.Name)
+			}
+			return itemUnit{}, err
+		}
+		unit.Name = productUnit.Name
+		unit.Factor = productUnit.Factor
+		allowDecimal = productUnit.AllowDecimal
+	} else if product != nil {
+		unit.Name = product.BaseUnit
+	}
+
+	if !allowDecimal && math.Abs(quantity-math.Round(quantity)) > quantityEpsilon {
+		label := unit.Name
+		if label == "" {
+			label = itemName
+		}
+		return itemUnit{}, fmt.Errorf("jumlah untuk %s harus bilangan bulat", label)
+	}
+	base := quantity * float64(unit.Factor)
+	unit.BaseQuantity = int(math.Round(base))
+	if math.Abs(base-float64(unit.BaseQuantity)) > quantityEpsilon || unit.BaseQuantity < 1 {
+		if product == nil {
+			return itemUnit{}, fmt.Errorf("jumlah untuk %s minimal 1", itemName)
+		}
+		return itemUnit{}, fmt.Errorf("jumlah %s %s pada %s tidak menghasilkan jumlah utuh dalam satuan dasar %s",
+			formatQuantity(quantity), unit.Name, itemName, product.BaseUnit)
+	}
+	return unit, nil
+}
+
+// UnitService adalah struct untuk layanan satuan produk
+type UnitService struct{}
+
+// NewUnitService membuat instance UnitService baru
+func NewUnitService() *UnitService {
+	return &UnitService{}
+}
+
+// GetProductUnits mengambil satuan alternatif sebuah produk
+func (s *UnitService) GetProductUnits(productID uint, userID uint) ([]models.ProductUnit, error) {
+	product, err := NewProductService().GetProductByID(productID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var units []models.ProductUnit
+	if err := database.DB.Where("product_id = ?", product.ID).Order("factor asc").Find(&units).Error; err != nil {
+		return nil, err
+	}
+	return units, nil
+}
+
+// SetProductUnits mengganti seluruh satuan alternatif produk. Transaksi lama tidak terpengaruh
+// karena setiap item menyimpan satuan & faktor konversinya sendiri.
+func (s *UnitService) SetProductUnits(productID uint, userID uint, input dto.SetProductUnitsInput) ([]models.ProductUnit, error) {
+	product, err := NewProductService().GetProductByID(productID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	units := []models.ProductUnit{}
+	seen := make(map[string]bool)
+	for _, unitInput := range input.Units {
+		name := strings.TrimSpace(unitInput.Name)
+		key := strings.ToLower(name)
+		if strings.EqualFold(name, product.BaseUnit) {
+			return nil, fmt.Errorf("satuan %s sudah menjadi satuan dasar produk", name)
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("satuan %s tercantum lebih dari sekali", name)
+		}
+		seen[key] = true
+		units = append(units, models.ProductUnit{
+			ProductID:    product.ID,
+			Name:         name,
+			Factor:       unitInput.Factor,
+			AllowDecimal: unitInput.AllowDecimal,
+			SellingPrice: unitInput.SellingPrice,
+		})
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// Hapus permanen agar nama satuan bisa dipakai ulang (unique per produk)
+		if err := tx.Unscoped().Where("product_id = ?", product.ID).Delete(&models.ProductUnit{}).Error; err != nil {
+			return err
+		}
+		if len(units) == 0 {
+			return nil
+		}
+		return tx.Create(&units).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetProductUnits(productID, userID)
+}
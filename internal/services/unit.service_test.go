@@ -0,0 +1,63 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/danishyusrah/go_bisnis/internal/models"
+)
+
+func TestResolveItemUnit(t *testing.T) {
+	db := newTestDB(t, &models.Product{}, &models.ProductUnit{})
+	product := models.Product{UserID: 1, Name: "Kopi", BaseUnit: "pcs"}
+	if err := db.Create(&product).Error; err != nil {
+		t.Fatal(err)
+	}
+	units := []models.ProductUnit{
+		{ProductID: product.ID, Name: "dus", Factor: 24},
+		{ProductID: product.ID, Name: "lusin", Factor: 12, AllowDecimal: true},
+	}
+	if err := db.Create(&units).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		product  *models.Product
+		itemName string
+		unitName string
+		quantity float64
+		want     itemUnit
+		wantErr  string
+	}{
+		{name: "item non-produk", itemName: "Ongkir", quantity: 2, want: itemUnit{Factor: 1, Quantity: 2, BaseQuantity: 2}},
+		{name: "item non-produk harus bilangan bulat", itemName: "Ongkir", quantity: 1.5, wantErr: "harus bilangan bulat"},
+		{name: "item non-produk minimal 1", itemName: "Ongkir", quantity: 0, wantErr: "minimal 1"},
+		{name: "tanpa satuan memakai satuan dasar", product: &product, itemName: "Kopi", quantity: 3, want: itemUnit{Name: "pcs", Factor: 1, Quantity: 3, BaseQuantity: 3}},
+		{name: "satuan dasar tidak peka huruf besar", product: &product, itemName: "Kopi", unitName: " PCS ", quantity: 5, want: itemUnit{Name: "pcs", Factor: 1, Quantity: 5, BaseQuantity: 5}},
+		{name: "satuan dasar tidak boleh desimal", product: &product, itemName: "Kopi", quantity: 2.5, wantErr: "harus bilangan bulat"},
+		{name: "konversi satuan besar", product: &product, itemName: "Kopi", unitName: "dus", quantity: 2, want: itemUnit{Name: "dus", Factor: 24, Quantity: 2, BaseQuantity: 48}},
+		{name: "satuan tanpa desimal", product: &product, itemName: "Kopi", unitName: "dus", quantity: 1.5, wantErr: "harus bilangan bulat"},
+		{name: "satuan desimal menjadi jumlah utuh", product: &product, itemName: "Kopi", unitName: "lusin", quantity: 0.25, want: itemUnit{Name: "lusin", Factor: 12, Quantity: 0.25, BaseQuantity: 3}},
+		{name: "satuan desimal tidak menjadi jumlah utuh", product: &product, itemName: "Kopi", unitName: "lusin", quantity: 0.1, wantErr: "tidak menghasilkan jumlah utuh"},
+		{name: "satuan tidak terdaftar", product: &product, itemName: "Kopi", unitName: "karton", quantity: 1, wantErr: "tidak tersedia"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveItemUnit(db, tt.product, tt.itemName, tt.unitName, tt.quantity)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("resolveItemUnit() error = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveItemUnit() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("resolveItemUnit() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Ukuran halaman A4 potret dalam satuan point (1/72 inci)
+const (
+	PDFPageWidth  = 595.0
+	PDFPageHeight = 842.0
+)
+
+// Font standar PDF yang tersedia tanpa perlu menyematkan file font
+const (
+	PDFFontRegular = "F1" // Helvetica
+	PDFFontBold    = "F2" // Helvetica-Bold
+	PDFFontMono    = "F3" // Courier, lebar tiap karakter 0.6 x ukuran font
+)
+
+// PDFDocument adalah generator PDF minimal untuk dokumen teks sederhana (laporan, surat tagihan).
+// Koordinat dihitung dari pojok kiri atas halaman agar mudah dipakai baris demi baris.
+type PDFDocument struct {
+	pages   []*bytes.Buffer
+	current *bytes.Buffer
+}
+
+// NewPDFDocument membuat dokumen PDF kosong (belum ada halaman)
+func NewPDFDocument() *PDFDocument {
+	return &PDFDocument{}
+}
+
+// AddPage menambah halaman baru dan menjadikannya halaman aktif
+func (d *PDFDocument) AddPage() {
+	d.current = &bytes.Buffer{}
+	d.pages = append(d.pages, d.current)
+}
+
+// PageCount mengembalikan jumlah halaman
+func (d *PDFDocument) PageCount() int {
+	return len(d.pages)
+}
+
+// Text menulis teks dengan garis dasar (baseline) pada posisi (x, y)
+func (d *PDFDocument) Text(x, y float64, font string, size float64, text string) {
+	if d.current == nil {
+		d.AddPage()
+	}
+	fmt.Fprintf(d.current, "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, PDFPageHeight-y, pdfEscape(text))
+}
+
+// TextRight menulis teks rata kanan yang berakhir di xRight.
+// Lebar hanya akurat untuk PDFFontMono; font lain memakai perkiraan rata-rata lebar karakter.
+func (d *PDFDocument) TextRight(xRight, y float64, font string, size float64, text string) {
+	d.Text(xRight-PDFTextWidth(font, size, text), y, font, size, text)
+}
+
+// Line menggambar garis lurus tipis dari (x1, y1) ke (x2, y2)
+func (d *PDFDocument) Line(x1, y1, x2, y2 float64) {
+	if d.current == nil {
+		d.AddPage()
+	}
+	fmt.Fprintf(d.current, "0.5 w %.2f %.2f m %.2f %.2f l S\n", x1, PDFPageHeight-y1, x2, PDFPageHeight-y2)
+}
+
+// PDFTextWidth memperkirakan lebar teks dalam point
+func PDFTextWidth(font string, size float64, text string) float64 {
+	perChar := 0.5 // Perkiraan rata-rata Helvetica
+	if font == PDFFontMono {
+		perChar = 0.6
+	}
+	return float64(len([]rune(text))) * perChar * size
+}
+
+// Bytes menyusun seluruh dokumen menjadi file PDF 1.4
+func (d *PDFDocument) Bytes() []byte {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+
+	var out bytes.Buffer
+	var offsets []int
+	writeObject := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Objek 1-5: katalog, daftar halaman, dan tiga font standar.
+	// Halaman ke-i memakai objek 6+2i (halaman) dan 7+2i (isi halaman).
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 6+2*i)
+	}
+	writeObject("<< /Type /Catalog /Pages 2 0 R >>")
+	writeObject(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	for _, name := range []string{"Helvetica", "Helvetica-Bold", "Courier"} {
+		writeObject(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", name))
+	}
+	for i, page := range d.pages {
+		writeObject(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] "+
+			"/Resources << /Font << /F1 3 0 R /F2 4 0 R /F3 5 0 R >> >> /Contents %d 0 R >>",
+			PDFPageWidth, PDFPageHeight, 7+2*i))
+		writeObject(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.Len(), page.String()))
+	}
+
+	xrefOffset := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xrefOffset)
+	return out.Bytes()
+}
+
+// pdfEscape mengubah teks menjadi string literal PDF (WinAnsi).
+// Karakter di luar Latin-1 diganti '?' karena font standar tidak memilikinya.
+func pdfEscape(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 32 && r < 127:
+			b.WriteRune(r)
+		case r >= 160 && r <= 255:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
@@ -42,6 +42,23 @@ document.addEventListener("DOMContentLoaded", () => {
         return response.json();
     };
 
+    /**
+     * [BARU] Memuat kategori pemasukan ke dropdown kategori produk
+     */
+    const loadCategoryOptions = async (selectedId) => {
+        const categorySelect = document.getElementById("category_id");
+        const categories = (await fetchWithAuth("/api/v1/categories")) || [];
+        categories.filter(cat => cat.type === "INCOME").forEach(cat => {
+            const option = document.createElement("option");
+            option.value = cat.id;
+            option.textContent = cat.name;
+            if (selectedId && cat.id === selectedId) option.selected = true;
+            categorySelect.appendChild(option);
+        });
+    };
+
+    loadCategoryOptions().catch(err => console.error("Gagal memuat kategori:", err));
+
     // --- 3. Event Listener untuk Submit Form ---
 
     addProductForm.addEventListener("submit", async (event) => {
@@ -68,6 +85,9 @@ document.addEventListener("DOMContentLoaded", () => {
                 // --- [BARU] ---
                 batas_stok_minimum: parseInt(formData.get("batas_stok_minimum"), 10) || 0,
                 // --- [AKHIR BARU] ---
+                tax_exempt: formData.get("tax_exempt") === "on", // [BARU]
+                track_lots: formData.get("track_lots") === "on", // [BARU]
+                category_id: formData.get("category_id") ? parseInt(formData.get("category_id"), 10) : null, // [BARU]
             };
 
             // Validasi frontend sederhana
@@ -15,6 +15,7 @@ document.addEventListener("DOMContentLoaded", () => {
     // Variabel global untuk menyimpan daftar
     let userProducts = [];
     let userCustomers = [];
+    let userSuppliers = []; // [BARU] Pihak lawan untuk Pengeluaran
     let userCategories = []; // <-- [BARU] Untuk Kategori
 
     // Ambil elemen-elemen form
@@ -37,6 +38,10 @@ document.addEventListener("DOMContentLoaded", () => {
     const categorySelectEl = document.getElementById("category_id");
     // --- [AKHIR BARU] ---
 
+    // [BARU] Elemen Lokasi
+    const locationGroup = document.getElementById("location-group");
+    const locationSelectEl = document.getElementById("location_id");
+
     // --- [BARU] Ambil elemen Utang/Piutang ---
     const paymentStatusGroup = document.getElementById("payment-status-group");
     const paymentStatusSelect = document.getElementById("payment_status");
@@ -103,7 +108,7 @@ document.addEventListener("DOMContentLoaded", () => {
      */
     const loadProducts = async () => {
         try {
-            userProducts = (await fetchWithAuth("/api/v1/products")) || [];
+            userProducts = (await fetchWithAuth("/api/v1/products?sellable=true")) || []; // [DIUBAH] Varian dijual per varian, produk induk tidak ditampilkan
             // [DIHAPUS] updateProductDropdowns(); // Pindah ke initializePage
         } catch (error) {
             console.error("Gagal memuat produk:", error);
@@ -122,6 +127,36 @@ document.addEventListener("DOMContentLoaded", () => {
         }
     };
 
+    /**
+     * [BARU] Memuat supplier milik user dari API
+     */
+    const loadSuppliers = async () => {
+        try {
+            userSuppliers = (await fetchWithAuth("/api/v1/suppliers")) || [];
+        } catch (error) {
+            console.error("Gagal memuat supplier:", error);
+        }
+    };
+
+    /**
+     * [BARU] Memuat lokasi milik user dari API
+     */
+    const loadLocations = async () => {
+        try {
+            const locations = (await fetchWithAuth("/api/v1/locations")) || [];
+            locationSelectEl.innerHTML = "";
+            locations.forEach((location) => {
+                const option = document.createElement("option");
+                option.value = location.id;
+                option.textContent = location.name;
+                option.selected = location.is_default;
+                locationSelectEl.appendChild(option);
+            });
+        } catch (error) {
+            console.error("Gagal memuat lokasi:", error);
+        }
+    };
+
     /**
      * [BARU] Memuat kategori milik user dari API
      */
@@ -160,15 +195,20 @@ document.addEventListener("DOMContentLoaded", () => {
     };
 
     /**
-     * Mengisi <select> pelanggan
+     * [DIUBAH] Mengisi <select> pelanggan (Pemasukan) atau supplier (Pengeluaran)
      */
-    const updateCustomerDropdown = () => {
+    const updateCustomerDropdown = (isExpense = false) => {
         if (!customerSelectEl) return;
         
-        const selectedValue = customerSelectEl.value;
-        customerSelectEl.innerHTML = `<option value="">-- Umum (Tanpa Pelanggan) --</option>`;
+        // ID pelanggan & supplier berbeda, jadi pilihan lama hanya dipertahankan jika jenisnya sama
+        const mode = isExpense ? "supplier" : "customer";
+        const selectedValue = customerSelectEl.dataset.mode === mode ? customerSelectEl.value : "";
+        customerSelectEl.dataset.mode = mode;
+        customerSelectEl.innerHTML = isExpense
+            ? `<option value="">-- Tanpa Supplier --</option>`
+            : `<option value="">-- Umum (Tanpa Pelanggan) --</option>`;
         
-        (userCustomers || []).forEach(customer => {
+        ((isExpense ? userSuppliers : userCustomers) || []).forEach(customer => {
             const option = document.createElement("option");
             option.value = customer.id;
             let customerText = customer.name;
@@ -227,6 +267,7 @@ document.addEventListener("DOMContentLoaded", () => {
             paymentStatusGroup.classList.add("hidden"); // [BARU]
             dueDateGroup.classList.add("hidden");       // [BARU]
             categoryGroup.classList.add("hidden");      // [BARU]
+            locationGroup.classList.add("hidden");      // [BARU]
 
             capitalAmountGroup.classList.remove("hidden");
             notesLabel.textContent = "Catatan (Cth: Modal awal buka warung)";
@@ -239,12 +280,13 @@ document.addEventListener("DOMContentLoaded", () => {
             itemTotalGroup.classList.remove("hidden");
             paymentStatusGroup.classList.remove("hidden"); // [BARU]
             categoryGroup.classList.remove("hidden");      // [BARU]
+            locationGroup.classList.remove("hidden");      // [BARU]
             
             capitalAmountGroup.classList.add("hidden");
             capitalAmountInput.value = 0; // Reset nilai modal
             
             // [BARU] Perbarui dropdown pelanggan & kategori berdasarkan data yang sudah di-load
-            updateCustomerDropdown(); 
+            updateCustomerDropdown(isExpense); 
             updateCategoryDropdown(isIncome ? "INCOME" : "EXPENSE");
 
             // [BARU] Tampilkan/sembunyikan Tgl Jatuh Tempo
@@ -309,10 +351,11 @@ document.addEventListener("DOMContentLoaded", () => {
                     <option value="other">Lainnya (Nama Kustom)</option> 
                 </select>
                 <input type="text" name="product_name" class="product-name-custom mt-1 block w-full text-sm py-2 px-2 bg-gray-50 border border-gray-300 rounded-lg focus:outline-none focus:ring-indigo-500 focus:border-indigo-500 hidden" placeholder="Nama Item Kustom">
+                <select name="unit" class="unit-select mt-1 block w-full text-sm py-2 px-2 bg-gray-50 border border-gray-300 rounded-lg focus:outline-none focus:ring-indigo-500 focus:border-indigo-500 hidden"></select>
             </div>
             <div class="col-span-2">
                 <label class="block text-xs font-medium text-gray-700">Jml</label>
-                <input type="number" name="quantity" value="1" min="1" class="item-calc mt-1 block w-full text-sm py-2 px-2 bg-gray-50 border border-gray-300 rounded-lg focus:outline-none focus:ring-indigo-500 focus:border-indigo-500" placeholder="Jml">
+                <input type="number" name="quantity" value="1" min="0" step="1" class="item-calc mt-1 block w-full text-sm py-2 px-2 bg-gray-50 border border-gray-300 rounded-lg focus:outline-none focus:ring-indigo-500 focus:border-indigo-500" placeholder="Jml">
             </div>
             <div class="col-span-3">
                 <label class="block text-xs font-medium text-gray-700">Harga Satuan</label>
@@ -365,6 +408,49 @@ document.addEventListener("DOMContentLoaded", () => {
             unitPriceInput.value = selectedOption.dataset.price || 0;
             customNameInput.value = selectedOption.textContent.split(' (Stok:')[0];
         }
+        // [BARU] Pilihan satuan produk
+        const product = (userProducts || []).find(p => String(p.id) === select.value);
+        updateUnitSelect(row, product);
+        row.querySelector('input[name="quantity"]').step = "1";
+        calculateTotal();
+    };
+
+    /**
+     * [BARU] Mengisi pilihan satuan sesuai produk yang dipilih (satuan dasar + satuan alternatif)
+     */
+    const updateUnitSelect = (row, product) => {
+        const unitSelect = row.querySelector(".unit-select");
+        unitSelect.innerHTML = "";
+        if (!product || !product.units || product.units.length === 0) {
+            unitSelect.classList.add("hidden");
+            return;
+        }
+        const baseOption = document.createElement("option");
+        baseOption.value = product.base_unit;
+        baseOption.textContent = product.base_unit;
+        baseOption.dataset.price = product.selling_price;
+        baseOption.dataset.decimal = "false";
+        unitSelect.appendChild(baseOption);
+        product.units.forEach(unit => {
+            const option = document.createElement("option");
+            option.value = unit.name;
+            option.textContent = `${unit.name} (${unit.factor} ${product.base_unit})`;
+            option.dataset.price = unit.selling_price || product.selling_price * unit.factor;
+            option.dataset.decimal = unit.allow_decimal ? "true" : "false";
+            unitSelect.appendChild(option);
+        });
+        unitSelect.classList.remove("hidden");
+    };
+
+    /**
+     * [BARU] Harga & langkah jumlah mengikuti satuan yang dipilih
+     */
+    const handleUnitSelectChange = (select) => {
+        const row = select.closest(".item-row");
+        const selectedOption = select.options[select.selectedIndex];
+        if (!selectedOption) return;
+        row.querySelector('input[name="unit_price"]').value = selectedOption.dataset.price || 0;
+        row.querySelector('input[name="quantity"]').step = selectedOption.dataset.decimal === "true" ? "any" : "1";
         calculateTotal();
     };
 
@@ -399,6 +485,12 @@ document.addEventListener("DOMContentLoaded", () => {
         if (productSelect) {
             productSelect.addEventListener("change", () => handleProductSelectChange(productSelect));
         }
+
+        // [BARU] Satuan
+        const unitSelect = row.querySelector(".unit-select");
+        if (unitSelect) {
+            unitSelect.addEventListener("change", () => handleUnitSelectChange(unitSelect));
+        }
     };
 
     // [DIUBAH] Listener untuk semua toggle
@@ -457,6 +549,9 @@ document.addEventListener("DOMContentLoaded", () => {
                 const categoryIDRaw = formData.get("category_id"); // [BARU]
                 const categoryID = categoryIDRaw ? parseInt(categoryIDRaw, 10) : null; // [BARU]
 
+                const locationIDRaw = formData.get("location_id"); // [BARU]
+                const locationID = locationIDRaw ? parseInt(locationIDRaw, 10) : null; // [BARU]
+
                 const paymentStatus = formData.get("payment_status"); // [BARU]
                 const dueDate = formData.get("due_date"); // [BARU]
 
@@ -473,10 +568,11 @@ document.addEventListener("DOMContentLoaded", () => {
                     }
                     
                     const productName = customNameInput.value;
-                    const quantity = parseInt(row.querySelector('input[name="quantity"]').value, 10);
+                    const quantity = parseFloat(row.querySelector('input[name="quantity"]').value); // [DIUBAH] Boleh desimal sesuai satuan
+                    const unitSelect = row.querySelector(".unit-select"); // [BARU]
                     const unitPrice = parseFloat(row.querySelector('input[name="unit_price"]').value);
 
-                    if (!productName || quantity <= 0 || unitPrice < 0) {
+                    if (!productName || !(quantity > 0) || unitPrice < 0) {
                         throw new Error("Data item tidak valid. Pastikan semua nama, jumlah, dan harga terisi dengan benar.");
                     }
 
@@ -485,6 +581,7 @@ document.addEventListener("DOMContentLoaded", () => {
                         product_name: productName,
                         quantity: quantity,
                         unit_price: unitPrice,
+                        unit: unitSelect ? unitSelect.value : "", // [BARU] Kosong = satuan dasar
                     });
                 }
 
@@ -494,10 +591,13 @@ document.addEventListener("DOMContentLoaded", () => {
 
                 payload = {
                     type: type,
-                    customer_id: customerID, 
+                    // [DIUBAH] Pengeluaran memakai supplier_id, Pemasukan memakai customer_id
+                    customer_id: type === "INCOME" ? customerID : null,
+                    supplier_id: type === "EXPENSE" ? customerID : null,
                     notes: notes,
                     items: items,
                     category_id: categoryID, // [BARU]
+                    location_id: locationID, // [BARU]
                     payment_status: paymentStatus, // [BARU]
                     due_date: dueDate || null, // [BARU]
                 };
@@ -505,6 +605,12 @@ document.addEventListener("DOMContentLoaded", () => {
                 throw new Error("Tipe transaksi tidak dikenal.");
             }
             
+            // [BARU] Tanggal transaksi opsional (berlaku untuk semua tipe)
+            const transactionDate = formData.get("transaction_date");
+            if (transactionDate) {
+                payload.transaction_date = transactionDate;
+            }
+
             // Kirim ke API (Payload sudah benar)
             await fetchWithAuth("/api/v1/transactions", {
                 method: "POST",
@@ -536,7 +642,9 @@ document.addEventListener("DOMContentLoaded", () => {
             await Promise.all([
                 loadProducts(),
                 loadCustomers(),
-                loadCategories()
+                loadSuppliers(), // [BARU]
+                loadCategories(),
+                loadLocations() // [BARU]
             ]);
             
             // Setelah SEMUA data siap, baru render form
@@ -38,6 +38,7 @@ document.addEventListener("DOMContentLoaded", () => {
     // [DIUBAH] Ambil elemen filter dropdown baru
     const filterMonthEl = document.getElementById("filter-month");
     const filterYearEl = document.getElementById("filter-year");
+    const filterLocationEl = document.getElementById("filter-location"); // [BARU]
     
     // Ambil elemen Grafik
     const chartContext = document.getElementById("mainChart").getContext("2d");
@@ -53,6 +54,9 @@ document.addEventListener("DOMContentLoaded", () => {
     const lowStockAlertSection = document.getElementById("low-stock-alert-section");
     const lowStockListEl = document.getElementById("low-stock-list");
     // --- [AKHIR BARU] ---
+    // [BARU] Batch mendekati kedaluwarsa
+    const nearExpirySection = document.getElementById("near-expiry-section");
+    const nearExpiryListEl = document.getElementById("near-expiry-list");
 
 
     // --- 2. Fungsi Helper (Sangat Profesional) ---
@@ -164,7 +168,32 @@ document.addEventListener("DOMContentLoaded", () => {
         const fromQuery = `from=${startDate.toISOString()}`;
         const toQuery = `to=${endDate.toISOString()}`;
         
-        return `?${fromQuery}&${toQuery}`;
+        return `?${fromQuery}&${toQuery}${getLocationQuery("&")}`;
+    };
+
+    /**
+     * [BARU] Helper filter lokasi (kosong = semua lokasi)
+     * @param {string} prefix - "?" atau "&"
+     */
+    const getLocationQuery = (prefix) => {
+        return filterLocationEl.value ? `${prefix}location_id=${filterLocationEl.value}` : "";
+    };
+
+    /**
+     * [BARU] Mengisi dropdown lokasi dari API
+     */
+    const populateLocationFilter = async () => {
+        try {
+            const locations = await fetchWithAuth("/api/v1/locations");
+            locations.forEach((location) => {
+                const option = document.createElement("option");
+                option.value = location.id;
+                option.textContent = location.name;
+                filterLocationEl.appendChild(option);
+            });
+        } catch (error) {
+            console.error("Error loading locations:", error);
+        }
     };
 
     // --- 4. Memuat Data dari API ---
@@ -410,7 +439,7 @@ document.addEventListener("DOMContentLoaded", () => {
      */
     const loadLowStockAlerts = async () => {
         try {
-            const products = await fetchWithAuth("/api/v1/dashboard/low-stock");
+            const products = await fetchWithAuth(`/api/v1/dashboard/low-stock${getLocationQuery("?")}`); // [DIUBAH]
             
             // Jika tidak ada produk, sembunyikan seluruh bagian
             if (!products || products.length === 0) {
@@ -455,6 +484,50 @@ document.addEventListener("DOMContentLoaded", () => {
     };
     // --- [AKHIR BARU] ---
 
+    // --- [BARU] Batch Mendekati Kedaluwarsa ---
+    /**
+     * Memuat daftar batch yang kedaluwarsa dalam 30 hari (atau sudah lewat)
+     */
+    const loadNearExpiryBatches = async () => {
+        try {
+            const batches = await fetchWithAuth(`/api/v1/dashboard/near-expiry?days=30${getLocationQuery("&")}`); // [DIUBAH]
+
+            if (!batches || batches.length === 0) {
+                nearExpirySection.classList.add("hidden");
+                return;
+            }
+
+            nearExpirySection.classList.remove("hidden");
+            nearExpiryListEl.innerHTML = "";
+
+            batches.forEach(batch => {
+                const element = document.createElement("a");
+                element.className = "flex items-center justify-between p-4 bg-white rounded-xl card-shadow hover:bg-gray-50 transition-colors cursor-pointer";
+                element.href = `/edit-product.html?id=${batch.product_id}`;
+
+                const lot = batch.lot_number ? `Lot ${batch.lot_number} · ` : "";
+                const locationStock = batch.location_stock != null ? ` · Stok lokasi: ${batch.location_stock}` : ""; // [BARU]
+                const status = batch.is_expired
+                    ? `<span class="text-sm font-semibold text-red-600 flex-shrink-0 ml-2">Kedaluwarsa</span>`
+                    : `<span class="text-sm font-semibold text-orange-600 flex-shrink-0 ml-2">${batch.days_to_expiry} hari lagi</span>`;
+
+                element.innerHTML = `
+                    <div class="flex-1 min-w-0">
+                        <p class="text-base font-medium text-gray-900 truncate">${batch.product_name}</p>
+                        <p class="text-xs text-gray-500 mt-0.5">${lot}Exp: ${batch.expiry_date} · Sisa: ${batch.remaining_quantity}${locationStock}</p>
+                    </div>
+                    ${status}
+                `;
+                nearExpiryListEl.appendChild(element);
+            });
+
+        } catch (error) {
+            console.error("Error loading near expiry batches:", error);
+            nearExpirySection.classList.add("hidden");
+        }
+    };
+    // --- [AKHIR BARU] ---
+
 
     // --- 6. Fungsi Ekspor PDF ---
 
@@ -544,6 +617,12 @@ document.addEventListener("DOMContentLoaded", () => {
     // [DIUBAH] Event listener untuk filter dropdown
     filterMonthEl.addEventListener("change", refreshAllData);
     filterYearEl.addEventListener("change", refreshAllData);
+    // [BARU] Ganti lokasi juga memuat ulang peringatan stok menipis
+    filterLocationEl.addEventListener("change", () => {
+        refreshAllData();
+        loadLowStockAlerts();
+        loadNearExpiryBatches(); // [BARU]
+    });
 
     // Event Listener Logout
     logoutButton.addEventListener("click", () => {
@@ -559,8 +638,10 @@ document.addEventListener("DOMContentLoaded", () => {
     loadTransactions(); // Muat transaksi terakhir (tidak tergantung filter)
     // --- [BARU] ---
     loadLowStockAlerts(); // Muat peringatan stok (tidak tergantung filter)
+    loadNearExpiryBatches(); // [BARU] Muat batch mendekati kedaluwarsa
     // --- [AKHIR BARU] ---
     populateYearFilter(); // <-- Panggil fungsi baru
+    populateLocationFilter(); // [BARU]
     setDefaultFilters();  // <-- Panggil fungsi baru
     refreshAllData();     // <-- Panggil ini TERAKHIR untuk memuat data bulan ini
 });
\ No newline at end of file
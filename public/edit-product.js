@@ -64,6 +64,21 @@ document.addEventListener("DOMContentLoaded", () => {
         return response.json();
     };
 
+    /**
+     * [BARU] Memuat kategori pemasukan ke dropdown kategori produk
+     */
+    const loadCategoryOptions = async (selectedId) => {
+        const categorySelect = document.getElementById("category_id");
+        const categories = (await fetchWithAuth("/api/v1/categories")) || [];
+        categories.filter(cat => cat.type === "INCOME").forEach(cat => {
+            const option = document.createElement("option");
+            option.value = cat.id;
+            option.textContent = cat.name;
+            if (selectedId && cat.id === selectedId) option.selected = true;
+            categorySelect.appendChild(option);
+        });
+    };
+
     // --- 3. Memuat Data Produk Awal ---
 
     const loadProductData = async () => {
@@ -84,6 +99,9 @@ document.addEventListener("DOMContentLoaded", () => {
             // --- [BARU] ---
             // Isi nilai batas stok minimum yang sudah tersimpan
             document.getElementById("batas_stok_minimum").value = product.batas_stok_minimum || 0;
+            document.getElementById("tax_exempt").checked = !!product.tax_exempt; // [BARU]
+            document.getElementById("track_lots").checked = !!product.track_lots; // [BARU]
+            await loadCategoryOptions(product.category_id); // [BARU]
             // --- [AKHIR BARU] ---
 
             // Sembunyikan loading
@@ -122,6 +140,9 @@ document.addEventListener("DOMContentLoaded", () => {
                 // Kirim nilai baru batas stok minimum
                 batas_stok_minimum: parseInt(formData.get("batas_stok_minimum"), 10) || 0,
                 // --- [AKHIR BARU] ---
+                tax_exempt: formData.get("tax_exempt") === "on", // [BARU]
+                track_lots: formData.get("track_lots") === "on", // [BARU]
+                category_id: formData.get("category_id") ? parseInt(formData.get("category_id"), 10) : null, // [BARU]
             };
 
             if (!payload.name || payload.selling_price < 0 || payload.stock < 0) {
@@ -23,6 +23,7 @@ document.addEventListener("DOMContentLoaded", () => {
     // [BARU] Ambil elemen filter dropdown baru
     const filterMonthEl = document.getElementById("filter-month");
     const filterYearEl = document.getElementById("filter-year");
+    const filterBasisEl = document.getElementById("filter-basis"); // [BARU]
 
     // Elemen Ringkasan
     const summarySkeleton = document.getElementById("summary-skeleton");
@@ -149,7 +150,9 @@ document.addEventListener("DOMContentLoaded", () => {
         const fromQuery = `from=${startDate.toISOString()}`;
         const toQuery = `to=${endDate.toISOString()}`;
         
-        return `?${fromQuery}&${toQuery}`;
+        const basisQuery = `basis=${filterBasisEl.value}`; // [BARU] accrual atau cash
+        
+        return `?${fromQuery}&${toQuery}&${basisQuery}`;
     };
 
     // --- 4. Memuat Data Laporan ---
@@ -384,6 +387,7 @@ document.addEventListener("DOMContentLoaded", () => {
     // [DIUBAH] Event listener untuk filter dropdown
     filterMonthEl.addEventListener("change", loadLedgerReport);
     filterYearEl.addEventListener("change", loadLedgerReport);
+    filterBasisEl.addEventListener("change", loadLedgerReport); // [BARU]
 
     // Event listener untuk tombol download PDF
     downloadPdfButton.addEventListener("click", exportToPDF);
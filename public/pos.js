@@ -15,6 +15,7 @@ document.addEventListener("DOMContentLoaded", () => {
     // Variabel global untuk menyimpan data
     let userProducts = [];
     let userCustomers = [];
+    let defaultTaxRate = null; // [BARU] Tarif PPN default (harga jual dianggap sudah termasuk PPN)
     let cartItems = []; // Keranjang belanja
     let debounceTimer;
 
@@ -30,6 +31,12 @@ document.addEventListener("DOMContentLoaded", () => {
     const cartItemsList = document.getElementById("cart-items-list");
     const emptyCartMessage = document.getElementById("empty-cart-message");
     const cartTotalAmount = document.getElementById("cartTotalAmount");
+    const cartSubtotalAmount = document.getElementById("cartSubtotalAmount"); // [BARU]
+    const cartDiscountAmount = document.getElementById("cartDiscountAmount"); // [BARU]
+    const discountTypeSelect = document.getElementById("pos_discount_type"); // [BARU]
+    const discountValueInput = document.getElementById("pos_discount_value"); // [BARU]
+    const cartPromotionList = document.getElementById("cartPromotionList"); // [BARU]
+    let previewTimer; // [BARU] Debounce pratinjau harga
     const completeSaleButton = document.getElementById("completeSaleButton");
     
     // Form Pembayaran
@@ -111,7 +118,7 @@ document.addEventListener("DOMContentLoaded", () => {
     // Memuat semua produk
     const loadProducts = async () => {
         try {
-            userProducts = (await fetchWithAuth("/api/v1/products")) || [];
+            userProducts = (await fetchWithAuth("/api/v1/products?sellable=true")) || []; // [DIUBAH] Varian dijual per varian, produk induk tidak ditampilkan
             renderProductGrid(userProducts);
         } catch (error) {
             console.error("Gagal memuat produk:", error);
@@ -136,6 +143,16 @@ document.addEventListener("DOMContentLoaded", () => {
         }
     };
 
+    // [BARU] Memuat tarif pajak default
+    const loadTaxRates = async () => {
+        try {
+            const taxRates = (await fetchWithAuth("/api/v1/tax-rates")) || [];
+            defaultTaxRate = taxRates.find(rate => rate.is_default) || null;
+        } catch (error) {
+            console.error("Gagal memuat tarif pajak:", error);
+        }
+    };
+
     // --- 5. Fungsi Tampilan (Renderers) ---
 
     // Merender kartu-kartu produk di grid
@@ -175,6 +192,17 @@ document.addEventListener("DOMContentLoaded", () => {
         });
     };
 
+    /**
+     * [BARU] Menghitung nominal diskon (sama dengan perhitungan di backend)
+     */
+    const calculateDiscount = (type, value, base) => {
+        if (!value || value <= 0) return 0;
+        if (type === "PERCENT") {
+            return Math.round(base * Math.min(value, 100)) / 100;
+        }
+        return Math.min(value, base);
+    };
+
     /**
      * [DIUBAH] Merender ulang tampilan keranjang dan total
      */
@@ -197,13 +225,26 @@ document.addEventListener("DOMContentLoaded", () => {
             emptyCartMessage.classList.add("hidden");
             
             cartItems.forEach(item => {
-                total += item.price * item.quantity;
+                // [BARU] Diskon per item
+                const lineGross = item.price * item.quantity;
+                const lineDiscount = calculateDiscount(item.discountType, item.discountValue, lineGross);
+                total += lineGross - lineDiscount;
                 const itemElement = document.createElement("div");
                 itemElement.className = "flex items-center space-x-3 py-3 border-b border-gray-100";
                 itemElement.innerHTML = `
                     <div class="flex-1 min-w-0">
                         <p class="font-medium text-gray-800 truncate">${item.name}</p>
                         <p class="text-sm text-gray-500">${formatCurrency(item.price)}</p>
+                        <!-- [BARU] Diskon item -->
+                        <div class="mt-1 flex items-center space-x-1">
+                            <select data-id="${item.id}" class="cart-item-discount-type text-xs px-1 py-0.5 bg-gray-50 border border-gray-300 rounded">
+                                <option value="PERCENT" ${item.discountType === "PERCENT" ? "selected" : ""}>%</option>
+                                <option value="NOMINAL" ${item.discountType === "NOMINAL" ? "selected" : ""}>Rp</option>
+                            </select>
+                            <input type="number" min="0" step="any" placeholder="Diskon" data-id="${item.id}" value="${item.discountValue || ""}"
+                                class="cart-item-discount-value w-20 text-xs px-2 py-0.5 bg-gray-50 border border-gray-300 rounded">
+                            ${lineDiscount > 0 ? `<span class="text-xs text-red-600">- ${formatCurrency(lineDiscount)}</span>` : ""}
+                        </div>
                     </div>
                     <!-- Tombol +/- -->
                     <div class="flex items-center space-x-2">
@@ -224,6 +265,13 @@ document.addEventListener("DOMContentLoaded", () => {
             });
         }
         
+        // [BARU] Diskon level transaksi
+        const subtotal = total;
+        const transactionDiscount = calculateDiscount(discountTypeSelect.value, parseFloat(discountValueInput.value) || 0, subtotal);
+        total = subtotal - transactionDiscount;
+        cartSubtotalAmount.textContent = formatCurrency(subtotal);
+        cartDiscountAmount.textContent = `- ${formatCurrency(transactionDiscount)}`;
+
         // Update Total
         cartTotalAmount.textContent = formatCurrency(total);
     
@@ -233,6 +281,9 @@ document.addEventListener("DOMContentLoaded", () => {
         // Update desktop summary
         cartItemCountDesktop.textContent = `${totalItemCount} Item`; // <-- DIPERBAIKI
 
+        // [BARU] Perbarui total dengan promo otomatis dari server
+        schedulePricePreview();
+
         // Atur tombol Selesaikan Penjualan
         if (totalItemCount > 0) { // <-- DIPERBAIKI
             completeSaleButton.disabled = false;
@@ -241,6 +292,64 @@ document.addEventListener("DOMContentLoaded", () => {
         }
     };
 
+    /**
+     * [BARU] Membuat payload harga keranjang (dipakai untuk pratinjau harga & simpan transaksi)
+     */
+    const buildPricingPayload = () => {
+        const transactionDiscountValue = parseFloat(discountValueInput.value) || 0;
+        return {
+            items: cartItems.map(item => ({
+                product_id: item.id,
+                product_name: item.name,
+                quantity: item.quantity,
+                unit_price: item.price,
+                tax_inclusive: true, // [BARU] Harga jual di POS sudah termasuk PPN
+                discount_type: item.discountValue > 0 ? item.discountType : "", // [BARU]
+                discount_value: item.discountValue || 0 // [BARU]
+            })),
+            tax_rate_id: defaultTaxRate ? defaultTaxRate.id : null, // [BARU]
+            discount_type: transactionDiscountValue > 0 ? discountTypeSelect.value : "", // [BARU]
+            discount_value: transactionDiscountValue // [BARU]
+        };
+    };
+
+    /**
+     * [BARU] Meminta pratinjau harga ke server (diskon + promo otomatis + pajak)
+     */
+    const refreshPricePreview = async () => {
+        if (cartItems.length === 0) {
+            cartPromotionList.innerHTML = "";
+            return;
+        }
+        try {
+            const preview = await fetchWithAuth("/api/v1/promotions/preview", {
+                method: "POST",
+                body: JSON.stringify(buildPricingPayload())
+            });
+            if (!preview) return;
+
+            cartSubtotalAmount.textContent = formatCurrency(preview.gross_amount - preview.item_discount);
+            cartDiscountAmount.textContent = `- ${formatCurrency(preview.discount_amount + preview.promotion_amount)}`;
+            cartTotalAmount.textContent = formatCurrency(preview.total_amount);
+            mobileCartTotal.textContent = formatCurrency(preview.total_amount);
+            cartPromotionList.innerHTML = preview.promotions.map(promo => `
+                <div class="flex justify-between text-green-700">
+                    <span>${promo.name}</span>
+                    <span>- ${formatCurrency(promo.discount_amount)}</span>
+                </div>
+            `).join("");
+        } catch (error) {
+            // Pratinjau gagal (cth: diskon tidak valid), tampilkan total hitungan lokal saja
+            console.error("Gagal memuat pratinjau harga:", error);
+            cartPromotionList.innerHTML = "";
+        }
+    };
+
+    const schedulePricePreview = () => {
+        clearTimeout(previewTimer);
+        previewTimer = setTimeout(refreshPricePreview, 300);
+    };
+
     // --- 6. Logika Keranjang (Cart) ---
 
     // Menambah produk ke keranjang
@@ -266,7 +375,9 @@ document.addEventListener("DOMContentLoaded", () => {
                 id: product.id,
                 name: product.name,
                 price: product.selling_price,
-                quantity: 1
+                quantity: 1,
+                discountType: "PERCENT", // [BARU]
+                discountValue: 0 // [BARU]
             });
         }
         renderCart();
@@ -305,6 +416,7 @@ document.addEventListener("DOMContentLoaded", () => {
     // Mengosongkan keranjang
     const clearCart = () => {
         cartItems = [];
+        discountValueInput.value = ""; // [BARU]
         renderCart();
     };
 
@@ -331,19 +443,13 @@ document.addEventListener("DOMContentLoaded", () => {
         }
         
         // Ubah format keranjang menjadi format API
-        const payloadItems = cartItems.map(item => ({
-            product_id: item.id,
-            product_name: item.name,
-            quantity: item.quantity,
-            unit_price: item.price
-        }));
-
+        // [DIUBAH] Item, pajak & diskon diambil dari buildPricingPayload (sama dengan pratinjau harga)
         const payload = {
             type: "INCOME",
             customer_id: customerID,
             payment_status: paymentStatus,
-            items: payloadItems,
-            notes: "Penjualan via POS"
+            notes: "Penjualan via POS",
+            ...buildPricingPayload()
             // due_date bisa ditambahkan di sini jika status "BELUM LUNAS"
         };
         
@@ -421,6 +527,25 @@ document.addEventListener("DOMContentLoaded", () => {
         }
     });
 
+    // [BARU] Mengubah diskon item (pakai 'change' agar fokus input tidak hilang saat mengetik)
+    cartItemsList.addEventListener("change", (e) => {
+        const target = e.target;
+        const itemInCart = cartItems.find(item => item.id === parseInt(target.dataset.id, 10));
+        if (!itemInCart) return;
+
+        if (target.classList.contains("cart-item-discount-type")) {
+            itemInCart.discountType = target.value;
+        }
+        if (target.classList.contains("cart-item-discount-value")) {
+            itemInCart.discountValue = Math.max(parseFloat(target.value) || 0, 0);
+        }
+        renderCart();
+    });
+
+    // [BARU] Diskon transaksi
+    discountTypeSelect.addEventListener("change", renderCart);
+    discountValueInput.addEventListener("input", renderCart);
+
     // Tombol Selesaikan Penjualan
     completeSaleButton.addEventListener("click", completeSale);
 
@@ -445,7 +570,8 @@ document.addEventListener("DOMContentLoaded", () => {
         // Muat produk dan pelanggan secara bersamaan
         await Promise.all([
             loadProducts(),
-            loadCustomers()
+            loadCustomers(),
+            loadTaxRates() // [BARU]
         ]);
         // Sembunyikan skeleton grid (dilakukan di dalam renderProductGrid)
     };
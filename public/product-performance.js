@@ -188,6 +188,7 @@ document.addEventListener("DOMContentLoaded", () => {
                         <div class="flex-shrink-0 ml-4 text-right">
                             <p class="text-lg font-bold text-green-600">${formatCurrency(item.total_revenue)}</p>
                             <p class="text-sm text-gray-500">Pendapatan</p>
+                            ${item.total_discount > 0 ? `<p class="text-xs text-gray-500">Kotor ${formatCurrency(item.gross_sales)} &middot; Diskon <span class="text-red-600">${formatCurrency(item.total_discount)}</span></p>` : ""}
                         </div>
                     </div>
                 `;
@@ -112,7 +112,7 @@ document.addEventListener("DOMContentLoaded", () => {
                     </div>
                     <div class="flex-1 ml-4 min-w-0"> <!-- min-w-0 untuk truncate -->
                         <p class="text-base font-medium text-gray-900 truncate">${product.name}</p>
-                        <p class="text-xs text-gray-500 mt-0.5 truncate">${product.sku || 'Tanpa SKU'}</p>
+                        <p class="text-xs text-gray-500 mt-0.5 truncate">${product.variants && product.variants.length > 0 ? `${product.variants.length} varian` : (product.sku || 'Tanpa SKU')}</p>
                     </div>
                     <div class="text-right flex-shrink-0 ml-2">
                         <p class="text-base font-semibold text-gray-900">${formatCurrency(product.selling_price)}</p>
@@ -125,6 +125,27 @@ document.addEventListener("DOMContentLoaded", () => {
                     </div>
                 `;
                 productListEl.appendChild(productElement);
+
+                // [BARU] Varian ditampilkan di bawah produk induknya
+                (product.variants || []).forEach(variant => {
+                    const attributes = (variant.attributes || []).map(attr => `${attr.name}: ${attr.value}`).join(", ");
+                    const variantElement = document.createElement("a");
+                    variantElement.className = "flex items-center ml-8 px-4 py-2.5 bg-white rounded-lg card-shadow hover:bg-gray-50 transition-colors cursor-pointer";
+                    variantElement.href = `/edit-product.html?id=${variant.id}`;
+                    variantElement.innerHTML = `
+                        <div class="flex-1 min-w-0">
+                            <p class="text-sm font-medium text-gray-800 truncate">${attributes || variant.name}</p>
+                            <p class="text-xs text-gray-500 mt-0.5 truncate">${variant.sku || 'Tanpa SKU'}</p>
+                        </div>
+                        <div class="text-right flex-shrink-0 ml-2">
+                            <p class="text-sm font-semibold text-gray-900">${formatCurrency(variant.selling_price)}</p>
+                            <p class="text-xs font-medium ${variant.stock <= 0 ? 'text-red-500' : 'text-gray-500'}">
+                                Stok: ${variant.stock}
+                            </p>
+                        </div>
+                    `;
+                    productListEl.appendChild(variantElement);
+                });
             });
 
         } catch (error) {
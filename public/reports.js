@@ -156,6 +156,14 @@ document.addEventListener("DOMContentLoaded", () => {
                 }
                 // --- [AKHIR BARU] ---
 
+                // --- [BARU] Tandai transaksi yang dibatalkan / diretur ---
+                if (tx.status === 'VOID') {
+                    statusHtml = `<div class="mt-1 flex items-center flex-wrap gap-1"><span class="text-xs font-medium text-gray-600 bg-gray-200 px-2 py-0.5 rounded-full">Dibatalkan</span></div>` + statusHtml;
+                } else if (tx.status === 'REFUNDED') {
+                    statusHtml = `<div class="mt-1 flex items-center flex-wrap gap-1"><span class="text-xs font-medium text-orange-700 bg-orange-100 px-2 py-0.5 rounded-full">Retur ${formatCurrency(tx.refunded_amount)}</span></div>` + statusHtml;
+                }
+                // --- [AKHIR BARU] ---
+
 
                 const txElement = document.createElement("div");
                 txElement.className = "flex items-center p-4 bg-white rounded-xl card-shadow";
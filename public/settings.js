@@ -27,6 +27,12 @@ document.addEventListener("DOMContentLoaded", () => {
     const saveProfileButton = document.getElementById("saveProfileButton");
     const savePasswordButton = document.getElementById("savePasswordButton");
 
+    // [BARU] Elemen metode penilaian persediaan
+    const costingForm = document.getElementById("costingForm");
+    const costingMethodSelect = document.getElementById("costing_method");
+    const costingMessageEl = document.getElementById("costingMessage");
+    const saveCostingButton = document.getElementById("saveCostingButton");
+
     // --- 2. Fungsi Helper ---
 
     /**
@@ -82,6 +88,7 @@ document.addEventListener("DOMContentLoaded", () => {
             fullNameInput.value = data.user.full_name;
             emailInput.value = data.user.email;
             usernameInput.value = data.user.username;
+            costingMethodSelect.value = data.user.costing_method || "AVERAGE"; // [BARU]
 
         } catch (error) {
             console.error("Gagal memuat profil:", error);
@@ -162,6 +169,27 @@ document.addEventListener("DOMContentLoaded", () => {
         }
     });
 
+    // [BARU] Handle "Simpan Metode" penilaian persediaan
+    costingForm.addEventListener("submit", async (event) => {
+        event.preventDefault();
+        saveCostingButton.disabled = true;
+        saveCostingButton.textContent = "Menyimpan...";
+        costingMessageEl.classList.add("hidden");
+
+        try {
+            await fetchWithAuth("/api/v1/settings/costing-method", {
+                method: "PUT",
+                body: JSON.stringify({ costing_method: costingMethodSelect.value }),
+            });
+            showMessage(costingMessageEl, "Metode penilaian persediaan berhasil diperbarui!", true);
+        } catch (error) {
+            showMessage(costingMessageEl, `Error: ${error.message}`, false);
+        } finally {
+            saveCostingButton.disabled = false;
+            saveCostingButton.textContent = "Simpan Metode";
+        }
+    });
+
     // Handle Logout
     logoutButton.addEventListener("click", () => {
         localStorage.removeItem("goBisnisToken");
@@ -149,10 +149,15 @@ document.addEventListener("DOMContentLoaded", () => {
                         <p class="text-base font-semibold text-gray-900 truncate">${item.primary_item}</p>
                         <p class="text-sm text-gray-500 truncate">${item.customer_name}</p>
                         <p class="text-xs text-gray-500 mt-1">${item.created_at}</p>
+                        ${item.paid_amount > 0 ? `<p class="text-xs text-gray-500">Dibayar ${formatCurrency(item.paid_amount)} dari ${formatCurrency(item.total_amount)}</p>` : ''}
                     </div>
                     <!-- [DIUBAH] Tambahkan tombol "Tandai Lunas" -->
                     <div class="text-right flex-shrink-0 ml-2 flex items-center space-x-2">
                         <p class="text-lg font-bold ${amountClass}">${formatCurrency(item.amount)}</p>
+                        <!-- [BARU] Tombol bayar cicilan -->
+                        <button title="Bayar Cicilan" data-id="${item.transaction_id}" data-amount="${item.amount}" class="add-payment-button p-2 text-blue-500 hover:bg-blue-100 rounded-full transition-colors">
+                            <svg xmlns="http://www.w3.org/2000/svg" width="20" height="20" viewBox="0 0 24 24" fill="none" stroke="currentColor" stroke-width="2.5" stroke-linecap="round" stroke-linejoin="round" class="lucide lucide-plus pointer-events-none"><path d="M5 12h14"/><path d="M12 5v14"/></svg>
+                        </button>
                         <!-- [PERBAIKAN DI SINI] Gunakan 'transaction_id' (huruf kecil) -->
                         <button title="Tandai Lunas" data-id="${item.transaction_id}" class="mark-paid-button p-2 text-green-500 hover:bg-green-100 rounded-full transition-colors">
                             <svg xmlns="http://www.w3.org/2000/svg" width="20" height="20" viewBox="0 0 24 24" fill="none" stroke="currentColor" stroke-width="2.5" stroke-linecap="round" stroke-linejoin="round" class="lucide lucide-check pointer-events-none"><path d="M20 6 9 17l-5-5"/></svg>
@@ -244,11 +249,48 @@ document.addEventListener("DOMContentLoaded", () => {
     };
 
 
+    // --- [BARU] Logika untuk Pembayaran Cicilan ---
+
+    /**
+     * @param {Event} e - Event klik
+     */
+    const handleAddPayment = async (e) => {
+        const button = e.target.closest('.add-payment-button');
+        if (!button) return; // Klik bukan di tombol
+
+        const transactionID = button.dataset.id;
+        const input = prompt(`Jumlah cicilan (sisa: ${formatCurrency(button.dataset.amount)})`);
+        if (!input) return;
+
+        const amount = parseFloat(input.replace(/[^0-9.,]/g, "").replace(",", "."));
+        if (isNaN(amount) || amount <= 0) {
+            showToast("Jumlah cicilan tidak valid", false);
+            return;
+        }
+
+        button.disabled = true;
+        try {
+            await fetchWithAuth(`/api/v1/transactions/${transactionID}/payments`, {
+                method: "POST",
+                body: JSON.stringify({ amount: amount }),
+            });
+            showToast("Cicilan berhasil dicatat!", true);
+            loadUnpaidReport();
+        } catch (error) {
+            console.error("Gagal mencatat cicilan:", error);
+            showToast(`Gagal: ${error.message}`, false);
+            button.disabled = false;
+        }
+    };
+
+
     // --- 3. Jalankan Fungsi Load Awal & Event Listeners ---
 
     // [BARU] Tambahkan event listener di parent
     receivablesListEl.addEventListener("click", handleMarkPaid);
     payablesListEl.addEventListener("click", handleMarkPaid);
+    receivablesListEl.addEventListener("click", handleAddPayment);
+    payablesListEl.addEventListener("click", handleAddPayment);
 
     loadUnpaidReport();
 });
\ No newline at end of file